@@ -0,0 +1,46 @@
+// Command posts-render stores the rendered HTML, excerpt and reading time of
+// posts written before descriptions were rendered. Run it once after
+// upgrading; posts created or edited since are rendered when written.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/ynwd/awesome-blog/config"
+	"github.com/ynwd/awesome-blog/internal/posts/repo"
+	"github.com/ynwd/awesome-blog/pkg/database"
+)
+
+func main() {
+	if os.Getenv("ENV") != "production" {
+		if err := godotenv.Load(); err != nil {
+			log.Printf("Warning: Error loading .env file: %v", err)
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	ctx := context.Background()
+	firestoreDB := database.NewFirestore(cfg.GoogleCloud.ProjectID, cfg.GoogleCloud.FirestoreDB)
+	if err := firestoreDB.Connect(ctx); err != nil {
+		log.Fatalf("Failed to connect to Firestore: %v", err)
+	}
+	defer firestoreDB.Close()
+
+	client, err := firestoreDB.Client()
+	if err != nil {
+		log.Fatalf("Failed to get firestore client: %v", err)
+	}
+
+	updated, err := repo.BackfillRendered(ctx, client)
+	if err != nil {
+		log.Fatalf("Failed to render posts: %v", err)
+	}
+	log.Printf("Rendered %d posts", updated)
+}
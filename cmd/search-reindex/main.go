@@ -0,0 +1,59 @@
+// Command search-reindex rebuilds the search index from the posts and comments
+// collections and writes it to the snapshot at SEARCH_INDEX_PATH. Replicas
+// read the snapshot when they start; a running replica can be rebuilt in place
+// with POST /admin/search/reindex instead.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/ynwd/awesome-blog/config"
+	"github.com/ynwd/awesome-blog/internal/search/index"
+	"github.com/ynwd/awesome-blog/internal/search/repo"
+	"github.com/ynwd/awesome-blog/internal/search/service"
+	"github.com/ynwd/awesome-blog/pkg/database"
+)
+
+func main() {
+	if os.Getenv("ENV") != "production" {
+		if err := godotenv.Load(); err != nil {
+			log.Printf("Warning: Error loading .env file: %v", err)
+		}
+	}
+
+	searchConfig := service.DefaultSearchConfig()
+	searchConfig.SnapshotPath = os.Getenv("SEARCH_INDEX_PATH")
+	if searchConfig.SnapshotPath == "" {
+		log.Fatal("SEARCH_INDEX_PATH must be set")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	ctx := context.Background()
+	firestoreDB := database.NewFirestore(cfg.GoogleCloud.ProjectID, cfg.GoogleCloud.FirestoreDB)
+	if err := firestoreDB.Connect(ctx); err != nil {
+		log.Fatalf("Failed to connect to Firestore: %v", err)
+	}
+	defer firestoreDB.Close()
+
+	client, err := firestoreDB.Client()
+	if err != nil {
+		log.Fatalf("Failed to get firestore client: %v", err)
+	}
+
+	search := service.NewSearchService(index.New(), repo.NewSourceRepository(client), searchConfig)
+	indexed, err := search.Reindex(ctx)
+	if err != nil {
+		log.Fatalf("Failed to rebuild search index: %v", err)
+	}
+	if err := search.SaveSnapshot(); err != nil {
+		log.Fatalf("Failed to save search snapshot: %v", err)
+	}
+	log.Printf("Indexed %d documents into %s", indexed, searchConfig.SnapshotPath)
+}
@@ -0,0 +1,46 @@
+// Command summary-rebuild recomputes the summary activity counters from the
+// posts, comments and likes collections. Stop the event subscribers first, as
+// events consumed during a rebuild may be lost or counted twice.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/ynwd/awesome-blog/config"
+	"github.com/ynwd/awesome-blog/internal/summary/repo"
+	"github.com/ynwd/awesome-blog/pkg/database"
+)
+
+func main() {
+	if os.Getenv("ENV") != "production" {
+		if err := godotenv.Load(); err != nil {
+			log.Printf("Warning: Error loading .env file: %v", err)
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	ctx := context.Background()
+	firestoreDB := database.NewFirestore(cfg.GoogleCloud.ProjectID, cfg.GoogleCloud.FirestoreDB)
+	if err := firestoreDB.Connect(ctx); err != nil {
+		log.Fatalf("Failed to connect to Firestore: %v", err)
+	}
+	defer firestoreDB.Close()
+
+	client, err := firestoreDB.Client()
+	if err != nil {
+		log.Fatalf("Failed to get firestore client: %v", err)
+	}
+
+	written, err := repo.NewCounterRepository(client).Rebuild(ctx)
+	if err != nil {
+		log.Fatalf("Failed to rebuild activity counters: %v", err)
+	}
+	log.Printf("Rebuilt %d activity counters", written)
+}
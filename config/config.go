@@ -33,6 +33,7 @@ type FirestoreCollections struct {
 type PubSubConfig struct {
 	Topic        string `json:"topic"`
 	Subscription string `json:"subscription"`
+	Driver       string `json:"driver"`
 }
 
 func Load() (*Config, error) {
@@ -53,10 +54,15 @@ func Load() (*Config, error) {
 			PubSub: PubSubConfig{
 				Topic:        os.Getenv("GOOGLE_CLOUD_PUBSUB_TOPIC"),
 				Subscription: os.Getenv("GOOGLE_CLOUD_PUBSUB_SUBSCRIPTION"),
+				Driver:       os.Getenv("PUBSUB_DRIVER"),
 			},
 		},
 	}
 
+	if config.GoogleCloud.PubSub.Driver == "" {
+		config.GoogleCloud.PubSub.Driver = "gcp"
+	}
+
 	return config, validate(config)
 }
 
@@ -91,5 +97,8 @@ func validate(c *Config) error {
 	if c.GoogleCloud.PubSub.Subscription == "" {
 		return fmt.Errorf("GOOGLE_CLOUD_PUBSUB_SUBSCRIPTION is required")
 	}
+	if c.GoogleCloud.PubSub.Driver != "gcp" && c.GoogleCloud.PubSub.Driver != "memory" {
+		return fmt.Errorf("PUBSUB_DRIVER must be gcp or memory")
+	}
 	return nil
 }
@@ -4,12 +4,21 @@ import (
 	"context"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ynwd/awesome-blog/config"
+	"github.com/ynwd/awesome-blog/internal/deadletter"
+	"github.com/ynwd/awesome-blog/internal/operations"
+	"github.com/ynwd/awesome-blog/internal/search"
+	"github.com/ynwd/awesome-blog/internal/webhooks"
 	"github.com/ynwd/awesome-blog/pkg/database"
+	"github.com/ynwd/awesome-blog/pkg/middleware"
 	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/outbox"
 	"github.com/ynwd/awesome-blog/pkg/pubsub"
+	"github.com/ynwd/awesome-blog/pkg/utils"
 )
 
 type App struct {
@@ -17,11 +26,23 @@ type App struct {
 	router      *gin.Engine
 	firestoreDB *database.FirestoreDB
 	pubsub      pubsub.PubSubClient
+	blacklist   utils.TokenBlacklist
+	processed   utils.ProcessedEvents
+	operations  module.OperationTracker
+	outbox      outbox.Store
+	jwt         utils.JWT
 	modules     []module.Module
+	webhooks    *webhooks.Module
+	search      *search.Module
+	events      *module.EventRegistry
+
+	// replicaEvents are run by every replica for every event
+	replicaEvents *module.EventRegistry
+	cancel        context.CancelFunc
 }
 
 func NewApp(cfg *config.Config) *App {
-	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
 	firestoreDB := database.NewFirestore(
 		cfg.GoogleCloud.ProjectID,
 		cfg.GoogleCloud.FirestoreDB,
@@ -31,20 +52,47 @@ func NewApp(cfg *config.Config) *App {
 		log.Fatalf("Failed to connect to Firestore: %v", err)
 	}
 
-	// Initialize PubSub
-	pubsubClient, err := pubsub.NewPubSubClient(
-		cfg.GoogleCloud.ProjectID,
-		os.Getenv("GOOGLE_CLOUD_PUBSUB_TOPIC"),
+	client, err := firestoreDB.Client()
+	if err != nil {
+		log.Fatalf("Failed to get firestore client: %v", err)
+	}
+
+	// Initialize the tracker that reports the status of asynchronous writes
+	tracker := operations.NewTracker(client)
+
+	// Initialize PubSub, dead-lettering events that keep failing
+	pubsubClient, err := newPubSubClient(cfg,
+		pubsub.WithRetryPolicy(retryPolicy()),
+		pubsub.WithDeadLetterSink(deadletter.NewSink(client, tracker)),
+		pubsub.WithDeadLetterTopic(os.Getenv("PUBSUB_DEAD_LETTER_TOPIC")),
 	)
 	if err != nil {
 		log.Fatalf("Failed to create pubsub client: %v", err)
 	}
 
+	// Initialize the token blacklist shared by the middleware and the users module
+	blacklist := utils.NewFirestoreBlacklist(client)
+	jwt, err := utils.NewJWT(blacklist)
+	if err != nil {
+		log.Fatalf("Failed to create JWT: %v", err)
+	}
+
+	// Initialize the store the event handlers use to skip redelivered events
+	processed := utils.NewFirestoreProcessedEvents(client, utils.ProcessedEventsTTL())
+
 	app := &App{
-		config:      cfg,
-		router:      gin.Default(),
-		firestoreDB: firestoreDB,
-		pubsub:      pubsubClient,
+		config:        cfg,
+		router:        newRouter(),
+		firestoreDB:   firestoreDB,
+		pubsub:        pubsubClient,
+		blacklist:     blacklist,
+		processed:     processed,
+		operations:    tracker,
+		outbox:        outbox.New(client),
+		events:        module.NewEventRegistry(),
+		replicaEvents: module.NewReplicaEventRegistry(),
+		jwt:           jwt,
+		cancel:        cancel,
 	}
 
 	// Setup middleware
@@ -55,9 +103,47 @@ func NewApp(cfg *config.Config) *App {
 
 	// Subscribe to PubSub
 	app.pubSubSubsribe(ctx)
+
+	// Start background jobs
+	app.startBlacklistCleanup(ctx)
+	app.startProcessedEventsCleanup(ctx)
+	app.startOutboxRelay(ctx)
+	app.startWebhookDelivery(ctx)
+	app.startSearchIndex(ctx)
 	return app
 }
 
+// newPubSubClient creates the PubSubClient selected by PUBSUB_DRIVER. The
+// memory driver delivers in-process, optionally after PUBSUB_DELIVERY_DELAY.
+func newPubSubClient(cfg *config.Config, opts ...pubsub.Option) (pubsub.PubSubClient, error) {
+	if cfg.GoogleCloud.PubSub.Driver == pubsub.DriverMemory {
+		delay, _ := time.ParseDuration(os.Getenv("PUBSUB_DELIVERY_DELAY"))
+		return pubsub.NewMemoryClient(append(opts, pubsub.WithDeliveryDelay(delay))...), nil
+	}
+
+	return pubsub.NewPubSubClient(
+		cfg.GoogleCloud.ProjectID,
+		os.Getenv("GOOGLE_CLOUD_PUBSUB_TOPIC"),
+		opts...,
+	)
+}
+
+// retryPolicy reads PUBSUB_MAX_ATTEMPTS, PUBSUB_MIN_BACKOFF and
+// PUBSUB_MAX_BACKOFF, keeping the defaults for values that are unset or invalid
+func retryPolicy() pubsub.RetryPolicy {
+	policy := pubsub.DefaultRetryPolicy()
+	if attempts, err := strconv.Atoi(os.Getenv("PUBSUB_MAX_ATTEMPTS")); err == nil && attempts > 0 {
+		policy.MaxAttempts = attempts
+	}
+	if backoff, err := time.ParseDuration(os.Getenv("PUBSUB_MIN_BACKOFF")); err == nil && backoff > 0 {
+		policy.MinBackoff = backoff
+	}
+	if backoff, err := time.ParseDuration(os.Getenv("PUBSUB_MAX_BACKOFF")); err == nil && backoff >= policy.MinBackoff {
+		policy.MaxBackoff = backoff
+	}
+	return policy
+}
+
 func (a *App) Router() *gin.Engine {
 	return a.router
 }
@@ -67,5 +153,18 @@ func (a *App) Start() error {
 }
 
 func (a *App) Close() error {
+	a.cancel()
+	a.pubsub.Close()
+	if err := a.search.Service().SaveSnapshot(); err != nil {
+		log.Printf("Error saving search snapshot: %v", err)
+	}
 	return a.firestoreDB.Close()
 }
+
+// newRouter returns a router with gin's default middleware, logging requests
+// without the tokens stream endpoints accept in the query
+func newRouter() *gin.Engine {
+	router := gin.New()
+	router.Use(middleware.Logger(), gin.Recovery())
+	return router
+}
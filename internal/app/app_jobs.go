@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	defaultBlacklistCleanupInterval       = time.Hour
+	defaultProcessedEventsCleanupInterval = time.Hour
+)
+
+// startBlacklistCleanup periodically removes expired entries from the shared
+// token blacklist until ctx is cancelled
+func (a *App) startBlacklistCleanup(ctx context.Context) {
+	interval := envDuration("TOKEN_BLACKLIST_CLEANUP_INTERVAL", defaultBlacklistCleanupInterval)
+	go runPeriodically(ctx, interval, func() {
+		if err := a.blacklist.Cleanup(); err != nil {
+			log.Printf("Error cleaning up token blacklist: %v", err)
+		}
+	})
+}
+
+// startProcessedEventsCleanup periodically removes expired records of
+// processed events until ctx is cancelled
+func (a *App) startProcessedEventsCleanup(ctx context.Context) {
+	interval := envDuration("PROCESSED_EVENTS_CLEANUP_INTERVAL", defaultProcessedEventsCleanupInterval)
+	go runPeriodically(ctx, interval, func() {
+		if err := a.processed.Cleanup(); err != nil {
+			log.Printf("Error cleaning up processed events: %v", err)
+		}
+	})
+}
+
+// envDuration reads a positive duration from the environment variable,
+// falling back to def
+func envDuration(key string, def time.Duration) time.Duration {
+	interval, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || interval <= 0 {
+		return def
+	}
+	return interval
+}
+
+func runPeriodically(ctx context.Context, interval time.Duration, job func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job()
+		}
+	}
+}
@@ -1,13 +1,23 @@
 package app
 
 import (
+	"expvar"
 	"log"
 
+	"github.com/gin-gonic/gin"
 	"github.com/ynwd/awesome-blog/internal/comments"
+	"github.com/ynwd/awesome-blog/internal/deadletter"
+	"github.com/ynwd/awesome-blog/internal/follows"
 	"github.com/ynwd/awesome-blog/internal/likes"
+	"github.com/ynwd/awesome-blog/internal/notifications"
+	"github.com/ynwd/awesome-blog/internal/operations"
 	"github.com/ynwd/awesome-blog/internal/posts"
+	"github.com/ynwd/awesome-blog/internal/search"
+	"github.com/ynwd/awesome-blog/internal/stream"
 	"github.com/ynwd/awesome-blog/internal/summary"
 	"github.com/ynwd/awesome-blog/internal/users"
+	"github.com/ynwd/awesome-blog/internal/webhooks"
+	"github.com/ynwd/awesome-blog/pkg/middleware"
 	"github.com/ynwd/awesome-blog/pkg/module"
 )
 
@@ -17,17 +27,40 @@ func (a *App) setupModules() {
 	if err != nil {
 		log.Fatal("Failed to get firestore client:", err)
 	}
+	usersModule := users.NewModule(client, a.jwt)
+	followsModule := follows.NewModule(client, usersModule.UserLookup())
+	postsModule := posts.NewModule(client, a.pubsub, a.processed, a.operations, followsModule.FollowGraph())
+	commentsModule := comments.NewModule(client, a.pubsub, postsModule.PostLookup(), a.processed, a.operations)
+	likesModule := likes.NewModule(client, a.pubsub, postsModule.PostLookup(), a.processed, a.operations)
+	streamModule := stream.NewModule(postsModule.PostLookup(), likesModule.LikeCounter(), a.pubsub, streamConfig())
+	a.webhooks = webhooks.NewModule(client, a.processed, webhookConfig())
+	a.search = search.NewModule(client, searchConfig())
+
 	modules := []module.Module{
-		users.NewModule(client),
-		posts.NewModule(client, a.pubsub),
-		comments.NewModule(client, a.pubsub),
-		likes.NewModule(client, a.pubsub),
-		summary.NewModule(client),
+		usersModule,
+		followsModule,
+		postsModule,
+		commentsModule,
+		likesModule,
+		summary.NewModule(client, postsModule.PostLookup(), a.processed),
+		deadletter.NewModule(client, a.pubsub),
+		operations.NewModule(client),
+		a.webhooks,
+		streamModule,
+		a.search,
+		notifications.NewModule(client, postsModule.PostLookup(), commentsModule.CommentLookup(), streamModule.Notifier(), a.processed, coalesceWindow()),
 	}
 
 	for _, m := range modules {
 		m.RegisterRoutes(a.router)
+		m.RegisterEvents(a.events)
+		if replica, ok := m.(module.ReplicaModule); ok {
+			replica.RegisterReplicaEvents(a.replicaEvents)
+		}
 	}
 
 	a.modules = modules
+
+	// Runtime and outbox metrics
+	a.router.GET("/debug/vars", middleware.RequireAdmin(), gin.WrapH(expvar.Handler()))
 }
@@ -0,0 +1,13 @@
+package app
+
+import (
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/notifications/service"
+)
+
+// coalesceWindow reads NOTIFICATIONS_COALESCE_WINDOW, the time an unread
+// notification keeps collecting the same activity
+func coalesceWindow() time.Duration {
+	return envDuration("NOTIFICATIONS_COALESCE_WINDOW", service.DefaultCoalesceWindow)
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ynwd/awesome-blog/pkg/outbox"
+	"github.com/ynwd/awesome-blog/pkg/pubsub"
+)
+
+const (
+	defaultOutboxPollInterval = time.Second
+	defaultOutboxBatchSize    = 100
+	defaultOutboxRetention    = 24 * time.Hour
+	defaultOutboxMaxAttempts  = 10
+)
+
+// outboxMetrics are published under "outbox" in /debug/vars
+var outboxMetrics = struct {
+	pending    *expvar.Int
+	lagSeconds *expvar.Float
+	published  *expvar.Int
+	failures   *expvar.Int
+	parked     *expvar.Int
+}{
+	pending:    new(expvar.Int),
+	lagSeconds: new(expvar.Float),
+	published:  new(expvar.Int),
+	failures:   new(expvar.Int),
+	parked:     new(expvar.Int),
+}
+
+func init() {
+	metrics := expvar.NewMap("outbox")
+	metrics.Set("pending", outboxMetrics.pending)
+	metrics.Set("lag_seconds", outboxMetrics.lagSeconds)
+	metrics.Set("published_total", outboxMetrics.published)
+	metrics.Set("failures_total", outboxMetrics.failures)
+	metrics.Set("parked_total", outboxMetrics.parked)
+}
+
+// outboxRelay publishes the events staged in the outbox. A record is marked
+// sent only after it was published, so an event is published at least once
+// and may be published again if marking fails or two replicas relay the same
+// batch; consumers skip the duplicates by event ID.
+type outboxRelay struct {
+	store       outbox.Store
+	pubsub      pubsub.PubSubClient
+	batchSize   int
+	maxAttempts int
+}
+
+// relay publishes one batch of pending records, oldest first, and returns how
+// many were published. A record that fails to publish is left for the next
+// run and the rest of the batch goes on, so it does not hold back later
+// events. Once a record has failed maxAttempts times while others in its
+// batch were published, it is parked.
+func (r *outboxRelay) relay(ctx context.Context) (int, error) {
+	records, err := r.store.Pending(ctx, r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	r.recordLag(records)
+
+	published := 0
+	var (
+		failed []outbox.Record
+		errs   []error
+	)
+	for _, record := range records {
+		if err := r.pubsub.Publish(ctx, json.RawMessage(record.Data)); err != nil {
+			outboxMetrics.failures.Add(1)
+			record.Attempts++
+			if markErr := r.store.MarkFailed(ctx, record.ID, err); markErr != nil {
+				log.Printf("Error recording outbox failure for %s: %v", record.ID, markErr)
+			}
+			failed = append(failed, record)
+			errs = append(errs, fmt.Errorf("publishing %s: %w", record.ID, err))
+			continue
+		}
+
+		published++
+		outboxMetrics.published.Add(1)
+		if err := r.store.MarkSent(ctx, record.ID); err != nil {
+			return published, err
+		}
+	}
+
+	// When nothing was published the broker is likely down, and the records
+	// are not at fault
+	if published > 0 {
+		for _, record := range failed {
+			if record.Attempts < r.maxAttempts {
+				continue
+			}
+			if err := r.store.Park(ctx, record.ID); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			outboxMetrics.parked.Add(1)
+			log.Printf("Parked outbox record %s after %d failed attempts", record.ID, record.Attempts)
+		}
+	}
+	return published, errors.Join(errs...)
+}
+
+// recordLag reports the pending records of a batch and the age of the oldest.
+// A full batch means at least that many records are pending.
+func (r *outboxRelay) recordLag(records []outbox.Record) {
+	outboxMetrics.pending.Set(int64(len(records)))
+	if len(records) == 0 {
+		outboxMetrics.lagSeconds.Set(0)
+		return
+	}
+	outboxMetrics.lagSeconds.Set(time.Since(records[0].CreatedAt).Seconds())
+}
+
+// startOutboxRelay publishes the outbox every OUTBOX_POLL_INTERVAL and deletes
+// records sent more than OUTBOX_RETENTION ago every hour, until ctx is
+// cancelled
+func (a *App) startOutboxRelay(ctx context.Context) {
+	batchSize, err := strconv.Atoi(os.Getenv("OUTBOX_BATCH_SIZE"))
+	if err != nil || batchSize <= 0 {
+		batchSize = defaultOutboxBatchSize
+	}
+	maxAttempts, err := strconv.Atoi(os.Getenv("OUTBOX_MAX_ATTEMPTS"))
+	if err != nil || maxAttempts <= 0 {
+		maxAttempts = defaultOutboxMaxAttempts
+	}
+	relay := &outboxRelay{
+		store:       a.outbox,
+		pubsub:      a.pubsub,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+	}
+
+	interval := envDuration("OUTBOX_POLL_INTERVAL", defaultOutboxPollInterval)
+	go runPeriodically(ctx, interval, func() {
+		// Keep going while full batches are published, to drain a backlog
+		for {
+			published, err := relay.relay(ctx)
+			if err != nil {
+				log.Printf("Error relaying outbox: %v", err)
+				return
+			}
+			if published < relay.batchSize {
+				return
+			}
+		}
+	})
+
+	retention := envDuration("OUTBOX_RETENTION", defaultOutboxRetention)
+	go runPeriodically(ctx, time.Hour, func() {
+		if err := a.outbox.Cleanup(ctx, time.Now().Add(-retention)); err != nil {
+			log.Printf("Error cleaning up outbox: %v", err)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ynwd/awesome-blog/pkg/outbox"
+	"github.com/ynwd/awesome-blog/tests/helper"
+)
+
+type fakeOutbox struct {
+	records []outbox.Record
+	sent    []string
+	failed  map[string]string
+}
+
+func (s *fakeOutbox) Pending(ctx context.Context, limit int) ([]outbox.Record, error) {
+	var pending []outbox.Record
+	for _, record := range s.records {
+		if record.Status == outbox.StatusPending && len(pending) < limit {
+			pending = append(pending, record)
+		}
+	}
+	return pending, nil
+}
+
+func (s *fakeOutbox) MarkSent(ctx context.Context, id string) error {
+	for i := range s.records {
+		if s.records[i].ID == id {
+			s.records[i].Status = outbox.StatusSent
+		}
+	}
+	s.sent = append(s.sent, id)
+	return nil
+}
+
+func (s *fakeOutbox) MarkFailed(ctx context.Context, id string, cause error) error {
+	for i := range s.records {
+		if s.records[i].ID == id {
+			s.records[i].Attempts++
+		}
+	}
+	s.failed[id] = cause.Error()
+	return nil
+}
+
+func (s *fakeOutbox) Park(ctx context.Context, id string) error {
+	for i := range s.records {
+		if s.records[i].ID == id {
+			s.records[i].Status = outbox.StatusParked
+		}
+	}
+	return nil
+}
+
+func (s *fakeOutbox) Cleanup(ctx context.Context, sentBefore time.Time) error {
+	return nil
+}
+
+func newFakeOutbox(ids ...string) *fakeOutbox {
+	store := &fakeOutbox{failed: make(map[string]string)}
+	for i, id := range ids {
+		store.add(id, time.Now().Add(time.Duration(i-len(ids))*time.Minute))
+	}
+	return store
+}
+
+func (s *fakeOutbox) add(id string, createdAt time.Time) {
+	s.records = append(s.records, outbox.Record{
+		ID:        id,
+		Data:      []byte(`{"id":"` + id + `"}`),
+		Status:    outbox.StatusPending,
+		CreatedAt: createdAt,
+	})
+}
+
+func TestOutboxRelay_PublishesPendingRecords(t *testing.T) {
+	store := newFakeOutbox("a", "b", "c")
+	var published []string
+	client := &helper.MockPubSub{
+		PublishFunc: func(ctx context.Context, event interface{}) error {
+			published = append(published, string(event.(json.RawMessage)))
+			return nil
+		},
+	}
+	relay := &outboxRelay{store: store, pubsub: client, batchSize: 2}
+
+	n, err := relay.relay(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{`{"id":"a"}`, `{"id":"b"}`}, published)
+	assert.Equal(t, []string{"a", "b"}, store.sent)
+	assert.Equal(t, int64(2), outboxMetrics.pending.Value())
+	assert.InDelta(t, 3*time.Minute.Seconds(), outboxMetrics.lagSeconds.Value(), 5)
+
+	n, err = relay.relay(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = relay.relay(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Zero(t, outboxMetrics.lagSeconds.Value())
+}
+
+func TestOutboxRelay_KeepsRecordsThatFailToPublish(t *testing.T) {
+	store := newFakeOutbox("a", "b")
+	client := &helper.MockPubSub{
+		PublishFunc: func(ctx context.Context, event interface{}) error {
+			return errors.New("broker unavailable")
+		},
+	}
+	relay := &outboxRelay{store: store, pubsub: client, batchSize: 10, maxAttempts: 1}
+	failures := outboxMetrics.failures.Value()
+
+	n, err := relay.relay(context.Background())
+	assert.ErrorContains(t, err, "broker unavailable")
+	assert.Zero(t, n)
+	assert.Empty(t, store.sent)
+	assert.Equal(t, map[string]string{"a": "broker unavailable", "b": "broker unavailable"}, store.failed)
+	assert.Equal(t, failures+2, outboxMetrics.failures.Value())
+
+	// Nothing was published, so the records are not parked
+	pending, _ := store.Pending(context.Background(), 10)
+	assert.Len(t, pending, 2)
+}
+
+func TestOutboxRelay_ParksPoisonRecords(t *testing.T) {
+	store := newFakeOutbox("a", "b", "c")
+	var published []string
+	client := &helper.MockPubSub{
+		PublishFunc: func(ctx context.Context, event interface{}) error {
+			data := string(event.(json.RawMessage))
+			if data == `{"id":"b"}` {
+				return errors.New("message too large")
+			}
+			published = append(published, data)
+			return nil
+		},
+	}
+	relay := &outboxRelay{store: store, pubsub: client, batchSize: 10, maxAttempts: 2}
+	parked := outboxMetrics.parked.Value()
+
+	// A failing record does not hold back the ones after it
+	n, err := relay.relay(context.Background())
+	assert.ErrorContains(t, err, "message too large")
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{`{"id":"a"}`, `{"id":"c"}`}, published)
+
+	pending, _ := store.Pending(context.Background(), 10)
+	require.Len(t, pending, 1)
+	assert.Equal(t, 1, pending[0].Attempts)
+
+	// Its last attempt fails while a later event is published
+	store.add("d", time.Now())
+	n, err = relay.relay(context.Background())
+	assert.ErrorContains(t, err, "message too large")
+	assert.Equal(t, 1, n)
+	assert.Equal(t, parked+1, outboxMetrics.parked.Value())
+	assert.Equal(t, outbox.StatusParked, store.records[1].Status)
+
+	pending, _ = store.Pending(context.Background(), 10)
+	assert.Empty(t, pending)
+}
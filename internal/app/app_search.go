@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/search/service"
+)
+
+const defaultSearchSnapshotInterval = 5 * time.Minute
+
+// searchConfig reads SEARCH_INDEX_PATH and SEARCH_SNIPPET_WORDS, keeping the
+// defaults for values that are unset or invalid
+func searchConfig() service.SearchConfig {
+	config := service.DefaultSearchConfig()
+	config.SnapshotPath = os.Getenv("SEARCH_INDEX_PATH")
+	if words, err := strconv.Atoi(os.Getenv("SEARCH_SNIPPET_WORDS")); err == nil && words > 0 {
+		config.SnippetWords = words
+	}
+	return config
+}
+
+// startSearchIndex restores the search index from its snapshot, then rebuilds
+// it from Firestore in the background to catch up with changes made while
+// this replica was down, unless SEARCH_REINDEX_ON_START is false. Searches
+// are served from the snapshot meanwhile. The index is saved every
+// SEARCH_SNAPSHOT_INTERVAL until ctx is cancelled.
+func (a *App) startSearchIndex(ctx context.Context) {
+	search := a.search.Service()
+
+	restored, err := search.LoadSnapshot()
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		log.Printf("No search snapshot yet, the index starts empty")
+	case err != nil:
+		log.Printf("Error restoring search snapshot: %v", err)
+	case restored > 0:
+		log.Printf("Restored %d documents from the search snapshot", restored)
+	}
+
+	if reindex, err := strconv.ParseBool(os.Getenv("SEARCH_REINDEX_ON_START")); err != nil || reindex {
+		go func() {
+			indexed, err := search.Reindex(ctx)
+			if err != nil {
+				log.Printf("Error rebuilding search index: %v", err)
+				return
+			}
+			log.Printf("Rebuilt search index with %d documents", indexed)
+		}()
+	}
+
+	interval := envDuration("SEARCH_SNAPSHOT_INTERVAL", defaultSearchSnapshotInterval)
+	go runPeriodically(ctx, interval, func() {
+		if err := search.SaveSnapshot(); err != nil {
+			log.Printf("Error saving search snapshot: %v", err)
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package app
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/ynwd/awesome-blog/internal/stream/service"
+)
+
+// streamConfig reads STREAM_BUFFER_SIZE, STREAM_MAX_CONNECTIONS_PER_USER,
+// STREAM_HEARTBEAT_INTERVAL and STREAM_WRITE_TIMEOUT, keeping the defaults
+// for values that are unset or invalid
+func streamConfig() service.StreamConfig {
+	config := service.DefaultStreamConfig()
+	if size, err := strconv.Atoi(os.Getenv("STREAM_BUFFER_SIZE")); err == nil && size > 0 {
+		config.BufferSize = size
+	}
+	if max, err := strconv.Atoi(os.Getenv("STREAM_MAX_CONNECTIONS_PER_USER")); err == nil && max > 0 {
+		config.MaxConnectionsPerUser = max
+	}
+	config.HeartbeatInterval = envDuration("STREAM_HEARTBEAT_INTERVAL", config.HeartbeatInterval)
+	config.WriteTimeout = envDuration("STREAM_WRITE_TIMEOUT", config.WriteTimeout)
+	return config
+}
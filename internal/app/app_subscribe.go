@@ -2,34 +2,30 @@ package app
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/pubsub"
 )
 
 func (a *App) pubSubSubsribe(ctx context.Context) error {
-	errChan := make(chan error, 1)
+	errChan := make(chan error, 2)
 
 	go func() {
 		topicSub := os.Getenv("GOOGLE_CLOUD_PUBSUB_SUBSCRIPTION")
-		err := a.pubsub.Subscribe(ctx, topicSub, func(event interface{}) {
-
-			// Convert to base event to check type
-			data, _ := json.Marshal(event)
-			var baseEvent module.BaseEvent
-			if err := json.Unmarshal(data, &baseEvent); err != nil {
-				log.Printf("Error unmarshaling event: %v", err)
-				return
-			}
-
-			// Route to correct module based on event type
-			for _, m := range a.modules {
-				m.RegisterEventHandlers(ctx, baseEvent)
+		err := a.pubsub.Subscribe(ctx, topicSub, func(data []byte) error {
+			// Route to the modules that registered the event type. If any of
+			// them fails the event is redelivered to all of them, unless it is
+			// malformed and is dead-lettered straight away.
+			err := a.events.Dispatch(ctx, data)
+			if errors.Is(err, module.ErrMalformedEvent) {
+				return pubsub.Permanent(err)
 			}
+			return err
 		})
 		if err != nil {
 			errChan <- err
@@ -37,6 +33,25 @@ func (a *App) pubSubSubsribe(ctx context.Context) error {
 		}
 	}()
 
+	// Modules keeping state in memory get every event on a subscription of
+	// this replica alone
+	if a.replicaEvents != nil && !a.replicaEvents.Empty() {
+		go func() {
+			subscriptionID := replicaSubscriptionID()
+			err := a.pubsub.SubscribeReplica(ctx, subscriptionID, func(data []byte) error {
+				// The shared subscription dead-letters malformed events
+				err := a.replicaEvents.Dispatch(ctx, data)
+				if errors.Is(err, module.ErrMalformedEvent) {
+					return nil
+				}
+				return err
+			})
+			if err != nil {
+				errChan <- err
+			}
+		}()
+	}
+
 	// Wait for potential immediate subscription errors
 	select {
 	case err := <-errChan:
@@ -46,3 +61,10 @@ func (a *App) pubSubSubsribe(ctx context.Context) error {
 		return nil
 	}
 }
+
+// replicaSubscriptionID names the subscription of this process after the
+// shared one. It is new on every start, since the subscription only receives
+// messages published while it exists.
+func replicaSubscriptionID() string {
+	return fmt.Sprintf("%s-replica-%s", os.Getenv("GOOGLE_CLOUD_PUBSUB_SUBSCRIPTION"), uuid.NewString())
+}
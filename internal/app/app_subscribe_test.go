@@ -0,0 +1,221 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/pubsub"
+)
+
+type recordedPayload struct {
+	Username string `json:"username"`
+	Title    string `json:"title"`
+}
+
+type recordingModule struct {
+	types  []module.EventType
+	events chan module.Event[recordedPayload]
+
+	// failures is how many deliveries fail before the event is accepted
+	failures int
+}
+
+func (m *recordingModule) RegisterRoutes(router *gin.Engine) {}
+
+func (m *recordingModule) RegisterEvents(registry *module.EventRegistry) {
+	for _, eventType := range m.types {
+		module.Handle(registry, eventType, 1, m.handle)
+	}
+}
+
+func (m *recordingModule) handle(ctx context.Context, event module.Event[recordedPayload]) error {
+	if m.failures > 0 {
+		m.failures--
+		return errors.New("handler failed")
+	}
+	m.events <- event
+	return nil
+}
+
+// replicaModule records events on the subscription of its replica
+type replicaModule struct {
+	recordingModule
+}
+
+func (m *replicaModule) RegisterEvents(registry *module.EventRegistry) {}
+
+func (m *replicaModule) RegisterReplicaEvents(registry *module.EventRegistry) {
+	m.recordingModule.RegisterEvents(registry)
+}
+
+// newSubscribedApp subscribes an app with the modules to the client
+func newSubscribedApp(ctx context.Context, t *testing.T, client pubsub.PubSubClient, modules ...module.Module) *App {
+	t.Helper()
+	os.Setenv("GOOGLE_CLOUD_PUBSUB_SUBSCRIPTION", "test-sub")
+	t.Cleanup(func() { os.Unsetenv("GOOGLE_CLOUD_PUBSUB_SUBSCRIPTION") })
+
+	app := &App{pubsub: client, modules: modules, events: module.NewEventRegistry(), replicaEvents: module.NewReplicaEventRegistry()}
+	for _, m := range modules {
+		m.RegisterEvents(app.events)
+		if replica, ok := m.(module.ReplicaModule); ok {
+			replica.RegisterReplicaEvents(app.replicaEvents)
+		}
+	}
+	require.NoError(t, app.pubSubSubsribe(ctx))
+	return app
+}
+
+func TestApp_PubSubSubscribeMemory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := pubsub.NewMemoryClient()
+	defer client.Close()
+
+	recorder := &recordingModule{types: []module.EventType{module.PostEvent}, events: make(chan module.Event[recordedPayload], 1)}
+	newSubscribedApp(ctx, t, client, recorder)
+
+	event := module.NewEvent(module.PostEvent, recordedPayload{Username: "alice", Title: "Hello"})
+	assert.NoError(t, client.Publish(ctx, event))
+
+	select {
+	case got := <-recorder.events:
+		assert.Equal(t, event.ID, got.ID)
+		assert.Equal(t, event.ProducerID, got.ProducerID)
+		assert.Equal(t, module.PostEvent, got.Type)
+		assert.Equal(t, module.PostEventVersion, got.Version)
+		assert.Equal(t, recordedPayload{Username: "alice", Title: "Hello"}, got.Payload)
+		assert.Equal(t, event.Timestamp, got.Timestamp.Format(time.RFC3339))
+	case <-time.After(2 * time.Second):
+		t.Fatal("event was not delivered to the module")
+	}
+}
+
+func TestApp_PubSubSubscribeDispatchesByType(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := pubsub.NewMemoryClient()
+	defer client.Close()
+
+	posts := &recordingModule{types: []module.EventType{module.PostEvent}, events: make(chan module.Event[recordedPayload], 2)}
+	likes := &recordingModule{types: []module.EventType{module.LikeEvent}, events: make(chan module.Event[recordedPayload], 2)}
+	newSubscribedApp(ctx, t, client, posts, likes)
+
+	assert.NoError(t, client.Publish(ctx, module.NewEvent(module.LikeEvent, recordedPayload{Username: "bob"})))
+
+	select {
+	case got := <-likes.events:
+		assert.Equal(t, "bob", got.Payload.Username)
+	case <-time.After(2 * time.Second):
+		t.Fatal("event was not delivered to the module")
+	}
+	assert.Empty(t, posts.events)
+}
+
+func TestApp_PubSubSubscribeDeliversToEveryReplica(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := pubsub.NewMemoryClient()
+	defer client.Close()
+
+	types := []module.EventType{module.PostEvent}
+	shared := make(chan module.Event[recordedPayload], 2)
+	first := &replicaModule{recordingModule{types: types, events: make(chan module.Event[recordedPayload], 1)}}
+	second := &replicaModule{recordingModule{types: types, events: make(chan module.Event[recordedPayload], 1)}}
+	newSubscribedApp(ctx, t, client, first, &recordingModule{types: types, events: shared})
+	newSubscribedApp(ctx, t, client, second, &recordingModule{types: types, events: shared})
+
+	event := module.NewEvent(module.PostEvent, recordedPayload{Username: "alice"})
+	assert.NoError(t, client.Publish(ctx, event))
+
+	// Both replicas see the event, the shared subscription hands it to one
+	for _, replica := range []*replicaModule{first, second} {
+		select {
+		case got := <-replica.events:
+			assert.Equal(t, event.ID, got.ID)
+		case <-time.After(2 * time.Second):
+			t.Fatal("event was not delivered to every replica")
+		}
+	}
+	select {
+	case <-shared:
+	case <-time.After(2 * time.Second):
+		t.Fatal("event was not delivered on the shared subscription")
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, shared)
+}
+
+func TestApp_PubSubSubscribeRedeliversFailedEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := pubsub.NewMemoryClient(pubsub.WithRetryPolicy(pubsub.RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+	}))
+	defer client.Close()
+
+	types := []module.EventType{module.LikeEvent}
+	healthy := &recordingModule{types: types, events: make(chan module.Event[recordedPayload], 3)}
+	flaky := &recordingModule{types: types, events: make(chan module.Event[recordedPayload], 1), failures: 2}
+	newSubscribedApp(ctx, t, client, healthy, flaky)
+
+	assert.NoError(t, client.Publish(ctx, module.NewEvent(module.LikeEvent, recordedPayload{})))
+
+	select {
+	case got := <-flaky.events:
+		assert.Equal(t, module.LikeEvent, got.Type)
+	case <-time.After(2 * time.Second):
+		t.Fatal("failed event was not redelivered")
+	}
+
+	// Every registered handler sees each delivery of the event
+	assert.Len(t, healthy.events, 3)
+}
+
+type recordingSink struct {
+	letters chan pubsub.DeadLetter
+}
+
+func (s *recordingSink) Put(ctx context.Context, letter pubsub.DeadLetter) error {
+	s.letters <- letter
+	return nil
+}
+
+func TestApp_PubSubSubscribeDeadLettersMalformedEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sink := &recordingSink{letters: make(chan pubsub.DeadLetter, 1)}
+	client := pubsub.NewMemoryClient(pubsub.WithDeadLetterSink(sink))
+	defer client.Close()
+
+	recorder := &recordingModule{types: []module.EventType{module.PostEvent}, events: make(chan module.Event[recordedPayload], 1)}
+	newSubscribedApp(ctx, t, client, recorder)
+
+	assert.NoError(t, client.Publish(ctx, map[string]interface{}{
+		"type":      module.PostEvent,
+		"payload":   "not an object",
+		"timestamp": "2025-02-01T10:00:00Z",
+	}))
+
+	select {
+	case letter := <-sink.letters:
+		assert.Equal(t, 1, letter.Attempts)
+		assert.Contains(t, letter.Error, module.ErrMalformedEvent.Error())
+	case <-time.After(2 * time.Second):
+		t.Fatal("malformed event was not dead-lettered")
+	}
+	assert.Empty(t, recorder.events)
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/webhooks/service"
+)
+
+const (
+	defaultWebhookPollInterval = 5 * time.Second
+	defaultWebhookRetention    = 7 * 24 * time.Hour
+)
+
+// webhookConfig reads WEBHOOK_MAX_ATTEMPTS, WEBHOOK_MIN_BACKOFF,
+// WEBHOOK_MAX_BACKOFF, WEBHOOK_DISABLE_AFTER and WEBHOOK_TIMEOUT, keeping the
+// defaults for values that are unset or invalid. The lease on a claimed
+// delivery is kept at least twice the timeout.
+func webhookConfig() service.DispatcherConfig {
+	config := service.DefaultDispatcherConfig()
+	if attempts, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS")); err == nil && attempts > 0 {
+		config.Retry.MaxAttempts = attempts
+	}
+	config.Retry.MinBackoff = envDuration("WEBHOOK_MIN_BACKOFF", config.Retry.MinBackoff)
+	if backoff := envDuration("WEBHOOK_MAX_BACKOFF", config.Retry.MaxBackoff); backoff >= config.Retry.MinBackoff {
+		config.Retry.MaxBackoff = backoff
+	}
+	if disableAfter, err := strconv.Atoi(os.Getenv("WEBHOOK_DISABLE_AFTER")); err == nil && disableAfter > 0 {
+		config.DisableAfter = disableAfter
+	}
+	config.Timeout = envDuration("WEBHOOK_TIMEOUT", config.Timeout)
+	config.Lease = max(config.Lease, 2*config.Timeout)
+	return config
+}
+
+// startWebhookDelivery sends due webhook deliveries every
+// WEBHOOK_POLL_INTERVAL and deletes deliveries older than WEBHOOK_RETENTION
+// every hour, until ctx is cancelled
+func (a *App) startWebhookDelivery(ctx context.Context) {
+	dispatcher := a.webhooks.Dispatcher()
+
+	interval := envDuration("WEBHOOK_POLL_INTERVAL", defaultWebhookPollInterval)
+	go runPeriodically(ctx, interval, func() {
+		if _, err := dispatcher.DeliverDue(ctx); err != nil {
+			log.Printf("Error delivering webhooks: %v", err)
+		}
+	})
+
+	retention := envDuration("WEBHOOK_RETENTION", defaultWebhookRetention)
+	go runPeriodically(ctx, time.Hour, func() {
+		if err := dispatcher.Cleanup(ctx, time.Now().Add(-retention)); err != nil {
+			log.Printf("Error cleaning up webhook deliveries: %v", err)
+		}
+	})
+}
@@ -1,42 +1,48 @@
 package comments
 
 import (
-	"context"
-
 	"cloud.google.com/go/firestore"
 	"github.com/ynwd/awesome-blog/internal/comments/handler"
 	"github.com/ynwd/awesome-blog/internal/comments/repo"
 	"github.com/ynwd/awesome-blog/internal/comments/service"
 	"github.com/ynwd/awesome-blog/pkg/module"
 	"github.com/ynwd/awesome-blog/pkg/pubsub"
+	"github.com/ynwd/awesome-blog/pkg/utils"
 )
 
 type Module struct {
 	handler      *handler.CommentsHandler
 	pubsub       pubsub.PubSubClient
 	eventHandler *handler.CommentsEventHandler
+	service      service.CommentsService
 }
 
-func NewModule(firestoreClient *firestore.Client, pubsubClient pubsub.PubSubClient) *Module {
+func NewModule(firestoreClient *firestore.Client, pubsubClient pubsub.PubSubClient, posts module.PostLookup, processed utils.ProcessedEvents, operations module.OperationTracker) *Module {
 	// Initialize repository
 	commentsRepo := repo.NewCommentsRepository(firestoreClient)
 
 	// Initialize service
-	commentsService := service.NewCommentsService(commentsRepo)
+	commentsService := service.NewCommentsService(commentsRepo, posts)
 
 	// Initialize handler
-	commentsHandler := handler.NewCommentsHandler(commentsService, pubsubClient)
+	commentsHandler := handler.NewCommentsHandler(commentsService, pubsubClient, operations)
 
 	// Initialize event handler
-	eventHandler := handler.NewCommentsEventHandler(commentsService)
+	eventHandler := handler.NewCommentsEventHandler(commentsService, processed, operations)
 
 	return &Module{
 		handler:      commentsHandler,
 		pubsub:       pubsubClient,
 		eventHandler: eventHandler,
+		service:      commentsService,
 	}
 }
 
-func (m *Module) RegisterEventHandlers(ctx context.Context, event module.BaseEvent) {
-	m.eventHandler.Handle(ctx, event)
+// CommentLookup exposes comment authors to other modules
+func (m *Module) CommentLookup() module.CommentLookup {
+	return m.service
+}
+
+func (m *Module) RegisterEvents(registry *module.EventRegistry) {
+	module.Handle(registry, module.CommentEvent, module.CommentEventVersion, m.eventHandler.Handle)
 }
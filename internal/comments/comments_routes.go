@@ -5,4 +5,8 @@ import "github.com/gin-gonic/gin"
 func (m *Module) RegisterRoutes(router *gin.Engine) {
 	router.POST("/comments", m.handler.CreateComment)
 	router.POST("/comments/pubsub", m.handler.PublishComment)
+	router.PATCH("/comments/:id", m.handler.UpdateComment)
+	router.DELETE("/comments/:id", m.handler.DeleteComment)
+
+	router.GET("/posts/:id/comments", m.handler.ListComments)
 }
@@ -1,10 +1,54 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// DeletedCommentText replaces the text of a deleted comment that still has
+// replies, so the thread stays intact
+const DeletedCommentText = "[deleted]"
+
+var (
+	ErrPostNotFound     = errors.New("post not found")
+	ErrCommentNotFound  = errors.New("comment not found")
+	ErrParentNotFound   = errors.New("parent comment not found on this post")
+	ErrMaxDepthExceeded = errors.New("reply is nested too deeply")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+)
 
 type Comments struct {
+	ID        string    `json:"id,omitempty" firestore:"-"`
 	Username  string    `json:"username" firestore:"username"`
 	PostID    string    `json:"post_id" firestore:"post_id"`
+	ParentID  string    `json:"parent_id,omitempty" firestore:"parent_id"`
+	Depth     int       `json:"depth" firestore:"depth"`
 	Comment   string    `json:"comment" firestore:"comment"`
+	Deleted   bool      `json:"deleted,omitempty" firestore:"deleted"`
 	CreatedAt time.Time `json:"created_at,omitempty" firestore:"created_at"`
+	UpdatedAt time.Time `json:"updated_at,omitempty" firestore:"updated_at"`
+}
+
+// Tombstone blanks the author and text of the comment while keeping its
+// place in the thread
+func (c *Comments) Tombstone(now time.Time) {
+	c.Username = ""
+	c.Comment = DeletedCommentText
+	c.Deleted = true
+	c.UpdatedAt = now
+}
+
+// CommentFilter selects a page of comments on a post, oldest first. When
+// ParentID is set only direct replies to that comment are returned.
+type CommentFilter struct {
+	PostID   string
+	ParentID string
+	Cursor   string
+	Limit    int
+}
+
+// CommentPage is a page of comments. NextCursor is empty on the last page.
+type CommentPage struct {
+	Comments   []Comments
+	NextCursor string
 }
@@ -1,7 +1,43 @@
 package dto
 
+import "time"
+
 type CreateCommentRequest struct {
-	Username string `json:"username" binding:"required"`
+	Username string `json:"username"`
 	PostID   string `json:"post_id" binding:"required"`
+	ParentID string `json:"parent_id"`
 	Comment  string `json:"comment" binding:"required"`
 }
+
+type UpdateCommentRequest struct {
+	Comment string `json:"comment" binding:"required"`
+}
+
+type ListCommentsQuery struct {
+	ParentID string `form:"parent_id"`
+	Cursor   string `form:"cursor"`
+	Limit    int    `form:"limit"`
+}
+
+type CommentResponse struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	PostID    string    `json:"post_id"`
+	ParentID  string    `json:"parent_id,omitempty"`
+	Depth     int       `json:"depth"`
+	Comment   string    `json:"comment"`
+	Deleted   bool      `json:"deleted,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ListCommentsResponse struct {
+	Comments   []CommentResponse `json:"comments"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// PublishCommentResponse identifies the asynchronous write; its status is at
+// GET /operations/:id
+type PublishCommentResponse struct {
+	OperationID string `json:"operation_id"`
+}
@@ -2,52 +2,62 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"log"
-	"time"
 
 	"github.com/ynwd/awesome-blog/internal/comments/domain"
 	"github.com/ynwd/awesome-blog/internal/comments/service"
 	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/pubsub"
 	"github.com/ynwd/awesome-blog/pkg/utils"
 )
 
 type CommentsEventHandler struct {
-	service service.CommentsService
+	service    service.CommentsService
+	processed  utils.ProcessedEvents
+	operations module.OperationTracker
 }
 
-func NewCommentsEventHandler(service service.CommentsService) *CommentsEventHandler {
+func NewCommentsEventHandler(service service.CommentsService, processed utils.ProcessedEvents, operations module.OperationTracker) *CommentsEventHandler {
 	return &CommentsEventHandler{
-		service: service,
+		service:    service,
+		processed:  processed,
+		operations: operations,
 	}
 }
 
-func (h *CommentsEventHandler) Handle(ctx context.Context, event module.BaseEvent) error {
-	if event.Type != module.CommentEvent {
-		return nil
-	}
-	log.Printf("Raw event received: %+v", event)
-	payloadData, timeStamp := utils.EventParser(event)
+func (h *CommentsEventHandler) Handle(ctx context.Context, event module.Event[domain.Comments]) error {
+	return utils.HandleOnce(ctx, h.processed, "comments", event.ID, func() error {
+		return h.handle(ctx, event)
+	})
+}
 
-	var payload domain.Comments
-	if err := json.Unmarshal(payloadData, &payload); err != nil {
-		log.Printf("Error unmarshaling payload: %v", err)
-		return err
-	}
+func (h *CommentsEventHandler) handle(ctx context.Context, event module.Event[domain.Comments]) error {
+	log.Printf("Event received: %s %s", event.Type, event.ID)
 
-	createdAt, _ := time.Parse(time.RFC3339, timeStamp)
+	payload := event.Payload
+	// The comment is created under the event ID, so a redelivery that gets
+	// past the processed events record does not create it again
 	comments := domain.Comments{
+		ID:        event.ID,
 		PostID:    payload.PostID,
+		ParentID:  payload.ParentID,
 		Username:  payload.Username,
 		Comment:   payload.Comment,
-		CreatedAt: createdAt,
+		CreatedAt: event.Timestamp,
 	}
 
-	err := h.service.CreateComment(ctx, comments)
+	comments, err := h.service.CreateComment(ctx, comments)
+	if errors.Is(err, domain.ErrPostNotFound) {
+		log.Printf("Rejecting comment event for missing post %s", payload.PostID)
+		// Retrying cannot make the post appear
+		return pubsub.Permanent(err)
+	}
 	if err != nil {
 		log.Printf("Error processing comment event: %v", err)
 		return err
 	}
+	module.SucceedOperation(ctx, h.operations, event.ID, comments.ID)
 
 	log.Printf("Successfully processed comment event: %+v", comments)
 	return nil
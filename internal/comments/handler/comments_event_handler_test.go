@@ -2,67 +2,79 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/ynwd/awesome-blog/internal/comments/domain"
 	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/pubsub"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+	"github.com/ynwd/awesome-blog/tests/helper"
 )
 
 func TestCommentsEventHandler_Handle(t *testing.T) {
 	tests := []struct {
-		name    string
-		event   module.BaseEvent
-		mockFn  func(*mockCommentsService)
-		wantErr bool
+		name          string
+		event         module.Event[domain.Comments]
+		mockFn        func(*mockCommentsService)
+		wantErr       bool
+		wantPermanent bool
 	}{
 		{
-			name: "different event type returns nil",
-			event: module.BaseEvent{
-				Type: "different_type",
+			name: "service error returns error",
+			event: module.Event[domain.Comments]{
+				Type:    module.CommentEvent,
+				Payload: domain.Comments{},
 			},
-			mockFn:  func(m *mockCommentsService) {},
-			wantErr: false,
+			mockFn: func(m *mockCommentsService) {
+				m.createCommentFunc = func(ctx context.Context, comment domain.Comments) (domain.Comments, error) {
+					return domain.Comments{}, errors.New("service error")
+				}
+			},
+			wantErr: true,
 		},
 		{
-			name: "invalid json payload returns error",
-			event: module.BaseEvent{
+			name: "missing post is rejected",
+			event: module.Event[domain.Comments]{
 				Type:    module.CommentEvent,
-				Payload: []byte("invalid json"),
+				Payload: domain.Comments{PostID: "missing"},
 			},
-			mockFn:  func(m *mockCommentsService) {},
-			wantErr: true,
+			mockFn: func(m *mockCommentsService) {
+				m.createCommentFunc = func(ctx context.Context, comment domain.Comments) (domain.Comments, error) {
+					return domain.Comments{}, domain.ErrPostNotFound
+				}
+			},
+			wantErr:       true,
+			wantPermanent: true,
 		},
 		{
-			name: "service error returns error",
-			event: module.BaseEvent{
-				Type: module.CommentEvent,
-				Payload: func() json.RawMessage {
-					b, _ := json.Marshal(domain.Comments{})
-					return b
-				}(),
+			name: "successful handling returns nil",
+			event: module.Event[domain.Comments]{
+				Type:    module.CommentEvent,
+				Payload: domain.Comments{},
 			},
 			mockFn: func(m *mockCommentsService) {
-				m.createCommentFunc = func(ctx context.Context, comment domain.Comments) error {
-					return errors.New("service error")
+				m.createCommentFunc = func(ctx context.Context, comment domain.Comments) (domain.Comments, error) {
+					return comment, nil
 				}
 			},
-			wantErr: true,
+			wantErr: false,
 		},
 		{
-			name: "successful handling returns nil",
-			event: module.BaseEvent{
-				Type: module.CommentEvent,
-				Payload: func() json.RawMessage {
-					b, _ := json.Marshal(domain.Comments{})
-					return b
-				}(),
+			name: "comment is created under the event ID",
+			event: module.Event[domain.Comments]{
+				ID:      "event-1",
+				Type:    module.CommentEvent,
+				Payload: domain.Comments{ID: "ignored", PostID: "post-1"},
 			},
 			mockFn: func(m *mockCommentsService) {
-				m.createCommentFunc = func(ctx context.Context, comment domain.Comments) error {
-					return nil
+				m.createCommentFunc = func(ctx context.Context, comment domain.Comments) (domain.Comments, error) {
+					if comment.ID != "event-1" {
+						return domain.Comments{}, errors.New("unexpected id " + comment.ID)
+					}
+					return comment, nil
 				}
 			},
 			wantErr: false,
@@ -73,12 +85,13 @@ func TestCommentsEventHandler_Handle(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := &mockCommentsService{}
 			tt.mockFn(mockService)
-			handler := NewCommentsEventHandler(mockService)
+			handler := NewCommentsEventHandler(mockService, utils.NewMemoryProcessedEvents(time.Hour), &helper.MockOperationTracker{})
 
 			err := handler.Handle(context.Background(), tt.event)
 
 			if tt.wantErr {
 				assert.Error(t, err)
+				assert.Equal(t, tt.wantPermanent, pubsub.IsPermanent(err))
 			} else {
 				assert.NoError(t, err)
 			}
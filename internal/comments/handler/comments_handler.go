@@ -1,13 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ynwd/awesome-blog/internal/comments/domain"
 	"github.com/ynwd/awesome-blog/internal/comments/dto"
 	"github.com/ynwd/awesome-blog/internal/comments/service"
+	"github.com/ynwd/awesome-blog/pkg/middleware"
 	"github.com/ynwd/awesome-blog/pkg/module"
 	"github.com/ynwd/awesome-blog/pkg/pubsub"
 	"github.com/ynwd/awesome-blog/pkg/res"
@@ -16,12 +17,14 @@ import (
 type CommentsHandler struct {
 	commentsService service.CommentsService
 	pubsub          pubsub.PubSubClient
+	operations      module.OperationTracker
 }
 
-func NewCommentsHandler(commentsService service.CommentsService, pubsub pubsub.PubSubClient) *CommentsHandler {
+func NewCommentsHandler(commentsService service.CommentsService, pubsub pubsub.PubSubClient, operations module.OperationTracker) *CommentsHandler {
 	return &CommentsHandler{
 		commentsService: commentsService,
 		pubsub:          pubsub,
+		operations:      operations,
 	}
 }
 
@@ -33,18 +36,98 @@ func (h *CommentsHandler) CreateComment(c *gin.Context) {
 		return
 	}
 
+	username, err := middleware.ActingUser(c, req.Username)
+	if err != nil {
+		c.JSON(middleware.IdentityErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
 	comment := domain.Comments{
-		Username: req.Username,
+		Username: username,
 		PostID:   req.PostID,
+		ParentID: req.ParentID,
 		Comment:  req.Comment,
 	}
 
-	if err := h.commentsService.CreateComment(c.Request.Context(), comment); err != nil {
-		c.JSON(http.StatusInternalServerError, res.Error(err.Error()))
+	created, err := h.commentsService.CreateComment(c.Request.Context(), comment)
+	if err != nil {
+		c.JSON(commentErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, res.Success(toCommentResponse(created), "Comment created successfully"))
+}
+
+// ListComments returns the comments on a post oldest first. With parent_id
+// only the direct replies to that comment are returned.
+func (h *CommentsHandler) ListComments(c *gin.Context) {
+	var query dto.ListCommentsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error("Invalid query parameters"))
+		return
+	}
+
+	filter := domain.CommentFilter{
+		PostID:   c.Param("id"),
+		ParentID: query.ParentID,
+		Cursor:   query.Cursor,
+		Limit:    query.Limit,
+	}
+
+	page, err := h.commentsService.ListComments(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(commentErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	response := dto.ListCommentsResponse{
+		Comments:   make([]dto.CommentResponse, 0, len(page.Comments)),
+		NextCursor: page.NextCursor,
+	}
+	for _, comment := range page.Comments {
+		response.Comments = append(response.Comments, toCommentResponse(comment))
+	}
+
+	c.JSON(http.StatusOK, res.Success(response, "Comments retrieved successfully"))
+}
+
+// UpdateComment edits the text of the caller's own comment
+func (h *CommentsHandler) UpdateComment(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	var req dto.UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error(err.Error()))
+		return
+	}
+
+	comment, err := h.commentsService.UpdateComment(c.Request.Context(), username, c.Param("id"), req.Comment)
+	if err != nil {
+		c.JSON(commentErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(toCommentResponse(comment), "Comment updated successfully"))
+}
+
+// DeleteComment removes the caller's own comment
+func (h *CommentsHandler) DeleteComment(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	if err := h.commentsService.DeleteComment(c.Request.Context(), username, c.Param("id")); err != nil {
+		c.JSON(commentErrorStatus(err), res.Error(err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusCreated, res.Success(comment, "Comment created successfully"))
+	c.JSON(http.StatusOK, res.Success(nil, "Comment deleted successfully"))
 }
 
 // PublishComment publishes a comment event to the pubsub
@@ -54,16 +137,60 @@ func (h *CommentsHandler) PublishComment(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, res.Error("Invalid request payload"))
 		return
 	}
-	event := module.BaseEvent{
-		Type:      module.CommentEvent,
-		Payload:   commentEvent,
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
+
+	username, err := middleware.ActingUser(c, commentEvent.Username)
+	if err != nil {
+		c.JSON(middleware.IdentityErrorStatus(err), res.Error(err.Error()))
+		return
 	}
+	commentEvent.Username = username
 
-	if err := h.pubsub.Publish(c.Request.Context(), event); err != nil {
+	event := module.NewEvent(module.CommentEvent, commentEvent)
+
+	err = module.PublishTracked(c.Request.Context(), h.pubsub, h.operations, event, username)
+	if errors.Is(err, module.ErrOperationNotStarted) {
+		c.JSON(http.StatusInternalServerError, res.Error("Failed to record comments operation"))
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, res.Error("Failed to publish comments event"))
 		return
 	}
 
-	c.JSON(http.StatusCreated, res.Success(nil, "comments event published successfully"))
+	c.Header("Location", "/operations/"+event.ID)
+	c.JSON(http.StatusCreated, res.Success(dto.PublishCommentResponse{OperationID: event.ID}, "comments event published successfully"))
+}
+
+func toCommentResponse(comment domain.Comments) dto.CommentResponse {
+	return dto.CommentResponse{
+		ID:        comment.ID,
+		Username:  comment.Username,
+		PostID:    comment.PostID,
+		ParentID:  comment.ParentID,
+		Depth:     comment.Depth,
+		Comment:   comment.Comment,
+		Deleted:   comment.Deleted,
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	}
+}
+
+func commentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrCommentNotFound),
+		errors.Is(err, domain.ErrPostNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrInvalidUsername):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrInvalidComment),
+		errors.Is(err, service.ErrInvalidPostID),
+		errors.Is(err, domain.ErrParentNotFound),
+		errors.Is(err, domain.ErrMaxDepthExceeded),
+		errors.Is(err, domain.ErrInvalidCursor):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
 }
@@ -7,29 +7,64 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/ynwd/awesome-blog/internal/comments/domain"
 	"github.com/ynwd/awesome-blog/internal/comments/dto"
+	"github.com/ynwd/awesome-blog/internal/comments/service"
 	"github.com/ynwd/awesome-blog/pkg/res"
+	"github.com/ynwd/awesome-blog/tests/helper"
 )
 
 type mockCommentsService struct {
-	createCommentFunc func(ctx context.Context, comment domain.Comments) error
+	createCommentFunc func(ctx context.Context, comment domain.Comments) (domain.Comments, error)
+	listCommentsFunc  func(ctx context.Context, filter domain.CommentFilter) (domain.CommentPage, error)
+	updateCommentFunc func(ctx context.Context, username, id, text string) (domain.Comments, error)
+	deleteCommentFunc func(ctx context.Context, username, id string) error
+	commentAuthorFunc func(ctx context.Context, id string) (string, error)
 }
 
-func (m *mockCommentsService) CreateComment(ctx context.Context, comment domain.Comments) error {
+func (m *mockCommentsService) CreateComment(ctx context.Context, comment domain.Comments) (domain.Comments, error) {
 	if m.createCommentFunc != nil {
 		return m.createCommentFunc(ctx, comment)
 	}
+	return comment, nil
+}
+
+func (m *mockCommentsService) ListComments(ctx context.Context, filter domain.CommentFilter) (domain.CommentPage, error) {
+	if m.listCommentsFunc != nil {
+		return m.listCommentsFunc(ctx, filter)
+	}
+	return domain.CommentPage{}, nil
+}
+
+func (m *mockCommentsService) UpdateComment(ctx context.Context, username, id, text string) (domain.Comments, error) {
+	if m.updateCommentFunc != nil {
+		return m.updateCommentFunc(ctx, username, id, text)
+	}
+	return domain.Comments{}, nil
+}
+
+func (m *mockCommentsService) DeleteComment(ctx context.Context, username, id string) error {
+	if m.deleteCommentFunc != nil {
+		return m.deleteCommentFunc(ctx, username, id)
+	}
 	return nil
 }
 
+func (m *mockCommentsService) CommentAuthor(ctx context.Context, id string) (string, error) {
+	if m.commentAuthorFunc != nil {
+		return m.commentAuthorFunc(ctx, id)
+	}
+	return "", nil
+}
+
 type mockPubSub struct {
 	publishFunc   func(ctx context.Context, event interface{}) error
-	subscribeFunc func(ctx context.Context, subscriptionID string, handler func(event interface{})) error
+	subscribeFunc func(ctx context.Context, subscriptionID string, handler func(data []byte) error) error
 }
 
 func (m *mockPubSub) Publish(ctx context.Context, event interface{}) error {
@@ -39,7 +74,14 @@ func (m *mockPubSub) Publish(ctx context.Context, event interface{}) error {
 	return nil
 }
 
-func (m *mockPubSub) Subscribe(ctx context.Context, subscriptionID string, handler func(event interface{})) error {
+func (m *mockPubSub) Subscribe(ctx context.Context, subscriptionID string, handler func(data []byte) error) error {
+	if m.subscribeFunc != nil {
+		return m.subscribeFunc(ctx, subscriptionID, handler)
+	}
+	return nil
+}
+
+func (m *mockPubSub) SubscribeReplica(ctx context.Context, subscriptionID string, handler func(data []byte) error) error {
 	if m.subscribeFunc != nil {
 		return m.subscribeFunc(ctx, subscriptionID, handler)
 	}
@@ -84,6 +126,20 @@ func TestPublishComment(t *testing.T) {
 				Message: "Failed to publish comments event",
 			},
 		},
+		{
+			name: "username mismatch",
+			payload: domain.Comments{
+				PostID:   "post1",
+				Username: "someone-else",
+				Comment:  "test comment",
+			},
+			setupMocks: func(s *mockCommentsService, p *mockPubSub) {},
+			wantStatus: http.StatusForbidden,
+			wantRes: res.Response{
+				Status:  "error",
+				Message: "username does not match the authenticated user",
+			},
+		},
 		{
 			name: "successful publish",
 			payload: domain.Comments{
@@ -112,7 +168,7 @@ func TestPublishComment(t *testing.T) {
 				tt.setupMocks(mockService, mockPub)
 			}
 
-			handler := NewCommentsHandler(mockService, mockPub)
+			handler := NewCommentsHandler(mockService, mockPub, &helper.MockOperationTracker{})
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
 
@@ -126,12 +182,20 @@ func TestPublishComment(t *testing.T) {
 
 			c.Request, _ = http.NewRequest(http.MethodPost, "/comments", bytes.NewBuffer(body))
 			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("user_id", "user1")
 
 			handler.PublishComment(c)
 
 			assert.Equal(t, tt.wantStatus, w.Code)
 			var response res.Response
 			json.Unmarshal(w.Body.Bytes(), &response)
+			if w.Code == http.StatusCreated {
+				// The operation ID is the random event ID, returned in both places
+				location := w.Header().Get("Location")
+				assert.True(t, strings.HasPrefix(location, "/operations/"))
+				assert.Equal(t, map[string]interface{}{"operation_id": strings.TrimPrefix(location, "/operations/")}, response.Data)
+				response.Data = nil
+			}
 			assert.Equal(t, tt.wantRes, response)
 		})
 	}
@@ -162,7 +226,7 @@ func TestCreateComment(t *testing.T) {
 			wantStatus: http.StatusBadRequest,
 			wantRes: res.Response{
 				Status:  "error",
-				Message: "Key: 'CreateCommentRequest.Username' Error:Field validation for 'Username' failed on the 'required' tag\nKey: 'CreateCommentRequest.PostID' Error:Field validation for 'PostID' failed on the 'required' tag\nKey: 'CreateCommentRequest.Comment' Error:Field validation for 'Comment' failed on the 'required' tag",
+				Message: "Key: 'CreateCommentRequest.PostID' Error:Field validation for 'PostID' failed on the 'required' tag\nKey: 'CreateCommentRequest.Comment' Error:Field validation for 'Comment' failed on the 'required' tag",
 			},
 		},
 		{
@@ -173,8 +237,8 @@ func TestCreateComment(t *testing.T) {
 				Comment:  "test comment",
 			},
 			setupMock: func(m *mockCommentsService) {
-				m.createCommentFunc = func(ctx context.Context, comment domain.Comments) error {
-					return errors.New("service error")
+				m.createCommentFunc = func(ctx context.Context, comment domain.Comments) (domain.Comments, error) {
+					return domain.Comments{}, errors.New("service error")
 				}
 			},
 			wantStatus: http.StatusInternalServerError,
@@ -183,6 +247,95 @@ func TestCreateComment(t *testing.T) {
 				Message: "service error",
 			},
 		},
+		{
+			name: "username mismatch",
+			payload: dto.CreateCommentRequest{
+				Username: "someone-else",
+				PostID:   "post1",
+				Comment:  "test comment",
+			},
+			setupMock:  func(m *mockCommentsService) {},
+			wantStatus: http.StatusForbidden,
+			wantRes: res.Response{
+				Status:  "error",
+				Message: "username does not match the authenticated user",
+			},
+		},
+		{
+			name: "username taken from token",
+			payload: dto.CreateCommentRequest{
+				PostID:  "post1",
+				Comment: "test comment",
+			},
+			setupMock: func(m *mockCommentsService) {
+				m.createCommentFunc = func(ctx context.Context, comment domain.Comments) (domain.Comments, error) {
+					return comment, nil
+				}
+			},
+			wantStatus: http.StatusCreated,
+			wantRes: res.Response{
+				Status:  "success",
+				Message: "Comment created successfully",
+				Data: domain.Comments{
+					Username: "user1",
+					PostID:   "post1",
+					Comment:  "test comment",
+				},
+			},
+		},
+		{
+			name: "post not found",
+			payload: dto.CreateCommentRequest{
+				PostID:  "missing",
+				Comment: "test comment",
+			},
+			setupMock: func(m *mockCommentsService) {
+				m.createCommentFunc = func(ctx context.Context, comment domain.Comments) (domain.Comments, error) {
+					return domain.Comments{}, domain.ErrPostNotFound
+				}
+			},
+			wantStatus: http.StatusNotFound,
+			wantRes: res.Response{
+				Status:  "error",
+				Message: "post not found",
+			},
+		},
+		{
+			name: "reply to missing parent",
+			payload: dto.CreateCommentRequest{
+				PostID:   "post1",
+				ParentID: "missing",
+				Comment:  "test reply",
+			},
+			setupMock: func(m *mockCommentsService) {
+				m.createCommentFunc = func(ctx context.Context, comment domain.Comments) (domain.Comments, error) {
+					return domain.Comments{}, domain.ErrParentNotFound
+				}
+			},
+			wantStatus: http.StatusBadRequest,
+			wantRes: res.Response{
+				Status:  "error",
+				Message: "parent comment not found on this post",
+			},
+		},
+		{
+			name: "reply too deep",
+			payload: dto.CreateCommentRequest{
+				PostID:   "post1",
+				ParentID: "deep",
+				Comment:  "test reply",
+			},
+			setupMock: func(m *mockCommentsService) {
+				m.createCommentFunc = func(ctx context.Context, comment domain.Comments) (domain.Comments, error) {
+					return domain.Comments{}, domain.ErrMaxDepthExceeded
+				}
+			},
+			wantStatus: http.StatusBadRequest,
+			wantRes: res.Response{
+				Status:  "error",
+				Message: "reply is nested too deeply",
+			},
+		},
 		{
 			name: "successful creation",
 			payload: dto.CreateCommentRequest{
@@ -191,8 +344,8 @@ func TestCreateComment(t *testing.T) {
 				Comment:  "test comment",
 			},
 			setupMock: func(m *mockCommentsService) {
-				m.createCommentFunc = func(ctx context.Context, comment domain.Comments) error {
-					return nil
+				m.createCommentFunc = func(ctx context.Context, comment domain.Comments) (domain.Comments, error) {
+					return comment, nil
 				}
 			},
 			wantStatus: http.StatusCreated,
@@ -215,7 +368,7 @@ func TestCreateComment(t *testing.T) {
 				tt.setupMock(mockService)
 			}
 
-			handler := NewCommentsHandler(mockService, nil)
+			handler := NewCommentsHandler(mockService, nil, &helper.MockOperationTracker{})
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
 
@@ -229,6 +382,7 @@ func TestCreateComment(t *testing.T) {
 
 			c.Request, _ = http.NewRequest(http.MethodPost, "/comments", bytes.NewBuffer(body))
 			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("user_id", "user1")
 
 			handler.CreateComment(c)
 
@@ -251,3 +405,229 @@ func TestCreateComment(t *testing.T) {
 		})
 	}
 }
+
+func TestListComments(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		url        string
+		setupMock  func(*mockCommentsService)
+		wantStatus int
+		wantCount  int
+		wantCursor string
+	}{
+		{
+			name: "success with filter",
+			url:  "/posts/post1/comments?parent_id=c1&limit=2&cursor=abc",
+			setupMock: func(m *mockCommentsService) {
+				m.listCommentsFunc = func(ctx context.Context, filter domain.CommentFilter) (domain.CommentPage, error) {
+					assert.Equal(t, domain.CommentFilter{PostID: "post1", ParentID: "c1", Cursor: "abc", Limit: 2}, filter)
+					return domain.CommentPage{
+						Comments:   []domain.Comments{{ID: "c2", ParentID: "c1", Depth: 1}, {ID: "c3", ParentID: "c1", Depth: 1}},
+						NextCursor: "next",
+					}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantCount:  2,
+			wantCursor: "next",
+		},
+		{
+			name:       "empty page",
+			url:        "/posts/post1/comments",
+			setupMock:  func(m *mockCommentsService) {},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid limit",
+			url:        "/posts/post1/comments?limit=abc",
+			setupMock:  func(m *mockCommentsService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid cursor",
+			url:  "/posts/post1/comments?cursor=bad",
+			setupMock: func(m *mockCommentsService) {
+				m.listCommentsFunc = func(ctx context.Context, filter domain.CommentFilter) (domain.CommentPage, error) {
+					return domain.CommentPage{}, domain.ErrInvalidCursor
+				}
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &mockCommentsService{}
+			tt.setupMock(mockService)
+			handler := NewCommentsHandler(mockService, nil, &helper.MockOperationTracker{})
+
+			router := gin.New()
+			router.GET("/posts/:id/comments", handler.ListComments)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var got struct {
+					Data dto.ListCommentsResponse `json:"data"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &got)
+				assert.NoError(t, err)
+				assert.NotNil(t, got.Data.Comments)
+				assert.Len(t, got.Data.Comments, tt.wantCount)
+				assert.Equal(t, tt.wantCursor, got.Data.NextCursor)
+			}
+		})
+	}
+}
+
+func TestUpdateComment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		payload    interface{}
+		user       string
+		setupMock  func(*mockCommentsService)
+		wantStatus int
+	}{
+		{
+			name:    "success",
+			payload: dto.UpdateCommentRequest{Comment: "edited"},
+			user:    "user1",
+			setupMock: func(m *mockCommentsService) {
+				m.updateCommentFunc = func(ctx context.Context, username, id, text string) (domain.Comments, error) {
+					assert.Equal(t, "user1", username)
+					assert.Equal(t, "c1", id)
+					assert.Equal(t, "edited", text)
+					return domain.Comments{ID: id, Username: username, Comment: text}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unauthenticated",
+			payload:    dto.UpdateCommentRequest{Comment: "edited"},
+			setupMock:  func(m *mockCommentsService) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "missing comment",
+			payload:    dto.UpdateCommentRequest{},
+			user:       "user1",
+			setupMock:  func(m *mockCommentsService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "not the author",
+			payload: dto.UpdateCommentRequest{Comment: "edited"},
+			user:    "user1",
+			setupMock: func(m *mockCommentsService) {
+				m.updateCommentFunc = func(ctx context.Context, username, id, text string) (domain.Comments, error) {
+					return domain.Comments{}, service.ErrForbidden
+				}
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:    "not found",
+			payload: dto.UpdateCommentRequest{Comment: "edited"},
+			user:    "user1",
+			setupMock: func(m *mockCommentsService) {
+				m.updateCommentFunc = func(ctx context.Context, username, id, text string) (domain.Comments, error) {
+					return domain.Comments{}, domain.ErrCommentNotFound
+				}
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &mockCommentsService{}
+			tt.setupMock(mockService)
+			handler := NewCommentsHandler(mockService, nil, &helper.MockOperationTracker{})
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.user != "" {
+					c.Set("user_id", tt.user)
+				}
+			})
+			router.PATCH("/comments/:id", handler.UpdateComment)
+
+			body, _ := json.Marshal(tt.payload)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPatch, "/comments/c1", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestDeleteComment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		user       string
+		setupMock  func(*mockCommentsService)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			user: "user1",
+			setupMock: func(m *mockCommentsService) {
+				m.deleteCommentFunc = func(ctx context.Context, username, id string) error {
+					assert.Equal(t, "user1", username)
+					assert.Equal(t, "c1", id)
+					return nil
+				}
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unauthenticated",
+			setupMock:  func(m *mockCommentsService) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "not the author",
+			user: "user1",
+			setupMock: func(m *mockCommentsService) {
+				m.deleteCommentFunc = func(ctx context.Context, username, id string) error {
+					return service.ErrForbidden
+				}
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &mockCommentsService{}
+			tt.setupMock(mockService)
+			handler := NewCommentsHandler(mockService, nil, &helper.MockOperationTracker{})
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.user != "" {
+					c.Set("user_id", tt.user)
+				}
+			})
+			router.DELETE("/comments/:id", handler.DeleteComment)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodDelete, "/comments/c1", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
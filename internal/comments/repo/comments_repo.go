@@ -5,21 +5,192 @@ import (
 
 	"cloud.google.com/go/firestore"
 	"github.com/ynwd/awesome-blog/internal/comments/domain"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/outbox"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// commentsFirestore writes every change to a comment together with the domain
+// event reporting it to the outbox
 type commentsFirestore struct {
 	client     *firestore.Client
 	collection string
+	outbox     *outbox.Outbox
 }
 
 func NewCommentsRepository(client *firestore.Client) CommentsRepository {
 	return &commentsFirestore{
 		client:     client,
 		collection: "comments",
+		outbox:     outbox.New(client),
 	}
 }
 
-func (r *commentsFirestore) Create(ctx context.Context, comment domain.Comments) error {
-	_, _, err := r.client.Collection(r.collection).Add(ctx, comment)
-	return err
+// Create writes the comment under its ID, or under a new one when it has
+// none. Creating a comment whose ID already exists is a no-op, so a
+// redelivered command does not write it twice.
+func (r *commentsFirestore) Create(ctx context.Context, comment domain.Comments) (string, error) {
+	ref := r.client.Collection(r.collection).NewDoc()
+	if comment.ID != "" {
+		ref = r.client.Collection(r.collection).Doc(comment.ID)
+	}
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := tx.Create(ref, comment); err != nil {
+			return err
+		}
+		comment.ID = ref.ID
+		return r.outbox.Add(tx, module.CommentCreatedEvent, comment)
+	})
+	if status.Code(err) == codes.AlreadyExists {
+		return ref.ID, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return ref.ID, nil
+}
+
+func (r *commentsFirestore) GetByID(ctx context.Context, id string) (domain.Comments, error) {
+	doc, err := r.client.Collection(r.collection).Doc(id).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return domain.Comments{}, domain.ErrCommentNotFound
+	}
+	if err != nil {
+		return domain.Comments{}, err
+	}
+	return toComment(doc)
+}
+
+// ListByPost returns comments oldest first, so a parent always comes before
+// its replies. It requires a composite index on (post_id, created_at, __name__)
+// and, when filtering by parent, on (post_id, parent_id, created_at, __name__).
+func (r *commentsFirestore) ListByPost(ctx context.Context, filter domain.CommentFilter) (domain.CommentPage, error) {
+	query := r.client.Collection(r.collection).Where("post_id", "==", filter.PostID)
+	if filter.ParentID != "" {
+		query = query.Where("parent_id", "==", filter.ParentID)
+	}
+	query = query.
+		OrderBy("created_at", firestore.Asc).
+		OrderBy(firestore.DocumentID, firestore.Asc)
+
+	if filter.Cursor != "" {
+		c, err := utils.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return domain.CommentPage{}, domain.ErrInvalidCursor
+		}
+		query = query.StartAfter(c.CreatedAt, c.ID)
+	}
+
+	// Fetch one extra document to know whether another page exists
+	iter := query.Limit(filter.Limit + 1).Documents(ctx)
+	defer iter.Stop()
+
+	var comments []domain.Comments
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return domain.CommentPage{}, err
+		}
+
+		comment, err := toComment(doc)
+		if err != nil {
+			return domain.CommentPage{}, err
+		}
+		comments = append(comments, comment)
+	}
+
+	page := domain.CommentPage{Comments: comments}
+	if len(comments) > filter.Limit {
+		page.Comments = comments[:filter.Limit]
+		last := page.Comments[filter.Limit-1]
+		page.NextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+	}
+	return page, nil
+}
+
+func (r *commentsFirestore) HasReplies(ctx context.Context, id string) (bool, error) {
+	iter := r.client.Collection(r.collection).
+		Where("parent_id", "==", id).
+		Limit(1).
+		Documents(ctx)
+	defer iter.Stop()
+
+	_, err := iter.Next()
+	if err == iterator.Done {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Update saves an edited comment, or a tombstoned one which is reported as
+// deleted
+func (r *commentsFirestore) Update(ctx context.Context, comment domain.Comments) error {
+	ref := r.client.Collection(r.collection).Doc(comment.ID)
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		_, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return domain.ErrCommentNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		err = tx.Update(ref, []firestore.Update{
+			{Path: "username", Value: comment.Username},
+			{Path: "comment", Value: comment.Comment},
+			{Path: "deleted", Value: comment.Deleted},
+			{Path: "updated_at", Value: comment.UpdatedAt},
+		})
+		if err != nil {
+			return err
+		}
+
+		eventType := module.CommentUpdatedEvent
+		if comment.Deleted {
+			eventType = module.CommentDeletedEvent
+		}
+		return r.outbox.Add(tx, eventType, comment)
+	})
+}
+
+// Delete removes the comment. Deleting a comment that does not exist emits no
+// event.
+func (r *commentsFirestore) Delete(ctx context.Context, id string) error {
+	ref := r.client.Collection(r.collection).Doc(id)
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		comment, err := toComment(doc)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(ref); err != nil {
+			return err
+		}
+		return r.outbox.Add(tx, module.CommentDeletedEvent, comment)
+	})
+}
+
+func toComment(doc *firestore.DocumentSnapshot) (domain.Comments, error) {
+	var comment domain.Comments
+	if err := doc.DataTo(&comment); err != nil {
+		return domain.Comments{}, err
+	}
+	comment.ID = doc.Ref.ID
+	return comment, nil
 }
@@ -93,7 +93,7 @@ func TestCommentsRepository_Create(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 
 			ctx := context.Background()
-			err := repo.Create(ctx, tt.comment)
+			_, err := repo.Create(ctx, tt.comment)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("commentsFirestore.Create() error = %v, wantErr %v", err, tt.wantErr)
@@ -126,4 +126,97 @@ func TestCommentsRepository_Create(t *testing.T) {
 			// }
 		})
 	}
+
+	t.Run("comment with an ID is created once", func(t *testing.T) {
+		ctx := context.Background()
+		comment := domain.Comments{ID: "event-1", Username: "user-123", PostID: postID, Comment: "Once", CreatedAt: time.Now()}
+
+		gotID, err := repo.Create(ctx, comment)
+		assert.NoError(t, err)
+		assert.Equal(t, "event-1", gotID)
+
+		comment.Comment = "Twice"
+		gotID, err = repo.Create(ctx, comment)
+		assert.NoError(t, err)
+		assert.Equal(t, "event-1", gotID)
+
+		saved, err := repo.GetByID(ctx, "event-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "Once", saved.Comment)
+	})
+}
+
+func TestCommentsRepository_Threads(t *testing.T) {
+	client := helper.SetupRepoClient(t)
+	setupUsersAndPosts(t, client)
+
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	repo := NewCommentsRepository(client)
+	ctx := context.Background()
+	base := time.Now()
+
+	rootID, err := repo.Create(ctx, domain.Comments{
+		Username:  "user-123",
+		PostID:    postID,
+		Comment:   "root",
+		CreatedAt: base,
+	})
+	assert.NoError(t, err)
+
+	hasReplies, err := repo.HasReplies(ctx, rootID)
+	assert.NoError(t, err)
+	assert.False(t, hasReplies)
+
+	for i := 1; i <= 3; i++ {
+		_, err := repo.Create(ctx, domain.Comments{
+			Username:  "user-123",
+			PostID:    postID,
+			ParentID:  rootID,
+			Depth:     1,
+			Comment:   "reply",
+			CreatedAt: base.Add(time.Duration(i) * time.Second),
+		})
+		assert.NoError(t, err)
+	}
+
+	hasReplies, err = repo.HasReplies(ctx, rootID)
+	assert.NoError(t, err)
+	assert.True(t, hasReplies)
+
+	// Walk all pages oldest first
+	var all []domain.Comments
+	cursor := ""
+	for {
+		page, err := repo.ListByPost(ctx, domain.CommentFilter{PostID: postID, Cursor: cursor, Limit: 2})
+		assert.NoError(t, err)
+		all = append(all, page.Comments...)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	assert.Len(t, all, 4)
+	assert.Equal(t, rootID, all[0].ID)
+
+	replies, err := repo.ListByPost(ctx, domain.CommentFilter{PostID: postID, ParentID: rootID, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, replies.Comments, 3)
+
+	root, err := repo.GetByID(ctx, rootID)
+	assert.NoError(t, err)
+	root.Tombstone(time.Now())
+	assert.NoError(t, repo.Update(ctx, root))
+
+	root, err = repo.GetByID(ctx, rootID)
+	assert.NoError(t, err)
+	assert.True(t, root.Deleted)
+	assert.Equal(t, domain.DeletedCommentText, root.Comment)
+
+	assert.NoError(t, repo.Delete(ctx, rootID))
+	_, err = repo.GetByID(ctx, rootID)
+	assert.ErrorIs(t, err, domain.ErrCommentNotFound)
 }
@@ -7,5 +7,10 @@ import (
 )
 
 type CommentsRepository interface {
-	Create(ctx context.Context, comment domain.Comments) error
+	Create(ctx context.Context, comment domain.Comments) (string, error)
+	GetByID(ctx context.Context, id string) (domain.Comments, error)
+	ListByPost(ctx context.Context, filter domain.CommentFilter) (domain.CommentPage, error)
+	HasReplies(ctx context.Context, id string) (bool, error)
+	Update(ctx context.Context, comment domain.Comments) error
+	Delete(ctx context.Context, id string) error
 }
@@ -3,33 +3,212 @@ package service
 import (
 	"context"
 	"errors"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/ynwd/awesome-blog/internal/comments/domain"
 	"github.com/ynwd/awesome-blog/internal/comments/repo"
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+const (
+	DefaultMaxDepth = 5
+	DefaultPageSize = 20
+	MaxPageSize     = 100
 )
 
 var (
 	ErrInvalidComment  = errors.New("invalid comment: username, postID and comment are required")
 	ErrInvalidPostID   = errors.New("invalid post ID: cannot be empty")
 	ErrInvalidUsername = errors.New("invalid username: cannot be empty")
+	ErrForbidden       = errors.New("only the author can modify this comment")
 )
 
 type commentsService struct {
 	commentsRepo repo.CommentsRepository
+	posts        module.PostLookup
+	maxDepth     int
 }
 
-func NewCommentsService(commentsRepo repo.CommentsRepository) CommentsService {
+// NewCommentsService creates the comments service. The maximum reply depth is
+// read from COMMENTS_MAX_DEPTH and defaults to DefaultMaxDepth.
+func NewCommentsService(commentsRepo repo.CommentsRepository, posts module.PostLookup) CommentsService {
+	maxDepth, err := strconv.Atoi(os.Getenv("COMMENTS_MAX_DEPTH"))
+	if err != nil || maxDepth < 0 {
+		maxDepth = DefaultMaxDepth
+	}
+
 	return &commentsService{
 		commentsRepo: commentsRepo,
+		posts:        posts,
+		maxDepth:     maxDepth,
 	}
 }
 
-func (s *commentsService) CreateComment(ctx context.Context, comment domain.Comments) error {
+func (s *commentsService) CreateComment(ctx context.Context, comment domain.Comments) (domain.Comments, error) {
 	if comment.Username == "" || comment.PostID == "" || comment.Comment == "" {
-		return ErrInvalidComment
+		return domain.Comments{}, ErrInvalidComment
+	}
+	if err := s.checkPost(ctx, comment.PostID); err != nil {
+		return domain.Comments{}, err
+	}
+
+	comment.Depth = 0
+	if comment.ParentID != "" {
+		parent, err := s.commentsRepo.GetByID(ctx, comment.ParentID)
+		if errors.Is(err, domain.ErrCommentNotFound) {
+			return domain.Comments{}, domain.ErrParentNotFound
+		}
+		if err != nil {
+			return domain.Comments{}, err
+		}
+		if parent.PostID != comment.PostID || parent.Deleted {
+			return domain.Comments{}, domain.ErrParentNotFound
+		}
+		if parent.Depth+1 > s.maxDepth {
+			return domain.Comments{}, domain.ErrMaxDepthExceeded
+		}
+		comment.Depth = parent.Depth + 1
+	}
+
+	if comment.CreatedAt.IsZero() {
+		comment.CreatedAt = time.Now()
+	}
+	comment.UpdatedAt = comment.CreatedAt
+	comment.Deleted = false
+
+	id, err := s.commentsRepo.Create(ctx, comment)
+	if err != nil {
+		return domain.Comments{}, err
+	}
+	comment.ID = id
+	return comment, nil
+}
+
+func (s *commentsService) ListComments(ctx context.Context, filter domain.CommentFilter) (domain.CommentPage, error) {
+	if filter.PostID == "" {
+		return domain.CommentPage{}, ErrInvalidPostID
+	}
+	if err := s.checkPost(ctx, filter.PostID); err != nil {
+		return domain.CommentPage{}, err
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultPageSize
+	}
+	if filter.Limit > MaxPageSize {
+		filter.Limit = MaxPageSize
+	}
+	return s.commentsRepo.ListByPost(ctx, filter)
+}
+
+func (s *commentsService) UpdateComment(ctx context.Context, username, id, text string) (domain.Comments, error) {
+	if text == "" {
+		return domain.Comments{}, ErrInvalidComment
+	}
+
+	comment, err := s.authorizedComment(ctx, username, id)
+	if err != nil {
+		return domain.Comments{}, err
+	}
+
+	comment.Comment = text
+	comment.UpdatedAt = time.Now()
+	if err := s.commentsRepo.Update(ctx, comment); err != nil {
+		return domain.Comments{}, err
+	}
+	return comment, nil
+}
+
+// DeleteComment removes the comment, or turns it into a tombstone when it
+// still has replies. Tombstones left without replies are removed as well.
+func (s *commentsService) DeleteComment(ctx context.Context, username, id string) error {
+	comment, err := s.authorizedComment(ctx, username, id)
+	if err != nil {
+		return err
+	}
+
+	hasReplies, err := s.commentsRepo.HasReplies(ctx, comment.ID)
+	if err != nil {
+		return err
+	}
+	if hasReplies {
+		comment.Tombstone(time.Now())
+		return s.commentsRepo.Update(ctx, comment)
+	}
+
+	if err := s.commentsRepo.Delete(ctx, comment.ID); err != nil {
+		return err
 	}
+	return s.pruneTombstones(ctx, comment.ParentID)
+}
+
+// CommentAuthor implements module.CommentLookup
+func (s *commentsService) CommentAuthor(ctx context.Context, id string) (string, error) {
+	comment, err := s.commentsRepo.GetByID(ctx, id)
+	if errors.Is(err, domain.ErrCommentNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return comment.Username, nil
+}
+
+// pruneTombstones walks up the thread deleting tombstones that no longer
+// have any replies
+func (s *commentsService) pruneTombstones(ctx context.Context, parentID string) error {
+	for parentID != "" {
+		parent, err := s.commentsRepo.GetByID(ctx, parentID)
+		if errors.Is(err, domain.ErrCommentNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !parent.Deleted {
+			return nil
+		}
 
-	comment.CreatedAt = time.Now()
-	return s.commentsRepo.Create(ctx, comment)
+		hasReplies, err := s.commentsRepo.HasReplies(ctx, parent.ID)
+		if err != nil || hasReplies {
+			return err
+		}
+		if err := s.commentsRepo.Delete(ctx, parent.ID); err != nil {
+			return err
+		}
+		parentID = parent.ParentID
+	}
+	return nil
+}
+
+// checkPost returns domain.ErrPostNotFound when the post does not exist
+func (s *commentsService) checkPost(ctx context.Context, postID string) error {
+	exists, err := s.posts.PostExists(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrPostNotFound
+	}
+	return nil
+}
+
+// authorizedComment loads the comment and checks that username is its author
+func (s *commentsService) authorizedComment(ctx context.Context, username, id string) (domain.Comments, error) {
+	if username == "" {
+		return domain.Comments{}, ErrInvalidUsername
+	}
+
+	comment, err := s.commentsRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Comments{}, err
+	}
+	if comment.Deleted {
+		return domain.Comments{}, domain.ErrCommentNotFound
+	}
+	if comment.Username != username {
+		return domain.Comments{}, ErrForbidden
+	}
+	return comment, nil
 }
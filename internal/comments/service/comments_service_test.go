@@ -2,20 +2,59 @@ package service
 
 import (
 	"context"
+	"errors"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/ynwd/awesome-blog/internal/comments/domain"
+	"github.com/ynwd/awesome-blog/tests/helper"
 )
 
 type mockCommentsRepo struct {
 	createFunc func(ctx context.Context, comment domain.Comments) error
+
+	// comments backs GetByID, HasReplies, Update and Delete
+	comments map[string]domain.Comments
+	deleted  []string
 }
 
-func (m *mockCommentsRepo) Create(ctx context.Context, comment domain.Comments) error {
+func (m *mockCommentsRepo) Create(ctx context.Context, comment domain.Comments) (string, error) {
 	if m.createFunc == nil {
-		return nil
+		return "new-id", nil
+	}
+	return "new-id", m.createFunc(ctx, comment)
+}
+
+func (m *mockCommentsRepo) GetByID(ctx context.Context, id string) (domain.Comments, error) {
+	comment, ok := m.comments[id]
+	if !ok {
+		return domain.Comments{}, domain.ErrCommentNotFound
+	}
+	return comment, nil
+}
+
+func (m *mockCommentsRepo) ListByPost(ctx context.Context, filter domain.CommentFilter) (domain.CommentPage, error) {
+	return domain.CommentPage{}, nil
+}
+
+func (m *mockCommentsRepo) HasReplies(ctx context.Context, id string) (bool, error) {
+	for _, comment := range m.comments {
+		if comment.ParentID == id {
+			return true, nil
+		}
 	}
-	return m.createFunc(ctx, comment)
+	return false, nil
+}
+
+func (m *mockCommentsRepo) Update(ctx context.Context, comment domain.Comments) error {
+	m.comments[comment.ID] = comment
+	return nil
+}
+
+func (m *mockCommentsRepo) Delete(ctx context.Context, id string) error {
+	delete(m.comments, id)
+	m.deleted = append(m.deleted, id)
+	return nil
 }
 
 func TestCreateComment(t *testing.T) {
@@ -67,9 +106,9 @@ func TestCreateComment(t *testing.T) {
 			mockRepo := &mockCommentsRepo{
 				createFunc: tt.mockFn,
 			}
-			service := NewCommentsService(mockRepo)
+			service := NewCommentsService(mockRepo, &helper.MockPostLookup{})
 
-			err := service.CreateComment(context.Background(), *tt.comment)
+			_, err := service.CreateComment(context.Background(), *tt.comment)
 
 			if tt.wantErr != nil {
 				if err == nil || err.Error() != tt.wantErr.Error() {
@@ -85,3 +124,214 @@ func TestCreateComment(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateComment_Reply(t *testing.T) {
+	t.Setenv("COMMENTS_MAX_DEPTH", "2")
+
+	repo := &mockCommentsRepo{
+		comments: map[string]domain.Comments{
+			"root":      {ID: "root", PostID: "post-1", Depth: 0},
+			"child":     {ID: "child", PostID: "post-1", ParentID: "root", Depth: 1},
+			"grandkid":  {ID: "grandkid", PostID: "post-1", ParentID: "child", Depth: 2},
+			"other":     {ID: "other", PostID: "post-2", Depth: 0},
+			"tombstone": {ID: "tombstone", PostID: "post-1", Deleted: true},
+		},
+	}
+	service := NewCommentsService(repo, &helper.MockPostLookup{})
+
+	tests := []struct {
+		name      string
+		parentID  string
+		wantDepth int
+		wantErr   error
+	}{
+		{name: "top level comment", parentID: "", wantDepth: 0},
+		{name: "reply to root", parentID: "root", wantDepth: 1},
+		{name: "reply at max depth", parentID: "child", wantDepth: 2},
+		{name: "reply beyond max depth", parentID: "grandkid", wantErr: domain.ErrMaxDepthExceeded},
+		{name: "parent on another post", parentID: "other", wantErr: domain.ErrParentNotFound},
+		{name: "parent deleted", parentID: "tombstone", wantErr: domain.ErrParentNotFound},
+		{name: "parent missing", parentID: "missing", wantErr: domain.ErrParentNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comment, err := service.CreateComment(context.Background(), domain.Comments{
+				Username: "user1",
+				PostID:   "post-1",
+				ParentID: tt.parentID,
+				Comment:  "reply",
+			})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "new-id", comment.ID)
+			assert.Equal(t, tt.wantDepth, comment.Depth)
+			assert.False(t, comment.CreatedAt.IsZero())
+		})
+	}
+}
+
+func TestUpdateComment(t *testing.T) {
+	newRepo := func() *mockCommentsRepo {
+		return &mockCommentsRepo{
+			comments: map[string]domain.Comments{
+				"c1":        {ID: "c1", Username: "user1", PostID: "post-1", Comment: "old"},
+				"tombstone": {ID: "tombstone", PostID: "post-1", Comment: domain.DeletedCommentText, Deleted: true},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		id       string
+		text     string
+		wantErr  error
+	}{
+		{name: "author edits", username: "user1", id: "c1", text: "new"},
+		{name: "other user", username: "user2", id: "c1", text: "new", wantErr: ErrForbidden},
+		{name: "empty text", username: "user1", id: "c1", text: "", wantErr: ErrInvalidComment},
+		{name: "deleted comment", username: "user1", id: "tombstone", text: "new", wantErr: domain.ErrCommentNotFound},
+		{name: "missing comment", username: "user1", id: "missing", text: "new", wantErr: domain.ErrCommentNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepo()
+			service := NewCommentsService(repo, &helper.MockPostLookup{})
+
+			comment, err := service.UpdateComment(context.Background(), tt.username, tt.id, tt.text)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.text, comment.Comment)
+			assert.Equal(t, tt.text, repo.comments[tt.id].Comment)
+			assert.False(t, comment.UpdatedAt.IsZero())
+		})
+	}
+}
+
+func TestDeleteComment(t *testing.T) {
+	t.Run("comment without replies is removed", func(t *testing.T) {
+		repo := &mockCommentsRepo{
+			comments: map[string]domain.Comments{
+				"c1": {ID: "c1", Username: "user1", PostID: "post-1"},
+			},
+		}
+		service := NewCommentsService(repo, &helper.MockPostLookup{})
+
+		err := service.DeleteComment(context.Background(), "user1", "c1")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"c1"}, repo.deleted)
+	})
+
+	t.Run("comment with replies becomes a tombstone", func(t *testing.T) {
+		repo := &mockCommentsRepo{
+			comments: map[string]domain.Comments{
+				"c1": {ID: "c1", Username: "user1", PostID: "post-1", Comment: "hello"},
+				"c2": {ID: "c2", Username: "user2", PostID: "post-1", ParentID: "c1", Depth: 1},
+			},
+		}
+		service := NewCommentsService(repo, &helper.MockPostLookup{})
+
+		err := service.DeleteComment(context.Background(), "user1", "c1")
+		assert.NoError(t, err)
+		assert.Empty(t, repo.deleted)
+
+		tombstone := repo.comments["c1"]
+		assert.True(t, tombstone.Deleted)
+		assert.Equal(t, domain.DeletedCommentText, tombstone.Comment)
+		assert.Empty(t, tombstone.Username)
+	})
+
+	t.Run("deleting the last reply prunes tombstones", func(t *testing.T) {
+		repo := &mockCommentsRepo{
+			comments: map[string]domain.Comments{
+				"c1": {ID: "c1", PostID: "post-1", Deleted: true},
+				"c2": {ID: "c2", PostID: "post-1", ParentID: "c1", Deleted: true},
+				"c3": {ID: "c3", Username: "user1", PostID: "post-1", ParentID: "c2"},
+			},
+		}
+		service := NewCommentsService(repo, &helper.MockPostLookup{})
+
+		err := service.DeleteComment(context.Background(), "user1", "c3")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"c3", "c2", "c1"}, repo.deleted)
+	})
+
+	t.Run("other user is forbidden", func(t *testing.T) {
+		repo := &mockCommentsRepo{
+			comments: map[string]domain.Comments{
+				"c1": {ID: "c1", Username: "user1", PostID: "post-1"},
+			},
+		}
+		service := NewCommentsService(repo, &helper.MockPostLookup{})
+
+		err := service.DeleteComment(context.Background(), "user2", "c1")
+		assert.True(t, errors.Is(err, ErrForbidden))
+		assert.Empty(t, repo.deleted)
+	})
+}
+
+func TestCommentsService_PostNotFound(t *testing.T) {
+	lookup := &helper.MockPostLookup{
+		PostExistsFunc: func(ctx context.Context, postID string) (bool, error) {
+			return postID == "post-1", nil
+		},
+	}
+	repo := &mockCommentsRepo{
+		createFunc: func(ctx context.Context, comment domain.Comments) error {
+			t.Fatal("comment on a missing post must not be stored")
+			return nil
+		},
+	}
+	service := NewCommentsService(repo, lookup)
+
+	_, err := service.CreateComment(context.Background(), domain.Comments{
+		Username: "user1",
+		PostID:   "missing",
+		Comment:  "orphan",
+	})
+	assert.ErrorIs(t, err, domain.ErrPostNotFound)
+
+	_, err = service.ListComments(context.Background(), domain.CommentFilter{PostID: "missing"})
+	assert.ErrorIs(t, err, domain.ErrPostNotFound)
+
+	lookup.PostExistsFunc = func(ctx context.Context, postID string) (bool, error) {
+		return false, errors.New("lookup failed")
+	}
+	_, err = service.CreateComment(context.Background(), domain.Comments{
+		Username: "user1",
+		PostID:   "post-1",
+		Comment:  "hello",
+	})
+	assert.EqualError(t, err, "lookup failed")
+}
+
+func TestCommentsService_CommentAuthor(t *testing.T) {
+	deleted := domain.Comments{ID: "c2", Username: "user2", PostID: "post-1"}
+	deleted.Tombstone(deleted.CreatedAt)
+	repo := &mockCommentsRepo{comments: map[string]domain.Comments{
+		"c1": {ID: "c1", Username: "user1", PostID: "post-1"},
+		"c2": deleted,
+	}}
+	service := NewCommentsService(repo, &helper.MockPostLookup{})
+
+	author, err := service.CommentAuthor(context.Background(), "c1")
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", author)
+
+	// Deleted and missing comments have no author
+	author, err = service.CommentAuthor(context.Background(), "c2")
+	assert.NoError(t, err)
+	assert.Empty(t, author)
+	author, err = service.CommentAuthor(context.Background(), "missing")
+	assert.NoError(t, err)
+	assert.Empty(t, author)
+}
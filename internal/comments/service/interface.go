@@ -7,5 +7,9 @@ import (
 )
 
 type CommentsService interface {
-	CreateComment(ctx context.Context, comment domain.Comments) error
+	CreateComment(ctx context.Context, comment domain.Comments) (domain.Comments, error)
+	ListComments(ctx context.Context, filter domain.CommentFilter) (domain.CommentPage, error)
+	UpdateComment(ctx context.Context, username, id, text string) (domain.Comments, error)
+	DeleteComment(ctx context.Context, username, id string) error
+	CommentAuthor(ctx context.Context, id string) (string, error)
 }
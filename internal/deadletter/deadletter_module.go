@@ -0,0 +1,37 @@
+package deadletter
+
+import (
+	"cloud.google.com/go/firestore"
+	"github.com/ynwd/awesome-blog/internal/deadletter/handler"
+	"github.com/ynwd/awesome-blog/internal/deadletter/repo"
+	"github.com/ynwd/awesome-blog/internal/deadletter/service"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/pubsub"
+)
+
+type Module struct {
+	handler *handler.DeadLetterHandler
+}
+
+func NewModule(firestoreClient *firestore.Client, pubsubClient pubsub.PubSubClient) *Module {
+	// Initialize repository
+	deadLetterRepo := repo.NewDeadLetterRepository(firestoreClient)
+
+	// Initialize service
+	deadLetterService := service.NewDeadLetterService(deadLetterRepo, pubsubClient)
+
+	// Initialize handler
+	deadLetterHandler := handler.NewDeadLetterHandler(deadLetterService)
+
+	return &Module{
+		handler: deadLetterHandler,
+	}
+}
+
+// NewSink returns the sink the Pub/Sub client stores dead letters in. It is
+// created before the module because the client is needed to build the module.
+func NewSink(firestoreClient *firestore.Client, operations module.OperationTracker) pubsub.DeadLetterSink {
+	return service.NewSink(repo.NewDeadLetterRepository(firestoreClient), operations)
+}
+
+func (m *Module) RegisterEvents(registry *module.EventRegistry) {}
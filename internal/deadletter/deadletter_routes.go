@@ -0,0 +1,13 @@
+package deadletter
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ynwd/awesome-blog/pkg/middleware"
+)
+
+func (m *Module) RegisterRoutes(router *gin.Engine) {
+	admin := router.Group("/admin", middleware.RequireAdmin())
+	admin.GET("/dead-letters", m.handler.ListDeadLetters)
+	admin.GET("/dead-letters/:id", m.handler.GetDeadLetter)
+	admin.POST("/dead-letters/:id/redrive", m.handler.Redrive)
+}
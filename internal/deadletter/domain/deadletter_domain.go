@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	ErrInvalidCursor      = errors.New("invalid cursor")
+	ErrInvalidStatus      = errors.New("invalid status: must be pending or redriven")
+)
+
+type Status string
+
+const (
+	// StatusPending dead letters have not been re-driven yet
+	StatusPending Status = "pending"
+
+	// StatusRedriven dead letters were published to the topic again
+	StatusRedriven Status = "redriven"
+)
+
+// DeadLetter is an event that failed on every delivery attempt. Data holds
+// the message exactly as it was published.
+type DeadLetter struct {
+	ID           string    `json:"id,omitempty" firestore:"-"`
+	Subscription string    `json:"subscription" firestore:"subscription"`
+	Data         string    `json:"data" firestore:"data"`
+	Attempts     int       `json:"attempts" firestore:"attempts"`
+	Error        string    `json:"error" firestore:"error"`
+	Status       Status    `json:"status" firestore:"status"`
+	Redrives     int       `json:"redrives" firestore:"redrives"`
+	FailedAt     time.Time `json:"failed_at" firestore:"failed_at"`
+	RedrivenAt   time.Time `json:"redriven_at,omitempty" firestore:"redriven_at"`
+}
+
+// DeadLetterFilter selects a page of dead letters, most recent failure
+// first. An empty Status lists all of them.
+type DeadLetterFilter struct {
+	Status Status
+	Cursor string
+	Limit  int
+}
+
+// DeadLetterPage is a page of dead letters. NextCursor is empty on the last page.
+type DeadLetterPage struct {
+	DeadLetters []DeadLetter
+	NextCursor  string
+}
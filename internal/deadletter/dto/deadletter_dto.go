@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ListDeadLettersQuery struct {
+	Status string `form:"status"`
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+}
+
+type DeadLetterResponse struct {
+	ID           string          `json:"id"`
+	Subscription string          `json:"subscription"`
+	Event        json.RawMessage `json:"event"`
+	Attempts     int             `json:"attempts"`
+	Error        string          `json:"error"`
+	Status       string          `json:"status"`
+	Redrives     int             `json:"redrives"`
+	FailedAt     time.Time       `json:"failed_at"`
+	RedrivenAt   *time.Time      `json:"redriven_at,omitempty"`
+}
+
+type ListDeadLettersResponse struct {
+	DeadLetters []DeadLetterResponse `json:"dead_letters"`
+	NextCursor  string               `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ynwd/awesome-blog/internal/deadletter/domain"
+	"github.com/ynwd/awesome-blog/internal/deadletter/dto"
+	"github.com/ynwd/awesome-blog/internal/deadletter/service"
+	"github.com/ynwd/awesome-blog/pkg/res"
+)
+
+type DeadLetterHandler struct {
+	service service.DeadLetterService
+}
+
+func NewDeadLetterHandler(service service.DeadLetterService) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		service: service,
+	}
+}
+
+func (h *DeadLetterHandler) ListDeadLetters(c *gin.Context) {
+	var query dto.ListDeadLettersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error("Invalid query parameters"))
+		return
+	}
+
+	page, err := h.service.ListDeadLetters(c.Request.Context(), domain.DeadLetterFilter{
+		Status: domain.Status(query.Status),
+		Cursor: query.Cursor,
+		Limit:  query.Limit,
+	})
+	if err != nil {
+		c.JSON(deadLetterErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	response := dto.ListDeadLettersResponse{
+		DeadLetters: make([]dto.DeadLetterResponse, 0, len(page.DeadLetters)),
+		NextCursor:  page.NextCursor,
+	}
+	for _, letter := range page.DeadLetters {
+		response.DeadLetters = append(response.DeadLetters, toDeadLetterResponse(letter))
+	}
+
+	c.JSON(http.StatusOK, res.Success(response, "Dead letters retrieved successfully"))
+}
+
+func (h *DeadLetterHandler) GetDeadLetter(c *gin.Context) {
+	letter, err := h.service.GetDeadLetter(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(deadLetterErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(toDeadLetterResponse(letter), "Dead letter retrieved successfully"))
+}
+
+// Redrive publishes a dead-lettered event again
+func (h *DeadLetterHandler) Redrive(c *gin.Context) {
+	letter, err := h.service.Redrive(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(deadLetterErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, res.Success(toDeadLetterResponse(letter), "Dead letter re-driven successfully"))
+}
+
+func toDeadLetterResponse(letter domain.DeadLetter) dto.DeadLetterResponse {
+	response := dto.DeadLetterResponse{
+		ID:           letter.ID,
+		Subscription: letter.Subscription,
+		Attempts:     letter.Attempts,
+		Error:        letter.Error,
+		Status:       string(letter.Status),
+		Redrives:     letter.Redrives,
+		FailedAt:     letter.FailedAt,
+	}
+
+	// Malformed messages are shown as a JSON string rather than breaking the response
+	if json.Valid([]byte(letter.Data)) {
+		response.Event = json.RawMessage(letter.Data)
+	} else {
+		response.Event, _ = json.Marshal(letter.Data)
+	}
+
+	if !letter.RedrivenAt.IsZero() {
+		redrivenAt := letter.RedrivenAt
+		response.RedrivenAt = &redrivenAt
+	}
+	return response
+}
+
+func deadLetterErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrDeadLetterNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidCursor),
+		errors.Is(err, domain.ErrInvalidStatus):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/ynwd/awesome-blog/internal/deadletter/domain"
+)
+
+type mockDeadLetterService struct {
+	listFunc    func(ctx context.Context, filter domain.DeadLetterFilter) (domain.DeadLetterPage, error)
+	getFunc     func(ctx context.Context, id string) (domain.DeadLetter, error)
+	redriveFunc func(ctx context.Context, id string) (domain.DeadLetter, error)
+}
+
+func (m *mockDeadLetterService) ListDeadLetters(ctx context.Context, filter domain.DeadLetterFilter) (domain.DeadLetterPage, error) {
+	return m.listFunc(ctx, filter)
+}
+
+func (m *mockDeadLetterService) GetDeadLetter(ctx context.Context, id string) (domain.DeadLetter, error) {
+	return m.getFunc(ctx, id)
+}
+
+func (m *mockDeadLetterService) Redrive(ctx context.Context, id string) (domain.DeadLetter, error) {
+	return m.redriveFunc(ctx, id)
+}
+
+var failedAt = time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+
+func setupRouter(service *mockDeadLetterService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewDeadLetterHandler(service)
+	router.GET("/admin/dead-letters", h.ListDeadLetters)
+	router.GET("/admin/dead-letters/:id", h.GetDeadLetter)
+	router.POST("/admin/dead-letters/:id/redrive", h.Redrive)
+	return router
+}
+
+func TestDeadLetterHandler_ListDeadLetters(t *testing.T) {
+	service := &mockDeadLetterService{
+		listFunc: func(ctx context.Context, filter domain.DeadLetterFilter) (domain.DeadLetterPage, error) {
+			assert.Equal(t, domain.StatusPending, filter.Status)
+			assert.Equal(t, 1, filter.Limit)
+			return domain.DeadLetterPage{
+				DeadLetters: []domain.DeadLetter{{
+					ID:           "letter-1",
+					Subscription: "sub",
+					Data:         `{"type":"POST"}`,
+					Attempts:     5,
+					Error:        "handler failed",
+					Status:       domain.StatusPending,
+					FailedAt:     failedAt,
+				}},
+				NextCursor: "next",
+			}, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	setupRouter(service).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/dead-letters?status=pending&limit=1", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"status": "success",
+		"message": "Dead letters retrieved successfully",
+		"data": {
+			"dead_letters": [{
+				"id": "letter-1",
+				"subscription": "sub",
+				"event": {"type": "POST"},
+				"attempts": 5,
+				"error": "handler failed",
+				"status": "pending",
+				"redrives": 0,
+				"failed_at": "2025-02-01T10:00:00Z"
+			}],
+			"next_cursor": "next"
+		}
+	}`, w.Body.String())
+}
+
+func TestDeadLetterHandler_ListDeadLettersInvalidStatus(t *testing.T) {
+	service := &mockDeadLetterService{
+		listFunc: func(ctx context.Context, filter domain.DeadLetterFilter) (domain.DeadLetterPage, error) {
+			return domain.DeadLetterPage{}, domain.ErrInvalidStatus
+		},
+	}
+
+	w := httptest.NewRecorder()
+	setupRouter(service).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/dead-letters?status=lost", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDeadLetterHandler_GetDeadLetter(t *testing.T) {
+	tests := []struct {
+		name       string
+		letter     domain.DeadLetter
+		err        error
+		wantStatus int
+		wantEvent  string
+	}{
+		{
+			name:       "found",
+			letter:     domain.DeadLetter{ID: "letter-1", Data: `{"type":"LIKE"}`, FailedAt: failedAt},
+			wantStatus: http.StatusOK,
+			wantEvent:  `{"type":"LIKE"}`,
+		},
+		{
+			name:       "malformed event is returned as a string",
+			letter:     domain.DeadLetter{ID: "letter-1", Data: `not json`, FailedAt: failedAt},
+			wantStatus: http.StatusOK,
+			wantEvent:  `"not json"`,
+		},
+		{
+			name:       "not found",
+			err:        domain.ErrDeadLetterNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "service error",
+			err:        errors.New("service error"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &mockDeadLetterService{
+				getFunc: func(ctx context.Context, id string) (domain.DeadLetter, error) {
+					assert.Equal(t, "letter-1", id)
+					return tt.letter, tt.err
+				},
+			}
+
+			w := httptest.NewRecorder()
+			setupRouter(service).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/dead-letters/letter-1", nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.wantEvent != "" {
+				var got struct {
+					Data struct {
+						Event json.RawMessage `json:"event"`
+					} `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				assert.JSONEq(t, tt.wantEvent, string(got.Data.Event))
+			}
+		})
+	}
+}
+
+func TestDeadLetterHandler_Redrive(t *testing.T) {
+	redrivenAt := failedAt.Add(time.Hour)
+	service := &mockDeadLetterService{
+		redriveFunc: func(ctx context.Context, id string) (domain.DeadLetter, error) {
+			if id == "missing" {
+				return domain.DeadLetter{}, domain.ErrDeadLetterNotFound
+			}
+			return domain.DeadLetter{
+				ID:         id,
+				Data:       `{"type":"POST"}`,
+				Status:     domain.StatusRedriven,
+				Redrives:   1,
+				FailedAt:   failedAt,
+				RedrivenAt: redrivenAt,
+			}, nil
+		},
+	}
+	router := setupRouter(service)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/dead-letters/letter-1/redrive", nil))
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	var got struct {
+		Data struct {
+			Status     string     `json:"status"`
+			Redrives   int        `json:"redrives"`
+			RedrivenAt *time.Time `json:"redriven_at"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, "redriven", got.Data.Status)
+	assert.Equal(t, 1, got.Data.Redrives)
+	assert.Equal(t, redrivenAt, *got.Data.RedrivenAt)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/dead-letters/missing/redrive", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,115 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/ynwd/awesome-blog/internal/deadletter/domain"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Collection holds the dead-lettered events
+const Collection = "dead_letters"
+
+type deadLetterFirestore struct {
+	client *firestore.Client
+}
+
+func NewDeadLetterRepository(client *firestore.Client) DeadLetterRepository {
+	return &deadLetterFirestore{
+		client: client,
+	}
+}
+
+func (r *deadLetterFirestore) Create(ctx context.Context, letter domain.DeadLetter) (string, error) {
+	ref, _, err := r.client.Collection(Collection).Add(ctx, letter)
+	if err != nil {
+		return "", err
+	}
+	return ref.ID, nil
+}
+
+func (r *deadLetterFirestore) GetByID(ctx context.Context, id string) (domain.DeadLetter, error) {
+	doc, err := r.client.Collection(Collection).Doc(id).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return domain.DeadLetter{}, domain.ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return domain.DeadLetter{}, err
+	}
+	return toDeadLetter(doc)
+}
+
+// List returns dead letters by most recent failure first. Filtering by status
+// requires a composite index on (status, failed_at desc, __name__ desc).
+func (r *deadLetterFirestore) List(ctx context.Context, filter domain.DeadLetterFilter) (domain.DeadLetterPage, error) {
+	query := r.client.Collection(Collection).Query
+	if filter.Status != "" {
+		query = query.Where("status", "==", filter.Status)
+	}
+	query = query.
+		OrderBy("failed_at", firestore.Desc).
+		OrderBy(firestore.DocumentID, firestore.Desc)
+
+	if filter.Cursor != "" {
+		c, err := utils.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return domain.DeadLetterPage{}, domain.ErrInvalidCursor
+		}
+		query = query.StartAfter(c.CreatedAt, c.ID)
+	}
+
+	// Fetch one extra document to know whether another page exists
+	iter := query.Limit(filter.Limit + 1).Documents(ctx)
+	defer iter.Stop()
+
+	var letters []domain.DeadLetter
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return domain.DeadLetterPage{}, err
+		}
+
+		letter, err := toDeadLetter(doc)
+		if err != nil {
+			return domain.DeadLetterPage{}, err
+		}
+		letters = append(letters, letter)
+	}
+
+	page := domain.DeadLetterPage{DeadLetters: letters}
+	if len(letters) > filter.Limit {
+		page.DeadLetters = letters[:filter.Limit]
+		last := page.DeadLetters[filter.Limit-1]
+		page.NextCursor = utils.EncodeCursor(last.FailedAt, last.ID)
+	}
+	return page, nil
+}
+
+func (r *deadLetterFirestore) MarkRedriven(ctx context.Context, id string, at time.Time) error {
+	_, err := r.client.Collection(Collection).Doc(id).Update(ctx, []firestore.Update{
+		{Path: "status", Value: domain.StatusRedriven},
+		{Path: "redriven_at", Value: at},
+		{Path: "redrives", Value: firestore.Increment(1)},
+	})
+	if status.Code(err) == codes.NotFound {
+		return domain.ErrDeadLetterNotFound
+	}
+	return err
+}
+
+func toDeadLetter(doc *firestore.DocumentSnapshot) (domain.DeadLetter, error) {
+	var letter domain.DeadLetter
+	if err := doc.DataTo(&letter); err != nil {
+		return domain.DeadLetter{}, err
+	}
+	letter.ID = doc.Ref.ID
+	return letter, nil
+}
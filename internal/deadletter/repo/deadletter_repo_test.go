@@ -0,0 +1,72 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ynwd/awesome-blog/internal/deadletter/domain"
+	"github.com/ynwd/awesome-blog/tests/helper"
+)
+
+func TestDeadLetterRepository(t *testing.T) {
+	ctx := context.Background()
+	client := helper.SetupRepoClient(t)
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	repo := NewDeadLetterRepository(client)
+	failedAt := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		id, err := repo.Create(ctx, domain.DeadLetter{
+			Subscription: "sub",
+			Data:         `{"type":"POST"}`,
+			Attempts:     5,
+			Error:        "handler failed",
+			Status:       domain.StatusPending,
+			FailedAt:     failedAt.Add(time.Duration(i) * time.Minute),
+		})
+		assert.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	got, err := repo.GetByID(ctx, ids[0])
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"POST"}`, got.Data)
+	assert.Equal(t, domain.StatusPending, got.Status)
+
+	_, err = repo.GetByID(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrDeadLetterNotFound)
+
+	// Most recent failure first, across pages
+	page, err := repo.List(ctx, domain.DeadLetterFilter{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ids[2], ids[1]}, []string{page.DeadLetters[0].ID, page.DeadLetters[1].ID})
+	assert.NotEmpty(t, page.NextCursor)
+
+	page, err = repo.List(ctx, domain.DeadLetterFilter{Limit: 2, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+	assert.Len(t, page.DeadLetters, 1)
+	assert.Empty(t, page.NextCursor)
+
+	// Re-driven letters leave the pending list
+	assert.NoError(t, repo.MarkRedriven(ctx, ids[1], failedAt.Add(time.Hour)))
+	page, err = repo.List(ctx, domain.DeadLetterFilter{Status: domain.StatusPending, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.DeadLetters, 2)
+
+	got, err = repo.GetByID(ctx, ids[1])
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StatusRedriven, got.Status)
+	assert.Equal(t, 1, got.Redrives)
+
+	assert.ErrorIs(t, repo.MarkRedriven(ctx, "missing", failedAt), domain.ErrDeadLetterNotFound)
+}
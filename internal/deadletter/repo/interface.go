@@ -0,0 +1,15 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/deadletter/domain"
+)
+
+type DeadLetterRepository interface {
+	Create(ctx context.Context, letter domain.DeadLetter) (string, error)
+	GetByID(ctx context.Context, id string) (domain.DeadLetter, error)
+	List(ctx context.Context, filter domain.DeadLetterFilter) (domain.DeadLetterPage, error)
+	MarkRedriven(ctx context.Context, id string, at time.Time) error
+}
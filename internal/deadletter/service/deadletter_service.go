@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/deadletter/domain"
+	"github.com/ynwd/awesome-blog/internal/deadletter/repo"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/pubsub"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+type deadLetterService struct {
+	repo   repo.DeadLetterRepository
+	pubsub pubsub.PubSubClient
+}
+
+func NewDeadLetterService(repo repo.DeadLetterRepository, pubsubClient pubsub.PubSubClient) DeadLetterService {
+	return &deadLetterService{
+		repo:   repo,
+		pubsub: pubsubClient,
+	}
+}
+
+func (s *deadLetterService) ListDeadLetters(ctx context.Context, filter domain.DeadLetterFilter) (domain.DeadLetterPage, error) {
+	switch filter.Status {
+	case "", domain.StatusPending, domain.StatusRedriven:
+	default:
+		return domain.DeadLetterPage{}, domain.ErrInvalidStatus
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultPageSize
+	}
+	if filter.Limit > MaxPageSize {
+		filter.Limit = MaxPageSize
+	}
+	return s.repo.List(ctx, filter)
+}
+
+func (s *deadLetterService) GetDeadLetter(ctx context.Context, id string) (domain.DeadLetter, error) {
+	if id == "" {
+		return domain.DeadLetter{}, domain.ErrDeadLetterNotFound
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Redrive publishes the dead-lettered event to the topic again, where every
+// subscriber receives it with a fresh retry budget
+func (s *deadLetterService) Redrive(ctx context.Context, id string) (domain.DeadLetter, error) {
+	letter, err := s.GetDeadLetter(ctx, id)
+	if err != nil {
+		return domain.DeadLetter{}, err
+	}
+
+	if err := s.pubsub.Publish(ctx, json.RawMessage(letter.Data)); err != nil {
+		return domain.DeadLetter{}, err
+	}
+
+	now := time.Now().UTC()
+	if err := s.repo.MarkRedriven(ctx, id, now); err != nil {
+		return domain.DeadLetter{}, err
+	}
+
+	letter.Status = domain.StatusRedriven
+	letter.RedrivenAt = now
+	letter.Redrives++
+	return letter, nil
+}
+
+// sink stores messages the Pub/Sub client gave up on
+type sink struct {
+	repo       repo.DeadLetterRepository
+	operations module.OperationTracker
+}
+
+// NewSink returns the pubsub.DeadLetterSink that records dead letters for
+// the admin endpoints and fails the operation tracking the event
+func NewSink(repo repo.DeadLetterRepository, operations module.OperationTracker) pubsub.DeadLetterSink {
+	return &sink{repo: repo, operations: operations}
+}
+
+func (s *sink) Put(ctx context.Context, letter pubsub.DeadLetter) error {
+	_, err := s.repo.Create(ctx, domain.DeadLetter{
+		Subscription: letter.Subscription,
+		Data:         string(letter.Data),
+		Attempts:     letter.Attempts,
+		Error:        letter.Error,
+		Status:       domain.StatusPending,
+		FailedAt:     letter.FailedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	var event struct {
+		ID string `json:"id"`
+	}
+	if json.Unmarshal(letter.Data, &event) != nil || event.ID == "" {
+		return nil
+	}
+	if err := s.operations.Fail(ctx, event.ID, letter.Error); err != nil {
+		log.Printf("Error recording failed operation %s: %v", event.ID, err)
+	}
+	return nil
+}
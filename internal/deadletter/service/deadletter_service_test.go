@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ynwd/awesome-blog/internal/deadletter/domain"
+	"github.com/ynwd/awesome-blog/pkg/pubsub"
+	"github.com/ynwd/awesome-blog/tests/helper"
+)
+
+type mockDeadLetterRepository struct {
+	createFunc       func(ctx context.Context, letter domain.DeadLetter) (string, error)
+	getByIDFunc      func(ctx context.Context, id string) (domain.DeadLetter, error)
+	listFunc         func(ctx context.Context, filter domain.DeadLetterFilter) (domain.DeadLetterPage, error)
+	markRedrivenFunc func(ctx context.Context, id string, at time.Time) error
+}
+
+func (m *mockDeadLetterRepository) Create(ctx context.Context, letter domain.DeadLetter) (string, error) {
+	if m.createFunc != nil {
+		return m.createFunc(ctx, letter)
+	}
+	return "letter-1", nil
+}
+
+func (m *mockDeadLetterRepository) GetByID(ctx context.Context, id string) (domain.DeadLetter, error) {
+	if m.getByIDFunc != nil {
+		return m.getByIDFunc(ctx, id)
+	}
+	return domain.DeadLetter{}, domain.ErrDeadLetterNotFound
+}
+
+func (m *mockDeadLetterRepository) List(ctx context.Context, filter domain.DeadLetterFilter) (domain.DeadLetterPage, error) {
+	if m.listFunc != nil {
+		return m.listFunc(ctx, filter)
+	}
+	return domain.DeadLetterPage{}, nil
+}
+
+func (m *mockDeadLetterRepository) MarkRedriven(ctx context.Context, id string, at time.Time) error {
+	if m.markRedrivenFunc != nil {
+		return m.markRedrivenFunc(ctx, id, at)
+	}
+	return nil
+}
+
+func TestDeadLetterService_ListDeadLetters(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    domain.DeadLetterFilter
+		wantLimit int
+		wantErr   error
+	}{
+		{name: "default page size", filter: domain.DeadLetterFilter{}, wantLimit: DefaultPageSize},
+		{name: "page size is capped", filter: domain.DeadLetterFilter{Limit: 1000}, wantLimit: MaxPageSize},
+		{name: "pending filter", filter: domain.DeadLetterFilter{Status: domain.StatusPending, Limit: 5}, wantLimit: 5},
+		{name: "unknown status", filter: domain.DeadLetterFilter{Status: "lost"}, wantErr: domain.ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockDeadLetterRepository{
+				listFunc: func(ctx context.Context, filter domain.DeadLetterFilter) (domain.DeadLetterPage, error) {
+					assert.Equal(t, tt.wantLimit, filter.Limit)
+					assert.Equal(t, tt.filter.Status, filter.Status)
+					return domain.DeadLetterPage{}, nil
+				},
+			}
+
+			_, err := NewDeadLetterService(repo, &helper.MockPubSub{}).ListDeadLetters(context.Background(), tt.filter)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestDeadLetterService_Redrive(t *testing.T) {
+	stored := domain.DeadLetter{
+		ID:       "letter-1",
+		Data:     `{"type":"POST","payload":{"title":"Hello"}}`,
+		Attempts: 5,
+		Status:   domain.StatusPending,
+	}
+
+	t.Run("publishes the original event and marks it re-driven", func(t *testing.T) {
+		var published interface{}
+		var marked string
+		repo := &mockDeadLetterRepository{
+			getByIDFunc: func(ctx context.Context, id string) (domain.DeadLetter, error) {
+				return stored, nil
+			},
+			markRedrivenFunc: func(ctx context.Context, id string, at time.Time) error {
+				marked = id
+				return nil
+			},
+		}
+		pubsubClient := &helper.MockPubSub{
+			PublishFunc: func(ctx context.Context, event interface{}) error {
+				published = event
+				return nil
+			},
+		}
+
+		got, err := NewDeadLetterService(repo, pubsubClient).Redrive(context.Background(), "letter-1")
+		assert.NoError(t, err)
+
+		data, err := json.Marshal(published)
+		assert.NoError(t, err)
+		assert.JSONEq(t, stored.Data, string(data))
+		assert.Equal(t, "letter-1", marked)
+		assert.Equal(t, domain.StatusRedriven, got.Status)
+		assert.Equal(t, 1, got.Redrives)
+		assert.False(t, got.RedrivenAt.IsZero())
+	})
+
+	t.Run("publish failure leaves the letter pending", func(t *testing.T) {
+		repo := &mockDeadLetterRepository{
+			getByIDFunc: func(ctx context.Context, id string) (domain.DeadLetter, error) {
+				return stored, nil
+			},
+			markRedrivenFunc: func(ctx context.Context, id string, at time.Time) error {
+				t.Fatal("letter must not be marked re-driven")
+				return nil
+			},
+		}
+		pubsubClient := &helper.MockPubSub{
+			PublishFunc: func(ctx context.Context, event interface{}) error {
+				return errors.New("publish failed")
+			},
+		}
+
+		_, err := NewDeadLetterService(repo, pubsubClient).Redrive(context.Background(), "letter-1")
+		assert.EqualError(t, err, "publish failed")
+	})
+
+	t.Run("missing letter", func(t *testing.T) {
+		_, err := NewDeadLetterService(&mockDeadLetterRepository{}, &helper.MockPubSub{}).Redrive(context.Background(), "missing")
+		assert.ErrorIs(t, err, domain.ErrDeadLetterNotFound)
+	})
+}
+
+func TestSink_Put(t *testing.T) {
+	failedAt := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	var created domain.DeadLetter
+	repo := &mockDeadLetterRepository{
+		createFunc: func(ctx context.Context, letter domain.DeadLetter) (string, error) {
+			created = letter
+			return "letter-1", nil
+		},
+	}
+
+	err := NewSink(repo, &helper.MockOperationTracker{}).Put(context.Background(), pubsub.DeadLetter{
+		Subscription: "sub",
+		Data:         json.RawMessage(`{"type":"LIKE"}`),
+		Attempts:     5,
+		Error:        "handler failed",
+		FailedAt:     failedAt,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DeadLetter{
+		Subscription: "sub",
+		Data:         `{"type":"LIKE"}`,
+		Attempts:     5,
+		Error:        "handler failed",
+		Status:       domain.StatusPending,
+		FailedAt:     failedAt,
+	}, created)
+}
+
+func TestSink_PutFailsOperation(t *testing.T) {
+	repo := &mockDeadLetterRepository{
+		createFunc: func(ctx context.Context, letter domain.DeadLetter) (string, error) {
+			return "letter-1", nil
+		},
+	}
+	var eventID, reason string
+	tracker := &helper.MockOperationTracker{
+		FailFunc: func(ctx context.Context, id, why string) error {
+			eventID, reason = id, why
+			return nil
+		},
+	}
+
+	err := NewSink(repo, tracker).Put(context.Background(), pubsub.DeadLetter{
+		Data:  json.RawMessage(`{"id":"event-1","type":"LIKE"}`),
+		Error: "post not found",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "event-1", eventID)
+	assert.Equal(t, "post not found", reason)
+}
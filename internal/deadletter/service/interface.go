@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ynwd/awesome-blog/internal/deadletter/domain"
+)
+
+type DeadLetterService interface {
+	ListDeadLetters(ctx context.Context, filter domain.DeadLetterFilter) (domain.DeadLetterPage, error)
+	GetDeadLetter(ctx context.Context, id string) (domain.DeadLetter, error)
+	Redrive(ctx context.Context, id string) (domain.DeadLetter, error)
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var (
+	ErrUserNotFound     = errors.New("user not found")
+	ErrSelfFollow       = errors.New("users cannot follow themselves")
+	ErrAlreadyFollowing = errors.New("user already followed")
+	ErrFollowNotFound   = errors.New("follow not found")
+	ErrTooManyFollowing = errors.New("following too many users")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+)
+
+// MaxFollowing bounds the users a user can follow, which keeps building their
+// feed to a bounded number of queries
+const MaxFollowing = 300
+
+// Follows records that Follower follows Followee
+type Follows struct {
+	Follower  string    `json:"follower" firestore:"follower"`
+	Followee  string    `json:"followee" firestore:"followee"`
+	CreatedAt time.Time `json:"created_at,omitempty" firestore:"created_at"`
+}
+
+// FollowID is the document ID of a follow. It is derived from both usernames
+// so a user can only follow another user once.
+func FollowID(follower, followee string) string {
+	sum := sha256.Sum256([]byte(follower + "\x00" + followee))
+	return hex.EncodeToString(sum[:])
+}
+
+// FollowFilter selects a page of a user's followers or followees, newest
+// first
+type FollowFilter struct {
+	Username string
+	Cursor   string
+	Limit    int
+}
+
+// FollowPage is a page of follows. NextCursor is empty on the last page.
+type FollowPage struct {
+	Follows    []Follows
+	NextCursor string
+}
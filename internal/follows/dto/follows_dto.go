@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+type ListFollowsQuery struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+}
+
+type FollowUserResponse struct {
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type FollowersResponse struct {
+	Username   string               `json:"username"`
+	Count      int64                `json:"count"`
+	Followers  []FollowUserResponse `json:"followers"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+type FollowingResponse struct {
+	Username   string               `json:"username"`
+	Count      int64                `json:"count"`
+	Following  []FollowUserResponse `json:"following"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
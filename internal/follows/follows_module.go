@@ -0,0 +1,34 @@
+package follows
+
+import (
+	"cloud.google.com/go/firestore"
+	"github.com/ynwd/awesome-blog/internal/follows/handler"
+	"github.com/ynwd/awesome-blog/internal/follows/repo"
+	"github.com/ynwd/awesome-blog/internal/follows/service"
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+type Module struct {
+	handler *handler.FollowsHandler
+	service service.FollowsService
+}
+
+func NewModule(firestoreClient *firestore.Client, users module.UserLookup) *Module {
+	// Initialize repository
+	followsRepo := repo.NewFollowsRepository(firestoreClient)
+
+	// Initialize service
+	followsService := service.NewFollowsService(followsRepo, users)
+
+	return &Module{
+		handler: handler.NewFollowsHandler(followsService),
+		service: followsService,
+	}
+}
+
+// FollowGraph exposes who users follow to other modules
+func (m *Module) FollowGraph() module.FollowGraph {
+	return m.service
+}
+
+func (m *Module) RegisterEvents(registry *module.EventRegistry) {}
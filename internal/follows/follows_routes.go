@@ -0,0 +1,10 @@
+package follows
+
+import "github.com/gin-gonic/gin"
+
+func (m *Module) RegisterRoutes(router *gin.Engine) {
+	router.POST("/api/v1/users/:username/follow", m.handler.Follow)
+	router.DELETE("/api/v1/users/:username/follow", m.handler.Unfollow)
+	router.GET("/api/v1/users/:username/followers", m.handler.GetFollowers)
+	router.GET("/api/v1/users/:username/following", m.handler.GetFollowing)
+}
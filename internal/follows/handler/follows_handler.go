@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ynwd/awesome-blog/internal/follows/domain"
+	"github.com/ynwd/awesome-blog/internal/follows/dto"
+	"github.com/ynwd/awesome-blog/internal/follows/service"
+	"github.com/ynwd/awesome-blog/pkg/middleware"
+	"github.com/ynwd/awesome-blog/pkg/res"
+)
+
+type FollowsHandler struct {
+	followsService service.FollowsService
+}
+
+func NewFollowsHandler(followsService service.FollowsService) *FollowsHandler {
+	return &FollowsHandler{followsService: followsService}
+}
+
+// Follow makes the authenticated user follow the user in the path
+func (h *FollowsHandler) Follow(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	if err := h.followsService.Follow(c.Request.Context(), username, c.Param("username")); err != nil {
+		c.JSON(followErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(nil, "User followed successfully"))
+}
+
+// Unfollow makes the authenticated user stop following the user in the path
+func (h *FollowsHandler) Unfollow(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	if err := h.followsService.Unfollow(c.Request.Context(), username, c.Param("username")); err != nil {
+		c.JSON(followErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(nil, "User unfollowed successfully"))
+}
+
+// GetFollowers returns the follower count of a user and a page of followers,
+// newest first
+func (h *FollowsHandler) GetFollowers(c *gin.Context) {
+	filter, ok := followFilter(c)
+	if !ok {
+		return
+	}
+
+	count, page, err := h.followsService.GetFollowers(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(followErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	response := dto.FollowersResponse{
+		Username:   filter.Username,
+		Count:      count,
+		Followers:  make([]dto.FollowUserResponse, 0, len(page.Follows)),
+		NextCursor: page.NextCursor,
+	}
+	for _, follow := range page.Follows {
+		response.Followers = append(response.Followers, dto.FollowUserResponse{
+			Username:  follow.Follower,
+			CreatedAt: follow.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, res.Success(response, "Followers retrieved successfully"))
+}
+
+// GetFollowing returns the number of users a user follows and a page of them,
+// most recently followed first
+func (h *FollowsHandler) GetFollowing(c *gin.Context) {
+	filter, ok := followFilter(c)
+	if !ok {
+		return
+	}
+
+	count, page, err := h.followsService.GetFollowing(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(followErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	response := dto.FollowingResponse{
+		Username:   filter.Username,
+		Count:      count,
+		Following:  make([]dto.FollowUserResponse, 0, len(page.Follows)),
+		NextCursor: page.NextCursor,
+	}
+	for _, follow := range page.Follows {
+		response.Following = append(response.Following, dto.FollowUserResponse{
+			Username:  follow.Followee,
+			CreatedAt: follow.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, res.Success(response, "Following retrieved successfully"))
+}
+
+// followFilter reads the page of follows to list, writing the error response
+// when the query is invalid
+func followFilter(c *gin.Context) (domain.FollowFilter, bool) {
+	var query dto.ListFollowsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error("Invalid query parameters"))
+		return domain.FollowFilter{}, false
+	}
+
+	return domain.FollowFilter{
+		Username: c.Param("username"),
+		Cursor:   query.Cursor,
+		Limit:    query.Limit,
+	}, true
+}
+
+func followErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrTooManyFollowing):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrSelfFollow),
+		errors.Is(err, domain.ErrInvalidCursor),
+		errors.Is(err, service.ErrInvalidUsername):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/ynwd/awesome-blog/internal/follows/domain"
+	"github.com/ynwd/awesome-blog/internal/follows/service"
+)
+
+type mockFollowsService struct {
+	followFunc       func(ctx context.Context, follower, followee string) error
+	unfollowFunc     func(ctx context.Context, follower, followee string) error
+	getFollowersFunc func(ctx context.Context, filter domain.FollowFilter) (int64, domain.FollowPage, error)
+	getFollowingFunc func(ctx context.Context, filter domain.FollowFilter) (int64, domain.FollowPage, error)
+}
+
+func (m *mockFollowsService) Follow(ctx context.Context, follower, followee string) error {
+	return m.followFunc(ctx, follower, followee)
+}
+
+func (m *mockFollowsService) Unfollow(ctx context.Context, follower, followee string) error {
+	return m.unfollowFunc(ctx, follower, followee)
+}
+
+func (m *mockFollowsService) GetFollowers(ctx context.Context, filter domain.FollowFilter) (int64, domain.FollowPage, error) {
+	return m.getFollowersFunc(ctx, filter)
+}
+
+func (m *mockFollowsService) GetFollowing(ctx context.Context, filter domain.FollowFilter) (int64, domain.FollowPage, error) {
+	return m.getFollowingFunc(ctx, filter)
+}
+
+func (m *mockFollowsService) Followees(ctx context.Context, username string) ([]string, error) {
+	return nil, nil
+}
+
+func setupRouter(followsService service.FollowsService, username string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if username != "" {
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", username)
+		})
+	}
+	h := NewFollowsHandler(followsService)
+	router.POST("/api/v1/users/:username/follow", h.Follow)
+	router.DELETE("/api/v1/users/:username/follow", h.Unfollow)
+	router.GET("/api/v1/users/:username/followers", h.GetFollowers)
+	router.GET("/api/v1/users/:username/following", h.GetFollowing)
+	return router
+}
+
+func TestFollowsHandler_Follow(t *testing.T) {
+	followsService := &mockFollowsService{
+		followFunc: func(ctx context.Context, follower, followee string) error {
+			switch followee {
+			case "nobody":
+				return domain.ErrUserNotFound
+			case follower:
+				return domain.ErrSelfFollow
+			case "popular":
+				return domain.ErrTooManyFollowing
+			}
+			return nil
+		},
+		unfollowFunc: func(ctx context.Context, follower, followee string) error {
+			assert.Equal(t, "reader", follower)
+			return nil
+		},
+	}
+
+	tests := []struct {
+		name       string
+		username   string
+		method     string
+		followee   string
+		wantStatus int
+	}{
+		{name: "follow", username: "reader", method: http.MethodPost, followee: "author", wantStatus: http.StatusOK},
+		{name: "unknown user", username: "reader", method: http.MethodPost, followee: "nobody", wantStatus: http.StatusNotFound},
+		{name: "self", username: "reader", method: http.MethodPost, followee: "reader", wantStatus: http.StatusBadRequest},
+		{name: "limit reached", username: "reader", method: http.MethodPost, followee: "popular", wantStatus: http.StatusConflict},
+		{name: "unauthenticated", method: http.MethodPost, followee: "author", wantStatus: http.StatusUnauthorized},
+		{name: "unfollow", username: "reader", method: http.MethodDelete, followee: "author", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupRouter(followsService, tt.username)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, "/api/v1/users/"+tt.followee+"/follow", nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestFollowsHandler_GetFollowers(t *testing.T) {
+	followedAt := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	followsService := &mockFollowsService{
+		getFollowersFunc: func(ctx context.Context, filter domain.FollowFilter) (int64, domain.FollowPage, error) {
+			if filter.Cursor == "bad" {
+				return 0, domain.FollowPage{}, domain.ErrInvalidCursor
+			}
+			assert.Equal(t, "author", filter.Username)
+			assert.Equal(t, 1, filter.Limit)
+			return 2, domain.FollowPage{
+				Follows:    []domain.Follows{{Follower: "reader", Followee: "author", CreatedAt: followedAt}},
+				NextCursor: "next",
+			}, nil
+		},
+		getFollowingFunc: func(ctx context.Context, filter domain.FollowFilter) (int64, domain.FollowPage, error) {
+			return 1, domain.FollowPage{
+				Follows: []domain.Follows{{Follower: "author", Followee: "alice", CreatedAt: followedAt}},
+			}, nil
+		},
+	}
+	router := setupRouter(followsService, "")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/author/followers?limit=1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"status": "success",
+		"message": "Followers retrieved successfully",
+		"data": {
+			"username": "author",
+			"count": 2,
+			"followers": [{"username": "reader", "created_at": "2025-02-01T10:00:00Z"}],
+			"next_cursor": "next"
+		}
+	}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/author/following", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"status": "success",
+		"message": "Following retrieved successfully",
+		"data": {
+			"username": "author",
+			"count": 1,
+			"following": [{"username": "alice", "created_at": "2025-02-01T10:00:00Z"}]
+		}
+	}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/author/followers?cursor=bad", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,124 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	firestorepb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	"github.com/ynwd/awesome-blog/internal/follows/domain"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const FollowsCollection = "follows"
+
+type followsFirestore struct {
+	client *firestore.Client
+}
+
+func NewFollowsRepository(client *firestore.Client) FollowsRepository {
+	return &followsFirestore{client: client}
+}
+
+// Create stores the follow under its deterministic ID and returns
+// domain.ErrAlreadyFollowing when the user already follows the followee
+func (r *followsFirestore) Create(ctx context.Context, follow domain.Follows) error {
+	ref := r.client.Collection(FollowsCollection).Doc(domain.FollowID(follow.Follower, follow.Followee))
+	_, err := ref.Create(ctx, follow)
+	if status.Code(err) == codes.AlreadyExists {
+		return domain.ErrAlreadyFollowing
+	}
+	return err
+}
+
+func (r *followsFirestore) Delete(ctx context.Context, follower, followee string) error {
+	ref := r.client.Collection(FollowsCollection).Doc(domain.FollowID(follower, followee))
+	_, err := ref.Delete(ctx, firestore.Exists)
+	if status.Code(err) == codes.NotFound {
+		return domain.ErrFollowNotFound
+	}
+	return err
+}
+
+func (r *followsFirestore) CountFollowers(ctx context.Context, username string) (int64, error) {
+	return r.count(ctx, "followee", username)
+}
+
+func (r *followsFirestore) CountFollowing(ctx context.Context, username string) (int64, error) {
+	return r.count(ctx, "follower", username)
+}
+
+// ListFollowers returns the user's followers, newest first. It requires a
+// composite index on (followee, created_at desc, __name__ desc).
+func (r *followsFirestore) ListFollowers(ctx context.Context, filter domain.FollowFilter) (domain.FollowPage, error) {
+	return r.list(ctx, "followee", filter)
+}
+
+// ListFollowing returns the users the user follows, newest first. It requires
+// a composite index on (follower, created_at desc, __name__ desc).
+func (r *followsFirestore) ListFollowing(ctx context.Context, filter domain.FollowFilter) (domain.FollowPage, error) {
+	return r.list(ctx, "follower", filter)
+}
+
+func (r *followsFirestore) count(ctx context.Context, field, username string) (int64, error) {
+	query := r.client.Collection(FollowsCollection).Where(field, "==", username)
+	result, err := query.NewAggregationQuery().WithCount("count").Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count, ok := result["count"].(*firestorepb.Value)
+	if !ok {
+		return 0, fmt.Errorf("unexpected count result: %v", result["count"])
+	}
+	return count.GetIntegerValue(), nil
+}
+
+func (r *followsFirestore) list(ctx context.Context, field string, filter domain.FollowFilter) (domain.FollowPage, error) {
+	query := r.client.Collection(FollowsCollection).
+		Where(field, "==", filter.Username).
+		OrderBy("created_at", firestore.Desc).
+		OrderBy(firestore.DocumentID, firestore.Desc)
+
+	if filter.Cursor != "" {
+		c, err := utils.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return domain.FollowPage{}, domain.ErrInvalidCursor
+		}
+		query = query.StartAfter(c.CreatedAt, c.ID)
+	}
+
+	// Fetch one extra document to know whether another page exists
+	iter := query.Limit(filter.Limit + 1).Documents(ctx)
+	defer iter.Stop()
+
+	var follows []domain.Follows
+	var ids []string
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return domain.FollowPage{}, err
+		}
+
+		var follow domain.Follows
+		if err := doc.DataTo(&follow); err != nil {
+			return domain.FollowPage{}, err
+		}
+		follows = append(follows, follow)
+		ids = append(ids, doc.Ref.ID)
+	}
+
+	page := domain.FollowPage{Follows: follows}
+	if len(follows) > filter.Limit {
+		page.Follows = follows[:filter.Limit]
+		last := page.Follows[filter.Limit-1]
+		page.NextCursor = utils.EncodeCursor(last.CreatedAt, ids[filter.Limit-1])
+	}
+	return page, nil
+}
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ynwd/awesome-blog/internal/follows/domain"
+	"github.com/ynwd/awesome-blog/tests/helper"
+)
+
+func TestFollowsRepository(t *testing.T) {
+	ctx := context.Background()
+	client := helper.SetupRepoClient(t)
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	err := helper.CleanDatabase()
+	require.NoError(t, err)
+
+	repo := NewFollowsRepository(client)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		err := repo.Create(ctx, domain.Follows{
+			Follower:  fmt.Sprintf("reader%d", i),
+			Followee:  "author",
+			CreatedAt: now.Add(time.Duration(i) * time.Second),
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.Create(ctx, domain.Follows{Follower: "reader0", Followee: "other", CreatedAt: now}))
+
+	err = repo.Create(ctx, domain.Follows{Follower: "reader0", Followee: "author", CreatedAt: now})
+	assert.ErrorIs(t, err, domain.ErrAlreadyFollowing)
+
+	count, err := repo.CountFollowers(ctx, "author")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	count, err = repo.CountFollowing(ctx, "reader0")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	page, err := repo.ListFollowers(ctx, domain.FollowFilter{Username: "author", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Follows, 2)
+	assert.Equal(t, "reader2", page.Follows[0].Follower)
+	assert.NotEmpty(t, page.NextCursor)
+
+	page, err = repo.ListFollowers(ctx, domain.FollowFilter{Username: "author", Cursor: page.NextCursor, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Follows, 1)
+	assert.Equal(t, "reader0", page.Follows[0].Follower)
+	assert.Empty(t, page.NextCursor)
+
+	page, err = repo.ListFollowing(ctx, domain.FollowFilter{Username: "reader0", Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, page.Follows, 2)
+
+	require.NoError(t, repo.Delete(ctx, "reader0", "author"))
+	assert.ErrorIs(t, repo.Delete(ctx, "reader0", "author"), domain.ErrFollowNotFound)
+
+	_, err = repo.ListFollowers(ctx, domain.FollowFilter{Username: "author", Cursor: "bad", Limit: 2})
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+}
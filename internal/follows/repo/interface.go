@@ -0,0 +1,16 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/ynwd/awesome-blog/internal/follows/domain"
+)
+
+type FollowsRepository interface {
+	Create(ctx context.Context, follow domain.Follows) error
+	Delete(ctx context.Context, follower, followee string) error
+	CountFollowers(ctx context.Context, username string) (int64, error)
+	CountFollowing(ctx context.Context, username string) (int64, error)
+	ListFollowers(ctx context.Context, filter domain.FollowFilter) (domain.FollowPage, error)
+	ListFollowing(ctx context.Context, filter domain.FollowFilter) (domain.FollowPage, error)
+}
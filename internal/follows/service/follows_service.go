@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/follows/domain"
+	"github.com/ynwd/awesome-blog/internal/follows/repo"
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var ErrInvalidUsername = errors.New("invalid username: cannot be empty")
+
+type followsService struct {
+	followsRepo repo.FollowsRepository
+	users       module.UserLookup
+}
+
+func NewFollowsService(followsRepo repo.FollowsRepository, users module.UserLookup) FollowsService {
+	return &followsService{
+		followsRepo: followsRepo,
+		users:       users,
+	}
+}
+
+// Follow makes follower follow followee. Following a user twice is a no-op.
+// The MaxFollowing limit is checked before the write, so concurrent follows
+// can exceed it slightly.
+func (s *followsService) Follow(ctx context.Context, follower, followee string) error {
+	if follower == "" || followee == "" {
+		return ErrInvalidUsername
+	}
+	if follower == followee {
+		return domain.ErrSelfFollow
+	}
+	if err := s.checkUser(ctx, followee); err != nil {
+		return err
+	}
+
+	following, err := s.followsRepo.CountFollowing(ctx, follower)
+	if err != nil {
+		return err
+	}
+	if following >= domain.MaxFollowing {
+		return domain.ErrTooManyFollowing
+	}
+
+	err = s.followsRepo.Create(ctx, domain.Follows{
+		Follower:  follower,
+		Followee:  followee,
+		CreatedAt: time.Now(),
+	})
+	if errors.Is(err, domain.ErrAlreadyFollowing) {
+		return nil
+	}
+	return err
+}
+
+// Unfollow stops follower following followee. Unfollowing a user that is not
+// followed is a no-op.
+func (s *followsService) Unfollow(ctx context.Context, follower, followee string) error {
+	if follower == "" || followee == "" {
+		return ErrInvalidUsername
+	}
+
+	err := s.followsRepo.Delete(ctx, follower, followee)
+	if errors.Is(err, domain.ErrFollowNotFound) {
+		return nil
+	}
+	return err
+}
+
+// GetFollowers returns the number of the user's followers and a page of them
+func (s *followsService) GetFollowers(ctx context.Context, filter domain.FollowFilter) (int64, domain.FollowPage, error) {
+	return s.list(ctx, filter, s.followsRepo.CountFollowers, s.followsRepo.ListFollowers)
+}
+
+// GetFollowing returns the number of users the user follows and a page of them
+func (s *followsService) GetFollowing(ctx context.Context, filter domain.FollowFilter) (int64, domain.FollowPage, error) {
+	return s.list(ctx, filter, s.followsRepo.CountFollowing, s.followsRepo.ListFollowing)
+}
+
+// Followees implements module.FollowGraph
+func (s *followsService) Followees(ctx context.Context, username string) ([]string, error) {
+	if username == "" {
+		return nil, ErrInvalidUsername
+	}
+
+	page, err := s.followsRepo.ListFollowing(ctx, domain.FollowFilter{Username: username, Limit: domain.MaxFollowing})
+	if err != nil {
+		return nil, err
+	}
+	followees := make([]string, 0, len(page.Follows))
+	for _, follow := range page.Follows {
+		followees = append(followees, follow.Followee)
+	}
+	return followees, nil
+}
+
+func (s *followsService) list(
+	ctx context.Context,
+	filter domain.FollowFilter,
+	count func(ctx context.Context, username string) (int64, error),
+	list func(ctx context.Context, filter domain.FollowFilter) (domain.FollowPage, error),
+) (int64, domain.FollowPage, error) {
+	if filter.Username == "" {
+		return 0, domain.FollowPage{}, ErrInvalidUsername
+	}
+	if err := s.checkUser(ctx, filter.Username); err != nil {
+		return 0, domain.FollowPage{}, err
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultPageSize
+	}
+	if filter.Limit > MaxPageSize {
+		filter.Limit = MaxPageSize
+	}
+
+	total, err := count(ctx, filter.Username)
+	if err != nil {
+		return 0, domain.FollowPage{}, err
+	}
+
+	page, err := list(ctx, filter)
+	if err != nil {
+		return 0, domain.FollowPage{}, err
+	}
+	return total, page, nil
+}
+
+// checkUser returns domain.ErrUserNotFound when the user does not exist
+func (s *followsService) checkUser(ctx context.Context, username string) error {
+	exists, err := s.users.UserExists(ctx, username)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
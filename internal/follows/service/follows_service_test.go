@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ynwd/awesome-blog/internal/follows/domain"
+	"github.com/ynwd/awesome-blog/tests/helper"
+)
+
+// memoryFollows keeps follows in memory, newest first
+type memoryFollows struct {
+	follows map[string]domain.Follows
+}
+
+func newMemoryFollows() *memoryFollows {
+	return &memoryFollows{follows: make(map[string]domain.Follows)}
+}
+
+func (m *memoryFollows) Create(ctx context.Context, follow domain.Follows) error {
+	id := domain.FollowID(follow.Follower, follow.Followee)
+	if _, ok := m.follows[id]; ok {
+		return domain.ErrAlreadyFollowing
+	}
+	m.follows[id] = follow
+	return nil
+}
+
+func (m *memoryFollows) Delete(ctx context.Context, follower, followee string) error {
+	id := domain.FollowID(follower, followee)
+	if _, ok := m.follows[id]; !ok {
+		return domain.ErrFollowNotFound
+	}
+	delete(m.follows, id)
+	return nil
+}
+
+func (m *memoryFollows) CountFollowers(ctx context.Context, username string) (int64, error) {
+	return int64(len(m.matching(func(f domain.Follows) bool { return f.Followee == username }))), nil
+}
+
+func (m *memoryFollows) CountFollowing(ctx context.Context, username string) (int64, error) {
+	return int64(len(m.matching(func(f domain.Follows) bool { return f.Follower == username }))), nil
+}
+
+func (m *memoryFollows) ListFollowers(ctx context.Context, filter domain.FollowFilter) (domain.FollowPage, error) {
+	return m.page(m.matching(func(f domain.Follows) bool { return f.Followee == filter.Username }), filter.Limit), nil
+}
+
+func (m *memoryFollows) ListFollowing(ctx context.Context, filter domain.FollowFilter) (domain.FollowPage, error) {
+	return m.page(m.matching(func(f domain.Follows) bool { return f.Follower == filter.Username }), filter.Limit), nil
+}
+
+func (m *memoryFollows) matching(match func(domain.Follows) bool) []domain.Follows {
+	var follows []domain.Follows
+	for _, follow := range m.follows {
+		if match(follow) {
+			follows = append(follows, follow)
+		}
+	}
+	sort.Slice(follows, func(i, j int) bool { return follows[i].CreatedAt.After(follows[j].CreatedAt) })
+	return follows
+}
+
+func (m *memoryFollows) page(follows []domain.Follows, limit int) domain.FollowPage {
+	if len(follows) > limit {
+		return domain.FollowPage{Follows: follows[:limit], NextCursor: "next"}
+	}
+	return domain.FollowPage{Follows: follows}
+}
+
+func newTestService() (FollowsService, *memoryFollows) {
+	follows := newMemoryFollows()
+	users := &helper.MockUserLookup{
+		UserExistsFunc: func(ctx context.Context, username string) (bool, error) {
+			return username != "nobody", nil
+		},
+	}
+	return NewFollowsService(follows, users), follows
+}
+
+func TestFollowsService_Follow(t *testing.T) {
+	service, follows := newTestService()
+	ctx := context.Background()
+
+	require.NoError(t, service.Follow(ctx, "reader", "author"))
+	require.NoError(t, service.Follow(ctx, "reader", "author"))
+	assert.Len(t, follows.follows, 1)
+
+	assert.ErrorIs(t, service.Follow(ctx, "reader", "reader"), domain.ErrSelfFollow)
+	assert.ErrorIs(t, service.Follow(ctx, "reader", "nobody"), domain.ErrUserNotFound)
+	assert.ErrorIs(t, service.Follow(ctx, "", "author"), ErrInvalidUsername)
+
+	require.NoError(t, service.Unfollow(ctx, "reader", "author"))
+	require.NoError(t, service.Unfollow(ctx, "reader", "author"))
+	assert.Empty(t, follows.follows)
+}
+
+func TestFollowsService_Follow_Limit(t *testing.T) {
+	service, follows := newTestService()
+	ctx := context.Background()
+
+	for i := 0; i < domain.MaxFollowing; i++ {
+		followee := fmt.Sprintf("user%d", i)
+		follows.follows[domain.FollowID("reader", followee)] = domain.Follows{Follower: "reader", Followee: followee}
+	}
+	assert.ErrorIs(t, service.Follow(ctx, "reader", "author"), domain.ErrTooManyFollowing)
+}
+
+func TestFollowsService_GetFollowers(t *testing.T) {
+	service, _ := newTestService()
+	ctx := context.Background()
+
+	for _, reader := range []string{"alice", "bob", "carol"} {
+		require.NoError(t, service.Follow(ctx, reader, "author"))
+		time.Sleep(time.Millisecond)
+	}
+	require.NoError(t, service.Follow(ctx, "author", "alice"))
+
+	count, page, err := service.GetFollowers(ctx, domain.FollowFilter{Username: "author", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	require.Len(t, page.Follows, 2)
+	assert.Equal(t, "carol", page.Follows[0].Follower)
+	assert.NotEmpty(t, page.NextCursor)
+
+	count, page, err = service.GetFollowing(ctx, domain.FollowFilter{Username: "author"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, "alice", page.Follows[0].Followee)
+
+	_, _, err = service.GetFollowers(ctx, domain.FollowFilter{Username: "nobody"})
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
+
+func TestFollowsService_Followees(t *testing.T) {
+	service, _ := newTestService()
+	ctx := context.Background()
+
+	require.NoError(t, service.Follow(ctx, "reader", "alice"))
+	time.Sleep(time.Millisecond)
+	require.NoError(t, service.Follow(ctx, "reader", "bob"))
+
+	followees, err := service.Followees(ctx, "reader")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob", "alice"}, followees)
+
+	followees, err = service.Followees(ctx, "author")
+	require.NoError(t, err)
+	assert.Empty(t, followees)
+}
+
+func TestFollowsService_UserLookupError(t *testing.T) {
+	users := &helper.MockUserLookup{
+		UserExistsFunc: func(ctx context.Context, username string) (bool, error) {
+			return false, errors.New("firestore error")
+		},
+	}
+	service := NewFollowsService(newMemoryFollows(), users)
+
+	assert.EqualError(t, service.Follow(context.Background(), "reader", "author"), "firestore error")
+}
@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ynwd/awesome-blog/internal/follows/domain"
+)
+
+type FollowsService interface {
+	Follow(ctx context.Context, follower, followee string) error
+	Unfollow(ctx context.Context, follower, followee string) error
+	GetFollowers(ctx context.Context, filter domain.FollowFilter) (int64, domain.FollowPage, error)
+	GetFollowing(ctx context.Context, filter domain.FollowFilter) (int64, domain.FollowPage, error)
+	Followees(ctx context.Context, username string) ([]string, error)
+}
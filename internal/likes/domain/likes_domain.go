@@ -1,9 +1,41 @@
 package domain
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var (
+	ErrPostNotFound  = errors.New("post not found")
+	ErrAlreadyLiked  = errors.New("post already liked")
+	ErrLikeNotFound  = errors.New("like not found")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
 
 type Likes struct {
 	PostID       string    `json:"post_id" firestore:"post_id"`
 	UsernameFrom string    `json:"username_from" firestore:"username_from"`
 	CreatedAt    time.Time `json:"created_at,omitempty" firestore:"created_at"`
 }
+
+// LikeID is the document ID of a user's like on a post. It is derived from
+// both values so a user can only like a post once.
+func LikeID(postID, username string) string {
+	sum := sha256.Sum256([]byte(postID + "\x00" + username))
+	return hex.EncodeToString(sum[:])
+}
+
+// LikeFilter selects a page of likes on a post, newest first
+type LikeFilter struct {
+	PostID string
+	Cursor string
+	Limit  int
+}
+
+// LikePage is a page of likes. NextCursor is empty on the last page.
+type LikePage struct {
+	Likes      []Likes
+	NextCursor string
+}
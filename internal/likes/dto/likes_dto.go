@@ -1,11 +1,36 @@
 package dto
 
+import "time"
+
 type CreateLikeRequest struct {
 	PostID       string `json:"post_id" binding:"required"`
-	UsernameFrom string `json:"username_from" binding:"required"`
+	UsernameFrom string `json:"username_from"`
 }
 
 type DeleteLikeRequest struct {
 	PostID       string `json:"post_id" binding:"required"`
-	UsernameFrom string `json:"username_from" binding:"required"`
+	UsernameFrom string `json:"username_from"`
+}
+
+type ListLikesQuery struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+}
+
+type LikerResponse struct {
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PostLikesResponse struct {
+	PostID     string          `json:"post_id"`
+	Count      int64           `json:"count"`
+	Likers     []LikerResponse `json:"likers"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// PublishLikeResponse identifies the asynchronous write; its status is at
+// GET /operations/:id
+type PublishLikeResponse struct {
+	OperationID string `json:"operation_id"`
 }
@@ -2,51 +2,70 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"log"
-	"time"
 
 	"github.com/ynwd/awesome-blog/internal/likes/domain"
 	"github.com/ynwd/awesome-blog/internal/likes/service"
 	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/pubsub"
 	"github.com/ynwd/awesome-blog/pkg/utils"
 )
 
 type LikeEventHandler struct {
 	// likesRepo    repo.LikesRepository
-	service service.LikesService
+	service    service.LikesService
+	processed  utils.ProcessedEvents
+	operations module.OperationTracker
 }
 
-func NewLikeEventHandler(service service.LikesService) *LikeEventHandler {
+func NewLikeEventHandler(service service.LikesService, processed utils.ProcessedEvents, operations module.OperationTracker) *LikeEventHandler {
 	return &LikeEventHandler{
-		service: service,
+		service:    service,
+		processed:  processed,
+		operations: operations,
 	}
 }
 
-func (h *LikeEventHandler) Handle(ctx context.Context, event module.BaseEvent) error {
-	if event.Type != module.LikeEvent {
-		return nil
-	}
-	log.Printf("Raw event received: %+v", event)
-	payloadData, timeStamp := utils.EventParser(event)
+// Handle handles LIKE and UNLIKE events
+func (h *LikeEventHandler) Handle(ctx context.Context, event module.Event[domain.Likes]) error {
+	return utils.HandleOnce(ctx, h.processed, "likes", event.ID, func() error {
+		return h.handle(ctx, event)
+	})
+}
 
-	var payload domain.Likes
-	if err := json.Unmarshal(payloadData, &payload); err != nil {
-		log.Printf("Error unmarshaling payload: %v", err)
-		return err
+func (h *LikeEventHandler) handle(ctx context.Context, event module.Event[domain.Likes]) error {
+	log.Printf("Event received: %s %s", event.Type, event.ID)
+	payload := event.Payload
+
+	if event.Type == module.UnlikeEvent {
+		if err := h.service.DeleteLike(ctx, payload.PostID, payload.UsernameFrom); err != nil {
+			log.Printf("Error removing like: %v", err)
+			return err
+		}
+		module.SucceedOperation(ctx, h.operations, event.ID, "")
+
+		log.Printf("Successfully processed unlike event: %+v", payload)
+		return nil
 	}
 
-	createdAt, _ := time.Parse(time.RFC3339, timeStamp)
 	like := domain.Likes{
 		PostID:       payload.PostID,
 		UsernameFrom: payload.UsernameFrom,
-		CreatedAt:    createdAt,
+		CreatedAt:    event.Timestamp,
 	}
 
-	if err := h.service.CreateLike(ctx, like); err != nil {
+	err := h.service.CreateLike(ctx, like)
+	if errors.Is(err, domain.ErrPostNotFound) {
+		log.Printf("Rejecting like event for missing post %s", payload.PostID)
+		// Retrying cannot make the post appear
+		return pubsub.Permanent(err)
+	}
+	if err != nil {
 		log.Printf("Error saving like: %v", err)
 		return err
 	}
+	module.SucceedOperation(ctx, h.operations, event.ID, domain.LikeID(like.PostID, like.UsernameFrom))
 
 	log.Printf("Successfully processed like event: %+v", like)
 	return nil
@@ -2,17 +2,23 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/ynwd/awesome-blog/internal/likes/domain"
 	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/pubsub"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+	"github.com/ynwd/awesome-blog/tests/helper"
 )
 
 type mockLikesService struct {
-	createLikeFunc func(ctx context.Context, like domain.Likes) error
+	createLikeFunc   func(ctx context.Context, like domain.Likes) error
+	deleteLikeFunc   func(ctx context.Context, postID, username string) error
+	getPostLikesFunc func(ctx context.Context, filter domain.LikeFilter) (int64, domain.LikePage, error)
+	likeCountFunc    func(ctx context.Context, postID string) (int64, error)
 }
 
 func (m *mockLikesService) CreateLike(ctx context.Context, like domain.Likes) error {
@@ -22,65 +28,113 @@ func (m *mockLikesService) CreateLike(ctx context.Context, like domain.Likes) er
 	return nil
 }
 
+func (m *mockLikesService) DeleteLike(ctx context.Context, postID, username string) error {
+	if m.deleteLikeFunc != nil {
+		return m.deleteLikeFunc(ctx, postID, username)
+	}
+	return nil
+}
+
+func (m *mockLikesService) GetPostLikes(ctx context.Context, filter domain.LikeFilter) (int64, domain.LikePage, error) {
+	if m.getPostLikesFunc != nil {
+		return m.getPostLikesFunc(ctx, filter)
+	}
+	return 0, domain.LikePage{}, nil
+}
+
+func (m *mockLikesService) LikeCount(ctx context.Context, postID string) (int64, error) {
+	if m.likeCountFunc != nil {
+		return m.likeCountFunc(ctx, postID)
+	}
+	return 0, nil
+}
+
 func TestLikesEventHandler_Handle(t *testing.T) {
 	tests := []struct {
-		name      string
-		event     module.BaseEvent
-		setupMock func(*mockLikesService)
-		wantErr   bool
+		name          string
+		event         module.Event[domain.Likes]
+		setupMock     func(*mockLikesService)
+		wantErr       bool
+		wantPermanent bool
 	}{
 		{
-			name: "different event type returns nil",
-			event: module.BaseEvent{
-				Type: "different_type",
+			name: "service error returns error",
+			event: module.Event[domain.Likes]{
+				Type:    module.LikeEvent,
+				Payload: domain.Likes{},
+			},
+			setupMock: func(m *mockLikesService) {
+				m.createLikeFunc = func(ctx context.Context, like domain.Likes) error {
+					return errors.New("service error")
+				}
 			},
-			setupMock: func(m *mockLikesService) {},
-			wantErr:   false,
+			wantErr: true,
 		},
 		{
-			name: "invalid json payload returns error",
-			event: module.BaseEvent{
+			name: "missing post is rejected",
+			event: module.Event[domain.Likes]{
 				Type:    module.LikeEvent,
-				Payload: []byte("invalid json"),
+				Payload: domain.Likes{PostID: "missing", UsernameFrom: "user1"},
 			},
-			setupMock: func(m *mockLikesService) {},
-			wantErr:   true,
+			setupMock: func(m *mockLikesService) {
+				m.createLikeFunc = func(ctx context.Context, like domain.Likes) error {
+					return domain.ErrPostNotFound
+				}
+			},
+			wantErr:       true,
+			wantPermanent: true,
 		},
 		{
-			name: "service error returns error",
-			event: module.BaseEvent{
+			name: "successful handling returns nil",
+			event: module.Event[domain.Likes]{
 				Type: module.LikeEvent,
-				Payload: func() json.RawMessage {
-					b, _ := json.Marshal(domain.Likes{})
-					return b
-				}(),
+				Payload: domain.Likes{
+					UsernameFrom: "user1",
+					PostID:       "post1",
+				},
 			},
 			setupMock: func(m *mockLikesService) {
 				m.createLikeFunc = func(ctx context.Context, like domain.Likes) error {
-					return errors.New("service error")
+					return nil
 				}
 			},
-			wantErr: true,
+			wantErr: false,
 		},
 		{
-			name: "successful handling returns nil",
-			event: module.BaseEvent{
-				Type: module.LikeEvent,
-				Payload: func() json.RawMessage {
-					b, _ := json.Marshal(domain.Likes{
-						UsernameFrom: "user1",
-						PostID:       "post1",
-					})
-					return b
-				}(),
+			name: "unlike event removes the like",
+			event: module.Event[domain.Likes]{
+				Type: module.UnlikeEvent,
+				Payload: domain.Likes{
+					UsernameFrom: "user1",
+					PostID:       "post1",
+				},
 			},
 			setupMock: func(m *mockLikesService) {
 				m.createLikeFunc = func(ctx context.Context, like domain.Likes) error {
+					return errors.New("unexpected create")
+				}
+				m.deleteLikeFunc = func(ctx context.Context, postID, username string) error {
+					if postID != "post1" || username != "user1" {
+						return errors.New("unexpected like")
+					}
 					return nil
 				}
 			},
 			wantErr: false,
 		},
+		{
+			name: "unlike service error returns error",
+			event: module.Event[domain.Likes]{
+				Type:    module.UnlikeEvent,
+				Payload: domain.Likes{},
+			},
+			setupMock: func(m *mockLikesService) {
+				m.deleteLikeFunc = func(ctx context.Context, postID, username string) error {
+					return errors.New("service error")
+				}
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -88,11 +142,12 @@ func TestLikesEventHandler_Handle(t *testing.T) {
 			mockService := &mockLikesService{}
 			tt.setupMock(mockService)
 
-			handler := NewLikeEventHandler(mockService)
+			handler := NewLikeEventHandler(mockService, utils.NewMemoryProcessedEvents(time.Hour), &helper.MockOperationTracker{})
 			err := handler.Handle(context.Background(), tt.event)
 
 			if tt.wantErr {
 				assert.Error(t, err)
+				assert.Equal(t, tt.wantPermanent, pubsub.IsPermanent(err))
 				return
 			}
 			assert.NoError(t, err)
@@ -1,13 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ynwd/awesome-blog/internal/likes/domain"
 	"github.com/ynwd/awesome-blog/internal/likes/dto"
 	"github.com/ynwd/awesome-blog/internal/likes/service"
+	"github.com/ynwd/awesome-blog/pkg/middleware"
 	"github.com/ynwd/awesome-blog/pkg/module"
 	"github.com/ynwd/awesome-blog/pkg/pubsub"
 	"github.com/ynwd/awesome-blog/pkg/res"
@@ -16,17 +17,21 @@ import (
 type LikesHandler struct {
 	likesService service.LikesService
 	pubsub       pubsub.PubSubClient
+	operations   module.OperationTracker
 }
 
-func NewLikesHandler(likesService service.LikesService, pubsubClient pubsub.PubSubClient) *LikesHandler {
+func NewLikesHandler(likesService service.LikesService, pubsubClient pubsub.PubSubClient, operations module.OperationTracker) *LikesHandler {
 	return &LikesHandler{
 		likesService: likesService,
 		pubsub:       pubsubClient,
+		operations:   operations,
 	}
 }
 
 func (h *LikesHandler) RegisterRoutes(router *gin.Engine) {
 	router.POST("/likes", h.CreateLike)
+	router.DELETE("/likes", h.DeleteLike)
+	router.GET("/posts/:id/likes", h.GetPostLikes)
 }
 
 func (h *LikesHandler) CreateLike(c *gin.Context) {
@@ -36,36 +41,131 @@ func (h *LikesHandler) CreateLike(c *gin.Context) {
 		return
 	}
 
+	username, err := middleware.ActingUser(c, req.UsernameFrom)
+	if err != nil {
+		c.JSON(middleware.IdentityErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
 	like := domain.Likes{
 		PostID:       req.PostID,
-		UsernameFrom: req.UsernameFrom,
+		UsernameFrom: username,
 	}
 
 	if err := h.likesService.CreateLike(c.Request.Context(), like); err != nil {
-		c.JSON(http.StatusInternalServerError, res.Error(err.Error()))
+		c.JSON(likeErrorStatus(err), res.Error(err.Error()))
 		return
 	}
 
 	c.JSON(http.StatusCreated, res.Success(nil, "Like created successfully"))
 }
 
+// DeleteLike removes the authenticated user's like from a post
+func (h *LikesHandler) DeleteLike(c *gin.Context) {
+	var req dto.DeleteLikeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error(err.Error()))
+		return
+	}
+
+	username, err := middleware.ActingUser(c, req.UsernameFrom)
+	if err != nil {
+		c.JSON(middleware.IdentityErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	if err := h.likesService.DeleteLike(c.Request.Context(), req.PostID, username); err != nil {
+		c.JSON(likeErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(nil, "Like deleted successfully"))
+}
+
+// GetPostLikes returns the like count of a post and a page of likers, newest first
+func (h *LikesHandler) GetPostLikes(c *gin.Context) {
+	var query dto.ListLikesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error("Invalid query parameters"))
+		return
+	}
+
+	filter := domain.LikeFilter{
+		PostID: c.Param("id"),
+		Cursor: query.Cursor,
+		Limit:  query.Limit,
+	}
+
+	count, page, err := h.likesService.GetPostLikes(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(likeErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	response := dto.PostLikesResponse{
+		PostID:     filter.PostID,
+		Count:      count,
+		Likers:     make([]dto.LikerResponse, 0, len(page.Likes)),
+		NextCursor: page.NextCursor,
+	}
+	for _, like := range page.Likes {
+		response.Likers = append(response.Likers, dto.LikerResponse{
+			Username:  like.UsernameFrom,
+			CreatedAt: like.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, res.Success(response, "Likes retrieved successfully"))
+}
+
 func (h *LikesHandler) PublishLike(c *gin.Context) {
+	h.publish(c, module.LikeEvent)
+}
+
+// PublishUnlike publishes an UNLIKE event that removes the like asynchronously
+func (h *LikesHandler) PublishUnlike(c *gin.Context) {
+	h.publish(c, module.UnlikeEvent)
+}
+
+func (h *LikesHandler) publish(c *gin.Context, eventType module.EventType) {
 	var likeEvent domain.Likes
 	if err := c.ShouldBindJSON(&likeEvent); err != nil {
 		c.JSON(http.StatusBadRequest, res.Error("Invalid request payload"))
 		return
 	}
 
-	event := module.BaseEvent{
-		Type:      module.LikeEvent,
-		Payload:   likeEvent,
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	username, err := middleware.ActingUser(c, likeEvent.UsernameFrom)
+	if err != nil {
+		c.JSON(middleware.IdentityErrorStatus(err), res.Error(err.Error()))
+		return
 	}
+	likeEvent.UsernameFrom = username
+
+	event := module.NewEvent(eventType, likeEvent)
 
-	if err := h.pubsub.Publish(c.Request.Context(), event); err != nil {
+	err = module.PublishTracked(c.Request.Context(), h.pubsub, h.operations, event, username)
+	if errors.Is(err, module.ErrOperationNotStarted) {
+		c.JSON(http.StatusInternalServerError, res.Error("Failed to record likes operation"))
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, res.Error("Failed to publish likes event"))
 		return
 	}
 
-	c.JSON(http.StatusCreated, res.Success(nil, "likes event published successfully"))
+	c.Header("Location", "/operations/"+event.ID)
+	c.JSON(http.StatusCreated, res.Success(dto.PublishLikeResponse{OperationID: event.ID}, "likes event published successfully"))
+}
+
+func likeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPostNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidLike),
+		errors.Is(err, service.ErrInvalidPostID),
+		errors.Is(err, domain.ErrInvalidCursor):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
 }
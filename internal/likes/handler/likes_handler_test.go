@@ -7,12 +7,15 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/ynwd/awesome-blog/internal/likes/domain"
 	"github.com/ynwd/awesome-blog/internal/likes/dto"
+	"github.com/ynwd/awesome-blog/pkg/module"
 	"github.com/ynwd/awesome-blog/pkg/res"
 	"github.com/ynwd/awesome-blog/tests/helper"
 )
@@ -54,6 +57,35 @@ func TestLikesHandler_CreateLike(t *testing.T) {
 				Message: "assert.AnError general error for testing",
 			},
 		},
+		{
+			name: "post not found",
+			payload: dto.CreateLikeRequest{
+				PostID: "missing",
+			},
+			setupMocks: func(s *mockLikesService, p *helper.MockPubSub) {
+				s.createLikeFunc = func(ctx context.Context, like domain.Likes) error {
+					return domain.ErrPostNotFound
+				}
+			},
+			wantStatus: http.StatusNotFound,
+			wantRes: res.Response{
+				Status:  "error",
+				Message: "post not found",
+			},
+		},
+		{
+			name: "username mismatch",
+			payload: dto.CreateLikeRequest{
+				PostID:       "post1",
+				UsernameFrom: "someone-else",
+			},
+			setupMocks: func(s *mockLikesService, p *helper.MockPubSub) {},
+			wantStatus: http.StatusForbidden,
+			wantRes: res.Response{
+				Status:  "error",
+				Message: "username does not match the authenticated user",
+			},
+		},
 		{
 			name: "success",
 			payload: dto.CreateLikeRequest{
@@ -76,12 +108,15 @@ func TestLikesHandler_CreateLike(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user_id", "user1")
+			})
 
 			mockService := &mockLikesService{}
 			mockPubsub := &helper.MockPubSub{}
 			tt.setupMocks(mockService, mockPubsub)
 
-			handler := NewLikesHandler(mockService, mockPubsub)
+			handler := NewLikesHandler(mockService, mockPubsub, &helper.MockOperationTracker{})
 			handler.RegisterRoutes(router)
 
 			payloadBytes, _ := json.Marshal(tt.payload)
@@ -131,6 +166,14 @@ func TestPostsHandler_PublishPost(t *testing.T) {
 			reqBody:    "invalid json",
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name: "username mismatch",
+			reqBody: domain.Likes{
+				PostID:       "post1",
+				UsernameFrom: "someone-else",
+			},
+			wantStatus: http.StatusForbidden,
+		},
 		{
 			name: "pubsub error",
 			reqBody: domain.Likes{
@@ -150,7 +193,7 @@ func TestPostsHandler_PublishPost(t *testing.T) {
 			mockPubSub := &helper.MockPubSub{
 				PublishFunc: tt.mockPubFn,
 			}
-			handler := NewLikesHandler(mockSvc, mockPubSub)
+			handler := NewLikesHandler(mockSvc, mockPubSub, &helper.MockOperationTracker{})
 
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
@@ -158,6 +201,7 @@ func TestPostsHandler_PublishPost(t *testing.T) {
 			jsonBody, _ := json.Marshal(tt.reqBody)
 			c.Request = httptest.NewRequest(http.MethodPost, "/likes/pubsub", bytes.NewBuffer(jsonBody))
 			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("user_id", "user1")
 
 			handler.PublishLike(c)
 
@@ -166,8 +210,210 @@ func TestPostsHandler_PublishPost(t *testing.T) {
 				var got res.Response
 				err := json.Unmarshal(w.Body.Bytes(), &got)
 				assert.NoError(t, err)
+
+				// The operation ID is the random event ID, returned in both places
+				location := w.Header().Get("Location")
+				assert.True(t, strings.HasPrefix(location, "/operations/"))
+				assert.Equal(t, map[string]interface{}{"operation_id": strings.TrimPrefix(location, "/operations/")}, got.Data)
+				got.Data = nil
 				assert.Equal(t, tt.wantResp, got)
 			}
 		})
 	}
 }
+
+func TestLikesHandler_DeleteLike(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		payload    interface{}
+		setupMocks func(*mockLikesService)
+		wantStatus int
+		wantRes    res.Response
+	}{
+		{
+			name:       "missing post id",
+			payload:    dto.DeleteLikeRequest{},
+			setupMocks: func(s *mockLikesService) {},
+			wantStatus: http.StatusBadRequest,
+			wantRes: res.Response{
+				Status:  "error",
+				Message: "Key: 'DeleteLikeRequest.PostID' Error:Field validation for 'PostID' failed on the 'required' tag",
+			},
+		},
+		{
+			name:       "username mismatch",
+			payload:    dto.DeleteLikeRequest{PostID: "post1", UsernameFrom: "someone-else"},
+			setupMocks: func(s *mockLikesService) {},
+			wantStatus: http.StatusForbidden,
+			wantRes: res.Response{
+				Status:  "error",
+				Message: "username does not match the authenticated user",
+			},
+		},
+		{
+			name:    "service error",
+			payload: dto.DeleteLikeRequest{PostID: "post1"},
+			setupMocks: func(s *mockLikesService) {
+				s.deleteLikeFunc = func(ctx context.Context, postID, username string) error {
+					return assert.AnError
+				}
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantRes: res.Response{
+				Status:  "error",
+				Message: "assert.AnError general error for testing",
+			},
+		},
+		{
+			name:    "success",
+			payload: dto.DeleteLikeRequest{PostID: "post1"},
+			setupMocks: func(s *mockLikesService) {
+				s.deleteLikeFunc = func(ctx context.Context, postID, username string) error {
+					assert.Equal(t, "post1", postID)
+					assert.Equal(t, "user1", username)
+					return nil
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantRes: res.Response{
+				Status:  "success",
+				Message: "Like deleted successfully",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user_id", "user1")
+			})
+
+			mockService := &mockLikesService{}
+			tt.setupMocks(mockService)
+
+			handler := NewLikesHandler(mockService, &helper.MockPubSub{}, &helper.MockOperationTracker{})
+			handler.RegisterRoutes(router)
+
+			payloadBytes, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest(http.MethodDelete, "/likes", bytes.NewBuffer(payloadBytes))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			var got res.Response
+			err := json.Unmarshal(w.Body.Bytes(), &got)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRes, got)
+		})
+	}
+}
+
+func TestLikesHandler_GetPostLikes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	likedAt := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		url        string
+		setupMocks func(*mockLikesService)
+		wantStatus int
+		wantData   dto.PostLikesResponse
+	}{
+		{
+			name: "success",
+			url:  "/posts/post1/likes?limit=1&cursor=abc",
+			setupMocks: func(s *mockLikesService) {
+				s.getPostLikesFunc = func(ctx context.Context, filter domain.LikeFilter) (int64, domain.LikePage, error) {
+					assert.Equal(t, domain.LikeFilter{PostID: "post1", Cursor: "abc", Limit: 1}, filter)
+					return 3, domain.LikePage{
+						Likes:      []domain.Likes{{PostID: "post1", UsernameFrom: "user2", CreatedAt: likedAt}},
+						NextCursor: "next",
+					}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantData: dto.PostLikesResponse{
+				PostID:     "post1",
+				Count:      3,
+				Likers:     []dto.LikerResponse{{Username: "user2", CreatedAt: likedAt}},
+				NextCursor: "next",
+			},
+		},
+		{
+			name:       "no likes",
+			url:        "/posts/post1/likes",
+			setupMocks: func(s *mockLikesService) {},
+			wantStatus: http.StatusOK,
+			wantData: dto.PostLikesResponse{
+				PostID: "post1",
+				Likers: []dto.LikerResponse{},
+			},
+		},
+		{
+			name:       "invalid limit",
+			url:        "/posts/post1/likes?limit=abc",
+			setupMocks: func(s *mockLikesService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+
+			mockService := &mockLikesService{}
+			tt.setupMocks(mockService)
+
+			handler := NewLikesHandler(mockService, &helper.MockPubSub{}, &helper.MockOperationTracker{})
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var got struct {
+					Data dto.PostLikesResponse `json:"data"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &got)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantData, got.Data)
+			}
+		})
+	}
+}
+
+func TestLikesHandler_PublishUnlike(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var published module.BaseEvent
+	mockPubSub := &helper.MockPubSub{
+		PublishFunc: func(ctx context.Context, event interface{}) error {
+			published = event.(module.BaseEvent)
+			return nil
+		},
+	}
+	handler := NewLikesHandler(&mockLikesService{}, mockPubSub, &helper.MockOperationTracker{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	jsonBody, _ := json.Marshal(domain.Likes{PostID: "post1"})
+	c.Request = httptest.NewRequest(http.MethodDelete, "/likes/pubsub", bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("user_id", "user1")
+
+	handler.PublishUnlike(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, module.UnlikeEvent, published.Type)
+	assert.Equal(t, "/operations/"+published.ID, w.Header().Get("Location"))
+	assert.Equal(t, domain.Likes{PostID: "post1", UsernameFrom: "user1"}, published.Payload)
+}
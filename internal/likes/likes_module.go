@@ -1,14 +1,13 @@
 package likes
 
 import (
-	"context"
-
 	"cloud.google.com/go/firestore"
 	"github.com/ynwd/awesome-blog/internal/likes/handler"
 	"github.com/ynwd/awesome-blog/internal/likes/repo"
 	"github.com/ynwd/awesome-blog/internal/likes/service"
 	"github.com/ynwd/awesome-blog/pkg/module"
 	"github.com/ynwd/awesome-blog/pkg/pubsub"
+	"github.com/ynwd/awesome-blog/pkg/utils"
 )
 
 type Module struct {
@@ -16,28 +15,36 @@ type Module struct {
 	// publishHandler *handler.LikesPublishHandler
 	eventHandler *handler.LikeEventHandler
 	pubsub       pubsub.PubSubClient
+	service      service.LikesService
 }
 
-func NewModule(firestoreClient *firestore.Client, pubsubClient pubsub.PubSubClient) *Module {
+func NewModule(firestoreClient *firestore.Client, pubsubClient pubsub.PubSubClient, posts module.PostLookup, processed utils.ProcessedEvents, operations module.OperationTracker) *Module {
 	// Initialize repository
 	likesRepo := repo.NewLikesRepository(firestoreClient)
 
 	// Initialize service
-	likesService := service.NewLikesService(likesRepo)
+	likesService := service.NewLikesService(likesRepo, posts)
 
 	// Initialize handler
-	likesHandler := handler.NewLikesHandler(likesService, pubsubClient)
+	likesHandler := handler.NewLikesHandler(likesService, pubsubClient, operations)
 
 	// Initialize event handler
-	eventHandler := handler.NewLikeEventHandler(likesService)
+	eventHandler := handler.NewLikeEventHandler(likesService, processed, operations)
 
 	return &Module{
 		handler:      likesHandler,
 		pubsub:       pubsubClient,
 		eventHandler: eventHandler,
+		service:      likesService,
 	}
 }
 
-func (m *Module) RegisterEventHandlers(ctx context.Context, event module.BaseEvent) {
-	m.eventHandler.Handle(ctx, event)
+// LikeCounter exposes like counts to other modules
+func (m *Module) LikeCounter() module.LikeCounter {
+	return m.service
+}
+
+func (m *Module) RegisterEvents(registry *module.EventRegistry) {
+	module.Handle(registry, module.LikeEvent, module.LikeEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.UnlikeEvent, module.UnlikeEventVersion, m.eventHandler.Handle)
 }
@@ -4,5 +4,9 @@ import "github.com/gin-gonic/gin"
 
 func (m *Module) RegisterRoutes(router *gin.Engine) {
 	router.POST("/likes", m.handler.CreateLike)
+	router.DELETE("/likes", m.handler.DeleteLike)
 	router.POST("/likes/pubsub", m.handler.PublishLike)
+	router.DELETE("/likes/pubsub", m.handler.PublishUnlike)
+
+	router.GET("/posts/:id/likes", m.handler.GetPostLikes)
 }
@@ -8,4 +8,7 @@ import (
 
 type LikesRepository interface {
 	Create(ctx context.Context, like domain.Likes) error
+	Delete(ctx context.Context, postID, username string) error
+	CountByPost(ctx context.Context, postID string) (int64, error)
+	ListByPost(ctx context.Context, filter domain.LikeFilter) (domain.LikePage, error)
 }
@@ -2,25 +2,136 @@ package repo
 
 import (
 	"context"
+	"fmt"
 	"os"
 
 	"cloud.google.com/go/firestore"
+	firestorepb "cloud.google.com/go/firestore/apiv1/firestorepb"
 	"github.com/ynwd/awesome-blog/internal/likes/domain"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/outbox"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// likesFirestore writes every like and unlike together with the domain event
+// reporting it to the outbox
 type likesFirestore struct {
 	client     *firestore.Client
 	collection string
+	outbox     *outbox.Outbox
 }
 
 func NewLikesRepository(client *firestore.Client) LikesRepository {
 	return &likesFirestore{
 		client:     client,
 		collection: os.Getenv("GOOGLE_CLOUD_FIRESTORE_COLLECTION_LIKES"),
+		outbox:     outbox.New(client),
 	}
 }
 
+// Create stores the like under its deterministic ID and returns
+// domain.ErrAlreadyLiked when the user has already liked the post
 func (r *likesFirestore) Create(ctx context.Context, like domain.Likes) error {
-	_, _, err := r.client.Collection(r.collection).Add(ctx, like)
-	return err
+	ref := r.client.Collection(r.collection).Doc(domain.LikeID(like.PostID, like.UsernameFrom))
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		_, err := tx.Get(ref)
+		if err == nil {
+			return domain.ErrAlreadyLiked
+		}
+		if status.Code(err) != codes.NotFound {
+			return err
+		}
+
+		if err := tx.Create(ref, like); err != nil {
+			return err
+		}
+		return r.outbox.Add(tx, module.LikeCreatedEvent, like)
+	})
+}
+
+func (r *likesFirestore) Delete(ctx context.Context, postID, username string) error {
+	ref := r.client.Collection(r.collection).Doc(domain.LikeID(postID, username))
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return domain.ErrLikeNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var like domain.Likes
+		if err := doc.DataTo(&like); err != nil {
+			return err
+		}
+		if err := tx.Delete(ref); err != nil {
+			return err
+		}
+		return r.outbox.Add(tx, module.LikeDeletedEvent, like)
+	})
+}
+
+func (r *likesFirestore) CountByPost(ctx context.Context, postID string) (int64, error) {
+	query := r.client.Collection(r.collection).Where("post_id", "==", postID)
+	result, err := query.NewAggregationQuery().WithCount("count").Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count, ok := result["count"].(*firestorepb.Value)
+	if !ok {
+		return 0, fmt.Errorf("unexpected count result: %v", result["count"])
+	}
+	return count.GetIntegerValue(), nil
+}
+
+// ListByPost returns likes newest first. It requires a composite index on
+// (post_id, created_at desc, __name__ desc).
+func (r *likesFirestore) ListByPost(ctx context.Context, filter domain.LikeFilter) (domain.LikePage, error) {
+	query := r.client.Collection(r.collection).
+		Where("post_id", "==", filter.PostID).
+		OrderBy("created_at", firestore.Desc).
+		OrderBy(firestore.DocumentID, firestore.Desc)
+
+	if filter.Cursor != "" {
+		c, err := utils.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return domain.LikePage{}, domain.ErrInvalidCursor
+		}
+		query = query.StartAfter(c.CreatedAt, c.ID)
+	}
+
+	// Fetch one extra document to know whether another page exists
+	iter := query.Limit(filter.Limit + 1).Documents(ctx)
+	defer iter.Stop()
+
+	var likes []domain.Likes
+	var ids []string
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return domain.LikePage{}, err
+		}
+
+		var like domain.Likes
+		if err := doc.DataTo(&like); err != nil {
+			return domain.LikePage{}, err
+		}
+		likes = append(likes, like)
+		ids = append(ids, doc.Ref.ID)
+	}
+
+	page := domain.LikePage{Likes: likes}
+	if len(likes) > filter.Limit {
+		page.Likes = likes[:filter.Limit]
+		last := page.Likes[filter.Limit-1]
+		page.NextCursor = utils.EncodeCursor(last.CreatedAt, ids[filter.Limit-1])
+	}
+	return page, nil
 }
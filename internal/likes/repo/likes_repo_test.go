@@ -64,3 +64,71 @@ func TestLikesRepository_Create(t *testing.T) {
 		})
 	}
 }
+
+func TestLikesRepository_Idempotency(t *testing.T) {
+	ctx := context.Background()
+	client := helper.SetupRepoClient(t)
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+
+	repo := NewLikesRepository(client)
+	like := domain.Likes{
+		UsernameFrom: "testuser",
+		PostID:       "test-post-123",
+		CreatedAt:    time.Now(),
+	}
+
+	assert.NoError(t, repo.Create(ctx, like))
+	assert.ErrorIs(t, repo.Create(ctx, like), domain.ErrAlreadyLiked)
+
+	count, err := repo.CountByPost(ctx, like.PostID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	assert.NoError(t, repo.Delete(ctx, like.PostID, like.UsernameFrom))
+	assert.ErrorIs(t, repo.Delete(ctx, like.PostID, like.UsernameFrom), domain.ErrLikeNotFound)
+
+	count, err = repo.CountByPost(ctx, like.PostID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestLikesRepository_ListByPost(t *testing.T) {
+	ctx := context.Background()
+	client := helper.SetupRepoClient(t)
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+
+	repo := NewLikesRepository(client)
+	base := time.Now()
+	for i, username := range []string{"user1", "user2", "user3"} {
+		err := repo.Create(ctx, domain.Likes{
+			UsernameFrom: username,
+			PostID:       "test-post-123",
+			CreatedAt:    base.Add(time.Duration(i) * time.Second),
+		})
+		assert.NoError(t, err)
+	}
+
+	page, err := repo.ListByPost(ctx, domain.LikeFilter{PostID: "test-post-123", Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Likes, 2)
+	assert.Equal(t, "user3", page.Likes[0].UsernameFrom)
+	assert.NotEmpty(t, page.NextCursor)
+
+	page, err = repo.ListByPost(ctx, domain.LikeFilter{PostID: "test-post-123", Cursor: page.NextCursor, Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Likes, 1)
+	assert.Equal(t, "user1", page.Likes[0].UsernameFrom)
+	assert.Empty(t, page.NextCursor)
+}
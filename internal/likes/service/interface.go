@@ -8,4 +8,7 @@ import (
 
 type LikesService interface {
 	CreateLike(ctx context.Context, like domain.Likes) error
+	DeleteLike(ctx context.Context, postID, username string) error
+	GetPostLikes(ctx context.Context, filter domain.LikeFilter) (int64, domain.LikePage, error)
+	LikeCount(ctx context.Context, postID string) (int64, error)
 }
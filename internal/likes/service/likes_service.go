@@ -7,6 +7,12 @@ import (
 
 	"github.com/ynwd/awesome-blog/internal/likes/domain"
 	"github.com/ynwd/awesome-blog/internal/likes/repo"
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
 )
 
 var (
@@ -17,19 +23,91 @@ var (
 
 type likesService struct {
 	likesRepo repo.LikesRepository
+	posts     module.PostLookup
 }
 
-func NewLikesService(likesRepo repo.LikesRepository) LikesService {
+func NewLikesService(likesRepo repo.LikesRepository, posts module.PostLookup) LikesService {
 	return &likesService{
 		likesRepo: likesRepo,
+		posts:     posts,
 	}
 }
 
+// CreateLike likes the post. Liking a post twice is a no-op.
 func (s *likesService) CreateLike(ctx context.Context, like domain.Likes) error {
 	if like.PostID == "" || like.UsernameFrom == "" {
 		return ErrInvalidLike
 	}
+	if err := s.checkPost(ctx, like.PostID); err != nil {
+		return err
+	}
+
+	if like.CreatedAt.IsZero() {
+		like.CreatedAt = time.Now()
+	}
+	err := s.likesRepo.Create(ctx, like)
+	if errors.Is(err, domain.ErrAlreadyLiked) {
+		return nil
+	}
+	return err
+}
+
+// DeleteLike removes the user's like. Unliking a post that is not liked is a no-op.
+func (s *likesService) DeleteLike(ctx context.Context, postID, username string) error {
+	if postID == "" || username == "" {
+		return ErrInvalidLike
+	}
+
+	err := s.likesRepo.Delete(ctx, postID, username)
+	if errors.Is(err, domain.ErrLikeNotFound) {
+		return nil
+	}
+	return err
+}
+
+// GetPostLikes returns the total number of likes on the post and a page of likers
+func (s *likesService) GetPostLikes(ctx context.Context, filter domain.LikeFilter) (int64, domain.LikePage, error) {
+	if filter.PostID == "" {
+		return 0, domain.LikePage{}, ErrInvalidPostID
+	}
+	if err := s.checkPost(ctx, filter.PostID); err != nil {
+		return 0, domain.LikePage{}, err
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultPageSize
+	}
+	if filter.Limit > MaxPageSize {
+		filter.Limit = MaxPageSize
+	}
+
+	count, err := s.likesRepo.CountByPost(ctx, filter.PostID)
+	if err != nil {
+		return 0, domain.LikePage{}, err
+	}
+
+	page, err := s.likesRepo.ListByPost(ctx, filter)
+	if err != nil {
+		return 0, domain.LikePage{}, err
+	}
+	return count, page, nil
+}
 
-	like.CreatedAt = time.Now()
-	return s.likesRepo.Create(ctx, like)
+// LikeCount returns the total number of likes on the post
+func (s *likesService) LikeCount(ctx context.Context, postID string) (int64, error) {
+	if postID == "" {
+		return 0, ErrInvalidPostID
+	}
+	return s.likesRepo.CountByPost(ctx, postID)
+}
+
+// checkPost returns domain.ErrPostNotFound when the post does not exist
+func (s *likesService) checkPost(ctx context.Context, postID string) error {
+	exists, err := s.posts.PostExists(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrPostNotFound
+	}
+	return nil
 }
@@ -6,10 +6,14 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/ynwd/awesome-blog/internal/likes/domain"
+	"github.com/ynwd/awesome-blog/tests/helper"
 )
 
 type mockLikesRepository struct {
 	createFunc func(ctx context.Context, like domain.Likes) error
+	deleteFunc func(ctx context.Context, postID, username string) error
+	countFunc  func(ctx context.Context, postID string) (int64, error)
+	listFunc   func(ctx context.Context, filter domain.LikeFilter) (domain.LikePage, error)
 }
 
 func (m *mockLikesRepository) Create(ctx context.Context, like domain.Likes) error {
@@ -19,6 +23,27 @@ func (m *mockLikesRepository) Create(ctx context.Context, like domain.Likes) err
 	return nil
 }
 
+func (m *mockLikesRepository) Delete(ctx context.Context, postID, username string) error {
+	if m.deleteFunc != nil {
+		return m.deleteFunc(ctx, postID, username)
+	}
+	return nil
+}
+
+func (m *mockLikesRepository) CountByPost(ctx context.Context, postID string) (int64, error) {
+	if m.countFunc != nil {
+		return m.countFunc(ctx, postID)
+	}
+	return 0, nil
+}
+
+func (m *mockLikesRepository) ListByPost(ctx context.Context, filter domain.LikeFilter) (domain.LikePage, error) {
+	if m.listFunc != nil {
+		return m.listFunc(ctx, filter)
+	}
+	return domain.LikePage{}, nil
+}
+
 func TestLikesService_CreateLike(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -77,10 +102,127 @@ func TestLikesService_CreateLike(t *testing.T) {
 			mockRepo := &mockLikesRepository{}
 			tt.mockFn(mockRepo)
 
-			service := NewLikesService(mockRepo)
+			service := NewLikesService(mockRepo, &helper.MockPostLookup{})
 			err := service.CreateLike(context.Background(), tt.like)
 
 			assert.Equal(t, tt.wantError, err)
 		})
 	}
 }
+
+func TestLikesService_CreateLike_AlreadyLiked(t *testing.T) {
+	mockRepo := &mockLikesRepository{
+		createFunc: func(ctx context.Context, like domain.Likes) error {
+			return domain.ErrAlreadyLiked
+		},
+	}
+	service := NewLikesService(mockRepo, &helper.MockPostLookup{})
+
+	err := service.CreateLike(context.Background(), domain.Likes{PostID: "post1", UsernameFrom: "user1"})
+	assert.NoError(t, err)
+}
+
+func TestLikesService_DeleteLike(t *testing.T) {
+	tests := []struct {
+		name      string
+		postID    string
+		username  string
+		repoErr   error
+		wantError error
+	}{
+		{name: "removes like", postID: "post1", username: "user1"},
+		{name: "not liked is a no-op", postID: "post1", username: "user1", repoErr: domain.ErrLikeNotFound},
+		{name: "empty post id", postID: "", username: "user1", wantError: ErrInvalidLike},
+		{name: "empty username", postID: "post1", username: "", wantError: ErrInvalidLike},
+		{name: "repository error", postID: "post1", username: "user1", repoErr: assert.AnError, wantError: assert.AnError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &mockLikesRepository{
+				deleteFunc: func(ctx context.Context, postID, username string) error {
+					assert.Equal(t, tt.postID, postID)
+					assert.Equal(t, tt.username, username)
+					return tt.repoErr
+				},
+			}
+			service := NewLikesService(mockRepo, &helper.MockPostLookup{})
+
+			err := service.DeleteLike(context.Background(), tt.postID, tt.username)
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestLikesService_GetPostLikes(t *testing.T) {
+	mockRepo := &mockLikesRepository{
+		countFunc: func(ctx context.Context, postID string) (int64, error) {
+			return 42, nil
+		},
+		listFunc: func(ctx context.Context, filter domain.LikeFilter) (domain.LikePage, error) {
+			assert.Equal(t, DefaultPageSize, filter.Limit)
+			return domain.LikePage{Likes: []domain.Likes{{PostID: "post1", UsernameFrom: "user1"}}}, nil
+		},
+	}
+	service := NewLikesService(mockRepo, &helper.MockPostLookup{})
+
+	count, page, err := service.GetPostLikes(context.Background(), domain.LikeFilter{PostID: "post1"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), count)
+	assert.Len(t, page.Likes, 1)
+
+	_, _, err = service.GetPostLikes(context.Background(), domain.LikeFilter{})
+	assert.ErrorIs(t, err, ErrInvalidPostID)
+}
+
+func TestLikesService_LikeCount(t *testing.T) {
+	mockRepo := &mockLikesRepository{
+		countFunc: func(ctx context.Context, postID string) (int64, error) {
+			assert.Equal(t, "post1", postID)
+			return 7, nil
+		},
+	}
+	service := NewLikesService(mockRepo, &helper.MockPostLookup{})
+
+	count, err := service.LikeCount(context.Background(), "post1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), count)
+
+	_, err = service.LikeCount(context.Background(), "")
+	assert.ErrorIs(t, err, ErrInvalidPostID)
+}
+
+func TestLikeID(t *testing.T) {
+	assert.Equal(t, domain.LikeID("post1", "user1"), domain.LikeID("post1", "user1"))
+	assert.NotEqual(t, domain.LikeID("post1", "user1"), domain.LikeID("post1", "user2"))
+	assert.NotEqual(t, domain.LikeID("a_b", "c"), domain.LikeID("a", "b_c"))
+}
+
+func TestLikesService_PostNotFound(t *testing.T) {
+	lookup := &helper.MockPostLookup{
+		PostExistsFunc: func(ctx context.Context, postID string) (bool, error) {
+			return false, nil
+		},
+	}
+	mockRepo := &mockLikesRepository{
+		createFunc: func(ctx context.Context, like domain.Likes) error {
+			t.Fatal("like on a missing post must not be stored")
+			return nil
+		},
+	}
+	service := NewLikesService(mockRepo, lookup)
+
+	err := service.CreateLike(context.Background(), domain.Likes{PostID: "missing", UsernameFrom: "user1"})
+	assert.ErrorIs(t, err, domain.ErrPostNotFound)
+
+	_, _, err = service.GetPostLikes(context.Background(), domain.LikeFilter{PostID: "missing"})
+	assert.ErrorIs(t, err, domain.ErrPostNotFound)
+
+	// Unliking does not depend on the post still existing
+	err = service.DeleteLike(context.Background(), "missing", "user1")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,188 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrInvalidType          = errors.New("invalid notification type")
+	ErrInvalidCursor        = errors.New("invalid cursor")
+)
+
+// Notification types
+const (
+	TypeComment = "comment" // someone commented on the user's post
+	TypeReply   = "reply"   // someone replied to the user's comment
+	TypeLike    = "like"    // someone liked the user's post
+)
+
+// Types lists the notification types a user can mute
+var Types = []string{TypeComment, TypeReply, TypeLike}
+
+// MaxActors bounds the actors kept on a notification for display. Count
+// covers every actor; the repository keeps the full set to tell repeats.
+const MaxActors = 100
+
+// Activity is something a user did that may notify another user
+type Activity struct {
+	Recipient string
+	Type      string
+	PostID    string
+
+	// CommentID is the comment replied to for replies, and the new comment
+	// for comments
+	CommentID string
+	Actor     string
+	At        time.Time
+}
+
+// Target is what the activity is about. Activity of the same type on the
+// same target is coalesced into a single notification.
+func (a Activity) Target() string {
+	if a.Type == TypeReply {
+		return a.CommentID
+	}
+	return a.PostID
+}
+
+// GroupKey identifies the notifications the activity is coalesced into
+func (a Activity) GroupKey() string {
+	sum := sha256.Sum256([]byte(a.Recipient + "\x00" + a.Type + "\x00" + a.Target()))
+	return hex.EncodeToString(sum[:])
+}
+
+// Notification tells a user about the activity of others. Bursts of the same
+// activity are coalesced while the notification is open: unread and updated
+// within the coalesce window.
+type Notification struct {
+	ID        string    `json:"id" firestore:"-"`
+	Username  string    `json:"username" firestore:"username"`
+	Type      string    `json:"type" firestore:"type"`
+	GroupKey  string    `json:"-" firestore:"group_key"`
+	PostID    string    `json:"post_id" firestore:"post_id"`
+	CommentID string    `json:"comment_id,omitempty" firestore:"comment_id"`
+	Actors    []string  `json:"actors" firestore:"actors"`
+	Count     int       `json:"count" firestore:"count"`
+	Read      bool      `json:"read" firestore:"read"`
+	Open      bool      `json:"-" firestore:"open"`
+	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" firestore:"updated_at"`
+}
+
+// NewNotification starts a notification for the activity
+func NewNotification(activity Activity) Notification {
+	return Notification{
+		Username:  activity.Recipient,
+		Type:      activity.Type,
+		GroupKey:  activity.GroupKey(),
+		PostID:    activity.PostID,
+		CommentID: activity.CommentID,
+		Actors:    []string{activity.Actor},
+		Count:     1,
+		Open:      true,
+		CreatedAt: activity.At,
+		UpdatedAt: activity.At,
+	}
+}
+
+// Coalesces reports whether activity at the given time can be added to the
+// notification rather than starting a new one
+func (n Notification) Coalesces(at time.Time, window time.Duration) bool {
+	return n.Open && !n.Read && at.Sub(n.UpdatedAt) <= window
+}
+
+// Add records another occurrence of the activity. known reports whether the
+// actor already acted on the notification, which Actors cannot tell once it is
+// capped. A known actor moves to the front without being counted again.
+func (n *Notification) Add(activity Activity, known bool) {
+	i := slices.Index(n.Actors, activity.Actor)
+	if i >= 0 {
+		n.Actors = slices.Delete(n.Actors, i, i+1)
+	}
+	if i < 0 && !known {
+		n.Count++
+	}
+	n.Actors = append([]string{activity.Actor}, n.Actors...)
+	if len(n.Actors) > MaxActors {
+		n.Actors = n.Actors[:MaxActors]
+	}
+	if activity.Type == TypeComment {
+		n.CommentID = activity.CommentID
+	}
+	if activity.At.After(n.UpdatedAt) {
+		n.UpdatedAt = activity.At
+	}
+}
+
+// Text describes the notification, such as "alice and bob liked your post"
+// or "12 people liked your post"
+func (n Notification) Text() string {
+	var action string
+	switch n.Type {
+	case TypeComment:
+		action = "commented on your post"
+	case TypeReply:
+		action = "replied to your comment"
+	case TypeLike:
+		action = "liked your post"
+	}
+
+	switch {
+	case n.Count == 1 && len(n.Actors) == 1:
+		return fmt.Sprintf("%s %s", n.Actors[0], action)
+	case n.Count == 2 && len(n.Actors) == 2:
+		return fmt.Sprintf("%s and %s %s", n.Actors[0], n.Actors[1], action)
+	default:
+		return fmt.Sprintf("%d people %s", n.Count, action)
+	}
+}
+
+// NotificationFilter selects a page of a user's notifications, most recently
+// updated first
+type NotificationFilter struct {
+	Username   string
+	UnreadOnly bool
+	Cursor     string
+	Limit      int
+}
+
+// NotificationPage is a page of notifications. NextCursor is empty on the
+// last page.
+type NotificationPage struct {
+	Notifications []Notification
+	NextCursor    string
+}
+
+// Preferences are a user's notification settings
+type Preferences struct {
+	Username  string    `json:"username" firestore:"-"`
+	Muted     []string  `json:"muted" firestore:"muted"`
+	UpdatedAt time.Time `json:"updated_at,omitempty" firestore:"updated_at"`
+}
+
+// Mutes reports whether the user muted the notification type
+func (p Preferences) Mutes(notificationType string) bool {
+	return slices.Contains(p.Muted, notificationType)
+}
+
+// ValidType reports whether the notification type exists
+func ValidType(notificationType string) bool {
+	return slices.Contains(Types, notificationType)
+}
+
+// ActivityEvent holds the fields of the comment and like event payloads
+// notifications are built from
+type ActivityEvent struct {
+	ID           string    `json:"id,omitempty"`
+	PostID       string    `json:"post_id"`
+	ParentID     string    `json:"parent_id,omitempty"`
+	Username     string    `json:"username,omitempty"`
+	UsernameFrom string    `json:"username_from,omitempty"`
+	CreatedAt    time.Time `json:"created_at,omitempty"`
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var at = time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+
+func like(actor string) Activity {
+	return Activity{Recipient: "author", Type: TypeLike, PostID: "post1", Actor: actor, At: at}
+}
+
+func TestNotification_Add(t *testing.T) {
+	notification := NewNotification(like("alice"))
+	assert.Equal(t, "alice liked your post", notification.Text())
+
+	notification.Add(like("bob"), false)
+	assert.Equal(t, 2, notification.Count)
+	assert.Equal(t, "bob and alice liked your post", notification.Text())
+
+	// An actor acting again moves to the front without being counted twice
+	notification.Add(like("alice"), true)
+	assert.Equal(t, 2, notification.Count)
+	assert.Equal(t, []string{"alice", "bob"}, notification.Actors)
+
+	for i := 0; i < 10; i++ {
+		notification.Add(like(fmt.Sprintf("user%d", i)), false)
+	}
+	assert.Equal(t, "12 people liked your post", notification.Text())
+}
+
+func TestNotification_AddKeepsMaxActors(t *testing.T) {
+	notification := NewNotification(like("user0"))
+	for i := 1; i <= MaxActors; i++ {
+		notification.Add(like(fmt.Sprintf("user%d", i)), false)
+	}
+	assert.Equal(t, MaxActors+1, notification.Count)
+	assert.Len(t, notification.Actors, MaxActors)
+	assert.Equal(t, fmt.Sprintf("user%d", MaxActors), notification.Actors[0])
+
+	// An actor dropped from the list is not counted again when known
+	notification.Add(like("user0"), true)
+	assert.Equal(t, MaxActors+1, notification.Count)
+	assert.Equal(t, "user0", notification.Actors[0])
+	assert.Len(t, notification.Actors, MaxActors)
+}
+
+func TestNotification_Coalesces(t *testing.T) {
+	notification := NewNotification(like("alice"))
+
+	assert.True(t, notification.Coalesces(at.Add(time.Hour), time.Hour))
+	assert.False(t, notification.Coalesces(at.Add(time.Hour+time.Second), time.Hour))
+
+	notification.Read = true
+	assert.False(t, notification.Coalesces(at, time.Hour))
+}
+
+func TestActivity_GroupKey(t *testing.T) {
+	reply := Activity{Recipient: "author", Type: TypeReply, PostID: "post1", CommentID: "c1"}
+	otherReply := Activity{Recipient: "author", Type: TypeReply, PostID: "post1", CommentID: "c2"}
+	comment := Activity{Recipient: "author", Type: TypeComment, PostID: "post1", CommentID: "c3"}
+	otherComment := Activity{Recipient: "author", Type: TypeComment, PostID: "post1", CommentID: "c4"}
+
+	// Replies group by the comment replied to, comments and likes by post
+	assert.NotEqual(t, reply.GroupKey(), otherReply.GroupKey())
+	assert.Equal(t, comment.GroupKey(), otherComment.GroupKey())
+	assert.NotEqual(t, comment.GroupKey(), like("alice").GroupKey())
+	assert.NotEqual(t, like("alice").GroupKey(), Activity{Recipient: "other", Type: TypeLike, PostID: "post1"}.GroupKey())
+}
@@ -0,0 +1,52 @@
+package dto
+
+import "time"
+
+// MessageType is the type of the stream messages carrying notifications
+const MessageType = "notification"
+
+// ResponseActors is the number of most recent actors included in a response
+const ResponseActors = 3
+
+type ListNotificationsQuery struct {
+	Unread bool   `form:"unread"`
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+}
+
+type UpdatePreferencesRequest struct {
+	Muted []string `json:"muted" binding:"required"`
+}
+
+// NotificationResponse describes a notification. Count is the number of
+// people involved; Actors are the most recent of them.
+type NotificationResponse struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Text      string    `json:"text"`
+	PostID    string    `json:"post_id"`
+	CommentID string    `json:"comment_id,omitempty"`
+	Actors    []string  `json:"actors"`
+	Count     int       `json:"count"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ListNotificationsResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	NextCursor    string                 `json:"next_cursor,omitempty"`
+}
+
+type UnreadCountResponse struct {
+	Count int64 `json:"count"`
+}
+
+type MarkAllReadResponse struct {
+	Marked int `json:"marked"`
+}
+
+type PreferencesResponse struct {
+	Muted     []string   `json:"muted"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"log"
+
+	"github.com/ynwd/awesome-blog/internal/notifications/domain"
+	"github.com/ynwd/awesome-blog/internal/notifications/dto"
+	"github.com/ynwd/awesome-blog/internal/notifications/service"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+)
+
+// NotificationsEventHandler notifies post authors of COMMENT_CREATED and
+// LIKE_CREATED events, and comment authors of replies
+type NotificationsEventHandler struct {
+	service   service.NotificationsService
+	posts     module.PostLookup
+	comments  module.CommentLookup
+	notifier  module.UserNotifier
+	processed utils.ProcessedEvents
+}
+
+func NewNotificationsEventHandler(service service.NotificationsService, posts module.PostLookup, comments module.CommentLookup, notifier module.UserNotifier, processed utils.ProcessedEvents) *NotificationsEventHandler {
+	return &NotificationsEventHandler{
+		service:   service,
+		posts:     posts,
+		comments:  comments,
+		notifier:  notifier,
+		processed: processed,
+	}
+}
+
+// Handle records the notifications and pushes them to the recipients' open
+// streams. A coalesced notification is pushed again with the same ID.
+func (h *NotificationsEventHandler) Handle(ctx context.Context, event module.Event[domain.ActivityEvent]) error {
+	return utils.HandleOnce(ctx, h.processed, "notifications", event.ID, func() error {
+		activities, err := h.activities(ctx, event)
+		if err != nil {
+			log.Printf("Error resolving recipients of %s event %s: %v", event.Type, event.ID, err)
+			return err
+		}
+
+		for _, activity := range activities {
+			notification, ok, err := h.service.Notify(ctx, activity)
+			if err != nil {
+				log.Printf("Error recording %s notification for %s: %v", activity.Type, activity.Recipient, err)
+				return err
+			}
+			if ok {
+				h.notifier.NotifyUser(notification.Username, notification.ID, dto.MessageType, toNotificationResponse(notification))
+			}
+		}
+		return nil
+	})
+}
+
+// activities returns the activity the event notifies users of. A reply
+// notifies the parent comment's author, and the post's author unless they
+// wrote the parent comment.
+func (h *NotificationsEventHandler) activities(ctx context.Context, event module.Event[domain.ActivityEvent]) ([]domain.Activity, error) {
+	payload := event.Payload
+
+	// Notify of the activity when it happened rather than when it was reported
+	at := payload.CreatedAt
+	if at.IsZero() {
+		at = event.Timestamp
+	}
+
+	postAuthor, err := h.posts.PostAuthor(ctx, payload.PostID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch event.Type {
+	case module.LikeCreatedEvent:
+		return []domain.Activity{{
+			Recipient: postAuthor,
+			Type:      domain.TypeLike,
+			PostID:    payload.PostID,
+			Actor:     payload.UsernameFrom,
+			At:        at,
+		}}, nil
+	case module.CommentCreatedEvent:
+		var activities []domain.Activity
+		var parentAuthor string
+		if payload.ParentID != "" {
+			parentAuthor, err = h.comments.CommentAuthor(ctx, payload.ParentID)
+			if err != nil {
+				return nil, err
+			}
+			activities = append(activities, domain.Activity{
+				Recipient: parentAuthor,
+				Type:      domain.TypeReply,
+				PostID:    payload.PostID,
+				CommentID: payload.ParentID,
+				Actor:     payload.Username,
+				At:        at,
+			})
+		}
+		if postAuthor != parentAuthor {
+			activities = append(activities, domain.Activity{
+				Recipient: postAuthor,
+				Type:      domain.TypeComment,
+				PostID:    payload.PostID,
+				CommentID: payload.ID,
+				Actor:     payload.Username,
+				At:        at,
+			})
+		}
+		return activities, nil
+	default:
+		return nil, nil
+	}
+}
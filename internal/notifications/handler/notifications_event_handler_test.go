@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ynwd/awesome-blog/internal/notifications/domain"
+	"github.com/ynwd/awesome-blog/internal/notifications/dto"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+	"github.com/ynwd/awesome-blog/tests/helper"
+)
+
+type mockCommentLookup struct {
+	authors map[string]string
+	err     error
+}
+
+func (m *mockCommentLookup) CommentAuthor(ctx context.Context, commentID string) (string, error) {
+	return m.authors[commentID], m.err
+}
+
+type notified struct {
+	username    string
+	id          string
+	messageType string
+	data        any
+}
+
+type mockNotifier struct {
+	notified []notified
+}
+
+func (m *mockNotifier) NotifyUser(username, id, messageType string, data any) {
+	m.notified = append(m.notified, notified{username: username, id: id, messageType: messageType, data: data})
+}
+
+func TestNotificationsEventHandler_Handle(t *testing.T) {
+	posts := &helper.MockPostLookup{
+		PostAuthorFunc: func(ctx context.Context, postID string) (string, error) {
+			return "author", nil
+		},
+	}
+	comments := &mockCommentLookup{authors: map[string]string{
+		"c-reader": "reader",
+		"c-author": "author",
+	}}
+
+	tests := []struct {
+		name      string
+		eventType module.EventType
+		payload   string
+		want      []domain.Activity
+	}{
+		{
+			name:      "like notifies the post author",
+			eventType: module.LikeCreatedEvent,
+			payload:   `{"post_id": "post1", "username_from": "alice", "created_at": "2025-02-01T10:00:00Z"}`,
+			want: []domain.Activity{
+				{Recipient: "author", Type: domain.TypeLike, PostID: "post1", Actor: "alice"},
+			},
+		},
+		{
+			name:      "comment notifies the post author",
+			eventType: module.CommentCreatedEvent,
+			payload:   `{"id": "c1", "post_id": "post1", "username": "alice", "created_at": "2025-02-01T10:00:00Z"}`,
+			want: []domain.Activity{
+				{Recipient: "author", Type: domain.TypeComment, PostID: "post1", CommentID: "c1", Actor: "alice"},
+			},
+		},
+		{
+			name:      "reply notifies the parent author and the post author",
+			eventType: module.CommentCreatedEvent,
+			payload:   `{"id": "c2", "post_id": "post1", "parent_id": "c-reader", "username": "alice", "created_at": "2025-02-01T10:00:00Z"}`,
+			want: []domain.Activity{
+				{Recipient: "reader", Type: domain.TypeReply, PostID: "post1", CommentID: "c-reader", Actor: "alice"},
+				{Recipient: "author", Type: domain.TypeComment, PostID: "post1", CommentID: "c2", Actor: "alice"},
+			},
+		},
+		{
+			name:      "reply to the post author's comment notifies them once",
+			eventType: module.CommentCreatedEvent,
+			payload:   `{"id": "c3", "post_id": "post1", "parent_id": "c-author", "username": "alice", "created_at": "2025-02-01T10:00:00Z"}`,
+			want: []domain.Activity{
+				{Recipient: "author", Type: domain.TypeReply, PostID: "post1", CommentID: "c-author", Actor: "alice"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var activities []domain.Activity
+			service := &mockNotificationsService{
+				notifyFunc: func(ctx context.Context, activity domain.Activity) (domain.Notification, bool, error) {
+					activities = append(activities, activity)
+					notification := domain.NewNotification(activity)
+					notification.ID = "n1"
+					return notification, true, nil
+				},
+			}
+			notifier := &mockNotifier{}
+			handler := NewNotificationsEventHandler(service, posts, comments, notifier, utils.NewMemoryProcessedEvents(time.Hour))
+
+			var payload domain.ActivityEvent
+			require.NoError(t, json.Unmarshal([]byte(tt.payload), &payload))
+			err := handler.Handle(context.Background(), module.Event[domain.ActivityEvent]{
+				ID:      "event-1",
+				Type:    tt.eventType,
+				Payload: payload,
+			})
+			require.NoError(t, err)
+
+			// Activity is dated when it happened
+			for i := range tt.want {
+				tt.want[i].At = createdAt
+			}
+			assert.Equal(t, tt.want, activities)
+
+			require.Len(t, notifier.notified, len(tt.want))
+			for i, want := range tt.want {
+				assert.Equal(t, want.Recipient, notifier.notified[i].username)
+				assert.Equal(t, "n1", notifier.notified[i].id)
+				assert.Equal(t, dto.MessageType, notifier.notified[i].messageType)
+			}
+		})
+	}
+}
+
+func TestNotificationsEventHandler_Handle_Retries(t *testing.T) {
+	fail := true
+	calls := 0
+	service := &mockNotificationsService{
+		notifyFunc: func(ctx context.Context, activity domain.Activity) (domain.Notification, bool, error) {
+			calls++
+			if fail {
+				return domain.Notification{}, false, errors.New("firestore error")
+			}
+			// Muted or self-inflicted activity is not pushed
+			return domain.Notification{}, false, nil
+		},
+	}
+	notifier := &mockNotifier{}
+	handler := NewNotificationsEventHandler(service, &helper.MockPostLookup{}, &mockCommentLookup{}, notifier, utils.NewMemoryProcessedEvents(time.Hour))
+
+	registry := module.NewEventRegistry()
+	module.Handle(registry, module.LikeCreatedEvent, module.LikeCreatedEventVersion, handler.Handle)
+
+	data, err := json.Marshal(module.NewEvent(module.LikeCreatedEvent, map[string]string{"post_id": "post1", "username_from": "alice"}))
+	require.NoError(t, err)
+
+	// A failed notification is retried, and a redelivered event is handled once
+	assert.Error(t, registry.Dispatch(context.Background(), data))
+	fail = false
+	assert.NoError(t, registry.Dispatch(context.Background(), data))
+	assert.NoError(t, registry.Dispatch(context.Background(), data))
+
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, notifier.notified)
+}
+
+func TestNotificationsEventHandler_Handle_LookupError(t *testing.T) {
+	service := &mockNotificationsService{
+		notifyFunc: func(ctx context.Context, activity domain.Activity) (domain.Notification, bool, error) {
+			t.Fatal("nothing must be recorded when the parent comment cannot be read")
+			return domain.Notification{}, false, nil
+		},
+	}
+	comments := &mockCommentLookup{err: errors.New("firestore error")}
+	handler := NewNotificationsEventHandler(service, &helper.MockPostLookup{}, comments, &mockNotifier{}, utils.NewMemoryProcessedEvents(time.Hour))
+
+	err := handler.Handle(context.Background(), module.Event[domain.ActivityEvent]{
+		ID:      "event-1",
+		Type:    module.CommentCreatedEvent,
+		Payload: domain.ActivityEvent{ID: "c1", PostID: "post1", ParentID: "c0", Username: "alice"},
+	})
+	assert.EqualError(t, err, "firestore error")
+}
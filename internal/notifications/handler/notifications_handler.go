@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ynwd/awesome-blog/internal/notifications/domain"
+	"github.com/ynwd/awesome-blog/internal/notifications/dto"
+	"github.com/ynwd/awesome-blog/internal/notifications/service"
+	"github.com/ynwd/awesome-blog/pkg/middleware"
+	"github.com/ynwd/awesome-blog/pkg/res"
+)
+
+type NotificationsHandler struct {
+	service service.NotificationsService
+}
+
+func NewNotificationsHandler(service service.NotificationsService) *NotificationsHandler {
+	return &NotificationsHandler{
+		service: service,
+	}
+}
+
+// ListNotifications returns the user's notifications, most recently updated
+// first. With unread=true only unread notifications are returned.
+func (h *NotificationsHandler) ListNotifications(c *gin.Context) {
+	var query dto.ListNotificationsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error("Invalid query parameters"))
+		return
+	}
+
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	page, err := h.service.ListNotifications(c.Request.Context(), domain.NotificationFilter{
+		Username:   username,
+		UnreadOnly: query.Unread,
+		Cursor:     query.Cursor,
+		Limit:      query.Limit,
+	})
+	if err != nil {
+		c.JSON(notificationErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	response := dto.ListNotificationsResponse{
+		Notifications: make([]dto.NotificationResponse, 0, len(page.Notifications)),
+		NextCursor:    page.NextCursor,
+	}
+	for _, notification := range page.Notifications {
+		response.Notifications = append(response.Notifications, toNotificationResponse(notification))
+	}
+	c.JSON(http.StatusOK, res.Success(response, "Notifications retrieved successfully"))
+}
+
+func (h *NotificationsHandler) UnreadCount(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	count, err := h.service.UnreadCount(c.Request.Context(), username)
+	if err != nil {
+		c.JSON(notificationErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(dto.UnreadCountResponse{Count: count}, "Unread count retrieved successfully"))
+}
+
+func (h *NotificationsHandler) MarkRead(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	if err := h.service.MarkRead(c.Request.Context(), username, c.Param("id")); err != nil {
+		c.JSON(notificationErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(nil, "Notification marked as read"))
+}
+
+func (h *NotificationsHandler) MarkAllRead(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	marked, err := h.service.MarkAllRead(c.Request.Context(), username)
+	if err != nil {
+		c.JSON(notificationErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(dto.MarkAllReadResponse{Marked: marked}, "Notifications marked as read"))
+}
+
+func (h *NotificationsHandler) GetPreferences(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	preferences, err := h.service.GetPreferences(c.Request.Context(), username)
+	if err != nil {
+		c.JSON(notificationErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(toPreferencesResponse(preferences), "Preferences retrieved successfully"))
+}
+
+// UpdatePreferences replaces the notification types the user muted
+func (h *NotificationsHandler) UpdatePreferences(c *gin.Context) {
+	var req dto.UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error(err.Error()))
+		return
+	}
+
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	preferences, err := h.service.UpdatePreferences(c.Request.Context(), username, req.Muted)
+	if err != nil {
+		c.JSON(notificationErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(toPreferencesResponse(preferences), "Preferences updated successfully"))
+}
+
+func toNotificationResponse(notification domain.Notification) dto.NotificationResponse {
+	actors := notification.Actors
+	if len(actors) > dto.ResponseActors {
+		actors = actors[:dto.ResponseActors]
+	}
+	if actors == nil {
+		actors = []string{}
+	}
+	return dto.NotificationResponse{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		Text:      notification.Text(),
+		PostID:    notification.PostID,
+		CommentID: notification.CommentID,
+		Actors:    actors,
+		Count:     notification.Count,
+		Read:      notification.Read,
+		CreatedAt: notification.CreatedAt,
+		UpdatedAt: notification.UpdatedAt,
+	}
+}
+
+func toPreferencesResponse(preferences domain.Preferences) dto.PreferencesResponse {
+	response := dto.PreferencesResponse{Muted: preferences.Muted}
+	if response.Muted == nil {
+		response.Muted = []string{}
+	}
+	if !preferences.UpdatedAt.IsZero() {
+		response.UpdatedAt = &preferences.UpdatedAt
+	}
+	return response
+}
+
+// notificationErrorStatus maps notification errors to HTTP status codes
+func notificationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrNotificationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidType),
+		errors.Is(err, domain.ErrInvalidCursor):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrInvalidUsername):
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
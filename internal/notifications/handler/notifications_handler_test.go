@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/ynwd/awesome-blog/internal/notifications/domain"
+)
+
+type mockNotificationsService struct {
+	notifyFunc            func(ctx context.Context, activity domain.Activity) (domain.Notification, bool, error)
+	listNotificationsFunc func(ctx context.Context, filter domain.NotificationFilter) (domain.NotificationPage, error)
+	unreadCountFunc       func(ctx context.Context, username string) (int64, error)
+	markReadFunc          func(ctx context.Context, username, id string) error
+	markAllReadFunc       func(ctx context.Context, username string) (int, error)
+	getPreferencesFunc    func(ctx context.Context, username string) (domain.Preferences, error)
+	updatePreferencesFunc func(ctx context.Context, username string, muted []string) (domain.Preferences, error)
+}
+
+func (m *mockNotificationsService) Notify(ctx context.Context, activity domain.Activity) (domain.Notification, bool, error) {
+	return m.notifyFunc(ctx, activity)
+}
+
+func (m *mockNotificationsService) ListNotifications(ctx context.Context, filter domain.NotificationFilter) (domain.NotificationPage, error) {
+	return m.listNotificationsFunc(ctx, filter)
+}
+
+func (m *mockNotificationsService) UnreadCount(ctx context.Context, username string) (int64, error) {
+	return m.unreadCountFunc(ctx, username)
+}
+
+func (m *mockNotificationsService) MarkRead(ctx context.Context, username, id string) error {
+	return m.markReadFunc(ctx, username, id)
+}
+
+func (m *mockNotificationsService) MarkAllRead(ctx context.Context, username string) (int, error) {
+	return m.markAllReadFunc(ctx, username)
+}
+
+func (m *mockNotificationsService) GetPreferences(ctx context.Context, username string) (domain.Preferences, error) {
+	return m.getPreferencesFunc(ctx, username)
+}
+
+func (m *mockNotificationsService) UpdatePreferences(ctx context.Context, username string, muted []string) (domain.Preferences, error) {
+	return m.updatePreferencesFunc(ctx, username, muted)
+}
+
+var createdAt = time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+
+var testNotification = domain.Notification{
+	ID:        "n1",
+	Username:  "author",
+	Type:      domain.TypeLike,
+	PostID:    "post1",
+	Actors:    []string{"dave", "carol", "bob", "alice"},
+	Count:     12,
+	Open:      true,
+	CreatedAt: createdAt,
+	UpdatedAt: createdAt.Add(time.Minute),
+}
+
+func setupRouter(service *mockNotificationsService, username string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if username != "" {
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", username)
+		})
+	}
+	h := NewNotificationsHandler(service)
+	router.GET("/notifications", h.ListNotifications)
+	router.GET("/notifications/unread-count", h.UnreadCount)
+	router.POST("/notifications/read", h.MarkAllRead)
+	router.POST("/notifications/:id/read", h.MarkRead)
+	router.GET("/notifications/preferences", h.GetPreferences)
+	router.PUT("/notifications/preferences", h.UpdatePreferences)
+	return router
+}
+
+func TestNotificationsHandler_ListNotifications(t *testing.T) {
+	service := &mockNotificationsService{
+		listNotificationsFunc: func(ctx context.Context, filter domain.NotificationFilter) (domain.NotificationPage, error) {
+			if filter.Cursor == "bad" {
+				return domain.NotificationPage{}, domain.ErrInvalidCursor
+			}
+			assert.Equal(t, "author", filter.Username)
+			assert.True(t, filter.UnreadOnly)
+			assert.Equal(t, 10, filter.Limit)
+			return domain.NotificationPage{Notifications: []domain.Notification{testNotification}, NextCursor: "next"}, nil
+		},
+	}
+
+	tests := []struct {
+		name       string
+		username   string
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "coalesced notification",
+			username:   "author",
+			query:      "?unread=true&limit=10",
+			wantStatus: http.StatusOK,
+			wantBody: `{
+				"status": "success",
+				"message": "Notifications retrieved successfully",
+				"data": {
+					"notifications": [{
+						"id": "n1",
+						"type": "like",
+						"text": "12 people liked your post",
+						"post_id": "post1",
+						"actors": ["dave", "carol", "bob"],
+						"count": 12,
+						"read": false,
+						"created_at": "2025-02-01T10:00:00Z",
+						"updated_at": "2025-02-01T10:01:00Z"
+					}],
+					"next_cursor": "next"
+				}
+			}`,
+		},
+		{
+			name:       "invalid cursor",
+			username:   "author",
+			query:      "?cursor=bad",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"status": "error", "message": "invalid cursor"}`,
+		},
+		{
+			name:       "unauthenticated",
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"status": "error", "message": "authentication required"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupRouter(service, tt.username)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/notifications"+tt.query, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestNotificationsHandler_Read(t *testing.T) {
+	service := &mockNotificationsService{
+		unreadCountFunc: func(ctx context.Context, username string) (int64, error) {
+			return 3, nil
+		},
+		markReadFunc: func(ctx context.Context, username, id string) error {
+			if id != "n1" {
+				return domain.ErrNotificationNotFound
+			}
+			return nil
+		},
+		markAllReadFunc: func(ctx context.Context, username string) (int, error) {
+			return 2, nil
+		},
+	}
+	router := setupRouter(service, "author")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/notifications/unread-count", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status": "success", "message": "Unread count retrieved successfully", "data": {"count": 3}}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/notifications/n1/read", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/notifications/other/read", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/notifications/read", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status": "success", "message": "Notifications marked as read", "data": {"marked": 2}}`, w.Body.String())
+}
+
+func TestNotificationsHandler_Preferences(t *testing.T) {
+	updatedAt := createdAt
+	service := &mockNotificationsService{
+		getPreferencesFunc: func(ctx context.Context, username string) (domain.Preferences, error) {
+			return domain.Preferences{Username: username}, nil
+		},
+		updatePreferencesFunc: func(ctx context.Context, username string, muted []string) (domain.Preferences, error) {
+			if len(muted) > 0 && muted[0] == "follow" {
+				return domain.Preferences{}, domain.ErrInvalidType
+			}
+			return domain.Preferences{Username: username, Muted: muted, UpdatedAt: updatedAt}, nil
+		},
+	}
+	router := setupRouter(service, "author")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/notifications/preferences", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status": "success", "message": "Preferences retrieved successfully", "data": {"muted": []}}`, w.Body.String())
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "mute likes",
+			body:       `{"muted": ["like"]}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status": "success", "message": "Preferences updated successfully", "data": {"muted": ["like"], "updated_at": "2025-02-01T10:00:00Z"}}`,
+		},
+		{
+			name:       "unknown type",
+			body:       `{"muted": ["follow"]}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"status": "error", "message": "invalid notification type"}`,
+		},
+		{
+			name:       "missing muted",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/notifications/preferences", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package notifications
+
+import (
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/ynwd/awesome-blog/internal/notifications/handler"
+	"github.com/ynwd/awesome-blog/internal/notifications/repo"
+	"github.com/ynwd/awesome-blog/internal/notifications/service"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+)
+
+type Module struct {
+	handler      *handler.NotificationsHandler
+	eventHandler *handler.NotificationsEventHandler
+}
+
+func NewModule(firestoreClient *firestore.Client, posts module.PostLookup, comments module.CommentLookup, notifier module.UserNotifier, processed utils.ProcessedEvents, coalesceWindow time.Duration) *Module {
+	// Initialize repositories
+	notificationsRepo := repo.NewNotificationsRepository(firestoreClient)
+	preferencesRepo := repo.NewPreferencesRepository(firestoreClient)
+
+	// Initialize service
+	notificationsService := service.NewNotificationsService(notificationsRepo, preferencesRepo, coalesceWindow)
+
+	// Initialize handlers
+	notificationsHandler := handler.NewNotificationsHandler(notificationsService)
+	eventHandler := handler.NewNotificationsEventHandler(notificationsService, posts, comments, notifier, processed)
+
+	return &Module{
+		handler:      notificationsHandler,
+		eventHandler: eventHandler,
+	}
+}
+
+// RegisterEvents notifies authors from the domain events, which are emitted
+// for both the synchronous and the /pubsub endpoints
+func (m *Module) RegisterEvents(registry *module.EventRegistry) {
+	module.Handle(registry, module.CommentCreatedEvent, module.CommentCreatedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.LikeCreatedEvent, module.LikeCreatedEventVersion, m.eventHandler.Handle)
+}
@@ -0,0 +1,12 @@
+package notifications
+
+import "github.com/gin-gonic/gin"
+
+func (m *Module) RegisterRoutes(router *gin.Engine) {
+	router.GET("/notifications", m.handler.ListNotifications)
+	router.GET("/notifications/unread-count", m.handler.UnreadCount)
+	router.POST("/notifications/read", m.handler.MarkAllRead)
+	router.POST("/notifications/:id/read", m.handler.MarkRead)
+	router.GET("/notifications/preferences", m.handler.GetPreferences)
+	router.PUT("/notifications/preferences", m.handler.UpdatePreferences)
+}
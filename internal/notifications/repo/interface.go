@@ -0,0 +1,29 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/notifications/domain"
+)
+
+type NotificationsRepository interface {
+	// Record adds the activity to the recipient's open notification of the
+	// same group when it still coalesces, or starts a new notification. It
+	// returns the stored notification.
+	Record(ctx context.Context, activity domain.Activity, window time.Duration) (domain.Notification, error)
+	GetByID(ctx context.Context, id string) (domain.Notification, error)
+	List(ctx context.Context, filter domain.NotificationFilter) (domain.NotificationPage, error)
+	CountUnread(ctx context.Context, username string) (int64, error)
+	MarkRead(ctx context.Context, id string) error
+
+	// MarkAllRead marks every unread notification of the user read and
+	// returns how many were marked
+	MarkAllRead(ctx context.Context, username string) (int, error)
+}
+
+type PreferencesRepository interface {
+	// Get returns the user's preferences, which are empty when never saved
+	Get(ctx context.Context, username string) (domain.Preferences, error)
+	Save(ctx context.Context, preferences domain.Preferences) error
+}
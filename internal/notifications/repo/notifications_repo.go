@@ -0,0 +1,245 @@
+package repo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"github.com/ynwd/awesome-blog/internal/notifications/domain"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NotificationsCollection holds the notifications of every user
+const NotificationsCollection = "notifications"
+
+// ActorsCollection is the subcollection of a notification holding every actor
+// counted on it, keyed by actorID
+const ActorsCollection = "actors"
+
+type notificationsFirestore struct {
+	client *firestore.Client
+}
+
+func NewNotificationsRepository(client *firestore.Client) NotificationsRepository {
+	return &notificationsFirestore{
+		client: client,
+	}
+}
+
+// Record looks up the open notification of the group and updates it in the
+// same transaction, so concurrent activity is never lost. A group has at most
+// one open notification: one that no longer coalesces is closed before the
+// new one is created.
+func (r *notificationsFirestore) Record(ctx context.Context, activity domain.Activity, window time.Duration) (domain.Notification, error) {
+	collection := r.client.Collection(NotificationsCollection)
+	query := collection.
+		Where("group_key", "==", activity.GroupKey()).
+		Where("open", "==", true).
+		Limit(1)
+
+	var notification domain.Notification
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.Documents(query).GetAll()
+		if err != nil {
+			return err
+		}
+
+		if len(docs) > 0 {
+			open, err := toNotification(docs[0])
+			if err != nil {
+				return err
+			}
+			if open.Coalesces(activity.At, window) {
+				actor := actorRef(docs[0].Ref, activity.Actor)
+				_, err := tx.Get(actor)
+				if err != nil && status.Code(err) != codes.NotFound {
+					return err
+				}
+
+				open.Add(activity, err == nil)
+				notification = open
+				if err := tx.Update(docs[0].Ref, []firestore.Update{
+					{Path: "actors", Value: open.Actors},
+					{Path: "count", Value: open.Count},
+					{Path: "comment_id", Value: open.CommentID},
+					{Path: "updated_at", Value: open.UpdatedAt},
+				}); err != nil {
+					return err
+				}
+				return tx.Set(actor, actorDoc(activity))
+			}
+			if err := tx.Update(docs[0].Ref, []firestore.Update{{Path: "open", Value: false}}); err != nil {
+				return err
+			}
+		}
+
+		ref := collection.NewDoc()
+		notification = domain.NewNotification(activity)
+		notification.ID = ref.ID
+		if err := tx.Create(ref, notification); err != nil {
+			return err
+		}
+		return tx.Set(actorRef(ref, activity.Actor), actorDoc(activity))
+	})
+	if err != nil {
+		return domain.Notification{}, err
+	}
+	return notification, nil
+}
+
+// actorRef is the document recording that actor acted on the notification.
+// Usernames are hashed since they may contain characters not allowed in
+// document IDs.
+func actorRef(notification *firestore.DocumentRef, actor string) *firestore.DocumentRef {
+	sum := sha256.Sum256([]byte(actor))
+	return notification.Collection(ActorsCollection).Doc(hex.EncodeToString(sum[:]))
+}
+
+func actorDoc(activity domain.Activity) map[string]any {
+	return map[string]any{
+		"actor": activity.Actor,
+		"at":    activity.At,
+	}
+}
+
+func (r *notificationsFirestore) GetByID(ctx context.Context, id string) (domain.Notification, error) {
+	doc, err := r.client.Collection(NotificationsCollection).Doc(id).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return domain.Notification{}, domain.ErrNotificationNotFound
+	}
+	if err != nil {
+		return domain.Notification{}, err
+	}
+	return toNotification(doc)
+}
+
+// List returns the user's notifications, most recently updated first. It
+// requires composite indexes on (username, updated_at desc, __name__ desc)
+// and (username, read, updated_at desc, __name__ desc).
+func (r *notificationsFirestore) List(ctx context.Context, filter domain.NotificationFilter) (domain.NotificationPage, error) {
+	query := r.client.Collection(NotificationsCollection).Where("username", "==", filter.Username)
+	if filter.UnreadOnly {
+		query = query.Where("read", "==", false)
+	}
+	query = query.
+		OrderBy("updated_at", firestore.Desc).
+		OrderBy(firestore.DocumentID, firestore.Desc)
+
+	if filter.Cursor != "" {
+		c, err := utils.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return domain.NotificationPage{}, domain.ErrInvalidCursor
+		}
+		query = query.StartAfter(c.CreatedAt, c.ID)
+	}
+
+	// Fetch one extra document to know whether another page exists
+	iter := query.Limit(filter.Limit + 1).Documents(ctx)
+	defer iter.Stop()
+
+	var notifications []domain.Notification
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return domain.NotificationPage{}, err
+		}
+
+		notification, err := toNotification(doc)
+		if err != nil {
+			return domain.NotificationPage{}, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	page := domain.NotificationPage{Notifications: notifications}
+	if len(notifications) > filter.Limit {
+		page.Notifications = notifications[:filter.Limit]
+		last := page.Notifications[filter.Limit-1]
+		page.NextCursor = utils.EncodeCursor(last.UpdatedAt, last.ID)
+	}
+	return page, nil
+}
+
+func (r *notificationsFirestore) CountUnread(ctx context.Context, username string) (int64, error) {
+	query := r.client.Collection(NotificationsCollection).
+		Where("username", "==", username).
+		Where("read", "==", false)
+	result, err := query.NewAggregationQuery().WithCount("count").Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count, ok := result["count"].(*firestorepb.Value)
+	if !ok {
+		return 0, fmt.Errorf("unexpected count result: %v", result["count"])
+	}
+	return count.GetIntegerValue(), nil
+}
+
+// MarkRead also closes the notification, so later activity starts a new one
+func (r *notificationsFirestore) MarkRead(ctx context.Context, id string) error {
+	_, err := r.client.Collection(NotificationsCollection).Doc(id).Update(ctx, []firestore.Update{
+		{Path: "read", Value: true},
+		{Path: "open", Value: false},
+	})
+	if status.Code(err) == codes.NotFound {
+		return domain.ErrNotificationNotFound
+	}
+	return err
+}
+
+func (r *notificationsFirestore) MarkAllRead(ctx context.Context, username string) (int, error) {
+	docs, err := r.client.Collection(NotificationsCollection).
+		Where("username", "==", username).
+		Where("read", "==", false).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(docs) == 0 {
+		return 0, nil
+	}
+
+	bw := r.client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(docs))
+	for _, doc := range docs {
+		job, err := bw.Update(doc.Ref, []firestore.Update{
+			{Path: "read", Value: true},
+			{Path: "open", Value: false},
+		})
+		if err != nil {
+			bw.End()
+			return 0, err
+		}
+		jobs = append(jobs, job)
+	}
+	bw.End()
+
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			return 0, err
+		}
+	}
+	return len(docs), nil
+}
+
+func toNotification(doc *firestore.DocumentSnapshot) (domain.Notification, error) {
+	var notification domain.Notification
+	if err := doc.DataTo(&notification); err != nil {
+		return domain.Notification{}, err
+	}
+	notification.ID = doc.Ref.ID
+	return notification, nil
+}
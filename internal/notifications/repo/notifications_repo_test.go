@@ -0,0 +1,113 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ynwd/awesome-blog/internal/notifications/domain"
+	"github.com/ynwd/awesome-blog/tests/helper"
+)
+
+func TestNotificationsRepository(t *testing.T) {
+	ctx := context.Background()
+	client := helper.SetupRepoClient(t)
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	repo := NewNotificationsRepository(client)
+	at := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	like := func(actor string, at time.Time) domain.Activity {
+		return domain.Activity{Recipient: "author", Type: domain.TypeLike, PostID: "post1", Actor: actor, At: at}
+	}
+
+	// Likes within the window are coalesced
+	first, err := repo.Record(ctx, like("alice", at), time.Hour)
+	require.NoError(t, err)
+	second, err := repo.Record(ctx, like("bob", at.Add(time.Minute)), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, 2, second.Count)
+	assert.Equal(t, []string{"bob", "alice"}, second.Actors)
+
+	// An actor acting again is not counted twice
+	repeat, err := repo.Record(ctx, like("alice", at.Add(2*time.Minute)), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, repeat.ID)
+	assert.Equal(t, 2, repeat.Count)
+	assert.Equal(t, []string{"alice", "bob"}, repeat.Actors)
+
+	count, err := repo.CountUnread(ctx, "author")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// A read notification no longer collects likes
+	require.NoError(t, repo.MarkRead(ctx, first.ID))
+	third, err := repo.Record(ctx, like("carol", at.Add(2*time.Minute)), time.Hour)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, third.ID)
+	assert.Equal(t, 1, third.Count)
+
+	// Neither does one outside the window
+	fourth, err := repo.Record(ctx, like("dave", at.Add(3*time.Hour)), time.Hour)
+	require.NoError(t, err)
+	assert.NotEqual(t, third.ID, fourth.ID)
+
+	page, err := repo.List(ctx, domain.NotificationFilter{Username: "author", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Notifications, 2)
+	assert.Equal(t, fourth.ID, page.Notifications[0].ID)
+	assert.NotEmpty(t, page.NextCursor)
+
+	page, err = repo.List(ctx, domain.NotificationFilter{Username: "author", Cursor: page.NextCursor, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Notifications, 1)
+	assert.Equal(t, first.ID, page.Notifications[0].ID)
+	assert.True(t, page.Notifications[0].Read)
+
+	page, err = repo.List(ctx, domain.NotificationFilter{Username: "author", UnreadOnly: true, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, page.Notifications, 2)
+
+	marked, err := repo.MarkAllRead(ctx, "author")
+	require.NoError(t, err)
+	assert.Equal(t, 2, marked)
+	count, err = repo.CountUnread(ctx, "author")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	_, err = repo.GetByID(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrNotificationNotFound)
+	assert.ErrorIs(t, repo.MarkRead(ctx, "missing"), domain.ErrNotificationNotFound)
+}
+
+func TestPreferencesRepository(t *testing.T) {
+	ctx := context.Background()
+	client := helper.SetupRepoClient(t)
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	repo := NewPreferencesRepository(client)
+
+	preferences, err := repo.Get(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, "user1", preferences.Username)
+	assert.Empty(t, preferences.Muted)
+
+	require.NoError(t, repo.Save(ctx, domain.Preferences{Username: "user1", Muted: []string{domain.TypeLike}}))
+	preferences, err = repo.Get(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{domain.TypeLike}, preferences.Muted)
+}
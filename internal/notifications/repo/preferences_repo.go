@@ -0,0 +1,48 @@
+package repo
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/ynwd/awesome-blog/internal/notifications/domain"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PreferencesCollection holds the notification preferences, keyed by username
+const PreferencesCollection = "notification_preferences"
+
+type preferencesFirestore struct {
+	client *firestore.Client
+}
+
+func NewPreferencesRepository(client *firestore.Client) PreferencesRepository {
+	return &preferencesFirestore{
+		client: client,
+	}
+}
+
+func (r *preferencesFirestore) Get(ctx context.Context, username string) (domain.Preferences, error) {
+	doc, err := r.client.Collection(PreferencesCollection).Doc(username).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return domain.Preferences{Username: username, Muted: []string{}}, nil
+	}
+	if err != nil {
+		return domain.Preferences{}, err
+	}
+
+	var preferences domain.Preferences
+	if err := doc.DataTo(&preferences); err != nil {
+		return domain.Preferences{}, err
+	}
+	preferences.Username = username
+	if preferences.Muted == nil {
+		preferences.Muted = []string{}
+	}
+	return preferences, nil
+}
+
+func (r *preferencesFirestore) Save(ctx context.Context, preferences domain.Preferences) error {
+	_, err := r.client.Collection(PreferencesCollection).Doc(preferences.Username).Set(ctx, preferences)
+	return err
+}
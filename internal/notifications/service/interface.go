@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ynwd/awesome-blog/internal/notifications/domain"
+)
+
+type NotificationsService interface {
+	// Notify records the activity for its recipient, unless the recipient is
+	// the actor or muted the notification type. It reports whether a
+	// notification was recorded.
+	Notify(ctx context.Context, activity domain.Activity) (domain.Notification, bool, error)
+
+	ListNotifications(ctx context.Context, filter domain.NotificationFilter) (domain.NotificationPage, error)
+	UnreadCount(ctx context.Context, username string) (int64, error)
+	MarkRead(ctx context.Context, username, id string) error
+	MarkAllRead(ctx context.Context, username string) (int, error)
+
+	GetPreferences(ctx context.Context, username string) (domain.Preferences, error)
+	UpdatePreferences(ctx context.Context, username string, muted []string) (domain.Preferences, error)
+}
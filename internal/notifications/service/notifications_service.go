@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/notifications/domain"
+	"github.com/ynwd/awesome-blog/internal/notifications/repo"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+
+	// DefaultCoalesceWindow is how long an unread notification keeps
+	// collecting the same activity
+	DefaultCoalesceWindow = 24 * time.Hour
+)
+
+var (
+	ErrInvalidUsername = errors.New("invalid username: cannot be empty")
+)
+
+type notificationsService struct {
+	notifications  repo.NotificationsRepository
+	preferences    repo.PreferencesRepository
+	coalesceWindow time.Duration
+}
+
+func NewNotificationsService(notifications repo.NotificationsRepository, preferences repo.PreferencesRepository, coalesceWindow time.Duration) NotificationsService {
+	return &notificationsService{
+		notifications:  notifications,
+		preferences:    preferences,
+		coalesceWindow: coalesceWindow,
+	}
+}
+
+func (s *notificationsService) Notify(ctx context.Context, activity domain.Activity) (domain.Notification, bool, error) {
+	if activity.Recipient == "" || activity.Recipient == activity.Actor {
+		return domain.Notification{}, false, nil
+	}
+	if !domain.ValidType(activity.Type) {
+		return domain.Notification{}, false, fmt.Errorf("%w: %s", domain.ErrInvalidType, activity.Type)
+	}
+
+	preferences, err := s.preferences.Get(ctx, activity.Recipient)
+	if err != nil {
+		return domain.Notification{}, false, err
+	}
+	if preferences.Mutes(activity.Type) {
+		return domain.Notification{}, false, nil
+	}
+
+	if activity.At.IsZero() {
+		activity.At = time.Now().UTC()
+	}
+	notification, err := s.notifications.Record(ctx, activity, s.coalesceWindow)
+	if err != nil {
+		return domain.Notification{}, false, err
+	}
+	return notification, true, nil
+}
+
+// ListNotifications returns a page of the user's notifications, most
+// recently updated first
+func (s *notificationsService) ListNotifications(ctx context.Context, filter domain.NotificationFilter) (domain.NotificationPage, error) {
+	if filter.Username == "" {
+		return domain.NotificationPage{}, ErrInvalidUsername
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultPageSize
+	}
+	if filter.Limit > MaxPageSize {
+		filter.Limit = MaxPageSize
+	}
+	return s.notifications.List(ctx, filter)
+}
+
+func (s *notificationsService) UnreadCount(ctx context.Context, username string) (int64, error) {
+	if username == "" {
+		return 0, ErrInvalidUsername
+	}
+	return s.notifications.CountUnread(ctx, username)
+}
+
+// MarkRead marks one of the user's notifications read. Notifications of other
+// users are reported as not found.
+func (s *notificationsService) MarkRead(ctx context.Context, username, id string) error {
+	if username == "" {
+		return ErrInvalidUsername
+	}
+
+	notification, err := s.notifications.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if notification.Username != username {
+		return domain.ErrNotificationNotFound
+	}
+	if notification.Read {
+		return nil
+	}
+	return s.notifications.MarkRead(ctx, id)
+}
+
+func (s *notificationsService) MarkAllRead(ctx context.Context, username string) (int, error) {
+	if username == "" {
+		return 0, ErrInvalidUsername
+	}
+	return s.notifications.MarkAllRead(ctx, username)
+}
+
+func (s *notificationsService) GetPreferences(ctx context.Context, username string) (domain.Preferences, error) {
+	if username == "" {
+		return domain.Preferences{}, ErrInvalidUsername
+	}
+	return s.preferences.Get(ctx, username)
+}
+
+// UpdatePreferences replaces the notification types the user muted
+func (s *notificationsService) UpdatePreferences(ctx context.Context, username string, muted []string) (domain.Preferences, error) {
+	if username == "" {
+		return domain.Preferences{}, ErrInvalidUsername
+	}
+	for _, notificationType := range muted {
+		if !domain.ValidType(notificationType) {
+			return domain.Preferences{}, fmt.Errorf("%w: %s", domain.ErrInvalidType, notificationType)
+		}
+	}
+
+	// Store the types once each, in a stable order
+	preferences := domain.Preferences{
+		Username:  username,
+		Muted:     []string{},
+		UpdatedAt: time.Now().UTC(),
+	}
+	for _, notificationType := range domain.Types {
+		if slices.Contains(muted, notificationType) {
+			preferences.Muted = append(preferences.Muted, notificationType)
+		}
+	}
+
+	if err := s.preferences.Save(ctx, preferences); err != nil {
+		return domain.Preferences{}, err
+	}
+	return preferences, nil
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ynwd/awesome-blog/internal/notifications/domain"
+)
+
+// memoryNotifications keeps notifications in memory, coalescing like the
+// Firestore repository
+type memoryNotifications struct {
+	notifications map[string]domain.Notification
+	actors        map[string]map[string]bool
+	nextID        int
+}
+
+func newMemoryNotifications() *memoryNotifications {
+	return &memoryNotifications{
+		notifications: make(map[string]domain.Notification),
+		actors:        make(map[string]map[string]bool),
+	}
+}
+
+func (m *memoryNotifications) Record(ctx context.Context, activity domain.Activity, window time.Duration) (domain.Notification, error) {
+	for id, notification := range m.notifications {
+		if notification.GroupKey != activity.GroupKey() || !notification.Open {
+			continue
+		}
+		if notification.Coalesces(activity.At, window) {
+			notification.Add(activity, m.actors[id][activity.Actor])
+			m.notifications[id] = notification
+			m.actors[id][activity.Actor] = true
+			return notification, nil
+		}
+		notification.Open = false
+		m.notifications[id] = notification
+	}
+
+	m.nextID++
+	notification := domain.NewNotification(activity)
+	notification.ID = fmt.Sprintf("n%d", m.nextID)
+	m.notifications[notification.ID] = notification
+	m.actors[notification.ID] = map[string]bool{activity.Actor: true}
+	return notification, nil
+}
+
+func (m *memoryNotifications) GetByID(ctx context.Context, id string) (domain.Notification, error) {
+	notification, ok := m.notifications[id]
+	if !ok {
+		return domain.Notification{}, domain.ErrNotificationNotFound
+	}
+	return notification, nil
+}
+
+func (m *memoryNotifications) List(ctx context.Context, filter domain.NotificationFilter) (domain.NotificationPage, error) {
+	var page domain.NotificationPage
+	for _, notification := range m.notifications {
+		if notification.Username == filter.Username && (!filter.UnreadOnly || !notification.Read) {
+			page.Notifications = append(page.Notifications, notification)
+		}
+	}
+	return page, nil
+}
+
+func (m *memoryNotifications) CountUnread(ctx context.Context, username string) (int64, error) {
+	var count int64
+	for _, notification := range m.notifications {
+		if notification.Username == username && !notification.Read {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *memoryNotifications) MarkRead(ctx context.Context, id string) error {
+	notification, ok := m.notifications[id]
+	if !ok {
+		return domain.ErrNotificationNotFound
+	}
+	notification.Read = true
+	notification.Open = false
+	m.notifications[id] = notification
+	return nil
+}
+
+func (m *memoryNotifications) MarkAllRead(ctx context.Context, username string) (int, error) {
+	marked := 0
+	for id, notification := range m.notifications {
+		if notification.Username == username && !notification.Read {
+			notification.Read = true
+			notification.Open = false
+			m.notifications[id] = notification
+			marked++
+		}
+	}
+	return marked, nil
+}
+
+type memoryPreferences struct {
+	preferences map[string]domain.Preferences
+	getErr      error
+}
+
+func (m *memoryPreferences) Get(ctx context.Context, username string) (domain.Preferences, error) {
+	if m.getErr != nil {
+		return domain.Preferences{}, m.getErr
+	}
+	preferences, ok := m.preferences[username]
+	if !ok {
+		return domain.Preferences{Username: username, Muted: []string{}}, nil
+	}
+	return preferences, nil
+}
+
+func (m *memoryPreferences) Save(ctx context.Context, preferences domain.Preferences) error {
+	m.preferences[preferences.Username] = preferences
+	return nil
+}
+
+func newTestService() (NotificationsService, *memoryNotifications, *memoryPreferences) {
+	notifications := newMemoryNotifications()
+	preferences := &memoryPreferences{preferences: make(map[string]domain.Preferences)}
+	return NewNotificationsService(notifications, preferences, time.Hour), notifications, preferences
+}
+
+var at = time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+
+func like(actor string, at time.Time) domain.Activity {
+	return domain.Activity{Recipient: "author", Type: domain.TypeLike, PostID: "post1", Actor: actor, At: at}
+}
+
+func TestNotificationsService_NotifyCoalesces(t *testing.T) {
+	service, _, _ := newTestService()
+	ctx := context.Background()
+
+	first, ok, err := service.Notify(ctx, like("alice", at))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	for i := 0; i < 11; i++ {
+		_, _, err := service.Notify(ctx, like(fmt.Sprintf("user%d", i), at.Add(time.Minute)))
+		require.NoError(t, err)
+	}
+	burst, _, err := service.Notify(ctx, like("alice", at.Add(2*time.Minute)))
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, burst.ID)
+	assert.Equal(t, 12, burst.Count)
+	assert.Equal(t, "12 people liked your post", burst.Text())
+
+	// Once read, new likes start a new notification
+	require.NoError(t, service.MarkRead(ctx, "author", first.ID))
+	next, _, err := service.Notify(ctx, like("bob", at.Add(3*time.Minute)))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, next.ID)
+	assert.Equal(t, 1, next.Count)
+
+	count, err := service.UnreadCount(ctx, "author")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestNotificationsService_NotifySkips(t *testing.T) {
+	service, notifications, _ := newTestService()
+	ctx := context.Background()
+
+	// Own activity and activity without a recipient are not notified
+	_, ok, err := service.Notify(ctx, like("author", at))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	activity := like("alice", at)
+	activity.Recipient = ""
+	_, ok, err = service.Notify(ctx, activity)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Muted types are not notified
+	_, err = service.UpdatePreferences(ctx, "author", []string{domain.TypeLike})
+	require.NoError(t, err)
+	_, ok, err = service.Notify(ctx, like("alice", at))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	comment := domain.Activity{Recipient: "author", Type: domain.TypeComment, PostID: "post1", CommentID: "c1", Actor: "alice", At: at}
+	_, ok, err = service.Notify(ctx, comment)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Len(t, notifications.notifications, 1)
+
+	activity.Recipient = "author"
+	activity.Type = "unknown"
+	_, _, err = service.Notify(ctx, activity)
+	assert.ErrorIs(t, err, domain.ErrInvalidType)
+}
+
+func TestNotificationsService_NotifyPreferencesError(t *testing.T) {
+	service, notifications, preferences := newTestService()
+	preferences.getErr = errors.New("firestore error")
+
+	_, _, err := service.Notify(context.Background(), like("alice", at))
+	assert.EqualError(t, err, "firestore error")
+	assert.Empty(t, notifications.notifications)
+}
+
+func TestNotificationsService_MarkRead(t *testing.T) {
+	service, _, _ := newTestService()
+	ctx := context.Background()
+
+	notification, _, err := service.Notify(ctx, like("alice", at))
+	require.NoError(t, err)
+
+	// Other users cannot see the notification
+	assert.ErrorIs(t, service.MarkRead(ctx, "alice", notification.ID), domain.ErrNotificationNotFound)
+	assert.ErrorIs(t, service.MarkRead(ctx, "author", "missing"), domain.ErrNotificationNotFound)
+	assert.ErrorIs(t, service.MarkRead(ctx, "", notification.ID), ErrInvalidUsername)
+
+	require.NoError(t, service.MarkRead(ctx, "author", notification.ID))
+	require.NoError(t, service.MarkRead(ctx, "author", notification.ID))
+
+	_, _, err = service.Notify(ctx, domain.Activity{Recipient: "author", Type: domain.TypeComment, PostID: "post1", Actor: "bob", At: at})
+	require.NoError(t, err)
+	marked, err := service.MarkAllRead(ctx, "author")
+	require.NoError(t, err)
+	assert.Equal(t, 1, marked)
+}
+
+func TestNotificationsService_ListNotifications(t *testing.T) {
+	service, _, _ := newTestService()
+
+	_, err := service.ListNotifications(context.Background(), domain.NotificationFilter{})
+	assert.ErrorIs(t, err, ErrInvalidUsername)
+}
+
+func TestNotificationsService_UpdatePreferences(t *testing.T) {
+	service, _, _ := newTestService()
+	ctx := context.Background()
+
+	preferences, err := service.GetPreferences(ctx, "user1")
+	require.NoError(t, err)
+	assert.Empty(t, preferences.Muted)
+
+	// Types are stored once each, in a stable order
+	preferences, err = service.UpdatePreferences(ctx, "user1", []string{domain.TypeLike, domain.TypeComment, domain.TypeLike})
+	require.NoError(t, err)
+	assert.Equal(t, []string{domain.TypeComment, domain.TypeLike}, preferences.Muted)
+
+	stored, err := service.GetPreferences(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, preferences.Muted, stored.Muted)
+
+	_, err = service.UpdatePreferences(ctx, "user1", []string{"follow"})
+	assert.ErrorIs(t, err, domain.ErrInvalidType)
+
+	preferences, err = service.UpdatePreferences(ctx, "user1", []string{})
+	require.NoError(t, err)
+	assert.Empty(t, preferences.Muted)
+}
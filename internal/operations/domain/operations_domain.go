@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrOperationNotFound = errors.New("operation not found")
+)
+
+type Status string
+
+const (
+	// StatusPending operations were published and not handled yet. Failed
+	// deliveries are retried while the operation stays pending.
+	StatusPending Status = "pending"
+
+	// StatusSucceeded operations were handled; ResourceID is the document
+	// they created, if any
+	StatusSucceeded Status = "succeeded"
+
+	// StatusFailed operations could not be published or were dead-lettered
+	// after every delivery failed. A re-driven event can still succeed.
+	StatusFailed Status = "failed"
+)
+
+// Operation tracks an asynchronous write requested through a /pubsub
+// endpoint. Its ID is the ID of the requesting event.
+type Operation struct {
+	ID         string    `firestore:"-"`
+	Type       string    `firestore:"type"`
+	Username   string    `firestore:"username"`
+	Status     Status    `firestore:"status"`
+	ResourceID string    `firestore:"resource_id"`
+	Error      string    `firestore:"error"`
+	CreatedAt  time.Time `firestore:"created_at"`
+	UpdatedAt  time.Time `firestore:"updated_at"`
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ynwd/awesome-blog/internal/operations/domain"
+	"github.com/ynwd/awesome-blog/internal/operations/dto"
+	"github.com/ynwd/awesome-blog/internal/operations/service"
+	"github.com/ynwd/awesome-blog/pkg/middleware"
+	"github.com/ynwd/awesome-blog/pkg/res"
+)
+
+type OperationsHandler struct {
+	service service.OperationsService
+}
+
+func NewOperationsHandler(service service.OperationsService) *OperationsHandler {
+	return &OperationsHandler{
+		service: service,
+	}
+}
+
+// GetOperation reports the status of one of the user's asynchronous writes
+func (h *OperationsHandler) GetOperation(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	operation, err := h.service.GetOperation(c.Request.Context(), c.Param("id"), username)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(toOperationResponse(operation), "Operation retrieved successfully"))
+}
+
+func toOperationResponse(operation domain.Operation) dto.OperationResponse {
+	return dto.OperationResponse{
+		ID:         operation.ID,
+		Type:       operation.Type,
+		Status:     string(operation.Status),
+		ResourceID: operation.ResourceID,
+		Error:      operation.Error,
+		CreatedAt:  operation.CreatedAt,
+		UpdatedAt:  operation.UpdatedAt,
+	}
+}
+
+func operationErrorStatus(err error) int {
+	if errors.Is(err, domain.ErrOperationNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/ynwd/awesome-blog/internal/operations/domain"
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+type mockOperationsService struct {
+	getOperationFunc func(ctx context.Context, id, username string) (domain.Operation, error)
+}
+
+func (m *mockOperationsService) Start(ctx context.Context, eventID string, eventType module.EventType, username string) error {
+	return nil
+}
+
+func (m *mockOperationsService) Succeed(ctx context.Context, eventID, resourceID string) error {
+	return nil
+}
+
+func (m *mockOperationsService) Fail(ctx context.Context, eventID, reason string) error {
+	return nil
+}
+
+func (m *mockOperationsService) GetOperation(ctx context.Context, id, username string) (domain.Operation, error) {
+	return m.getOperationFunc(ctx, id, username)
+}
+
+func setupRouter(service *mockOperationsService, username string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if username != "" {
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", username)
+		})
+	}
+	router.GET("/operations/:id", NewOperationsHandler(service).GetOperation)
+	return router
+}
+
+func TestOperationsHandler_GetOperation(t *testing.T) {
+	at := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	service := &mockOperationsService{
+		getOperationFunc: func(ctx context.Context, id, username string) (domain.Operation, error) {
+			assert.Equal(t, "user1", username)
+			if id != "event-1" {
+				return domain.Operation{}, domain.ErrOperationNotFound
+			}
+			return domain.Operation{
+				ID:         id,
+				Type:       "POST",
+				Username:   username,
+				Status:     domain.StatusSucceeded,
+				ResourceID: "post-1",
+				CreatedAt:  at,
+				UpdatedAt:  at,
+			}, nil
+		},
+	}
+
+	t.Run("found", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		setupRouter(service, "user1").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/operations/event-1", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{
+			"status": "success",
+			"message": "Operation retrieved successfully",
+			"data": {
+				"id": "event-1",
+				"type": "POST",
+				"status": "succeeded",
+				"resource_id": "post-1",
+				"created_at": "2025-02-01T10:00:00Z",
+				"updated_at": "2025-02-01T10:00:00Z"
+			}
+		}`, w.Body.String())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		setupRouter(service, "user1").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/operations/event-2", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		setupRouter(service, "").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/operations/event-1", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
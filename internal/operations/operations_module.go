@@ -0,0 +1,45 @@
+package operations
+
+import (
+	"cloud.google.com/go/firestore"
+	"github.com/ynwd/awesome-blog/internal/operations/handler"
+	"github.com/ynwd/awesome-blog/internal/operations/repo"
+	"github.com/ynwd/awesome-blog/internal/operations/service"
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+type Module struct {
+	handler *handler.OperationsHandler
+	service service.OperationsService
+}
+
+func NewModule(firestoreClient *firestore.Client) *Module {
+	// Initialize repository
+	operationsRepo := repo.NewOperationsRepository(firestoreClient)
+
+	// Initialize service
+	operationsService := service.NewOperationsService(operationsRepo)
+
+	// Initialize handler
+	operationsHandler := handler.NewOperationsHandler(operationsService)
+
+	return &Module{
+		handler: operationsHandler,
+		service: operationsService,
+	}
+}
+
+// Tracker exposes operation tracking to the modules that publish and handle
+// asynchronous writes
+func (m *Module) Tracker() module.OperationTracker {
+	return m.service
+}
+
+// NewTracker returns the tracker shared by the publish endpoints, the event
+// handlers and the dead-letter sink. It is created before the module because
+// the Pub/Sub client needs it.
+func NewTracker(firestoreClient *firestore.Client) module.OperationTracker {
+	return service.NewOperationsService(repo.NewOperationsRepository(firestoreClient))
+}
+
+func (m *Module) RegisterEvents(registry *module.EventRegistry) {}
@@ -0,0 +1,7 @@
+package operations
+
+import "github.com/gin-gonic/gin"
+
+func (m *Module) RegisterRoutes(router *gin.Engine) {
+	router.GET("/operations/:id", m.handler.GetOperation)
+}
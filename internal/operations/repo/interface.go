@@ -0,0 +1,15 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/operations/domain"
+)
+
+type OperationsRepository interface {
+	Create(ctx context.Context, operation domain.Operation) error
+	GetByID(ctx context.Context, id string) (domain.Operation, error)
+	MarkSucceeded(ctx context.Context, id, resourceID string, at time.Time) error
+	MarkFailed(ctx context.Context, id, reason string, at time.Time) error
+}
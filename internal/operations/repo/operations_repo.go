@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/ynwd/awesome-blog/internal/operations/domain"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Collection holds the operations, keyed by event ID
+const Collection = "operations"
+
+type operationsFirestore struct {
+	client *firestore.Client
+}
+
+func NewOperationsRepository(client *firestore.Client) OperationsRepository {
+	return &operationsFirestore{
+		client: client,
+	}
+}
+
+func (r *operationsFirestore) Create(ctx context.Context, operation domain.Operation) error {
+	_, err := r.client.Collection(Collection).Doc(operation.ID).Create(ctx, operation)
+	return err
+}
+
+func (r *operationsFirestore) GetByID(ctx context.Context, id string) (domain.Operation, error) {
+	doc, err := r.client.Collection(Collection).Doc(id).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return domain.Operation{}, domain.ErrOperationNotFound
+	}
+	if err != nil {
+		return domain.Operation{}, err
+	}
+
+	var operation domain.Operation
+	if err := doc.DataTo(&operation); err != nil {
+		return domain.Operation{}, err
+	}
+	operation.ID = doc.Ref.ID
+	return operation, nil
+}
+
+func (r *operationsFirestore) MarkSucceeded(ctx context.Context, id, resourceID string, at time.Time) error {
+	return r.update(ctx, id, []firestore.Update{
+		{Path: "status", Value: domain.StatusSucceeded},
+		{Path: "resource_id", Value: resourceID},
+		{Path: "error", Value: ""},
+		{Path: "updated_at", Value: at},
+	})
+}
+
+func (r *operationsFirestore) MarkFailed(ctx context.Context, id, reason string, at time.Time) error {
+	return r.update(ctx, id, []firestore.Update{
+		{Path: "status", Value: domain.StatusFailed},
+		{Path: "error", Value: reason},
+		{Path: "updated_at", Value: at},
+	})
+}
+
+func (r *operationsFirestore) update(ctx context.Context, id string, updates []firestore.Update) error {
+	if id == "" {
+		return domain.ErrOperationNotFound
+	}
+	_, err := r.client.Collection(Collection).Doc(id).Update(ctx, updates)
+	if status.Code(err) == codes.NotFound {
+		return domain.ErrOperationNotFound
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ynwd/awesome-blog/internal/operations/domain"
+	"github.com/ynwd/awesome-blog/tests/helper"
+)
+
+func TestOperationsRepository(t *testing.T) {
+	ctx := context.Background()
+	client := helper.SetupRepoClient(t)
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	repo := NewOperationsRepository(client)
+	createdAt := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	operation := domain.Operation{
+		ID:        "event-1",
+		Type:      "POST",
+		Username:  "user1",
+		Status:    domain.StatusPending,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	assert.NoError(t, repo.Create(ctx, operation))
+	assert.Error(t, repo.Create(ctx, operation))
+
+	got, err := repo.GetByID(ctx, "event-1")
+	assert.NoError(t, err)
+	assert.Equal(t, operation, got)
+
+	_, err = repo.GetByID(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrOperationNotFound)
+
+	// A failed operation succeeds when its re-driven event is handled
+	assert.NoError(t, repo.MarkFailed(ctx, "event-1", "post not found", createdAt.Add(time.Minute)))
+	got, err = repo.GetByID(ctx, "event-1")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StatusFailed, got.Status)
+	assert.Equal(t, "post not found", got.Error)
+
+	assert.NoError(t, repo.MarkSucceeded(ctx, "event-1", "post-1", createdAt.Add(2*time.Minute)))
+	got, err = repo.GetByID(ctx, "event-1")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StatusSucceeded, got.Status)
+	assert.Equal(t, "post-1", got.ResourceID)
+	assert.Empty(t, got.Error)
+	assert.Equal(t, createdAt.Add(2*time.Minute), got.UpdatedAt)
+
+	assert.ErrorIs(t, repo.MarkSucceeded(ctx, "missing", "post-1", createdAt), domain.ErrOperationNotFound)
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ynwd/awesome-blog/internal/operations/domain"
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+type OperationsService interface {
+	module.OperationTracker
+
+	// GetOperation returns the user's operation. Operations of other users
+	// are reported as not found.
+	GetOperation(ctx context.Context, id, username string) (domain.Operation, error)
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/operations/domain"
+	"github.com/ynwd/awesome-blog/internal/operations/repo"
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+type operationsService struct {
+	repo repo.OperationsRepository
+}
+
+func NewOperationsService(repo repo.OperationsRepository) OperationsService {
+	return &operationsService{
+		repo: repo,
+	}
+}
+
+func (s *operationsService) Start(ctx context.Context, eventID string, eventType module.EventType, username string) error {
+	now := time.Now().UTC()
+	return s.repo.Create(ctx, domain.Operation{
+		ID:        eventID,
+		Type:      string(eventType),
+		Username:  username,
+		Status:    domain.StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+func (s *operationsService) Succeed(ctx context.Context, eventID, resourceID string) error {
+	err := s.repo.MarkSucceeded(ctx, eventID, resourceID, time.Now().UTC())
+	if errors.Is(err, domain.ErrOperationNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (s *operationsService) Fail(ctx context.Context, eventID, reason string) error {
+	err := s.repo.MarkFailed(ctx, eventID, reason, time.Now().UTC())
+	if errors.Is(err, domain.ErrOperationNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (s *operationsService) GetOperation(ctx context.Context, id, username string) (domain.Operation, error) {
+	if id == "" {
+		return domain.Operation{}, domain.ErrOperationNotFound
+	}
+
+	operation, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Operation{}, err
+	}
+	if operation.Username != username {
+		return domain.Operation{}, domain.ErrOperationNotFound
+	}
+	return operation, nil
+}
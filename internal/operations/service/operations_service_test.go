@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ynwd/awesome-blog/internal/operations/domain"
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+type mockOperationsRepository struct {
+	createFunc        func(ctx context.Context, operation domain.Operation) error
+	getByIDFunc       func(ctx context.Context, id string) (domain.Operation, error)
+	markSucceededFunc func(ctx context.Context, id, resourceID string, at time.Time) error
+	markFailedFunc    func(ctx context.Context, id, reason string, at time.Time) error
+}
+
+func (m *mockOperationsRepository) Create(ctx context.Context, operation domain.Operation) error {
+	return m.createFunc(ctx, operation)
+}
+
+func (m *mockOperationsRepository) GetByID(ctx context.Context, id string) (domain.Operation, error) {
+	return m.getByIDFunc(ctx, id)
+}
+
+func (m *mockOperationsRepository) MarkSucceeded(ctx context.Context, id, resourceID string, at time.Time) error {
+	return m.markSucceededFunc(ctx, id, resourceID, at)
+}
+
+func (m *mockOperationsRepository) MarkFailed(ctx context.Context, id, reason string, at time.Time) error {
+	return m.markFailedFunc(ctx, id, reason, at)
+}
+
+func TestOperationsService_Start(t *testing.T) {
+	var created domain.Operation
+	repo := &mockOperationsRepository{
+		createFunc: func(ctx context.Context, operation domain.Operation) error {
+			created = operation
+			return nil
+		},
+	}
+
+	err := NewOperationsService(repo).Start(context.Background(), "event-1", module.PostEvent, "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, "event-1", created.ID)
+	assert.Equal(t, "POST", created.Type)
+	assert.Equal(t, "user1", created.Username)
+	assert.Equal(t, domain.StatusPending, created.Status)
+	assert.False(t, created.CreatedAt.IsZero())
+	assert.Equal(t, created.CreatedAt, created.UpdatedAt)
+}
+
+func TestOperationsService_SucceedAndFail(t *testing.T) {
+	repo := &mockOperationsRepository{
+		markSucceededFunc: func(ctx context.Context, id, resourceID string, at time.Time) error {
+			assert.Equal(t, "post-1", resourceID)
+			return domain.ErrOperationNotFound
+		},
+		markFailedFunc: func(ctx context.Context, id, reason string, at time.Time) error {
+			return errors.New("firestore error")
+		},
+	}
+	service := NewOperationsService(repo)
+
+	// Events published before operations were tracked have no record
+	assert.NoError(t, service.Succeed(context.Background(), "event-1", "post-1"))
+	assert.EqualError(t, service.Fail(context.Background(), "event-1", "boom"), "firestore error")
+}
+
+func TestOperationsService_GetOperation(t *testing.T) {
+	repo := &mockOperationsRepository{
+		getByIDFunc: func(ctx context.Context, id string) (domain.Operation, error) {
+			if id != "event-1" {
+				return domain.Operation{}, domain.ErrOperationNotFound
+			}
+			return domain.Operation{ID: id, Username: "user1", Status: domain.StatusSucceeded}, nil
+		},
+	}
+	service := NewOperationsService(repo)
+
+	tests := []struct {
+		name     string
+		id       string
+		username string
+		wantErr  error
+	}{
+		{name: "owner", id: "event-1", username: "user1"},
+		{name: "other user", id: "event-1", username: "user2", wantErr: domain.ErrOperationNotFound},
+		{name: "missing", id: "event-2", username: "user1", wantErr: domain.ErrOperationNotFound},
+		{name: "empty id", id: "", username: "user1", wantErr: domain.ErrOperationNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operation, err := service.GetOperation(context.Background(), tt.id, tt.username)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, domain.StatusSucceeded, operation.Status)
+		})
+	}
+}
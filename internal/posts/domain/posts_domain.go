@@ -1,10 +1,187 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/ynwd/awesome-blog/pkg/markdown"
+)
+
+var (
+	ErrPostNotFound       = errors.New("post not found")
+	ErrInvalidCursor      = errors.New("invalid cursor")
+	ErrTooManyTags        = errors.New("too many tags: a post can have at most 5")
+	ErrInvalidTag         = errors.New("invalid tag: use up to 30 lowercase letters, digits and hyphens")
+	ErrInvalidCategory    = errors.New("invalid category: use up to 30 lowercase letters, digits and hyphens")
+	ErrInvalidFormat      = errors.New("invalid format: must be markdown or html")
+	ErrDescriptionTooLong = fmt.Errorf("invalid description: must be at most %d bytes", MaxDescriptionLength)
+)
+
+// Tag limits. Tags and categories are slugs such as "go" or "machine-learning".
+const (
+	MaxTags      = 5
+	MaxTagLength = 30
+)
+
+// MaxDescriptionLength bounds the markdown source of a post in bytes
+const MaxDescriptionLength = 100_000
+
+// Formats a post description can be returned in
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Posts is a blog post. Description is the markdown source; the HTML,
+// excerpt and reading time are derived from it by Render.
 type Posts struct {
-	Username    string    `json:"username" firestore:"username"`
-	Title       string    `json:"title"  firestore:"title"`
-	Description string    `json:"description" firestore:"description"`
-	CreatedAt   time.Time `json:"created_at,omitempty" firestore:"created_at"`
+	ID              string    `json:"id,omitempty" firestore:"-"`
+	Username        string    `json:"username" firestore:"username"`
+	Title           string    `json:"title"  firestore:"title"`
+	Description     string    `json:"description" firestore:"description"`
+	DescriptionHTML string    `json:"description_html,omitempty" firestore:"description_html"`
+	Excerpt         string    `json:"excerpt,omitempty" firestore:"excerpt"`
+	ReadingTime     int       `json:"reading_time,omitempty" firestore:"reading_time"`
+	Tags            []string  `json:"tags,omitempty" firestore:"tags"`
+	Category        string    `json:"category,omitempty" firestore:"category"`
+	CreatedAt       time.Time `json:"created_at,omitempty" firestore:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at,omitempty" firestore:"updated_at"`
+}
+
+// Render renders the markdown description to sanitized HTML and derives the
+// excerpt and reading time in minutes. The derived fields are always
+// overwritten, so rendered HTML never comes from a client.
+func (p *Posts) Render() error {
+	if len(p.Description) > MaxDescriptionLength {
+		return ErrDescriptionTooLong
+	}
+
+	doc, err := markdown.Render(p.Description)
+	if err != nil {
+		return err
+	}
+	p.DescriptionHTML = doc.HTML
+	p.Excerpt = doc.Excerpt
+	p.ReadingTime = doc.ReadingTime
+	return nil
+}
+
+// ParseFormat checks the format a description is requested in, defaulting to
+// the markdown source
+func ParseFormat(format string) (string, error) {
+	switch format {
+	case "", FormatMarkdown:
+		return FormatMarkdown, nil
+	case FormatHTML:
+		return FormatHTML, nil
+	default:
+		return "", ErrInvalidFormat
+	}
+}
+
+// NormalizeTopics normalizes the tags and category of the post in place and
+// checks them against the tag limits. Normalizing twice changes nothing.
+func (p *Posts) NormalizeTopics() error {
+	tags, err := NormalizeTags(p.Tags)
+	if err != nil {
+		return err
+	}
+
+	category := ""
+	if strings.TrimSpace(p.Category) != "" {
+		category, err = NormalizeTag(p.Category)
+		if err != nil {
+			return ErrInvalidCategory
+		}
+	}
+
+	p.Tags = tags
+	p.Category = category
+	return nil
+}
+
+// NormalizeTag lowercases the tag, drops a leading "#" and turns spaces and
+// underscores into hyphens, so "#Machine Learning" becomes "machine-learning"
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	tag = strings.TrimPrefix(tag, "#")
+	tag = strings.Join(strings.FieldsFunc(tag, func(r rune) bool {
+		return r == ' ' || r == '_' || r == '\t'
+	}), "-")
+
+	if len(tag) > MaxTagLength || !slugPattern.MatchString(tag) {
+		return "", ErrInvalidTag
+	}
+	return tag, nil
+}
+
+// NormalizeTags normalizes each tag and drops duplicates, keeping the order
+// the tags were given in
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > MaxTags {
+		return nil, ErrTooManyTags
+	}
+	return normalized, nil
+}
+
+// PostUpdate holds the fields to change on a post; nil fields are left as is
+type PostUpdate struct {
+	Title       *string
+	Description *string
+	Tags        *[]string
+	Category    *string
+}
+
+// PostFilter selects a page of posts, newest first. Tag and Category must be
+// normalized.
+type PostFilter struct {
+	Username string
+	Tag      string
+	Category string
+	Cursor   string
+	Limit    int
+}
+
+// FeedFilter selects a page of the posts of the authors Username follows,
+// newest first
+type FeedFilter struct {
+	Username string
+	Cursor   string
+	Limit    int
+}
+
+// FeedChunkSize is the number of authors a single feed query covers, the most
+// Firestore allows in an "in" filter
+const FeedChunkSize = 30
+
+// TagCount is the number of posts carrying a tag
+type TagCount struct {
+	Tag       string    `json:"tag" firestore:"-"`
+	Count     int64     `json:"count" firestore:"count"`
+	UpdatedAt time.Time `json:"updated_at" firestore:"updated_at"`
+}
+
+// PostPage is a page of posts. NextCursor is empty on the last page.
+type PostPage struct {
+	Posts      []Posts
+	NextCursor string
 }
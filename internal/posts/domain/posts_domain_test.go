@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		tag     string
+		want    string
+		wantErr error
+	}{
+		{tag: "go", want: "go"},
+		{tag: "  #Go ", want: "go"},
+		{tag: "Machine Learning", want: "machine-learning"},
+		{tag: "web_dev", want: "web-dev"},
+		{tag: "html5", want: "html5"},
+		{tag: "", wantErr: ErrInvalidTag},
+		{tag: "#", wantErr: ErrInvalidTag},
+		{tag: "c++", wantErr: ErrInvalidTag},
+		{tag: "-go", wantErr: ErrInvalidTag},
+		{tag: "café", wantErr: ErrInvalidTag},
+		{tag: strings.Repeat("a", MaxTagLength), want: strings.Repeat("a", MaxTagLength)},
+		{tag: strings.Repeat("a", MaxTagLength+1), wantErr: ErrInvalidTag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, err := NormalizeTag(tt.tag)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPosts_NormalizeTopics(t *testing.T) {
+	post := Posts{Tags: []string{"Go", "#go", "Testing"}, Category: " Tutorials "}
+	assert.NoError(t, post.NormalizeTopics())
+	assert.Equal(t, []string{"go", "testing"}, post.Tags)
+	assert.Equal(t, "tutorials", post.Category)
+
+	// Normalizing again changes nothing
+	assert.NoError(t, post.NormalizeTopics())
+	assert.Equal(t, []string{"go", "testing"}, post.Tags)
+
+	// Duplicates do not count towards the limit
+	post = Posts{Tags: []string{"a", "b", "c", "d", "e", "E"}}
+	assert.NoError(t, post.NormalizeTopics())
+
+	post = Posts{Tags: []string{"a", "b", "c", "d", "e", "f"}}
+	assert.ErrorIs(t, post.NormalizeTopics(), ErrTooManyTags)
+
+	post = Posts{Category: "c++"}
+	assert.ErrorIs(t, post.NormalizeTopics(), ErrInvalidCategory)
+}
+
+func TestPosts_Render(t *testing.T) {
+	post := Posts{
+		Description:     "# Hello\n\nSome **bold** text\n\n<script>alert(1)</script>",
+		DescriptionHTML: "<script>alert(1)</script>",
+	}
+	assert.NoError(t, post.Render())
+	assert.Equal(t, "<h1>Hello</h1>\n<p>Some <strong>bold</strong> text</p>\n", post.DescriptionHTML)
+	assert.Equal(t, "Hello Some bold text", post.Excerpt)
+	assert.Equal(t, 1, post.ReadingTime)
+
+	post = Posts{Description: strings.Repeat("a", MaxDescriptionLength+1)}
+	assert.ErrorIs(t, post.Render(), ErrDescriptionTooLong)
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		want    string
+		wantErr error
+	}{
+		{format: "", want: FormatMarkdown},
+		{format: "markdown", want: FormatMarkdown},
+		{format: "html", want: FormatHTML},
+		{format: "HTML", wantErr: ErrInvalidFormat},
+		{format: "pdf", wantErr: ErrInvalidFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := ParseFormat(tt.format)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,14 +1,83 @@
 package dto
 
+import "time"
+
 type CreatePostRequest struct {
-	Username    string `json:"username" binding:"required"`
-	Title       string `json:"title" binding:"required"`
-	Description string `json:"description" binding:"required"`
+	Username    string   `json:"username"`
+	Title       string   `json:"title" binding:"required"`
+	Description string   `json:"description" binding:"required"`
+	Tags        []string `json:"tags"`
+	Category    string   `json:"category"`
+}
+
+type UpdatePostRequest struct {
+	Title       *string   `json:"title"`
+	Description *string   `json:"description"`
+	Tags        *[]string `json:"tags"`
+	Category    *string   `json:"category"`
+}
+
+// PostQuery selects the form the description of returned posts is in:
+// markdown (the default) or html
+type PostQuery struct {
+	Format string `form:"format"`
+}
+
+type ListPostsQuery struct {
+	Author   string `form:"author"`
+	Tag      string `form:"tag"`
+	Category string `form:"category"`
+	Cursor   string `form:"cursor"`
+	Limit    int    `form:"limit"`
+	Format   string `form:"format"`
+}
+
+type ListTagPostsQuery struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+	Format string `form:"format"`
+}
+
+type ListTagsQuery struct {
+	Limit int `form:"limit"`
+}
+
+type FeedQuery struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+	Format string `form:"format"`
 }
 
 type PostResponse struct {
-	ID          string `json:"id"`
-	Username    string `json:"username"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	ID                string    `json:"id"`
+	Username          string    `json:"username"`
+	Title             string    `json:"title"`
+	Description       string    `json:"description"`
+	DescriptionFormat string    `json:"description_format"`
+	Excerpt           string    `json:"excerpt"`
+	ReadingTime       int       `json:"reading_time_minutes"`
+	Tags              []string  `json:"tags"`
+	Category          string    `json:"category,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type ListPostsResponse struct {
+	Posts      []PostResponse `json:"posts"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+type TagResponse struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+type ListTagsResponse struct {
+	Tags []TagResponse `json:"tags"`
+}
+
+// PublishPostResponse identifies the asynchronous write; its status is at
+// GET /operations/:id
+type PublishPostResponse struct {
+	OperationID string `json:"operation_id"`
 }
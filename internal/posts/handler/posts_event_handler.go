@@ -2,9 +2,7 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
 	"log"
-	"time"
 
 	"github.com/ynwd/awesome-blog/internal/posts/domain"
 	"github.com/ynwd/awesome-blog/internal/posts/service"
@@ -14,41 +12,46 @@ import (
 
 type PostEventHandler struct {
 	// repo    repo.PostsRepository
-	service service.PostsService
+	service    service.PostsService
+	processed  utils.ProcessedEvents
+	operations module.OperationTracker
 }
 
-func NewPostEventHandler(service service.PostsService) *PostEventHandler {
+func NewPostEventHandler(service service.PostsService, processed utils.ProcessedEvents, operations module.OperationTracker) *PostEventHandler {
 	return &PostEventHandler{
-		service: service,
+		service:    service,
+		processed:  processed,
+		operations: operations,
 	}
 }
 
-func (h *PostEventHandler) Handle(ctx context.Context, event module.BaseEvent) error {
-	if event.Type != module.PostEvent {
-		return nil
-	}
-	log.Printf("Raw event received: %+v", event)
-	payloadData, timeStamp := utils.EventParser(event)
+func (h *PostEventHandler) Handle(ctx context.Context, event module.Event[domain.Posts]) error {
+	return utils.HandleOnce(ctx, h.processed, "posts", event.ID, func() error {
+		return h.handle(ctx, event)
+	})
+}
 
-	var payload domain.Posts
-	if err := json.Unmarshal(payloadData, &payload); err != nil {
-		log.Printf("Error unmarshaling payload: %v", err)
-		return err
-	}
+func (h *PostEventHandler) handle(ctx context.Context, event module.Event[domain.Posts]) error {
+	log.Printf("Event received: %s %s", event.Type, event.ID)
 
-	createdAt, _ := time.Parse(time.RFC3339, timeStamp)
+	// The post is created under the event ID, so a redelivery that gets past
+	// the processed events record does not create it again
 	post := domain.Posts{
-		Username:    payload.Username,
-		Title:       payload.Title,
-		Description: payload.Description,
-		CreatedAt:   createdAt,
+		ID:          event.ID,
+		Username:    event.Payload.Username,
+		Title:       event.Payload.Title,
+		Description: event.Payload.Description,
+		Tags:        event.Payload.Tags,
+		Category:    event.Payload.Category,
+		CreatedAt:   event.Timestamp,
 	}
 
-	_, err := h.service.CreatePost(ctx, post)
+	created, err := h.service.CreatePost(ctx, post)
 	if err != nil {
 		log.Printf("Error processing posts event: %v", err)
 		return err
 	}
+	module.SucceedOperation(ctx, h.operations, event.ID, created.ID)
 
 	log.Printf("Successfully processed posts event: %+v", post)
 	return nil
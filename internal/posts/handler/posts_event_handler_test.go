@@ -10,72 +10,73 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/ynwd/awesome-blog/internal/posts/domain"
 	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+	"github.com/ynwd/awesome-blog/tests/helper"
 )
 
 func TestPostsEventHandler_Handle(t *testing.T) {
 	tests := []struct {
 		name    string
-		event   module.BaseEvent
+		event   module.Event[domain.Posts]
 		mockFn  func(*mockPostsService)
 		wantErr bool
 	}{
 		{
-			name: "different event type returns nil",
-			event: module.BaseEvent{
-				Type: "different_type",
+			name: "service error returns error",
+			event: module.Event[domain.Posts]{
+				Type: module.PostEvent,
+				Payload: domain.Posts{
+					Username:    "testuser",
+					Title:       "Test Post",
+					Description: "Test Description",
+				},
+				Timestamp: time.Now(),
 			},
-			mockFn:  func(m *mockPostsService) {},
-			wantErr: false,
-		},
-		{
-			name: "invalid json payload returns error",
-			event: module.BaseEvent{
-				Type:    module.PostEvent,
-				Payload: json.RawMessage(`invalid json`),
+			mockFn: func(m *mockPostsService) {
+				m.createPostFunc = func(ctx context.Context, post domain.Posts) (domain.Posts, error) {
+					return domain.Posts{}, errors.New("repository error")
+				}
 			},
-			mockFn:  func(m *mockPostsService) {},
 			wantErr: true,
 		},
 		{
-			name: "service error returns error",
-			event: module.BaseEvent{
+			name: "successful handling returns nil",
+			event: module.Event[domain.Posts]{
 				Type: module.PostEvent,
-				Payload: func() json.RawMessage {
-					post := domain.Posts{
-						Username:    "testuser",
-						Title:       "Test Post",
-						Description: "Test Description",
-						CreatedAt:   time.Now(),
-					}
-					b, _ := json.Marshal(post)
-					return b
-				}(),
+				Payload: domain.Posts{
+					Username:    "testuser",
+					Title:       "Test Post",
+					Description: "Test Description",
+				},
+				Timestamp: time.Now(),
 			},
 			mockFn: func(m *mockPostsService) {
-				m.createPostFunc = func(ctx context.Context, post domain.Posts) (string, error) {
-					return "", errors.New("repository error")
+				m.createPostFunc = func(ctx context.Context, post domain.Posts) (domain.Posts, error) {
+					post.ID = "post-123"
+					return post, nil
 				}
 			},
-			wantErr: true,
+			wantErr: false,
 		},
 		{
-			name: "successful handling returns nil",
-			event: module.BaseEvent{
+			name: "post is created under the event ID",
+			event: module.Event[domain.Posts]{
+				ID:   "event-1",
 				Type: module.PostEvent,
-				Payload: func() json.RawMessage {
-					post := domain.Posts{
-						Username:    "testuser",
-						Title:       "Test Post",
-						Description: "Test Description",
-						CreatedAt:   time.Now(),
-					}
-					b, _ := json.Marshal(post)
-					return b
-				}(),
+				Payload: domain.Posts{
+					ID:          "ignored",
+					Username:    "testuser",
+					Title:       "Test Post",
+					Description: "Test Description",
+				},
+				Timestamp: time.Now(),
 			},
 			mockFn: func(m *mockPostsService) {
-				m.createPostFunc = func(ctx context.Context, post domain.Posts) (string, error) {
-					return "post-123", nil
+				m.createPostFunc = func(ctx context.Context, post domain.Posts) (domain.Posts, error) {
+					if post.ID != "event-1" {
+						return domain.Posts{}, errors.New("unexpected id " + post.ID)
+					}
+					return post, nil
 				}
 			},
 			wantErr: false,
@@ -86,7 +87,7 @@ func TestPostsEventHandler_Handle(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := &mockPostsService{}
 			tt.mockFn(mockService)
-			handler := NewPostEventHandler(mockService)
+			handler := NewPostEventHandler(mockService, utils.NewMemoryProcessedEvents(time.Hour), &helper.MockOperationTracker{})
 
 			err := handler.Handle(context.Background(), tt.event)
 
@@ -98,3 +99,56 @@ func TestPostsEventHandler_Handle(t *testing.T) {
 		})
 	}
 }
+
+func TestPostsEventHandler_HandleRedelivery(t *testing.T) {
+	calls := 0
+	mockService := &mockPostsService{
+		createPostFunc: func(ctx context.Context, post domain.Posts) (domain.Posts, error) {
+			calls++
+			post.ID = "post-123"
+			return post, nil
+		},
+	}
+	handler := NewPostEventHandler(mockService, utils.NewMemoryProcessedEvents(time.Hour), &helper.MockOperationTracker{})
+
+	registry := module.NewEventRegistry()
+	module.Handle(registry, module.PostEvent, module.PostEventVersion, handler.Handle)
+
+	data, err := json.Marshal(module.NewEvent(module.PostEvent, domain.Posts{
+		Username:    "testuser",
+		Title:       "Test Post",
+		Description: "Test Description",
+	}))
+	assert.NoError(t, err)
+
+	assert.NoError(t, registry.Dispatch(context.Background(), data))
+	assert.NoError(t, registry.Dispatch(context.Background(), data))
+	assert.Equal(t, 1, calls)
+}
+
+func TestPostsEventHandler_HandleSucceedsOperation(t *testing.T) {
+	mockService := &mockPostsService{
+		createPostFunc: func(ctx context.Context, post domain.Posts) (domain.Posts, error) {
+			post.ID = "post-123"
+			return post, nil
+		},
+	}
+	var eventID, resourceID string
+	tracker := &helper.MockOperationTracker{
+		SucceedFunc: func(ctx context.Context, id, resource string) error {
+			eventID, resourceID = id, resource
+			return errors.New("firestore error")
+		},
+	}
+	handler := NewPostEventHandler(mockService, utils.NewMemoryProcessedEvents(time.Hour), tracker)
+
+	// A tracking failure does not fail the event, the post is already written
+	err := handler.Handle(context.Background(), module.Event[domain.Posts]{
+		ID:      "event-1",
+		Type:    module.PostEvent,
+		Payload: domain.Posts{Username: "testuser", Title: "Test Post"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "event-1", eventID)
+	assert.Equal(t, "post-123", resourceID)
+}
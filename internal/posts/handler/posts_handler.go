@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/ynwd/awesome-blog/internal/posts/domain"
 	"github.com/ynwd/awesome-blog/internal/posts/dto"
 	"github.com/ynwd/awesome-blog/internal/posts/service"
+	"github.com/ynwd/awesome-blog/pkg/middleware"
 	"github.com/ynwd/awesome-blog/pkg/module"
 	"github.com/ynwd/awesome-blog/pkg/pubsub"
 	"github.com/ynwd/awesome-blog/pkg/res"
@@ -16,12 +18,14 @@ import (
 type PostsHandler struct {
 	postsService service.PostsService
 	pubsub       pubsub.PubSubClient
+	operations   module.OperationTracker
 }
 
-func NewPostsHandler(postsService service.PostsService, pubsubClient pubsub.PubSubClient) *PostsHandler {
+func NewPostsHandler(postsService service.PostsService, pubsubClient pubsub.PubSubClient, operations module.OperationTracker) *PostsHandler {
 	return &PostsHandler{
 		postsService: postsService,
 		pubsub:       pubsubClient,
+		operations:   operations,
 	}
 }
 
@@ -32,26 +36,226 @@ func (h *PostsHandler) CreatePost(c *gin.Context) {
 		return
 	}
 
+	format, ok := descriptionFormat(c)
+	if !ok {
+		return
+	}
+
+	username, err := middleware.ActingUser(c, req.Username)
+	if err != nil {
+		c.JSON(middleware.IdentityErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
 	post := domain.Posts{
-		Username:    req.Username,
+		Username:    username,
+		Title:       req.Title,
+		Description: req.Description,
+		Tags:        req.Tags,
+		Category:    req.Category,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	post, err = h.postsService.CreatePost(c.Request.Context(), post)
+	if err != nil {
+		c.JSON(postErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, res.Success(toPostResponse(post, format), "Post created successfully"))
+}
+
+// GetPost returns a single post by ID
+func (h *PostsHandler) GetPost(c *gin.Context) {
+	format, ok := descriptionFormat(c)
+	if !ok {
+		return
+	}
+
+	post, err := h.postsService.GetPost(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(postErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(toPostResponse(post, format), "Post retrieved successfully"))
+}
+
+// ListPosts returns posts newest first, optionally filtered by author, tag or
+// category. Pass next_cursor from the previous response as cursor to get the
+// next page.
+func (h *PostsHandler) ListPosts(c *gin.Context) {
+	var query dto.ListPostsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error("Invalid query parameters"))
+		return
+	}
+
+	h.listPosts(c, query.Format, domain.PostFilter{
+		Username: query.Author,
+		Tag:      query.Tag,
+		Category: query.Category,
+		Cursor:   query.Cursor,
+		Limit:    query.Limit,
+	})
+}
+
+// ListTagPosts returns the posts carrying a tag, newest first
+func (h *PostsHandler) ListTagPosts(c *gin.Context) {
+	var query dto.ListTagPostsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error("Invalid query parameters"))
+		return
+	}
+
+	h.listPosts(c, query.Format, domain.PostFilter{
+		Tag:    c.Param("tag"),
+		Cursor: query.Cursor,
+		Limit:  query.Limit,
+	})
+}
+
+// ListTags returns the tags in use with their post counts, most used first
+func (h *PostsHandler) ListTags(c *gin.Context) {
+	var query dto.ListTagsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error("Invalid query parameters"))
+		return
+	}
+
+	tags, err := h.postsService.ListTags(c.Request.Context(), query.Limit)
+	if err != nil {
+		c.JSON(postErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	response := dto.ListTagsResponse{Tags: make([]dto.TagResponse, 0, len(tags))}
+	for _, tag := range tags {
+		response.Tags = append(response.Tags, dto.TagResponse{Tag: tag.Tag, Count: tag.Count})
+	}
+
+	c.JSON(http.StatusOK, res.Success(response, "Tags retrieved successfully"))
+}
+
+func (h *PostsHandler) listPosts(c *gin.Context, format string, filter domain.PostFilter) {
+	format, err := domain.ParseFormat(format)
+	if err != nil {
+		c.JSON(postErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	page, err := h.postsService.ListPosts(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(postErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	response := dto.ListPostsResponse{
+		Posts:      make([]dto.PostResponse, 0, len(page.Posts)),
+		NextCursor: page.NextCursor,
+	}
+	for _, post := range page.Posts {
+		response.Posts = append(response.Posts, toPostResponse(post, format))
+	}
+
+	c.JSON(http.StatusOK, res.Success(response, "Posts retrieved successfully"))
+}
+
+// Feed returns the posts of the authors the authenticated user follows,
+// newest first. Pass next_cursor from the previous response as cursor to get
+// the next page.
+func (h *PostsHandler) Feed(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	var query dto.FeedQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error("Invalid query parameters"))
+		return
+	}
+
+	format, err := domain.ParseFormat(query.Format)
+	if err != nil {
+		c.JSON(postErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	filter := domain.FeedFilter{
+		Username: username,
+		Cursor:   query.Cursor,
+		Limit:    query.Limit,
+	}
+
+	page, err := h.postsService.Feed(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(postErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	response := dto.ListPostsResponse{
+		Posts:      make([]dto.PostResponse, 0, len(page.Posts)),
+		NextCursor: page.NextCursor,
+	}
+	for _, post := range page.Posts {
+		response.Posts = append(response.Posts, toPostResponse(post, format))
+	}
+
+	c.JSON(http.StatusOK, res.Success(response, "Feed retrieved successfully"))
+}
+
+// UpdatePost changes the title, description, tags or category of the
+// caller's own post
+func (h *PostsHandler) UpdatePost(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	var req dto.UpdatePostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error("Invalid request payload"))
+		return
+	}
+
+	format, ok := descriptionFormat(c)
+	if !ok {
+		return
+	}
+
+	update := domain.PostUpdate{
 		Title:       req.Title,
 		Description: req.Description,
+		Tags:        req.Tags,
+		Category:    req.Category,
 	}
 
-	postID, err := h.postsService.CreatePost(c.Request.Context(), post)
+	post, err := h.postsService.UpdatePost(c.Request.Context(), username, c.Param("id"), update)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, res.Error(err.Error()))
+		c.JSON(postErrorStatus(err), res.Error(err.Error()))
 		return
 	}
 
-	response := dto.PostResponse{
-		ID:          postID,
-		Username:    post.Username,
-		Title:       post.Title,
-		Description: post.Description,
+	c.JSON(http.StatusOK, res.Success(toPostResponse(post, format), "Post updated successfully"))
+}
+
+// DeletePost removes the caller's own post
+func (h *PostsHandler) DeletePost(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
 	}
 
-	c.JSON(http.StatusCreated, res.Success(response, "Post created successfully"))
+	if err := h.postsService.DeletePost(c.Request.Context(), username, c.Param("id")); err != nil {
+		c.JSON(postErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(nil, "Post deleted successfully"))
 }
 
 func (h *PostsHandler) PublishPost(c *gin.Context) {
@@ -61,16 +265,102 @@ func (h *PostsHandler) PublishPost(c *gin.Context) {
 		return
 	}
 
-	event := module.BaseEvent{
-		Type:      module.PostEvent,
-		Payload:   postEvent,
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	username, err := middleware.ActingUser(c, postEvent.Username)
+	if err != nil {
+		c.JSON(middleware.IdentityErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+	postEvent.Username = username
+
+	// Reject bad tags and oversized descriptions now rather than
+	// dead-lettering the event
+	if err := postEvent.NormalizeTopics(); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error(err.Error()))
+		return
+	}
+	if len(postEvent.Description) > domain.MaxDescriptionLength {
+		c.JSON(http.StatusBadRequest, res.Error(domain.ErrDescriptionTooLong.Error()))
+		return
 	}
 
-	if err := h.pubsub.Publish(c.Request.Context(), event); err != nil {
+	event := module.NewEvent(module.PostEvent, postEvent)
+
+	err = module.PublishTracked(c.Request.Context(), h.pubsub, h.operations, event, username)
+	if errors.Is(err, module.ErrOperationNotStarted) {
+		c.JSON(http.StatusInternalServerError, res.Error("Failed to record posts operation"))
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, res.Error("Failed to publish posts event"))
 		return
 	}
 
-	c.JSON(http.StatusCreated, res.Success(nil, "posts event published successfully"))
+	c.Header("Location", "/operations/"+event.ID)
+	c.JSON(http.StatusCreated, res.Success(dto.PublishPostResponse{OperationID: event.ID}, "posts event published successfully"))
+}
+
+// descriptionFormat reads the format query parameter, answering 400 when it is invalid
+func descriptionFormat(c *gin.Context) (string, bool) {
+	var query dto.PostQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error("Invalid query parameters"))
+		return "", false
+	}
+
+	format, err := domain.ParseFormat(query.Format)
+	if err != nil {
+		c.JSON(postErrorStatus(err), res.Error(err.Error()))
+		return "", false
+	}
+	return format, true
+}
+
+// toPostResponse returns the description as its markdown source or as
+// sanitized HTML, depending on format
+func toPostResponse(post domain.Posts, format string) dto.PostResponse {
+	// Posts written before tags existed have none stored
+	tags := post.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	description := post.Description
+	if format == domain.FormatHTML {
+		description = post.DescriptionHTML
+	}
+
+	return dto.PostResponse{
+		ID:                post.ID,
+		Username:          post.Username,
+		Title:             post.Title,
+		Description:       description,
+		DescriptionFormat: format,
+		Excerpt:           post.Excerpt,
+		ReadingTime:       post.ReadingTime,
+		Tags:              tags,
+		Category:          post.Category,
+		CreatedAt:         post.CreatedAt,
+		UpdatedAt:         post.UpdatedAt,
+	}
+}
+
+func postErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPostNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrInvalidUsername):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrInvalidPost),
+		errors.Is(err, domain.ErrInvalidCursor),
+		errors.Is(err, domain.ErrTooManyTags),
+		errors.Is(err, domain.ErrInvalidTag),
+		errors.Is(err, domain.ErrInvalidCategory),
+		errors.Is(err, domain.ErrInvalidFormat),
+		errors.Is(err, domain.ErrDescriptionTooLong):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
 }
@@ -12,27 +12,83 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/ynwd/awesome-blog/internal/posts/domain"
 	"github.com/ynwd/awesome-blog/internal/posts/dto"
+	"github.com/ynwd/awesome-blog/internal/posts/service"
+	"github.com/ynwd/awesome-blog/pkg/module"
 	"github.com/ynwd/awesome-blog/pkg/res"
 	"github.com/ynwd/awesome-blog/tests/helper"
 )
 
 type mockPostsService struct {
-	createPostFunc func(ctx context.Context, post domain.Posts) (string, error)
+	createPostFunc func(ctx context.Context, post domain.Posts) (domain.Posts, error)
+	getPostFunc    func(ctx context.Context, id string) (domain.Posts, error)
+	listPostsFunc  func(ctx context.Context, filter domain.PostFilter) (domain.PostPage, error)
+	feedFunc       func(ctx context.Context, filter domain.FeedFilter) (domain.PostPage, error)
+	listTagsFunc   func(ctx context.Context, limit int) ([]domain.TagCount, error)
+	updatePostFunc func(ctx context.Context, username, id string, update domain.PostUpdate) (domain.Posts, error)
+	deletePostFunc func(ctx context.Context, username, id string) error
+	postExistsFunc func(ctx context.Context, id string) (bool, error)
+	postAuthorFunc func(ctx context.Context, id string) (string, error)
 }
 
-func (m *mockPostsService) CreatePost(ctx context.Context, post domain.Posts) (string, error) {
+func (m *mockPostsService) CreatePost(ctx context.Context, post domain.Posts) (domain.Posts, error) {
 	return m.createPostFunc(ctx, post)
 }
 
+func (m *mockPostsService) GetPost(ctx context.Context, id string) (domain.Posts, error) {
+	return m.getPostFunc(ctx, id)
+}
+
+func (m *mockPostsService) ListPosts(ctx context.Context, filter domain.PostFilter) (domain.PostPage, error) {
+	return m.listPostsFunc(ctx, filter)
+}
+
+func (m *mockPostsService) Feed(ctx context.Context, filter domain.FeedFilter) (domain.PostPage, error) {
+	return m.feedFunc(ctx, filter)
+}
+
+func (m *mockPostsService) ListTags(ctx context.Context, limit int) ([]domain.TagCount, error) {
+	return m.listTagsFunc(ctx, limit)
+}
+
+func (m *mockPostsService) UpdatePost(ctx context.Context, username, id string, update domain.PostUpdate) (domain.Posts, error) {
+	return m.updatePostFunc(ctx, username, id, update)
+}
+
+func (m *mockPostsService) DeletePost(ctx context.Context, username, id string) error {
+	return m.deletePostFunc(ctx, username, id)
+}
+
+func (m *mockPostsService) PostExists(ctx context.Context, id string) (bool, error) {
+	return m.postExistsFunc(ctx, id)
+}
+
+func (m *mockPostsService) PostAuthor(ctx context.Context, id string) (string, error) {
+	return m.postAuthorFunc(ctx, id)
+}
+
+// storedPost prepares the post the way the service stores it
+func storedPost(post domain.Posts, id string) (domain.Posts, error) {
+	if err := post.NormalizeTopics(); err != nil {
+		return domain.Posts{}, err
+	}
+	if err := post.Render(); err != nil {
+		return domain.Posts{}, err
+	}
+	post.ID = id
+	post.UpdatedAt = post.CreatedAt
+	return post, nil
+}
+
 func TestPostsHandler_CreatePost(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	tests := []struct {
 		name       string
 		reqBody    interface{}
-		mockSvcFn  func(ctx context.Context, post domain.Posts) (string, error)
+		mockSvcFn  func(ctx context.Context, post domain.Posts) (domain.Posts, error)
 		wantStatus int
 		wantResp   *res.Response
 	}{
@@ -43,26 +99,81 @@ func TestPostsHandler_CreatePost(t *testing.T) {
 				Title:       "Test Post",
 				Description: "Test Description",
 			},
-			mockSvcFn: func(ctx context.Context, post domain.Posts) (string, error) {
-				return "post-123", nil
+			mockSvcFn: func(ctx context.Context, post domain.Posts) (domain.Posts, error) {
+				return storedPost(post, "post-123")
 			},
 			wantStatus: http.StatusCreated,
 			wantResp: &res.Response{
 				Status:  "success",
 				Message: "Post created successfully",
 				Data: dto.PostResponse{
-					ID:          "post-123",
-					Username:    "testuser",
-					Title:       "Test Post",
-					Description: "Test Description",
+					ID:                "post-123",
+					Username:          "testuser",
+					Title:             "Test Post",
+					Description:       "Test Description",
+					DescriptionFormat: "markdown",
+					Excerpt:           "Test Description",
+					ReadingTime:       1,
+					Tags:              []string{},
 				},
 			},
 		},
+		{
+			name: "success with tags",
+			reqBody: dto.CreatePostRequest{
+				Username:    "testuser",
+				Title:       "Test Post",
+				Description: "Test Description",
+				Tags:        []string{"#Go", "Web Dev"},
+				Category:    "Tutorials",
+			},
+			mockSvcFn: func(ctx context.Context, post domain.Posts) (domain.Posts, error) {
+				return storedPost(post, "post-123")
+			},
+			wantStatus: http.StatusCreated,
+			wantResp: &res.Response{
+				Status:  "success",
+				Message: "Post created successfully",
+				Data: dto.PostResponse{
+					ID:                "post-123",
+					Username:          "testuser",
+					Title:             "Test Post",
+					Description:       "Test Description",
+					DescriptionFormat: "markdown",
+					Excerpt:           "Test Description",
+					ReadingTime:       1,
+					Tags:              []string{"go", "web-dev"},
+					Category:          "tutorials",
+				},
+			},
+		},
+		{
+			name: "invalid tag",
+			reqBody: dto.CreatePostRequest{
+				Username:    "testuser",
+				Title:       "Test Post",
+				Description: "Test Description",
+				Tags:        []string{"c++"},
+			},
+			mockSvcFn: func(ctx context.Context, post domain.Posts) (domain.Posts, error) {
+				return storedPost(post, "post-123")
+			},
+			wantStatus: http.StatusBadRequest,
+		},
 		{
 			name:       "invalid request body",
 			reqBody:    "invalid json",
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name: "username mismatch",
+			reqBody: dto.CreatePostRequest{
+				Username:    "someone-else",
+				Title:       "Test Post",
+				Description: "Test Description",
+			},
+			wantStatus: http.StatusForbidden,
+		},
 		{
 			name: "service error",
 			reqBody: dto.CreatePostRequest{
@@ -70,8 +181,8 @@ func TestPostsHandler_CreatePost(t *testing.T) {
 				Title:       "Test Post",
 				Description: "Test Description",
 			},
-			mockSvcFn: func(ctx context.Context, post domain.Posts) (string, error) {
-				return "", errors.New("service error")
+			mockSvcFn: func(ctx context.Context, post domain.Posts) (domain.Posts, error) {
+				return domain.Posts{}, errors.New("service error")
 			},
 			wantStatus: http.StatusInternalServerError,
 		},
@@ -83,7 +194,7 @@ func TestPostsHandler_CreatePost(t *testing.T) {
 				createPostFunc: tt.mockSvcFn,
 			}
 			mockPubSub := &helper.MockPubSub{}
-			handler := NewPostsHandler(mockSvc, mockPubSub)
+			handler := NewPostsHandler(mockSvc, mockPubSub, &helper.MockOperationTracker{})
 
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
@@ -91,6 +202,7 @@ func TestPostsHandler_CreatePost(t *testing.T) {
 			jsonBody, _ := json.Marshal(tt.reqBody)
 			c.Request = httptest.NewRequest(http.MethodPost, "/posts", bytes.NewBuffer(jsonBody))
 			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("user_id", "testuser")
 
 			handler.CreatePost(c)
 
@@ -110,6 +222,12 @@ func TestPostsHandler_CreatePost(t *testing.T) {
 
 				assert.Equal(t, tt.wantResp.Status, got.Status)
 				assert.Equal(t, tt.wantResp.Message, got.Message)
+				assert.False(t, postResp.CreatedAt.IsZero())
+				assert.Equal(t, postResp.CreatedAt, postResp.UpdatedAt)
+
+				// Timestamps are set by the handler, so only compare the rest
+				postResp.CreatedAt = time.Time{}
+				postResp.UpdatedAt = time.Time{}
 				assert.Equal(t, tt.wantResp.Data.(dto.PostResponse), postResp)
 			}
 		})
@@ -124,8 +242,10 @@ func TestPostsHandler_PublishPost(t *testing.T) {
 		name       string
 		reqBody    interface{}
 		mockPubFn  func(ctx context.Context, event interface{}) error
+		startErr   error
 		wantStatus int
 		wantResp   interface{}
+		wantFailed bool
 	}{
 		{
 			name: "success",
@@ -149,6 +269,15 @@ func TestPostsHandler_PublishPost(t *testing.T) {
 			reqBody:    "invalid json",
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name: "username mismatch",
+			reqBody: domain.Posts{
+				Username:    "someone-else",
+				Title:       "Test Post",
+				Description: "Test Description",
+			},
+			wantStatus: http.StatusForbidden,
+		},
 		{
 			name: "pubsub error",
 			reqBody: domain.Posts{
@@ -161,6 +290,21 @@ func TestPostsHandler_PublishPost(t *testing.T) {
 				return errors.New("pubsub error")
 			},
 			wantStatus: http.StatusInternalServerError,
+			wantFailed: true,
+		},
+		{
+			name: "operation not recorded",
+			reqBody: domain.Posts{
+				Username:    "testuser",
+				Title:       "Test Post",
+				Description: "Test Description",
+			},
+			mockPubFn: func(ctx context.Context, event interface{}) error {
+				t.Error("event published without an operation")
+				return nil
+			},
+			startErr:   errors.New("firestore error"),
+			wantStatus: http.StatusInternalServerError,
 		},
 	}
 
@@ -170,7 +314,20 @@ func TestPostsHandler_PublishPost(t *testing.T) {
 			mockPubSub := &helper.MockPubSub{
 				PublishFunc: tt.mockPubFn,
 			}
-			handler := NewPostsHandler(mockSvc, mockPubSub)
+			var started, failed string
+			tracker := &helper.MockOperationTracker{
+				StartFunc: func(ctx context.Context, eventID string, eventType module.EventType, username string) error {
+					assert.Equal(t, module.PostEvent, eventType)
+					assert.Equal(t, "testuser", username)
+					started = eventID
+					return tt.startErr
+				},
+				FailFunc: func(ctx context.Context, eventID, reason string) error {
+					failed = eventID
+					return nil
+				},
+			}
+			handler := NewPostsHandler(mockSvc, mockPubSub, tracker)
 
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
@@ -178,16 +335,505 @@ func TestPostsHandler_PublishPost(t *testing.T) {
 			jsonBody, _ := json.Marshal(tt.reqBody)
 			c.Request = httptest.NewRequest(http.MethodPost, "/posts/publish", bytes.NewBuffer(jsonBody))
 			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("user_id", "testuser")
 
 			handler.PublishPost(c)
 
 			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantFailed {
+				assert.Equal(t, started, failed)
+			}
 			if tt.wantResp != nil {
 				var got res.Response
 				err := json.Unmarshal(w.Body.Bytes(), &got)
 				assert.NoError(t, err)
+				assert.NotEmpty(t, started)
+				assert.Equal(t, map[string]interface{}{"operation_id": started}, got.Data)
+				assert.Equal(t, "/operations/"+started, w.Header().Get("Location"))
+
+				got.Data = nil
 				assert.Equal(t, tt.wantResp, got)
 			}
 		})
 	}
 }
+
+func TestPostsHandler_GetPost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	createdAt := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		mockSvcFn  func(ctx context.Context, id string) (domain.Posts, error)
+		wantStatus int
+		wantID     string
+	}{
+		{
+			name: "success",
+			mockSvcFn: func(ctx context.Context, id string) (domain.Posts, error) {
+				return domain.Posts{
+					ID:          id,
+					Username:    "testuser",
+					Title:       "Test Post",
+					Description: "Test Description",
+					CreatedAt:   createdAt,
+					UpdatedAt:   createdAt,
+				}, nil
+			},
+			wantStatus: http.StatusOK,
+			wantID:     "post-123",
+		},
+		{
+			name: "not found",
+			mockSvcFn: func(ctx context.Context, id string) (domain.Posts, error) {
+				return domain.Posts{}, domain.ErrPostNotFound
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "service error",
+			mockSvcFn: func(ctx context.Context, id string) (domain.Posts, error) {
+				return domain.Posts{}, errors.New("service error")
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockPostsService{getPostFunc: tt.mockSvcFn}
+			handler := NewPostsHandler(mockSvc, &helper.MockPubSub{}, &helper.MockOperationTracker{})
+
+			router := gin.New()
+			router.GET("/posts/:id", handler.GetPost)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/posts/post-123", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantID != "" {
+				var got struct {
+					Data dto.PostResponse `json:"data"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &got)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantID, got.Data.ID)
+				assert.Equal(t, createdAt, got.Data.CreatedAt)
+				assert.Equal(t, createdAt, got.Data.UpdatedAt)
+			}
+		})
+	}
+}
+
+func TestPostsHandler_GetPost_Format(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	post := domain.Posts{
+		ID:          "post-123",
+		Username:    "testuser",
+		Title:       "Test Post",
+		Description: "Some **bold** text",
+	}
+	require.NoError(t, post.Render())
+
+	tests := []struct {
+		name            string
+		query           string
+		wantStatus      int
+		wantDescription string
+		wantFormat      string
+	}{
+		{name: "markdown by default", wantStatus: http.StatusOK, wantDescription: "Some **bold** text", wantFormat: "markdown"},
+		{name: "markdown", query: "?format=markdown", wantStatus: http.StatusOK, wantDescription: "Some **bold** text", wantFormat: "markdown"},
+		{name: "html", query: "?format=html", wantStatus: http.StatusOK, wantDescription: "<p>Some <strong>bold</strong> text</p>\n", wantFormat: "html"},
+		{name: "unknown format", query: "?format=pdf", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockPostsService{getPostFunc: func(ctx context.Context, id string) (domain.Posts, error) {
+				return post, nil
+			}}
+			handler := NewPostsHandler(mockSvc, &helper.MockPubSub{}, &helper.MockOperationTracker{})
+
+			router := gin.New()
+			router.GET("/posts/:id", handler.GetPost)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/posts/post-123"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got struct {
+				Data dto.PostResponse `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, tt.wantDescription, got.Data.Description)
+			assert.Equal(t, tt.wantFormat, got.Data.DescriptionFormat)
+			assert.Equal(t, "Some bold text", got.Data.Excerpt)
+			assert.Equal(t, 1, got.Data.ReadingTime)
+		})
+	}
+}
+
+func TestPostsHandler_ListPosts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		url        string
+		mockSvcFn  func(ctx context.Context, filter domain.PostFilter) (domain.PostPage, error)
+		wantStatus int
+		wantCount  int
+		wantCursor string
+	}{
+		{
+			name: "success with filter",
+			url:  "/posts?author=testuser&limit=2&cursor=abc",
+			mockSvcFn: func(ctx context.Context, filter domain.PostFilter) (domain.PostPage, error) {
+				assert.Equal(t, domain.PostFilter{Username: "testuser", Cursor: "abc", Limit: 2}, filter)
+				return domain.PostPage{
+					Posts:      []domain.Posts{{ID: "p2"}, {ID: "p1"}},
+					NextCursor: "next",
+				}, nil
+			},
+			wantStatus: http.StatusOK,
+			wantCount:  2,
+			wantCursor: "next",
+		},
+		{
+			name: "empty page",
+			url:  "/posts",
+			mockSvcFn: func(ctx context.Context, filter domain.PostFilter) (domain.PostPage, error) {
+				return domain.PostPage{}, nil
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid limit",
+			url:        "/posts?limit=abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid cursor",
+			url:  "/posts?cursor=bad",
+			mockSvcFn: func(ctx context.Context, filter domain.PostFilter) (domain.PostPage, error) {
+				return domain.PostPage{}, domain.ErrInvalidCursor
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockPostsService{listPostsFunc: tt.mockSvcFn}
+			handler := NewPostsHandler(mockSvc, &helper.MockPubSub{}, &helper.MockOperationTracker{})
+
+			router := gin.New()
+			router.GET("/posts", handler.ListPosts)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var got struct {
+					Data dto.ListPostsResponse `json:"data"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &got)
+				assert.NoError(t, err)
+				assert.NotNil(t, got.Data.Posts)
+				assert.Len(t, got.Data.Posts, tt.wantCount)
+				assert.Equal(t, tt.wantCursor, got.Data.NextCursor)
+			}
+		})
+	}
+}
+
+func TestPostsHandler_Feed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		username   string
+		url        string
+		mockSvcFn  func(ctx context.Context, filter domain.FeedFilter) (domain.PostPage, error)
+		wantStatus int
+		wantCount  int
+		wantCursor string
+	}{
+		{
+			name:     "followed authors",
+			username: "reader",
+			url:      "/feed?limit=2&cursor=abc",
+			mockSvcFn: func(ctx context.Context, filter domain.FeedFilter) (domain.PostPage, error) {
+				assert.Equal(t, domain.FeedFilter{Username: "reader", Cursor: "abc", Limit: 2}, filter)
+				return domain.PostPage{
+					Posts:      []domain.Posts{{ID: "p2", Username: "bob"}, {ID: "p1", Username: "alice"}},
+					NextCursor: "next",
+				}, nil
+			},
+			wantStatus: http.StatusOK,
+			wantCount:  2,
+			wantCursor: "next",
+		},
+		{
+			name:     "following nobody",
+			username: "reader",
+			url:      "/feed",
+			mockSvcFn: func(ctx context.Context, filter domain.FeedFilter) (domain.PostPage, error) {
+				return domain.PostPage{}, nil
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:     "invalid cursor",
+			username: "reader",
+			url:      "/feed?cursor=bad",
+			mockSvcFn: func(ctx context.Context, filter domain.FeedFilter) (domain.PostPage, error) {
+				return domain.PostPage{}, domain.ErrInvalidCursor
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unauthenticated",
+			url:        "/feed",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockPostsService{feedFunc: tt.mockSvcFn}
+			handler := NewPostsHandler(mockSvc, &helper.MockPubSub{}, &helper.MockOperationTracker{})
+
+			router := gin.New()
+			if tt.username != "" {
+				router.Use(func(c *gin.Context) {
+					c.Set("user_id", tt.username)
+				})
+			}
+			router.GET("/feed", handler.Feed)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var got struct {
+					Data dto.ListPostsResponse `json:"data"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &got)
+				assert.NoError(t, err)
+				assert.NotNil(t, got.Data.Posts)
+				assert.Len(t, got.Data.Posts, tt.wantCount)
+				assert.Equal(t, tt.wantCursor, got.Data.NextCursor)
+			}
+		})
+	}
+}
+
+func TestPostsHandler_UpdatePost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		reqBody    interface{}
+		user       string
+		mockSvcFn  func(ctx context.Context, username, id string, update domain.PostUpdate) (domain.Posts, error)
+		wantStatus int
+	}{
+		{
+			name:    "success",
+			reqBody: map[string]string{"title": "New Title"},
+			user:    "testuser",
+			mockSvcFn: func(ctx context.Context, username, id string, update domain.PostUpdate) (domain.Posts, error) {
+				assert.Equal(t, "testuser", username)
+				assert.Equal(t, "post-123", id)
+				assert.Equal(t, "New Title", *update.Title)
+				assert.Nil(t, update.Description)
+				return domain.Posts{ID: id, Username: username, Title: *update.Title}, nil
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unauthenticated",
+			reqBody:    map[string]string{"title": "New Title"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid request body",
+			reqBody:    "invalid json",
+			user:       "testuser",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "not the author",
+			reqBody: map[string]string{"title": "New Title"},
+			user:    "testuser",
+			mockSvcFn: func(ctx context.Context, username, id string, update domain.PostUpdate) (domain.Posts, error) {
+				return domain.Posts{}, service.ErrForbidden
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:    "empty title",
+			reqBody: map[string]string{"title": ""},
+			user:    "testuser",
+			mockSvcFn: func(ctx context.Context, username, id string, update domain.PostUpdate) (domain.Posts, error) {
+				return domain.Posts{}, service.ErrInvalidPost
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "not found",
+			reqBody: map[string]string{"title": "New Title"},
+			user:    "testuser",
+			mockSvcFn: func(ctx context.Context, username, id string, update domain.PostUpdate) (domain.Posts, error) {
+				return domain.Posts{}, domain.ErrPostNotFound
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockPostsService{updatePostFunc: tt.mockSvcFn}
+			handler := NewPostsHandler(mockSvc, &helper.MockPubSub{}, &helper.MockOperationTracker{})
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.user != "" {
+					c.Set("user_id", tt.user)
+				}
+			})
+			router.PATCH("/posts/:id", handler.UpdatePost)
+
+			jsonBody, _ := json.Marshal(tt.reqBody)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPatch, "/posts/post-123", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestPostsHandler_DeletePost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		user       string
+		mockSvcFn  func(ctx context.Context, username, id string) error
+		wantStatus int
+	}{
+		{
+			name: "success",
+			user: "testuser",
+			mockSvcFn: func(ctx context.Context, username, id string) error {
+				assert.Equal(t, "testuser", username)
+				assert.Equal(t, "post-123", id)
+				return nil
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unauthenticated",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "not the author",
+			user: "testuser",
+			mockSvcFn: func(ctx context.Context, username, id string) error {
+				return service.ErrForbidden
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "not found",
+			user: "testuser",
+			mockSvcFn: func(ctx context.Context, username, id string) error {
+				return domain.ErrPostNotFound
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockPostsService{deletePostFunc: tt.mockSvcFn}
+			handler := NewPostsHandler(mockSvc, &helper.MockPubSub{}, &helper.MockOperationTracker{})
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.user != "" {
+					c.Set("user_id", tt.user)
+				}
+			})
+			router.DELETE("/posts/:id", handler.DeletePost)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodDelete, "/posts/post-123", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestPostsHandler_Tags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mockPostsService{
+		listTagsFunc: func(ctx context.Context, limit int) ([]domain.TagCount, error) {
+			assert.Equal(t, 2, limit)
+			return []domain.TagCount{{Tag: "go", Count: 3}, {Tag: "testing", Count: 1}}, nil
+		},
+		listPostsFunc: func(ctx context.Context, filter domain.PostFilter) (domain.PostPage, error) {
+			if filter.Tag == "c++" {
+				return domain.PostPage{}, domain.ErrInvalidTag
+			}
+			assert.Equal(t, domain.PostFilter{Tag: "go", Cursor: "abc", Limit: 1}, filter)
+			return domain.PostPage{
+				Posts:      []domain.Posts{{ID: "p1", Tags: []string{"go"}}},
+				NextCursor: "next",
+			}, nil
+		},
+	}
+	handler := NewPostsHandler(mockSvc, &helper.MockPubSub{}, &helper.MockOperationTracker{})
+
+	router := gin.New()
+	router.GET("/tags", handler.ListTags)
+	router.GET("/tags/:tag/posts", handler.ListTagPosts)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tags?limit=2", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"status": "success",
+		"message": "Tags retrieved successfully",
+		"data": {"tags": [{"tag": "go", "count": 3}, {"tag": "testing", "count": 1}]}
+	}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tags/go/posts?cursor=abc&limit=1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got struct {
+		Data dto.ListPostsResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Len(t, got.Data.Posts, 1)
+	assert.Equal(t, []string{"go"}, got.Data.Posts[0].Tags)
+	assert.Equal(t, "next", got.Data.NextCursor)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tags/c++/posts", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
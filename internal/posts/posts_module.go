@@ -1,42 +1,48 @@
 package posts
 
 import (
-	"context"
-
 	"cloud.google.com/go/firestore"
 	"github.com/ynwd/awesome-blog/internal/posts/handler"
 	"github.com/ynwd/awesome-blog/internal/posts/repo"
 	"github.com/ynwd/awesome-blog/internal/posts/service"
 	"github.com/ynwd/awesome-blog/pkg/module"
 	"github.com/ynwd/awesome-blog/pkg/pubsub"
+	"github.com/ynwd/awesome-blog/pkg/utils"
 )
 
 type Module struct {
 	handler      *handler.PostsHandler
 	pubsub       pubsub.PubSubClient
 	eventHandler *handler.PostEventHandler
+	service      service.PostsService
 }
 
-func NewModule(firestoreClient *firestore.Client, pubsubClient pubsub.PubSubClient) *Module {
+func NewModule(firestoreClient *firestore.Client, pubsubClient pubsub.PubSubClient, processed utils.ProcessedEvents, operations module.OperationTracker, follows module.FollowGraph) *Module {
 	// Initialize repository
 	postsRepo := repo.NewPostsRepository(firestoreClient)
 
 	// Initialize service with repository
-	postsService := service.NewPostsService(postsRepo)
+	postsService := service.NewPostsService(postsRepo, repo.NewTagsRepository(firestoreClient), follows)
 
 	// Initialize handler with service
-	postsHandler := handler.NewPostsHandler(postsService, pubsubClient)
+	postsHandler := handler.NewPostsHandler(postsService, pubsubClient, operations)
 
 	// Initialize event handler with repository
-	eventHandler := handler.NewPostEventHandler(postsService)
+	eventHandler := handler.NewPostEventHandler(postsService, processed, operations)
 
 	return &Module{
 		pubsub:       pubsubClient,
 		handler:      postsHandler,
 		eventHandler: eventHandler,
+		service:      postsService,
 	}
 }
 
-func (m *Module) RegisterEventHandlers(ctx context.Context, event module.BaseEvent) {
-	m.eventHandler.Handle(ctx, event)
+// PostLookup exposes post existence checks to other modules
+func (m *Module) PostLookup() module.PostLookup {
+	return m.service
+}
+
+func (m *Module) RegisterEvents(registry *module.EventRegistry) {
+	module.Handle(registry, module.PostEvent, module.PostEventVersion, m.eventHandler.Handle)
 }
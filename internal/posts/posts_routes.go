@@ -5,4 +5,13 @@ import "github.com/gin-gonic/gin"
 func (m *Module) RegisterRoutes(router *gin.Engine) {
 	router.POST("/post", m.handler.CreatePost)
 	router.POST("/post/pubsub", m.handler.PublishPost)
+
+	router.GET("/posts", m.handler.ListPosts)
+	router.GET("/feed", m.handler.Feed)
+
+	router.GET("/tags", m.handler.ListTags)
+	router.GET("/tags/:tag/posts", m.handler.ListTagPosts)
+	router.GET("/posts/:id", m.handler.GetPost)
+	router.PATCH("/posts/:id", m.handler.UpdatePost)
+	router.DELETE("/posts/:id", m.handler.DeletePost)
 }
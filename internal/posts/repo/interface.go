@@ -8,4 +8,13 @@ import (
 
 type PostsRepository interface {
 	Create(ctx context.Context, post domain.Posts) (string, error)
+	GetByID(ctx context.Context, id string) (domain.Posts, error)
+	List(ctx context.Context, filter domain.PostFilter) (domain.PostPage, error)
+	ListByAuthors(ctx context.Context, authors []string, filter domain.FeedFilter) (domain.PostPage, error)
+	Update(ctx context.Context, post domain.Posts) error
+	Delete(ctx context.Context, id string) error
+}
+
+type TagsRepository interface {
+	List(ctx context.Context, limit int) ([]domain.TagCount, error)
 }
@@ -2,27 +2,295 @@ package repo
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"github.com/ynwd/awesome-blog/internal/posts/domain"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/outbox"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// TagsCollection holds a counter document per tag, keyed by the tag
+const TagsCollection = "tags"
+
+// postsFirestore writes every change to a post together with the domain
+// event reporting it to the outbox and the counters of the tags it adds or
+// removes
 type postsFirestore struct {
 	client     *firestore.Client
 	collection string
+	outbox     *outbox.Outbox
 }
 
 func NewPostsRepository(client *firestore.Client) PostsRepository {
 	return &postsFirestore{
 		client:     client,
 		collection: "posts",
+		outbox:     outbox.New(client),
 	}
 }
 
+// Create writes the post under its ID, or under a new one when it has none.
+// Creating a post whose ID already exists is a no-op, so a redelivered
+// command does not write it twice.
 func (r *postsFirestore) Create(ctx context.Context, post domain.Posts) (string, error) {
-	doc, _, err := r.client.Collection(r.collection).Add(ctx, post)
+	ref := r.client.Collection(r.collection).NewDoc()
+	if post.ID != "" {
+		ref = r.client.Collection(r.collection).Doc(post.ID)
+	}
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := tx.Create(ref, post); err != nil {
+			return err
+		}
+		if err := r.countTags(tx, nil, post.Tags); err != nil {
+			return err
+		}
+		post.ID = ref.ID
+		return r.outbox.Add(tx, module.PostCreatedEvent, post)
+	})
+	if status.Code(err) == codes.AlreadyExists {
+		return ref.ID, nil
+	}
 	if err != nil {
 		return "", err
 	}
-	return doc.ID, nil
+	return ref.ID, nil
+}
+
+func (r *postsFirestore) GetByID(ctx context.Context, id string) (domain.Posts, error) {
+	doc, err := r.client.Collection(r.collection).Doc(id).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return domain.Posts{}, domain.ErrPostNotFound
+	}
+	if err != nil {
+		return domain.Posts{}, err
+	}
+	return toPost(doc)
+}
+
+// List returns posts newest first. Each filter requires a composite index on
+// (username, created_at desc, __name__ desc), (tags array-contains,
+// created_at desc, __name__ desc) or (category, created_at desc, __name__
+// desc), and combining filters requires an index on all of their fields.
+func (r *postsFirestore) List(ctx context.Context, filter domain.PostFilter) (domain.PostPage, error) {
+	query := r.client.Collection(r.collection).Query
+	if filter.Username != "" {
+		query = query.Where("username", "==", filter.Username)
+	}
+	if filter.Tag != "" {
+		query = query.Where("tags", "array-contains", filter.Tag)
+	}
+	if filter.Category != "" {
+		query = query.Where("category", "==", filter.Category)
+	}
+
+	posts, err := r.fetch(ctx, query, filter.Cursor, filter.Limit)
+	if err != nil {
+		return domain.PostPage{}, err
+	}
+	return toPage(posts, filter.Limit), nil
+}
+
+// ListByAuthors returns the posts of the given authors newest first. It runs
+// one query per domain.FeedChunkSize authors and merges their pages, using
+// the same index as List.
+func (r *postsFirestore) ListByAuthors(ctx context.Context, authors []string, filter domain.FeedFilter) (domain.PostPage, error) {
+	var chunks [][]string
+	for start := 0; start < len(authors); start += domain.FeedChunkSize {
+		chunks = append(chunks, authors[start:min(start+domain.FeedChunkSize, len(authors))])
+	}
+
+	results := make([][]domain.Posts, len(chunks))
+	errs := make([]error, len(chunks))
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			query := r.client.Collection(r.collection).Where("username", "in", chunk)
+			results[i], errs[i] = r.fetch(ctx, query, filter.Cursor, filter.Limit)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return domain.PostPage{}, err
+	}
+
+	// Each chunk holds its newest posts after the cursor, so the newest of
+	// all of them are the page
+	var posts []domain.Posts
+	for _, result := range results {
+		posts = append(posts, result...)
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		if !posts[i].CreatedAt.Equal(posts[j].CreatedAt) {
+			return posts[i].CreatedAt.After(posts[j].CreatedAt)
+		}
+		return posts[i].ID > posts[j].ID
+	})
+	if len(posts) > filter.Limit+1 {
+		posts = posts[:filter.Limit+1]
+	}
+	return toPage(posts, filter.Limit), nil
+}
+
+func (r *postsFirestore) Update(ctx context.Context, post domain.Posts) error {
+	ref := r.client.Collection(r.collection).Doc(post.ID)
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return domain.ErrPostNotFound
+		}
+		if err != nil {
+			return err
+		}
+		stored, err := toPost(doc)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Update(ref, []firestore.Update{
+			{Path: "title", Value: post.Title},
+			{Path: "description", Value: post.Description},
+			{Path: "description_html", Value: post.DescriptionHTML},
+			{Path: "excerpt", Value: post.Excerpt},
+			{Path: "reading_time", Value: post.ReadingTime},
+			{Path: "tags", Value: post.Tags},
+			{Path: "category", Value: post.Category},
+			{Path: "updated_at", Value: post.UpdatedAt},
+		})
+		if err != nil {
+			return err
+		}
+		if err := r.countTags(tx, stored.Tags, post.Tags); err != nil {
+			return err
+		}
+		return r.outbox.Add(tx, module.PostUpdatedEvent, post)
+	})
+}
+
+// Delete removes the post. Deleting a post that does not exist emits no event.
+func (r *postsFirestore) Delete(ctx context.Context, id string) error {
+	ref := r.client.Collection(r.collection).Doc(id)
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		post, err := toPost(doc)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(ref); err != nil {
+			return err
+		}
+		if err := r.countTags(tx, post.Tags, nil); err != nil {
+			return err
+		}
+		return r.outbox.Add(tx, module.PostDeletedEvent, post)
+	})
+}
+
+// countTags moves the tag counters from the tags a post had to the tags it
+// has now. Counters are incremented rather than read, so posts sharing a
+// popular tag do not contend on its document; a counter at zero is left in
+// place and hidden from the tag list.
+func (r *postsFirestore) countTags(tx *firestore.Transaction, before, after []string) error {
+	now := time.Now()
+	for _, tag := range before {
+		if slices.Contains(after, tag) {
+			continue
+		}
+		if err := r.addToTag(tx, tag, -1, now); err != nil {
+			return err
+		}
+	}
+	for _, tag := range after {
+		if slices.Contains(before, tag) {
+			continue
+		}
+		if err := r.addToTag(tx, tag, 1, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *postsFirestore) addToTag(tx *firestore.Transaction, tag string, delta int, now time.Time) error {
+	ref := r.client.Collection(TagsCollection).Doc(tag)
+	return tx.Set(ref, map[string]any{
+		"count":      firestore.Increment(delta),
+		"updated_at": now,
+	}, firestore.MergeAll)
+}
+
+// fetch returns up to limit+1 posts of the query after the cursor, newest
+// first. The extra post tells whether another page exists.
+func (r *postsFirestore) fetch(ctx context.Context, query firestore.Query, cursor string, limit int) ([]domain.Posts, error) {
+	query = query.
+		OrderBy("created_at", firestore.Desc).
+		OrderBy(firestore.DocumentID, firestore.Desc)
+
+	if cursor != "" {
+		c, err := utils.DecodeCursor(cursor)
+		if err != nil {
+			return nil, domain.ErrInvalidCursor
+		}
+		query = query.StartAfter(c.CreatedAt, c.ID)
+	}
+
+	iter := query.Limit(limit + 1).Documents(ctx)
+	defer iter.Stop()
+
+	var posts []domain.Posts
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		post, err := toPost(doc)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+	return posts, nil
+}
+
+// toPage trims the posts to the page size, setting the cursor when there
+// are more
+func toPage(posts []domain.Posts, limit int) domain.PostPage {
+	page := domain.PostPage{Posts: posts}
+	if len(posts) > limit {
+		page.Posts = posts[:limit]
+		last := page.Posts[limit-1]
+		page.NextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+	}
+	return page
+}
+
+func toPost(doc *firestore.DocumentSnapshot) (domain.Posts, error) {
+	var post domain.Posts
+	if err := doc.DataTo(&post); err != nil {
+		return domain.Posts{}, err
+	}
+	post.ID = doc.Ref.ID
+	return post, nil
 }
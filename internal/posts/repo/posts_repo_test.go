@@ -2,11 +2,14 @@ package repo
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/ynwd/awesome-blog/internal/posts/domain"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/outbox"
 	"github.com/ynwd/awesome-blog/tests/helper"
 )
 
@@ -66,6 +69,23 @@ func TestPostsFirestore_Create(t *testing.T) {
 			assert.NotZero(t, savedPost.CreatedAt)
 		})
 	}
+
+	t.Run("post with an ID is created once", func(t *testing.T) {
+		post := domain.Posts{ID: "event-1", Username: "testuser", Title: "Once", Description: "Once", CreatedAt: time.Now()}
+
+		gotID, err := repo.Create(ctx, post)
+		assert.NoError(t, err)
+		assert.Equal(t, "event-1", gotID)
+
+		post.Title = "Twice"
+		gotID, err = repo.Create(ctx, post)
+		assert.NoError(t, err)
+		assert.Equal(t, "event-1", gotID)
+
+		saved, err := repo.GetByID(ctx, "event-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "Once", saved.Title)
+	})
 }
 
 func TestPostsFirestore_GetAll(t *testing.T) {
@@ -111,3 +131,261 @@ func TestPostsFirestore_GetAll(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestPostsFirestore_GetUpdateDelete(t *testing.T) {
+	client := helper.SetupRepoClient(t)
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	repo := NewPostsRepository(client)
+	ctx := context.Background()
+
+	id, err := repo.Create(ctx, domain.Posts{
+		Username:    "user1",
+		Title:       "Post 1",
+		Description: "Description 1",
+		CreatedAt:   time.Now(),
+	})
+	assert.NoError(t, err)
+
+	post, err := repo.GetByID(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, id, post.ID)
+	assert.Equal(t, "Post 1", post.Title)
+
+	post.Title = "Post 1 edited"
+	post.UpdatedAt = time.Now()
+	err = repo.Update(ctx, post)
+	assert.NoError(t, err)
+
+	updated, err := repo.GetByID(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "Post 1 edited", updated.Title)
+
+	err = repo.Delete(ctx, id)
+	assert.NoError(t, err)
+
+	_, err = repo.GetByID(ctx, id)
+	assert.ErrorIs(t, err, domain.ErrPostNotFound)
+
+	err = repo.Update(ctx, post)
+	assert.ErrorIs(t, err, domain.ErrPostNotFound)
+
+	// Every write staged its event in the outbox
+	pending, err := outbox.New(client).Pending(ctx, 10)
+	assert.NoError(t, err)
+	var types []module.EventType
+	for _, record := range pending {
+		types = append(types, record.Type)
+	}
+	assert.Equal(t, []module.EventType{module.PostCreatedEvent, module.PostUpdatedEvent, module.PostDeletedEvent}, types)
+}
+
+func TestPostsFirestore_List(t *testing.T) {
+	client := helper.SetupRepoClient(t)
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	repo := NewPostsRepository(client)
+	ctx := context.Background()
+
+	base := time.Now()
+	for i := 0; i < 5; i++ {
+		username := "user1"
+		if i%2 == 1 {
+			username = "user2"
+		}
+		_, err := repo.Create(ctx, domain.Posts{
+			Username:    username,
+			Title:       "Post",
+			Description: "Description",
+			CreatedAt:   base.Add(time.Duration(i) * time.Minute),
+		})
+		assert.NoError(t, err)
+	}
+
+	// Walk all pages and check ordering
+	var all []domain.Posts
+	cursor := ""
+	for {
+		page, err := repo.List(ctx, domain.PostFilter{Cursor: cursor, Limit: 2})
+		assert.NoError(t, err)
+		all = append(all, page.Posts...)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	assert.Len(t, all, 5)
+	for i := 1; i < len(all); i++ {
+		assert.True(t, all[i-1].CreatedAt.After(all[i].CreatedAt))
+	}
+
+	page, err := repo.List(ctx, domain.PostFilter{Username: "user1", Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.Posts, 3)
+	assert.Empty(t, page.NextCursor)
+
+	_, err = repo.List(ctx, domain.PostFilter{Cursor: "not-a-cursor", Limit: 10})
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+}
+
+func TestPostsFirestore_ListByAuthors(t *testing.T) {
+	client := helper.SetupRepoClient(t)
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	repo := NewPostsRepository(client)
+	ctx := context.Background()
+
+	// Authors beyond the first chunk are queried separately and merged
+	authors := make([]string, domain.FeedChunkSize+5)
+	for i := range authors {
+		authors[i] = fmt.Sprintf("author%d", i)
+	}
+	base := time.Now()
+	for i, username := range []string{"author0", "author32", "stranger", "author1", "author34"} {
+		_, err := repo.Create(ctx, domain.Posts{
+			Username:    username,
+			Title:       "Post",
+			Description: "Description",
+			CreatedAt:   base.Add(time.Duration(i) * time.Minute),
+		})
+		assert.NoError(t, err)
+	}
+
+	var all []domain.Posts
+	cursor := ""
+	for {
+		page, err := repo.ListByAuthors(ctx, authors, domain.FeedFilter{Cursor: cursor, Limit: 2})
+		assert.NoError(t, err)
+		all = append(all, page.Posts...)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	var usernames []string
+	for _, post := range all {
+		usernames = append(usernames, post.Username)
+	}
+	assert.Equal(t, []string{"author34", "author1", "author32", "author0"}, usernames)
+
+	_, err = repo.ListByAuthors(ctx, authors, domain.FeedFilter{Cursor: "not-a-cursor", Limit: 10})
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+}
+
+func TestPostsFirestore_Tags(t *testing.T) {
+	client := helper.SetupRepoClient(t)
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	repo := NewPostsRepository(client)
+	tags := NewTagsRepository(client)
+	ctx := context.Background()
+
+	now := time.Now()
+	first, err := repo.Create(ctx, domain.Posts{Username: "user1", Title: "Post", Description: "Description", Tags: []string{"go", "testing"}, CreatedAt: now})
+	assert.NoError(t, err)
+	_, err = repo.Create(ctx, domain.Posts{Username: "user2", Title: "Post", Description: "Description", Tags: []string{"go"}, Category: "tutorials", CreatedAt: now.Add(time.Minute)})
+	assert.NoError(t, err)
+
+	counts, err := tags.List(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"go", "testing"}, tagNames(counts))
+	assert.Equal(t, int64(2), counts[0].Count)
+
+	page, err := repo.List(ctx, domain.PostFilter{Tag: "go", Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.Posts, 2)
+
+	page, err = repo.List(ctx, domain.PostFilter{Category: "tutorials", Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.Posts, 1)
+
+	// Editing moves the counts from the removed tags to the added ones
+	post, err := repo.GetByID(ctx, first)
+	assert.NoError(t, err)
+	post.Tags = []string{"go", "databases"}
+	assert.NoError(t, repo.Update(ctx, post))
+
+	counts, err = tags.List(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"go", "databases"}, tagNames(counts))
+
+	// Tags no post carries any more are not listed
+	assert.NoError(t, repo.Delete(ctx, first))
+	counts, err = tags.List(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"go"}, tagNames(counts))
+	assert.Equal(t, int64(1), counts[0].Count)
+}
+
+func tagNames(counts []domain.TagCount) []string {
+	names := make([]string, 0, len(counts))
+	for _, count := range counts {
+		names = append(names, count.Tag)
+	}
+	return names
+}
+
+func TestBackfillRendered(t *testing.T) {
+	client := helper.SetupRepoClient(t)
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	ctx := context.Background()
+	posts := client.Collection("posts")
+
+	// A post written before rendering, and one already rendered
+	_, err = posts.Doc("legacy").Set(ctx, map[string]any{"username": "alice", "description": "# Hello"})
+	assert.NoError(t, err)
+	_, err = posts.Doc("rendered").Set(ctx, map[string]any{"username": "bob", "description": "Hi", "description_html": "<p>Hi</p>\n"})
+	assert.NoError(t, err)
+
+	updated, err := BackfillRendered(ctx, client)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, updated)
+
+	post, err := NewPostsRepository(client).GetByID(ctx, "legacy")
+	assert.NoError(t, err)
+	assert.Equal(t, "<h1>Hello</h1>\n", post.DescriptionHTML)
+	assert.Equal(t, "Hello", post.Excerpt)
+	assert.Equal(t, 1, post.ReadingTime)
+
+	// Running it again changes nothing
+	updated, err = BackfillRendered(ctx, client)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, updated)
+}
@@ -0,0 +1,68 @@
+package repo
+
+import (
+	"context"
+	"log"
+
+	"cloud.google.com/go/firestore"
+	"github.com/ynwd/awesome-blog/internal/posts/domain"
+	"google.golang.org/api/iterator"
+)
+
+// BackfillRendered stores the HTML, excerpt and reading time of posts written
+// before descriptions were rendered, and returns how many posts it updated.
+// Posts whose description is over today's length limit are skipped. An update
+// only applies if the post was not changed since it was read, since an edit
+// renders the post itself. No event is published: the source of the post is
+// unchanged.
+func BackfillRendered(ctx context.Context, client *firestore.Client) (int, error) {
+	iter := client.Collection("posts").Select("description", "description_html").Documents(ctx)
+	defer iter.Stop()
+
+	bulk := client.BulkWriter(ctx)
+	var jobs []*firestore.BulkWriterJob
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			bulk.End()
+			return 0, err
+		}
+
+		docData := doc.Data()
+		description, _ := docData["description"].(string)
+		rendered, _ := docData["description_html"].(string)
+		if description == "" || rendered != "" {
+			continue
+		}
+
+		post := domain.Posts{Description: description}
+		if err := post.Render(); err != nil {
+			log.Printf("Not rendering post %s: %v", doc.Ref.ID, err)
+			continue
+		}
+		job, err := bulk.Update(doc.Ref, []firestore.Update{
+			{Path: "description_html", Value: post.DescriptionHTML},
+			{Path: "excerpt", Value: post.Excerpt},
+			{Path: "reading_time", Value: post.ReadingTime},
+		}, firestore.LastUpdateTime(doc.UpdateTime))
+		if err != nil {
+			bulk.End()
+			return 0, err
+		}
+		jobs = append(jobs, job)
+	}
+	bulk.End()
+
+	updated := 0
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			log.Printf("Not rendering a post changed during the backfill: %v", err)
+			continue
+		}
+		updated++
+	}
+	return updated, nil
+}
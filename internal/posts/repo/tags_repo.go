@@ -0,0 +1,47 @@
+package repo
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/ynwd/awesome-blog/internal/posts/domain"
+	"google.golang.org/api/iterator"
+)
+
+type tagsFirestore struct {
+	client *firestore.Client
+}
+
+func NewTagsRepository(client *firestore.Client) TagsRepository {
+	return &tagsFirestore{client: client}
+}
+
+// List returns the tags in use, most used first. Counters are maintained by
+// the posts repository.
+func (r *tagsFirestore) List(ctx context.Context, limit int) ([]domain.TagCount, error) {
+	iter := r.client.Collection(TagsCollection).
+		Where("count", ">", 0).
+		OrderBy("count", firestore.Desc).
+		Limit(limit).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var tags []domain.TagCount
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var tag domain.TagCount
+		if err := doc.DataTo(&tag); err != nil {
+			return nil, err
+		}
+		tag.Tag = doc.Ref.ID
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
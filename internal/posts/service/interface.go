@@ -7,5 +7,15 @@ import (
 )
 
 type PostsService interface {
-	CreatePost(ctx context.Context, post domain.Posts) (string, error)
+	// CreatePost normalizes, renders and stores the post, returning it as
+	// stored
+	CreatePost(ctx context.Context, post domain.Posts) (domain.Posts, error)
+	GetPost(ctx context.Context, id string) (domain.Posts, error)
+	ListPosts(ctx context.Context, filter domain.PostFilter) (domain.PostPage, error)
+	Feed(ctx context.Context, filter domain.FeedFilter) (domain.PostPage, error)
+	ListTags(ctx context.Context, limit int) ([]domain.TagCount, error)
+	UpdatePost(ctx context.Context, username, id string, update domain.PostUpdate) (domain.Posts, error)
+	DeletePost(ctx context.Context, username, id string) error
+	PostExists(ctx context.Context, id string) (bool, error)
+	PostAuthor(ctx context.Context, id string) (string, error)
 }
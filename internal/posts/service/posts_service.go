@@ -7,28 +7,205 @@ import (
 
 	"github.com/ynwd/awesome-blog/internal/posts/domain"
 	"github.com/ynwd/awesome-blog/internal/posts/repo"
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+
+	DefaultTagsPageSize = 50
 )
 
 var (
 	ErrInvalidPost     = errors.New("invalid post: title, description and username are required")
 	ErrInvalidUsername = errors.New("invalid username: username cannot be empty")
+	ErrForbidden       = errors.New("only the author can modify this post")
 )
 
 type postsService struct {
 	postsRepo repo.PostsRepository
+	tagsRepo  repo.TagsRepository
+	follows   module.FollowGraph
 }
 
-func NewPostsService(postsRepo repo.PostsRepository) PostsService {
+func NewPostsService(postsRepo repo.PostsRepository, tagsRepo repo.TagsRepository, follows module.FollowGraph) PostsService {
 	return &postsService{
 		postsRepo: postsRepo,
+		tagsRepo:  tagsRepo,
+		follows:   follows,
 	}
 }
 
-func (s *postsService) CreatePost(ctx context.Context, post domain.Posts) (string, error) {
+func (s *postsService) CreatePost(ctx context.Context, post domain.Posts) (domain.Posts, error) {
 	if post.Title == "" || post.Description == "" || post.Username == "" {
-		return "", ErrInvalidPost
+		return domain.Posts{}, ErrInvalidPost
+	}
+	if err := post.NormalizeTopics(); err != nil {
+		return domain.Posts{}, err
+	}
+	if err := post.Render(); err != nil {
+		return domain.Posts{}, err
+	}
+
+	if post.CreatedAt.IsZero() {
+		post.CreatedAt = time.Now()
+	}
+	post.UpdatedAt = post.CreatedAt
+
+	id, err := s.postsRepo.Create(ctx, post)
+	if err != nil {
+		return domain.Posts{}, err
+	}
+	post.ID = id
+	return post, nil
+}
+
+func (s *postsService) GetPost(ctx context.Context, id string) (domain.Posts, error) {
+	if id == "" {
+		return domain.Posts{}, domain.ErrPostNotFound
+	}
+	return s.postsRepo.GetByID(ctx, id)
+}
+
+func (s *postsService) ListPosts(ctx context.Context, filter domain.PostFilter) (domain.PostPage, error) {
+	if filter.Tag != "" {
+		tag, err := domain.NormalizeTag(filter.Tag)
+		if err != nil {
+			return domain.PostPage{}, err
+		}
+		filter.Tag = tag
 	}
+	if filter.Category != "" {
+		category, err := domain.NormalizeTag(filter.Category)
+		if err != nil {
+			return domain.PostPage{}, domain.ErrInvalidCategory
+		}
+		filter.Category = category
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultPageSize
+	}
+	if filter.Limit > MaxPageSize {
+		filter.Limit = MaxPageSize
+	}
+	return s.postsRepo.List(ctx, filter)
+}
+
+// ListTags returns the tags in use with the number of posts carrying them,
+// most used first
+func (s *postsService) ListTags(ctx context.Context, limit int) ([]domain.TagCount, error) {
+	if limit <= 0 {
+		limit = DefaultTagsPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	return s.tagsRepo.List(ctx, limit)
+}
 
-	post.CreatedAt = time.Now()
-	return s.postsRepo.Create(ctx, post)
+// Feed returns the posts of the authors the user follows, newest first. The
+// followed authors are read on every request, so follows and unfollows show
+// on the next page.
+func (s *postsService) Feed(ctx context.Context, filter domain.FeedFilter) (domain.PostPage, error) {
+	if filter.Username == "" {
+		return domain.PostPage{}, ErrInvalidUsername
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultPageSize
+	}
+	if filter.Limit > MaxPageSize {
+		filter.Limit = MaxPageSize
+	}
+
+	authors, err := s.follows.Followees(ctx, filter.Username)
+	if err != nil {
+		return domain.PostPage{}, err
+	}
+	if len(authors) == 0 {
+		return domain.PostPage{}, nil
+	}
+	return s.postsRepo.ListByAuthors(ctx, authors, filter)
+}
+
+func (s *postsService) UpdatePost(ctx context.Context, username, id string, update domain.PostUpdate) (domain.Posts, error) {
+	post, err := s.authorizedPost(ctx, username, id)
+	if err != nil {
+		return domain.Posts{}, err
+	}
+
+	if update.Title != nil {
+		post.Title = *update.Title
+	}
+	if update.Description != nil {
+		post.Description = *update.Description
+	}
+	if update.Tags != nil {
+		post.Tags = *update.Tags
+	}
+	if update.Category != nil {
+		post.Category = *update.Category
+	}
+	if post.Title == "" || post.Description == "" {
+		return domain.Posts{}, ErrInvalidPost
+	}
+	if err := post.NormalizeTopics(); err != nil {
+		return domain.Posts{}, err
+	}
+	if err := post.Render(); err != nil {
+		return domain.Posts{}, err
+	}
+
+	post.UpdatedAt = time.Now()
+	if err := s.postsRepo.Update(ctx, post); err != nil {
+		return domain.Posts{}, err
+	}
+	return post, nil
+}
+
+func (s *postsService) DeletePost(ctx context.Context, username, id string) error {
+	if _, err := s.authorizedPost(ctx, username, id); err != nil {
+		return err
+	}
+	return s.postsRepo.Delete(ctx, id)
+}
+
+// PostExists implements module.PostLookup
+func (s *postsService) PostExists(ctx context.Context, id string) (bool, error) {
+	_, err := s.GetPost(ctx, id)
+	if errors.Is(err, domain.ErrPostNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// PostAuthor implements module.PostLookup
+func (s *postsService) PostAuthor(ctx context.Context, id string) (string, error) {
+	post, err := s.GetPost(ctx, id)
+	if errors.Is(err, domain.ErrPostNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return post.Username, nil
+}
+
+// authorizedPost loads the post and checks that username is its author
+func (s *postsService) authorizedPost(ctx context.Context, username, id string) (domain.Posts, error) {
+	if username == "" {
+		return domain.Posts{}, ErrInvalidUsername
+	}
+
+	post, err := s.GetPost(ctx, id)
+	if err != nil {
+		return domain.Posts{}, err
+	}
+	if post.Username != username {
+		return domain.Posts{}, ErrForbidden
+	}
+	return post, nil
 }
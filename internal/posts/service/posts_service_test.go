@@ -3,12 +3,14 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/ynwd/awesome-blog/internal/posts/domain"
+	"github.com/ynwd/awesome-blog/tests/helper"
 )
 
 type mockPostsRepository struct {
@@ -20,6 +22,42 @@ func (m *mockPostsRepository) Create(ctx context.Context, post domain.Posts) (st
 	return args.String(0), args.Error(1)
 }
 
+func (m *mockPostsRepository) GetByID(ctx context.Context, id string) (domain.Posts, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.Posts), args.Error(1)
+}
+
+func (m *mockPostsRepository) List(ctx context.Context, filter domain.PostFilter) (domain.PostPage, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(domain.PostPage), args.Error(1)
+}
+
+func (m *mockPostsRepository) ListByAuthors(ctx context.Context, authors []string, filter domain.FeedFilter) (domain.PostPage, error) {
+	args := m.Called(ctx, authors, filter)
+	return args.Get(0).(domain.PostPage), args.Error(1)
+}
+
+func (m *mockPostsRepository) Update(ctx context.Context, post domain.Posts) error {
+	args := m.Called(ctx, post)
+	return args.Error(0)
+}
+
+func (m *mockPostsRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type mockTagsRepository struct {
+	listFunc func(ctx context.Context, limit int) ([]domain.TagCount, error)
+}
+
+func (m *mockTagsRepository) List(ctx context.Context, limit int) ([]domain.TagCount, error) {
+	if m.listFunc != nil {
+		return m.listFunc(ctx, limit)
+	}
+	return nil, nil
+}
+
 func TestPostsService_CreatePost(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -47,6 +85,61 @@ func TestPostsService_CreatePost(t *testing.T) {
 			wantID:  "post-123",
 			wantErr: nil,
 		},
+		{
+			name: "tags and category are normalized",
+			post: domain.Posts{
+				Username:    "testuser",
+				Title:       "Test Post",
+				Description: "Test Description",
+				Tags:        []string{"#Go", "Machine Learning", "go"},
+				Category:    " Tutorials ",
+			},
+			mockFn: func(m *mockPostsRepository) {
+				m.On("Create", mock.Anything, mock.MatchedBy(func(post domain.Posts) bool {
+					return assert.ObjectsAreEqual([]string{"go", "machine-learning"}, post.Tags) &&
+						post.Category == "tutorials"
+				})).Return("post-123", nil)
+			},
+			wantID: "post-123",
+		},
+		{
+			name: "description is rendered",
+			post: domain.Posts{
+				Username:        "testuser",
+				Title:           "Test Post",
+				Description:     "Some *markdown*",
+				DescriptionHTML: "<script>alert(1)</script>",
+			},
+			mockFn: func(m *mockPostsRepository) {
+				m.On("Create", mock.Anything, mock.MatchedBy(func(post domain.Posts) bool {
+					return post.DescriptionHTML == "<p>Some <em>markdown</em></p>\n" &&
+						post.Excerpt == "Some markdown" &&
+						post.ReadingTime == 1
+				})).Return("post-123", nil)
+			},
+			wantID: "post-123",
+		},
+		{
+			name: "description too long",
+			post: domain.Posts{
+				Username:    "testuser",
+				Title:       "Test Post",
+				Description: strings.Repeat("a", domain.MaxDescriptionLength+1),
+			},
+			mockFn:  func(m *mockPostsRepository) {},
+			wantErr: domain.ErrDescriptionTooLong,
+		},
+		{
+			name: "invalid tag",
+			post: domain.Posts{
+				Username:    "testuser",
+				Title:       "Test Post",
+				Description: "Test Description",
+				Tags:        []string{"c++"},
+			},
+			mockFn:  func(m *mockPostsRepository) {},
+			wantErr: domain.ErrInvalidTag,
+		},
 		{
 			name: "empty title",
 			post: domain.Posts{
@@ -102,19 +195,324 @@ func TestPostsService_CreatePost(t *testing.T) {
 				tt.mockFn(mockRepo)
 			}
 
-			service := NewPostsService(mockRepo)
-			gotID, err := service.CreatePost(context.Background(), tt.post)
+			service := NewPostsService(mockRepo, &mockTagsRepository{}, &helper.MockFollowGraph{})
+			got, err := service.CreatePost(context.Background(), tt.post)
 
 			if tt.wantErr != nil {
 				assert.Error(t, err)
 				assert.Equal(t, tt.wantErr.Error(), err.Error())
-				assert.Empty(t, gotID)
+				assert.Empty(t, got.ID)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantID, got.ID)
+				assert.NotEmpty(t, got.DescriptionHTML)
+				assert.Equal(t, got.CreatedAt, got.UpdatedAt)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostsService_ListPosts(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    domain.PostFilter
+		wantLimit int
+	}{
+		{name: "default limit", filter: domain.PostFilter{}, wantLimit: DefaultPageSize},
+		{name: "limit capped", filter: domain.PostFilter{Limit: 1000}, wantLimit: MaxPageSize},
+		{name: "limit kept", filter: domain.PostFilter{Limit: 5}, wantLimit: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mockPostsRepository)
+			mockRepo.On("List", mock.Anything, mock.MatchedBy(func(f domain.PostFilter) bool {
+				return f.Limit == tt.wantLimit
+			})).Return(domain.PostPage{}, nil)
+
+			service := NewPostsService(mockRepo, &mockTagsRepository{}, &helper.MockFollowGraph{})
+			_, err := service.ListPosts(context.Background(), tt.filter)
+
+			assert.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostsService_UpdatePost(t *testing.T) {
+	existing := domain.Posts{
+		ID:          "post-123",
+		Username:    "testuser",
+		Title:       "Old Title",
+		Description: "Old Description",
+		CreatedAt:   time.Now().Add(-time.Hour),
+	}
+	newTitle := "New Title"
+	emptyTitle := ""
+	newDescription := "## New"
+	tags := []string{"Go", "testing"}
+	tooManyTags := []string{"a", "b", "c", "d", "e", "f"}
+
+	tests := []struct {
+		name     string
+		username string
+		update   domain.PostUpdate
+		mockFn   func(*mockPostsRepository)
+		wantErr  error
+	}{
+		{
+			name:     "successful update",
+			username: "testuser",
+			update:   domain.PostUpdate{Title: &newTitle},
+			mockFn: func(m *mockPostsRepository) {
+				m.On("GetByID", mock.Anything, "post-123").Return(existing, nil)
+				m.On("Update", mock.Anything, mock.MatchedBy(func(post domain.Posts) bool {
+					return post.Title == "New Title" &&
+						post.Description == "Old Description" &&
+						post.UpdatedAt.After(existing.CreatedAt)
+				})).Return(nil)
+			},
+		},
+		{
+			name:     "description is rendered",
+			username: "testuser",
+			update:   domain.PostUpdate{Title: &newTitle, Description: &newDescription},
+			mockFn: func(m *mockPostsRepository) {
+				m.On("GetByID", mock.Anything, "post-123").Return(existing, nil)
+				m.On("Update", mock.Anything, mock.MatchedBy(func(post domain.Posts) bool {
+					return post.DescriptionHTML == "<h2>New</h2>\n" && post.Excerpt == "New"
+				})).Return(nil)
+			},
+		},
+		{
+			name:     "tags are normalized",
+			username: "testuser",
+			update:   domain.PostUpdate{Title: &newTitle, Tags: &tags},
+			mockFn: func(m *mockPostsRepository) {
+				m.On("GetByID", mock.Anything, "post-123").Return(existing, nil)
+				m.On("Update", mock.Anything, mock.MatchedBy(func(post domain.Posts) bool {
+					return assert.ObjectsAreEqual([]string{"go", "testing"}, post.Tags)
+				})).Return(nil)
+			},
+		},
+		{
+			name:     "too many tags",
+			username: "testuser",
+			update:   domain.PostUpdate{Tags: &tooManyTags},
+			mockFn: func(m *mockPostsRepository) {
+				m.On("GetByID", mock.Anything, "post-123").Return(existing, nil)
+			},
+			wantErr: domain.ErrTooManyTags,
+		},
+		{
+			name:     "not the author",
+			username: "otheruser",
+			update:   domain.PostUpdate{Title: &newTitle},
+			mockFn: func(m *mockPostsRepository) {
+				m.On("GetByID", mock.Anything, "post-123").Return(existing, nil)
+			},
+			wantErr: ErrForbidden,
+		},
+		{
+			name:     "empty title",
+			username: "testuser",
+			update:   domain.PostUpdate{Title: &emptyTitle},
+			mockFn: func(m *mockPostsRepository) {
+				m.On("GetByID", mock.Anything, "post-123").Return(existing, nil)
+			},
+			wantErr: ErrInvalidPost,
+		},
+		{
+			name:     "post not found",
+			username: "testuser",
+			update:   domain.PostUpdate{Title: &newTitle},
+			mockFn: func(m *mockPostsRepository) {
+				m.On("GetByID", mock.Anything, "post-123").Return(domain.Posts{}, domain.ErrPostNotFound)
+			},
+			wantErr: domain.ErrPostNotFound,
+		},
+		{
+			name:     "empty username",
+			username: "",
+			update:   domain.PostUpdate{Title: &newTitle},
+			mockFn:   func(m *mockPostsRepository) {},
+			wantErr:  ErrInvalidUsername,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mockPostsRepository)
+			tt.mockFn(mockRepo)
+
+			service := NewPostsService(mockRepo, &mockTagsRepository{}, &helper.MockFollowGraph{})
+			post, err := service.UpdatePost(context.Background(), tt.username, "post-123", tt.update)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.wantID, gotID)
+				assert.Equal(t, "New Title", post.Title)
 			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostsService_DeletePost(t *testing.T) {
+	existing := domain.Posts{ID: "post-123", Username: "testuser"}
+
+	tests := []struct {
+		name     string
+		username string
+		mockFn   func(*mockPostsRepository)
+		wantErr  error
+	}{
+		{
+			name:     "successful delete",
+			username: "testuser",
+			mockFn: func(m *mockPostsRepository) {
+				m.On("GetByID", mock.Anything, "post-123").Return(existing, nil)
+				m.On("Delete", mock.Anything, "post-123").Return(nil)
+			},
+		},
+		{
+			name:     "not the author",
+			username: "otheruser",
+			mockFn: func(m *mockPostsRepository) {
+				m.On("GetByID", mock.Anything, "post-123").Return(existing, nil)
+			},
+			wantErr: ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mockPostsRepository)
+			tt.mockFn(mockRepo)
+
+			service := NewPostsService(mockRepo, &mockTagsRepository{}, &helper.MockFollowGraph{})
+			err := service.DeletePost(context.Background(), tt.username, "post-123")
 
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
 			mockRepo.AssertExpectations(t)
 		})
 	}
 }
+
+func TestPostsService_PostExists(t *testing.T) {
+	mockRepo := new(mockPostsRepository)
+	mockRepo.On("GetByID", mock.Anything, "post-123").Return(domain.Posts{ID: "post-123"}, nil)
+	mockRepo.On("GetByID", mock.Anything, "missing").Return(domain.Posts{}, domain.ErrPostNotFound)
+	mockRepo.On("GetByID", mock.Anything, "broken").Return(domain.Posts{}, errors.New("repository error"))
+
+	service := NewPostsService(mockRepo, &mockTagsRepository{}, &helper.MockFollowGraph{})
+
+	exists, err := service.PostExists(context.Background(), "post-123")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = service.PostExists(context.Background(), "missing")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = service.PostExists(context.Background(), "broken")
+	assert.Error(t, err)
+
+	exists, err = service.PostExists(context.Background(), "")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestPostsService_PostAuthor(t *testing.T) {
+	mockRepo := new(mockPostsRepository)
+	mockRepo.On("GetByID", mock.Anything, "post-123").Return(domain.Posts{ID: "post-123", Username: "alice"}, nil)
+	mockRepo.On("GetByID", mock.Anything, "missing").Return(domain.Posts{}, domain.ErrPostNotFound)
+	mockRepo.On("GetByID", mock.Anything, "broken").Return(domain.Posts{}, errors.New("repository error"))
+
+	service := NewPostsService(mockRepo, &mockTagsRepository{}, &helper.MockFollowGraph{})
+
+	author, err := service.PostAuthor(context.Background(), "post-123")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", author)
+
+	author, err = service.PostAuthor(context.Background(), "missing")
+	assert.NoError(t, err)
+	assert.Empty(t, author)
+
+	_, err = service.PostAuthor(context.Background(), "broken")
+	assert.Error(t, err)
+}
+
+func TestPostsService_Feed(t *testing.T) {
+	mockRepo := new(mockPostsRepository)
+	mockRepo.On("ListByAuthors", mock.Anything, []string{"alice", "bob"}, domain.FeedFilter{Username: "reader", Limit: DefaultPageSize}).
+		Return(domain.PostPage{Posts: []domain.Posts{{ID: "post-1", Username: "bob"}}}, nil)
+
+	follows := &helper.MockFollowGraph{
+		FolloweesFunc: func(ctx context.Context, username string) ([]string, error) {
+			if username == "reader" {
+				return []string{"alice", "bob"}, nil
+			}
+			return nil, nil
+		},
+	}
+	service := NewPostsService(mockRepo, &mockTagsRepository{}, follows)
+
+	page, err := service.Feed(context.Background(), domain.FeedFilter{Username: "reader"})
+	assert.NoError(t, err)
+	assert.Len(t, page.Posts, 1)
+
+	// Users who follow nobody get an empty feed without a query
+	page, err = service.Feed(context.Background(), domain.FeedFilter{Username: "loner", Limit: 500})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Posts)
+
+	_, err = service.Feed(context.Background(), domain.FeedFilter{})
+	assert.ErrorIs(t, err, ErrInvalidUsername)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPostsService_ListPosts_Tag(t *testing.T) {
+	mockRepo := new(mockPostsRepository)
+	mockRepo.On("List", mock.Anything, domain.PostFilter{Tag: "machine-learning", Category: "tutorials", Limit: DefaultPageSize}).
+		Return(domain.PostPage{}, nil)
+
+	service := NewPostsService(mockRepo, &mockTagsRepository{}, &helper.MockFollowGraph{})
+
+	_, err := service.ListPosts(context.Background(), domain.PostFilter{Tag: "Machine Learning", Category: "Tutorials"})
+	assert.NoError(t, err)
+
+	_, err = service.ListPosts(context.Background(), domain.PostFilter{Tag: "c++"})
+	assert.ErrorIs(t, err, domain.ErrInvalidTag)
+
+	_, err = service.ListPosts(context.Background(), domain.PostFilter{Category: "c++"})
+	assert.ErrorIs(t, err, domain.ErrInvalidCategory)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPostsService_ListTags(t *testing.T) {
+	var gotLimit int
+	tags := &mockTagsRepository{
+		listFunc: func(ctx context.Context, limit int) ([]domain.TagCount, error) {
+			gotLimit = limit
+			return []domain.TagCount{{Tag: "go", Count: 3}}, nil
+		},
+	}
+	service := NewPostsService(new(mockPostsRepository), tags, &helper.MockFollowGraph{})
+
+	got, err := service.ListTags(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultTagsPageSize, gotLimit)
+	assert.Equal(t, []domain.TagCount{{Tag: "go", Count: 3}}, got)
+
+	_, err = service.ListTags(context.Background(), 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, MaxPageSize, gotLimit)
+}
@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ynwd/awesome-blog/pkg/markdown"
+)
+
+var (
+	ErrEmptyQuery       = errors.New("search query has no searchable terms")
+	ErrQueryTooLong     = errors.New("search query is too long")
+	ErrInvalidKind      = errors.New("type must be post or comment")
+	ErrInvalidDate      = errors.New("invalid date: must be formatted as YYYY-MM-DD")
+	ErrInvalidDateRange = errors.New("from must not be after to")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrReindexRunning   = errors.New("a reindex is already running")
+)
+
+// Kinds of searchable documents
+const (
+	KindPost    = "post"
+	KindComment = "comment"
+)
+
+const (
+	// MaxQueryLength bounds the length in bytes of a search query
+	MaxQueryLength = 256
+
+	// DateLayout is the format of the from and to dates
+	DateLayout = "2006-01-02"
+)
+
+// Document is a post or comment as the index sees it. Comments have no title
+// or tags of their own.
+type Document struct {
+	Kind      string
+	ID        string
+	PostID    string
+	Author    string
+	Title     string
+	Body      string
+	Tags      []string
+	CreatedAt time.Time
+}
+
+// Key identifies the document in the index. Post and comment IDs come from
+// different collections, so the kind is part of the key.
+func (d Document) Key() string {
+	return DocumentKey(d.Kind, d.ID)
+}
+
+func DocumentKey(kind, id string) string {
+	return kind + ":" + id
+}
+
+// Query is a search request. Empty filters match every document; From and To
+// bound the creation time and are inclusive.
+type Query struct {
+	Text   string
+	Author string
+	Tag    string
+	Kind   string
+	From   time.Time
+	To     time.Time
+	Cursor string
+	Limit  int
+}
+
+// ParseDateRange parses the from and to dates of a search as whole days in
+// UTC, both inclusive. Empty dates leave that side of the range open.
+func ParseDateRange(from, to string) (time.Time, time.Time, error) {
+	var start, end time.Time
+	var err error
+	if from != "" {
+		if start, err = time.Parse(DateLayout, from); err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidDate
+		}
+	}
+	if to != "" {
+		if end, err = time.Parse(DateLayout, to); err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidDate
+		}
+		end = end.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return start, end, nil
+}
+
+// Matches reports whether the document passes the query filters. A tag filter
+// only matches posts.
+func (q Query) Matches(doc Document) bool {
+	if q.Kind != "" && doc.Kind != q.Kind {
+		return false
+	}
+	if q.Author != "" && doc.Author != q.Author {
+		return false
+	}
+	if !q.From.IsZero() && doc.CreatedAt.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && doc.CreatedAt.After(q.To) {
+		return false
+	}
+	if q.Tag != "" {
+		for _, tag := range doc.Tags {
+			if tag == q.Tag {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Hit is a document matching a query with its relevance score and a snippet
+// of its body. The snippet is HTML-escaped with matched words in <mark>.
+type Hit struct {
+	Document
+	Score   float64
+	Snippet string
+}
+
+type Result struct {
+	Hits       []Hit
+	Total      int
+	NextCursor string
+}
+
+// PostEvent is the payload of the POST_* events the index is kept current from
+type PostEvent struct {
+	ID          string    `json:"id"`
+	Username    string    `json:"username"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Tags        []string  `json:"tags"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Document indexes the prose of the markdown description, so snippets show no
+// markup
+func (p PostEvent) Document() Document {
+	return Document{
+		Kind:      KindPost,
+		ID:        p.ID,
+		PostID:    p.ID,
+		Author:    p.Username,
+		Title:     p.Title,
+		Body:      markdown.PlainText(p.Description),
+		Tags:      p.Tags,
+		CreatedAt: p.CreatedAt,
+	}
+}
+
+// CommentEvent is the payload of the COMMENT_* events the index is kept
+// current from
+type CommentEvent struct {
+	ID        string    `json:"id"`
+	PostID    string    `json:"post_id"`
+	Username  string    `json:"username"`
+	Comment   string    `json:"comment"`
+	Deleted   bool      `json:"deleted"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (c CommentEvent) Document() Document {
+	return Document{
+		Kind:      KindComment,
+		ID:        c.ID,
+		PostID:    c.PostID,
+		Author:    c.Username,
+		Body:      c.Comment,
+		CreatedAt: c.CreatedAt,
+	}
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDateRange(t *testing.T) {
+	from, to, err := ParseDateRange("2025-01-01", "2025-01-31")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2025, 1, 31, 23, 59, 59, 999999999, time.UTC), to)
+
+	from, to, err = ParseDateRange("", "")
+	assert.NoError(t, err)
+	assert.True(t, from.IsZero())
+	assert.True(t, to.IsZero())
+
+	for _, dates := range [][2]string{{"01/01/2025", ""}, {"", "2025-13-01"}} {
+		_, _, err := ParseDateRange(dates[0], dates[1])
+		assert.ErrorIs(t, err, ErrInvalidDate)
+	}
+}
+
+func TestQuery_Matches(t *testing.T) {
+	day := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	post := Document{Kind: KindPost, ID: "p1", PostID: "p1", Author: "alice", Tags: []string{"go", "testing"}, CreatedAt: day}
+	comment := Document{Kind: KindComment, ID: "c1", PostID: "p1", Author: "bob", CreatedAt: day}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  [2]bool
+	}{
+		{name: "no filters", query: Query{}, want: [2]bool{true, true}},
+		{name: "kind", query: Query{Kind: KindComment}, want: [2]bool{false, true}},
+		{name: "author", query: Query{Author: "alice"}, want: [2]bool{true, false}},
+		{name: "tag only matches posts", query: Query{Tag: "testing"}, want: [2]bool{true, false}},
+		{name: "other tag", query: Query{Tag: "rust"}, want: [2]bool{false, false}},
+		{name: "inclusive range", query: Query{From: day, To: day}, want: [2]bool{true, true}},
+		{name: "before range", query: Query{From: day.Add(time.Second)}, want: [2]bool{false, false}},
+		{name: "after range", query: Query{To: day.Add(-time.Second)}, want: [2]bool{false, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, [2]bool{tt.query.Matches(post), tt.query.Matches(comment)})
+		})
+	}
+}
+
+func TestPostEvent_Document(t *testing.T) {
+	doc := PostEvent{ID: "p1", Username: "alice", Title: "Search", Description: "Full **text** [search](https://example.com)"}.Document()
+	assert.Equal(t, "Full text search", doc.Body)
+	assert.Equal(t, "post:p1", doc.Key())
+}
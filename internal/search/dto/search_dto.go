@@ -0,0 +1,37 @@
+package dto
+
+import "time"
+
+type SearchQuery struct {
+	Q      string `form:"q"`
+	Author string `form:"author"`
+	Tag    string `form:"tag"`
+	Type   string `form:"type"`
+	From   string `form:"from"`
+	To     string `form:"to"`
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+}
+
+type SearchHitResponse struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	PostID    string    `json:"post_id"`
+	Title     string    `json:"title,omitempty"`
+	Author    string    `json:"author"`
+	Tags      []string  `json:"tags,omitempty"`
+	Snippet   string    `json:"snippet"`
+	Score     float64   `json:"score"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SearchResponse struct {
+	Query      string              `json:"query"`
+	Total      int                 `json:"total"`
+	Hits       []SearchHitResponse `json:"hits"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+type ReindexResponse struct {
+	Indexed int `json:"indexed"`
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/ynwd/awesome-blog/internal/search/domain"
+	"github.com/ynwd/awesome-blog/internal/search/service"
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+// SearchEventHandler keeps the index of this replica current. Its events
+// come from a subscription of the replica alone, so every replica applies
+// every event to its own index. Applying an event twice leaves the index
+// unchanged, so redelivered events are not skipped.
+type SearchEventHandler struct {
+	service service.SearchService
+}
+
+func NewSearchEventHandler(service service.SearchService) *SearchEventHandler {
+	return &SearchEventHandler{
+		service: service,
+	}
+}
+
+// HandlePost indexes created and updated posts and removes deleted posts
+// together with their comments
+func (h *SearchEventHandler) HandlePost(ctx context.Context, event module.Event[domain.PostEvent]) error {
+	switch event.Type {
+	case module.PostCreatedEvent, module.PostUpdatedEvent:
+		h.service.Index(event.Payload.Document())
+	case module.PostDeletedEvent:
+		h.service.RemovePost(event.Payload.ID)
+	}
+	return nil
+}
+
+// HandleComment indexes created and updated comments and removes deleted
+// ones, including comments kept as tombstones
+func (h *SearchEventHandler) HandleComment(ctx context.Context, event module.Event[domain.CommentEvent]) error {
+	comment := event.Payload
+	switch {
+	case event.Type == module.CommentDeletedEvent, comment.Deleted:
+		h.service.Remove(domain.KindComment, comment.ID)
+	case event.Type == module.CommentCreatedEvent, event.Type == module.CommentUpdatedEvent:
+		h.service.Index(comment.Document())
+	}
+	return nil
+}
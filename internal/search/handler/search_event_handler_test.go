@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ynwd/awesome-blog/internal/search/domain"
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+// recordingService records the changes made to the index
+type recordingService struct {
+	mockSearchService
+	indexed []domain.Document
+	removed []string
+}
+
+func newRecordingService() *recordingService {
+	s := &recordingService{}
+	s.indexFunc = func(doc domain.Document) { s.indexed = append(s.indexed, doc) }
+	s.removeFunc = func(kind, id string) { s.removed = append(s.removed, domain.DocumentKey(kind, id)) }
+	s.removePostFunc = func(postID string) { s.removed = append(s.removed, "post+comments:"+postID) }
+	return s
+}
+
+func TestSearchEventHandler_HandlePost(t *testing.T) {
+	post := domain.PostEvent{ID: "post1", Username: "alice", Title: "Search", Description: "Body", Tags: []string{"go"}, CreatedAt: createdAt}
+
+	for _, eventType := range []module.EventType{module.PostCreatedEvent, module.PostUpdatedEvent} {
+		service := newRecordingService()
+		err := NewSearchEventHandler(service).HandlePost(context.Background(), module.Event[domain.PostEvent]{Type: eventType, Payload: post})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Document{post.Document()}, service.indexed)
+	}
+
+	service := newRecordingService()
+	err := NewSearchEventHandler(service).HandlePost(context.Background(), module.Event[domain.PostEvent]{Type: module.PostDeletedEvent, Payload: post})
+
+	assert.NoError(t, err)
+	assert.Empty(t, service.indexed)
+	assert.Equal(t, []string{"post+comments:post1"}, service.removed)
+}
+
+func TestSearchEventHandler_HandleComment(t *testing.T) {
+	comment := domain.CommentEvent{ID: "c1", PostID: "post1", Username: "bob", Comment: "Nice", CreatedAt: createdAt}
+	tombstone := domain.CommentEvent{ID: "c1", PostID: "post1", Deleted: true, CreatedAt: createdAt}
+
+	tests := []struct {
+		name        string
+		eventType   module.EventType
+		payload     domain.CommentEvent
+		wantIndexed []domain.Document
+		wantRemoved []string
+	}{
+		{name: "created", eventType: module.CommentCreatedEvent, payload: comment, wantIndexed: []domain.Document{comment.Document()}},
+		{name: "updated", eventType: module.CommentUpdatedEvent, payload: comment, wantIndexed: []domain.Document{comment.Document()}},
+		{name: "tombstoned", eventType: module.CommentDeletedEvent, payload: tombstone, wantRemoved: []string{"comment:c1"}},
+		{name: "deleted", eventType: module.CommentDeletedEvent, payload: comment, wantRemoved: []string{"comment:c1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newRecordingService()
+			err := NewSearchEventHandler(service).HandleComment(context.Background(), module.Event[domain.CommentEvent]{Type: tt.eventType, Payload: tt.payload})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantIndexed, service.indexed)
+			assert.Equal(t, tt.wantRemoved, service.removed)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ynwd/awesome-blog/internal/search/domain"
+	"github.com/ynwd/awesome-blog/internal/search/dto"
+	"github.com/ynwd/awesome-blog/internal/search/service"
+	"github.com/ynwd/awesome-blog/pkg/res"
+)
+
+type SearchHandler struct {
+	service service.SearchService
+}
+
+func NewSearchHandler(service service.SearchService) *SearchHandler {
+	return &SearchHandler{
+		service: service,
+	}
+}
+
+func (h *SearchHandler) Search(c *gin.Context) {
+	var query dto.SearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error("Invalid query parameters"))
+		return
+	}
+
+	from, to, err := domain.ParseDateRange(query.From, query.To)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, res.Error(err.Error()))
+		return
+	}
+
+	result, err := h.service.Search(c.Request.Context(), domain.Query{
+		Text:   query.Q,
+		Author: query.Author,
+		Tag:    query.Tag,
+		Kind:   query.Type,
+		From:   from,
+		To:     to,
+		Cursor: query.Cursor,
+		Limit:  query.Limit,
+	})
+	if err != nil {
+		c.JSON(searchErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	response := dto.SearchResponse{
+		Query:      query.Q,
+		Total:      result.Total,
+		Hits:       make([]dto.SearchHitResponse, 0, len(result.Hits)),
+		NextCursor: result.NextCursor,
+	}
+	for _, hit := range result.Hits {
+		response.Hits = append(response.Hits, dto.SearchHitResponse{
+			Type:      hit.Kind,
+			ID:        hit.ID,
+			PostID:    hit.PostID,
+			Title:     hit.Title,
+			Author:    hit.Author,
+			Tags:      hit.Tags,
+			Snippet:   hit.Snippet,
+			Score:     hit.Score,
+			CreatedAt: hit.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, res.Success(response, "Search results retrieved successfully"))
+}
+
+// Reindex rebuilds the index of this replica from Firestore. Searches keep
+// being served from the current index until the rebuild completes.
+func (h *SearchHandler) Reindex(c *gin.Context) {
+	indexed, err := h.service.Reindex(c.Request.Context())
+	if err != nil {
+		c.JSON(searchErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(dto.ReindexResponse{Indexed: indexed}, "Search index rebuilt successfully"))
+}
+
+func searchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrEmptyQuery),
+		errors.Is(err, domain.ErrQueryTooLong),
+		errors.Is(err, domain.ErrInvalidKind),
+		errors.Is(err, domain.ErrInvalidDate),
+		errors.Is(err, domain.ErrInvalidDateRange),
+		errors.Is(err, domain.ErrInvalidCursor):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrReindexRunning):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/ynwd/awesome-blog/internal/search/domain"
+)
+
+type mockSearchService struct {
+	searchFunc     func(ctx context.Context, query domain.Query) (domain.Result, error)
+	indexFunc      func(doc domain.Document)
+	removeFunc     func(kind, id string)
+	removePostFunc func(postID string)
+	reindexFunc    func(ctx context.Context) (int, error)
+}
+
+func (m *mockSearchService) Search(ctx context.Context, query domain.Query) (domain.Result, error) {
+	return m.searchFunc(ctx, query)
+}
+
+func (m *mockSearchService) Index(doc domain.Document) {
+	m.indexFunc(doc)
+}
+
+func (m *mockSearchService) Remove(kind, id string) {
+	m.removeFunc(kind, id)
+}
+
+func (m *mockSearchService) RemovePost(postID string) {
+	m.removePostFunc(postID)
+}
+
+func (m *mockSearchService) Reindex(ctx context.Context) (int, error) {
+	return m.reindexFunc(ctx)
+}
+
+func (m *mockSearchService) LoadSnapshot() (int, error) {
+	return 0, nil
+}
+
+func (m *mockSearchService) SaveSnapshot() error {
+	return nil
+}
+
+var createdAt = time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+
+func setupRouter(service *mockSearchService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewSearchHandler(service)
+	router.GET("/search", h.Search)
+	router.POST("/admin/search/reindex", h.Reindex)
+	return router
+}
+
+func TestSearchHandler_Search(t *testing.T) {
+	service := &mockSearchService{
+		searchFunc: func(ctx context.Context, query domain.Query) (domain.Result, error) {
+			if query.Text == "the" {
+				return domain.Result{}, domain.ErrEmptyQuery
+			}
+			assert.Equal(t, domain.Query{
+				Text:   "go search",
+				Author: "alice",
+				Tag:    "go",
+				Kind:   "post",
+				From:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				To:     time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond),
+				Cursor: "abc",
+				Limit:  5,
+			}, query)
+			return domain.Result{
+				Total: 7,
+				Hits: []domain.Hit{{
+					Document: domain.Document{
+						Kind:      domain.KindPost,
+						ID:        "post1",
+						PostID:    "post1",
+						Author:    "alice",
+						Title:     "Search in Go",
+						Tags:      []string{"go"},
+						CreatedAt: createdAt,
+					},
+					Score:   1.5,
+					Snippet: "An inverted <mark>index</mark>",
+				}},
+				NextCursor: "next",
+			}, nil
+		},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "results",
+			query:      "?q=go+search&author=alice&tag=go&type=post&from=2025-01-01&to=2025-01-31&cursor=abc&limit=5",
+			wantStatus: http.StatusOK,
+			wantBody: `{
+				"status": "success",
+				"message": "Search results retrieved successfully",
+				"data": {
+					"query": "go search",
+					"total": 7,
+					"hits": [{
+						"type": "post",
+						"id": "post1",
+						"post_id": "post1",
+						"title": "Search in Go",
+						"author": "alice",
+						"tags": ["go"],
+						"snippet": "An inverted <mark>index</mark>",
+						"score": 1.5,
+						"created_at": "2025-02-01T10:00:00Z"
+					}],
+					"next_cursor": "next"
+				}
+			}`,
+		},
+		{
+			name:       "no searchable terms",
+			query:      "?q=the",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"status": "error", "message": "search query has no searchable terms"}`,
+		},
+		{
+			name:       "invalid date",
+			query:      "?q=go&from=yesterday",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"status": "error", "message": "invalid date: must be formatted as YYYY-MM-DD"}`,
+		},
+		{
+			name:       "invalid limit",
+			query:      "?q=go&limit=many",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"status": "error", "message": "Invalid query parameters"}`,
+		},
+	}
+
+	router := setupRouter(service)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/search"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestSearchHandler_Reindex(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "rebuilt",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status": "success", "message": "Search index rebuilt successfully", "data": {"indexed": 42}}`,
+		},
+		{
+			name:       "already running",
+			err:        domain.ErrReindexRunning,
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"status": "error", "message": "a reindex is already running"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupRouter(&mockSearchService{
+				reindexFunc: func(ctx context.Context) (int, error) {
+					if tt.err != nil {
+						return 0, tt.err
+					}
+					return 42, nil
+				},
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/admin/search/reindex", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
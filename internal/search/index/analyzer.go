@@ -0,0 +1,92 @@
+package index
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxTermLength bounds the length in runes of an indexed word. Longer words
+// are usually hashes or URLs nobody searches for.
+const MaxTermLength = 64
+
+// token is a word of a text with its byte offsets, lowercased
+type token struct {
+	word       string
+	start, end int
+}
+
+// tokenize splits text into words of letters and digits. An apostrophe
+// between two letters is part of the word, so "don't" stays one word.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if !inWord && start >= 0 && isApostrophe(r) {
+			next, _ := utf8.DecodeRuneInString(text[i+utf8.RuneLen(r):])
+			inWord = unicode.IsLetter(next)
+		}
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			tokens = append(tokens, token{word: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{word: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+func isApostrophe(r rune) bool {
+	return r == '\'' || r == '’'
+}
+
+// term normalizes a word to the form it is indexed under, or returns "" for
+// words that are not indexed: stopwords, single characters and overly long
+// words. Possessives are dropped before stemming.
+func term(word string) string {
+	if strings.ContainsAny(word, "'’") {
+		word = strings.TrimSuffix(strings.TrimSuffix(word, "'s"), "’s")
+		word = strings.NewReplacer("'", "", "’", "").Replace(word)
+	}
+	if n := utf8.RuneCountInString(word); n < 2 || n > MaxTermLength || stopwords[word] {
+		return ""
+	}
+	return Stem(word)
+}
+
+// Analyze returns the terms of text in order, the way both documents and
+// queries are indexed
+func Analyze(text string) []string {
+	var terms []string
+	for _, t := range tokenize(text) {
+		if term := term(t.word); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// stopwords are common English words that say nothing about what a text is
+// about
+var stopwords = toSet(`a about above after again against all am an and any are
+as at be because been before being below between both but by can could did do
+does doing down during each few for from further had has have having he her
+here hers herself him himself his how i if in into is it its itself just me
+more most my myself no nor not now of off on once only or other our ours
+ourselves out over own same she should so some such than that the their theirs
+them themselves then there these they this those through to too under until up
+very was we were what when where which while who whom why will with would you
+your yours yourself yourselves`)
+
+func toSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
@@ -0,0 +1,64 @@
+package index
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "", want: nil},
+		{text: "The Running of the Dogs", want: []string{"run", "dog"}},
+		{text: "Go's goroutines, explained!", want: []string{"go", "goroutin", "explain"}},
+		{text: "don't  panic\n(really)", want: []string{"dont", "panic", "realli"}},
+		{text: "it is what it is", want: nil},
+		{text: "a b c html5 café", want: []string{"html5", "café"}},
+		{text: "x " + strings.Repeat("a", MaxTermLength+1), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.want, Analyze(tt.text))
+		})
+	}
+}
+
+func TestTokenize_Offsets(t *testing.T) {
+	text := "Café: it’s 'quoted'"
+	tokens := tokenize(text)
+
+	words := make([]string, 0, len(tokens))
+	for _, tok := range tokens {
+		words = append(words, tok.word)
+		assert.Equal(t, tok.word, strings.ToLower(text[tok.start:tok.end]))
+	}
+	assert.Equal(t, []string{"café", "it’s", "quoted"}, words)
+}
+
+func TestSnippet(t *testing.T) {
+	text := "Intro words here. Then we talk about searching\n\nposts with an index & more. The end of the story is far away from here."
+
+	t.Run("highlights the best passage", func(t *testing.T) {
+		got := Snippet(text, Analyze("search index"), 8)
+		assert.Equal(t, "… we talk about <mark>searching</mark> posts with an <mark>index</mark> …", got)
+	})
+
+	t.Run("starts at the beginning without a match", func(t *testing.T) {
+		got := Snippet(text, Analyze("kubernetes"), 3)
+		assert.Equal(t, "Intro words here …", got)
+	})
+
+	t.Run("short text is returned whole", func(t *testing.T) {
+		got := Snippet("Search <b>&</b> me", Analyze("searches"), 30)
+		assert.Equal(t, "<mark>Search</mark> &lt;b&gt;&amp;&lt;/b&gt; me", got)
+	})
+
+	t.Run("empty text", func(t *testing.T) {
+		assert.Equal(t, "", Snippet("  ", Analyze("search"), 30))
+	})
+}
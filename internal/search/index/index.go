@@ -0,0 +1,261 @@
+// Package index is an in-memory inverted index of posts and comments ranked
+// with BM25.
+package index
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/ynwd/awesome-blog/internal/search/domain"
+)
+
+// BM25 parameters: k1 limits how much repeating a term keeps adding to the
+// score and b how much long documents are penalized
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Field weights. A term in the title or tags counts as that many occurrences
+// in the body.
+const (
+	titleWeight = 3
+	tagWeight   = 2
+)
+
+// Index is safe for concurrent use. Documents are replaced as a whole; there
+// is no partial update.
+type Index struct {
+	rebuilding sync.Mutex
+
+	mu      sync.RWMutex
+	data    *store
+	journal []func(*store) // changes made while a rebuild runs, nil otherwise
+}
+
+func New() *Index {
+	return &Index{data: newStore()}
+}
+
+// Put adds the document or replaces the document with the same key
+func (idx *Index) Put(doc domain.Document) {
+	e := newEntry(doc)
+	idx.apply(func(s *store) { s.put(e) })
+}
+
+// Remove removes the document, if indexed
+func (idx *Index) Remove(kind, id string) {
+	key := domain.DocumentKey(kind, id)
+	idx.apply(func(s *store) { s.remove(key) })
+}
+
+// RemovePost removes the post and every comment on it
+func (idx *Index) RemovePost(postID string) {
+	idx.apply(func(s *store) {
+		for key := range s.byPost[postID] {
+			s.remove(key)
+		}
+	})
+}
+
+func (idx *Index) apply(change func(*store)) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	change(idx.data)
+	if idx.journal != nil {
+		idx.journal = append(idx.journal, change)
+	}
+}
+
+// Len returns the number of indexed documents
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.data.docs)
+}
+
+// Rebuild replaces the contents of the index with the documents load passes
+// to put. The index keeps serving searches from its current contents while
+// load runs, and changes made meanwhile are replayed on the new contents
+// before they replace the old ones, so they win over what load read. It
+// returns the number of documents loaded, or ErrReindexRunning when another
+// rebuild has not finished.
+func (idx *Index) Rebuild(load func(put func(domain.Document)) error) (int, error) {
+	if !idx.rebuilding.TryLock() {
+		return 0, domain.ErrReindexRunning
+	}
+	defer idx.rebuilding.Unlock()
+
+	idx.mu.Lock()
+	idx.journal = []func(*store){}
+	idx.mu.Unlock()
+
+	next := newStore()
+	loaded := 0
+	err := load(func(doc domain.Document) {
+		next.put(newEntry(doc))
+		loaded++
+	})
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if err == nil {
+		for _, change := range idx.journal {
+			change(next)
+		}
+		idx.data = next
+	}
+	idx.journal = nil
+	return loaded, err
+}
+
+// Documents returns every indexed document
+func (idx *Index) Documents() []domain.Document {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	docs := make([]domain.Document, 0, len(idx.data.docs))
+	for _, e := range idx.data.docs {
+		docs = append(docs, e.doc)
+	}
+	return docs
+}
+
+// Search returns the documents that contain any of the terms and pass the
+// filter, best match first. Equal scores are ordered newest first.
+func (idx *Index) Search(terms []string, filter func(domain.Document) bool) []domain.Hit {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	s := idx.data
+	if len(s.docs) == 0 {
+		return nil
+	}
+	n := float64(len(s.docs))
+	avgLength := float64(s.length) / n
+
+	scores := make(map[string]float64)
+	excluded := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, term := range terms {
+		postings := s.postings[term]
+		if len(postings) == 0 || seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for key, freq := range postings {
+			if excluded[key] {
+				continue
+			}
+			e := s.docs[key]
+			if _, scored := scores[key]; !scored && !filter(e.doc) {
+				excluded[key] = true
+				continue
+			}
+			tf := float64(freq)
+			scores[key] += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*float64(e.length)/avgLength))
+		}
+	}
+
+	hits := make([]domain.Hit, 0, len(scores))
+	for key, score := range scores {
+		hits = append(hits, domain.Hit{Document: s.docs[key].doc, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if !hits[i].CreatedAt.Equal(hits[j].CreatedAt) {
+			return hits[i].CreatedAt.After(hits[j].CreatedAt)
+		}
+		return hits[i].Key() < hits[j].Key()
+	})
+	return hits
+}
+
+// entry is an analyzed document
+type entry struct {
+	doc    domain.Document
+	terms  map[string]int // weighted frequency of each term
+	length int            // sum of the weighted frequencies
+}
+
+func newEntry(doc domain.Document) *entry {
+	e := &entry{doc: doc, terms: make(map[string]int)}
+	add := func(text string, weight int) {
+		for _, term := range Analyze(text) {
+			e.terms[term] += weight
+			e.length += weight
+		}
+	}
+	add(doc.Title, titleWeight)
+	for _, tag := range doc.Tags {
+		add(tag, tagWeight)
+	}
+	add(doc.Body, 1)
+	return e
+}
+
+// store holds the documents and postings of an index
+type store struct {
+	docs     map[string]*entry
+	postings map[string]map[string]int      // term -> document key -> frequency
+	byPost   map[string]map[string]struct{} // post ID -> keys of the post and its comments
+	length   int
+}
+
+func newStore() *store {
+	return &store{
+		docs:     make(map[string]*entry),
+		postings: make(map[string]map[string]int),
+		byPost:   make(map[string]map[string]struct{}),
+	}
+}
+
+func (s *store) put(e *entry) {
+	key := e.doc.Key()
+	s.remove(key)
+
+	s.docs[key] = e
+	s.length += e.length
+	for term, freq := range e.terms {
+		postings, ok := s.postings[term]
+		if !ok {
+			postings = make(map[string]int)
+			s.postings[term] = postings
+		}
+		postings[key] = freq
+	}
+	keys, ok := s.byPost[e.doc.PostID]
+	if !ok {
+		keys = make(map[string]struct{})
+		s.byPost[e.doc.PostID] = keys
+	}
+	keys[key] = struct{}{}
+}
+
+func (s *store) remove(key string) {
+	e, ok := s.docs[key]
+	if !ok {
+		return
+	}
+
+	delete(s.docs, key)
+	s.length -= e.length
+	for term := range e.terms {
+		delete(s.postings[term], key)
+		if len(s.postings[term]) == 0 {
+			delete(s.postings, term)
+		}
+	}
+	delete(s.byPost[e.doc.PostID], key)
+	if len(s.byPost[e.doc.PostID]) == 0 {
+		delete(s.byPost, e.doc.PostID)
+	}
+}
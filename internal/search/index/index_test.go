@@ -0,0 +1,185 @@
+package index
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ynwd/awesome-blog/internal/search/domain"
+)
+
+var now = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func post(id, author, title, body string, tags ...string) domain.Document {
+	return domain.Document{Kind: domain.KindPost, ID: id, PostID: id, Author: author, Title: title, Body: body, Tags: tags, CreatedAt: now}
+}
+
+func comment(id, postID, author, body string) domain.Document {
+	return domain.Document{Kind: domain.KindComment, ID: id, PostID: postID, Author: author, Body: body, CreatedAt: now}
+}
+
+func all(domain.Document) bool { return true }
+
+func keys(hits []domain.Hit) []string {
+	keys := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		keys = append(keys, hit.Key())
+	}
+	return keys
+}
+
+func newTestIndex() *Index {
+	idx := New()
+	idx.Put(post("p1", "alice", "Concurrency in Go", "Goroutines and channels make concurrent programs simple.", "go"))
+	idx.Put(post("p2", "bob", "Flour for bread", "A long post about flour, water, salt and patience. Flour matters.", "cooking"))
+	idx.Put(post("p3", "alice", "Testing tips", "Table driven tests keep Go code honest.", "go", "testing"))
+	idx.Put(comment("c1", "p2", "carol", "Which flour do you use for baking?"))
+	idx.Put(comment("c2", "p1", "bob", "Channels are great"))
+	return idx
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := newTestIndex()
+
+	t.Run("ranks by relevance", func(t *testing.T) {
+		hits := idx.Search(Analyze("flour"), all)
+		assert.Equal(t, []string{"post:p2", "comment:c1"}, keys(hits))
+		assert.Greater(t, hits[0].Score, hits[1].Score)
+	})
+
+	t.Run("matches stemmed words", func(t *testing.T) {
+		assert.Equal(t, []string{"comment:c2", "post:p1"}, keys(idx.Search(Analyze("channel"), all)))
+	})
+
+	t.Run("title matches outrank body matches", func(t *testing.T) {
+		idx.Put(post("p4", "dave", "Notes", "Some notes on concurrency."))
+		defer idx.Remove(domain.KindPost, "p4")
+
+		assert.Equal(t, []string{"post:p1", "post:p4"}, keys(idx.Search(Analyze("concurrency"), all)))
+	})
+
+	t.Run("matches any term", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"post:p1", "post:p2", "post:p3", "comment:c1"}, keys(idx.Search(Analyze("bread goroutines tests flour"), all)))
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		query := domain.Query{Author: "alice", Tag: "go"}
+		assert.Equal(t, []string{"post:p3"}, keys(idx.Search(Analyze("tests"), query.Matches)))
+		assert.Empty(t, idx.Search(Analyze("flour"), domain.Query{Kind: domain.KindComment, Author: "bob"}.Matches))
+	})
+
+	t.Run("unknown terms", func(t *testing.T) {
+		assert.Empty(t, idx.Search(Analyze("kubernetes"), all))
+		assert.Empty(t, New().Search(Analyze("flour"), all))
+	})
+}
+
+func TestIndex_PutReplaces(t *testing.T) {
+	idx := newTestIndex()
+
+	idx.Put(post("p2", "bob", "Sourdough", "Starter and levain."))
+
+	assert.Equal(t, 5, idx.Len())
+	assert.Equal(t, []string{"comment:c1"}, keys(idx.Search(Analyze("flour"), all)))
+	assert.Equal(t, []string{"post:p2"}, keys(idx.Search(Analyze("sourdough"), all)))
+}
+
+func TestIndex_Remove(t *testing.T) {
+	idx := newTestIndex()
+
+	idx.Remove(domain.KindComment, "c1")
+	idx.Remove(domain.KindComment, "missing")
+	assert.Equal(t, []string{"post:p2"}, keys(idx.Search(Analyze("flour"), all)))
+
+	idx.RemovePost("p1")
+	assert.Equal(t, 2, idx.Len())
+	assert.Empty(t, idx.Search(Analyze("channels"), all))
+}
+
+func TestIndex_Rebuild(t *testing.T) {
+	idx := newTestIndex()
+	loading := make(chan struct{})
+	proceed := make(chan struct{})
+
+	done := make(chan error)
+	go func() {
+		_, err := idx.Rebuild(func(put func(domain.Document)) error {
+			put(post("p1", "alice", "Concurrency in Go", "Stale copy"))
+			put(post("p5", "erin", "Gardening", "Tomatoes"))
+			close(loading)
+			<-proceed
+			return nil
+		})
+		done <- err
+	}()
+
+	<-loading
+	// Searches keep using the old contents while the rebuild runs
+	assert.Equal(t, 5, idx.Len())
+	_, err := idx.Rebuild(func(func(domain.Document)) error { return nil })
+	assert.ErrorIs(t, err, domain.ErrReindexRunning)
+
+	// Changes made meanwhile win over what the rebuild loaded
+	idx.Put(post("p1", "alice", "Concurrency in Go", "Fresh copy"))
+	idx.Remove(domain.KindPost, "p5")
+	close(proceed)
+	require.NoError(t, <-done)
+
+	assert.Equal(t, 1, idx.Len())
+	assert.Equal(t, []string{"post:p1"}, keys(idx.Search(Analyze("fresh"), all)))
+	assert.Empty(t, idx.Search(Analyze("stale tomatoes flour"), all))
+}
+
+func TestIndex_RebuildError(t *testing.T) {
+	idx := newTestIndex()
+	failure := errors.New("scan failed")
+
+	_, err := idx.Rebuild(func(put func(domain.Document)) error {
+		put(post("p9", "zed", "Partial", "Partial"))
+		return failure
+	})
+
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, 5, idx.Len())
+}
+
+func TestIndex_Snapshot(t *testing.T) {
+	idx := newTestIndex()
+
+	var buf bytes.Buffer
+	require.NoError(t, idx.Save(&buf))
+
+	restored := New()
+	n, err := restored.Load(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, keys(idx.Search(Analyze("flour channels"), all)), keys(restored.Search(Analyze("flour channels"), all)))
+	assert.ElementsMatch(t, idx.Documents(), restored.Documents())
+}
+
+func TestIndex_SnapshotFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.snapshot")
+
+	_, err := New().LoadFile(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, newTestIndex().SaveFile(path))
+	restored := New()
+	n, err := restored.LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	require.NoError(t, os.WriteFile(path, []byte("garbage"), 0o600))
+	_, err = restored.LoadFile(path)
+	assert.Error(t, err)
+	assert.Equal(t, 5, restored.Len())
+}
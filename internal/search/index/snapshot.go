@@ -0,0 +1,91 @@
+package index
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/search/domain"
+)
+
+// snapshotVersion changes whenever the snapshot layout does
+const snapshotVersion = 1
+
+var ErrSnapshotVersion = errors.New("unsupported search snapshot version")
+
+// snapshot stores the documents rather than the postings, so a snapshot stays
+// valid when the analyzer changes and is re-analyzed on load
+type snapshot struct {
+	Version   int
+	SavedAt   time.Time
+	Documents []domain.Document
+}
+
+// Save writes the indexed documents to w
+func (idx *Index) Save(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(snapshot{
+		Version:   snapshotVersion,
+		SavedAt:   time.Now(),
+		Documents: idx.Documents(),
+	})
+}
+
+// Load replaces the contents of the index with the documents of a snapshot
+// written by Save
+func (idx *Index) Load(r io.Reader) (int, error) {
+	var snap snapshot
+	if err := gob.NewDecoder(r).Decode(&snap); err != nil {
+		return 0, fmt.Errorf("decoding search snapshot: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return 0, fmt.Errorf("%w: %d", ErrSnapshotVersion, snap.Version)
+	}
+
+	return idx.Rebuild(func(put func(domain.Document)) error {
+		for _, doc := range snap.Documents {
+			put(doc)
+		}
+		return nil
+	})
+}
+
+// SaveFile writes a snapshot to path. It writes a temporary file next to it
+// first, so an interrupted save leaves the previous snapshot intact.
+func (idx *Index) SaveFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	if err := idx.Save(w); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadFile loads the snapshot at path. The error wraps os.ErrNotExist when
+// there is no snapshot yet.
+func (idx *Index) LoadFile(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return idx.Load(bufio.NewReader(f))
+}
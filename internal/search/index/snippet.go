@@ -0,0 +1,84 @@
+package index
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+// Snippet returns the passage of at most size words of text with the most
+// distinct terms, HTML-escaped and with the matching words wrapped in <mark>.
+// Text without a match yields its first words. An ellipsis marks text left
+// out before or after the passage.
+func Snippet(text string, terms []string, size int) string {
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return ""
+	}
+
+	wanted := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		wanted[term] = true
+	}
+	matches := make([]string, len(tokens))
+	for i, t := range tokens {
+		if term := term(t.word); wanted[term] {
+			matches[i] = term
+		}
+	}
+
+	first, last := bestWindow(matches, size)
+
+	var sb strings.Builder
+	if first > 0 {
+		sb.WriteString("… ")
+	}
+	for i := first; i <= last; i++ {
+		if i > first {
+			sb.WriteString(html.EscapeString(whitespace.ReplaceAllString(text[tokens[i-1].end:tokens[i].start], " ")))
+		}
+		word := html.EscapeString(text[tokens[i].start:tokens[i].end])
+		if matches[i] != "" {
+			word = "<mark>" + word + "</mark>"
+		}
+		sb.WriteString(word)
+	}
+	if last < len(tokens)-1 {
+		sb.WriteString(" …")
+	}
+	return sb.String()
+}
+
+// bestWindow returns the first and last index of the run of at most size
+// tokens with the most distinct matches, then the most matches. The earliest
+// such run wins.
+func bestWindow(matches []string, size int) (int, int) {
+	if size <= 0 || size > len(matches) {
+		size = len(matches)
+	}
+
+	counts := make(map[string]int)
+	best, bestDistinct, bestTotal := 0, -1, -1
+	total := 0
+	for i, match := range matches {
+		if match != "" {
+			counts[match]++
+			total++
+		}
+		if i >= size {
+			if out := matches[i-size]; out != "" {
+				counts[out]--
+				if counts[out] == 0 {
+					delete(counts, out)
+				}
+				total--
+			}
+		}
+		if i >= size-1 && (len(counts) > bestDistinct || len(counts) == bestDistinct && total > bestTotal) {
+			best, bestDistinct, bestTotal = i-size+1, len(counts), total
+		}
+	}
+	return best, best + size - 1
+}
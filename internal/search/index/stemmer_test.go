@@ -0,0 +1,86 @@
+package index
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStem(t *testing.T) {
+	// Examples from Porter's paper and its reference vocabulary
+	tests := map[string]string{
+		"caresses":        "caress",
+		"ponies":          "poni",
+		"ties":            "ti",
+		"caress":          "caress",
+		"cats":            "cat",
+		"feed":            "feed",
+		"agreed":          "agre",
+		"plastered":       "plaster",
+		"bled":            "bled",
+		"motoring":        "motor",
+		"sing":            "sing",
+		"conflated":       "conflat",
+		"troubled":        "troubl",
+		"sized":           "size",
+		"hopping":         "hop",
+		"tanned":          "tan",
+		"falling":         "fall",
+		"hissing":         "hiss",
+		"fizzed":          "fizz",
+		"failing":         "fail",
+		"filing":          "file",
+		"happy":           "happi",
+		"sky":             "sky",
+		"relational":      "relat",
+		"conditional":     "condit",
+		"rational":        "ration",
+		"digitizer":       "digit",
+		"vietnamization":  "vietnam",
+		"predication":     "predic",
+		"operator":        "oper",
+		"feudalism":       "feudal",
+		"decisiveness":    "decis",
+		"hopefulness":     "hope",
+		"callousness":     "callous",
+		"formaliti":       "formal",
+		"triplicate":      "triplic",
+		"formative":       "form",
+		"electrical":      "electr",
+		"goodness":        "good",
+		"revival":         "reviv",
+		"allowance":       "allow",
+		"inference":       "infer",
+		"airliner":        "airlin",
+		"adjustable":      "adjust",
+		"defensible":      "defens",
+		"irritant":        "irrit",
+		"replacement":     "replac",
+		"adoption":        "adopt",
+		"homologous":      "homolog",
+		"communism":       "commun",
+		"effective":       "effect",
+		"bowdlerize":      "bowdler",
+		"probate":         "probat",
+		"rate":            "rate",
+		"cease":           "ceas",
+		"controlling":     "control",
+		"generalizations": "gener",
+		"oscillators":     "oscil",
+		"connection":      "connect",
+		"connected":       "connect",
+		"connecting":      "connect",
+		"running":         "run",
+		"searching":       "search",
+		// Left alone
+		"go":    "go",
+		"html5": "html5",
+		"café":  "café",
+	}
+
+	for word, want := range tests {
+		t.Run(word, func(t *testing.T) {
+			assert.Equal(t, want, Stem(word))
+		})
+	}
+}
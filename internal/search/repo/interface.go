@@ -0,0 +1,13 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/ynwd/awesome-blog/internal/search/domain"
+)
+
+// SourceRepository reads the searchable documents from where they are stored
+type SourceRepository interface {
+	// Scan calls fn for every post and every live comment on an existing post
+	Scan(ctx context.Context, fn func(domain.Document)) error
+}
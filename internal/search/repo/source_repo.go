@@ -0,0 +1,104 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/ynwd/awesome-blog/internal/search/domain"
+	"google.golang.org/api/iterator"
+)
+
+// Collections scanned by a reindex. They belong to the posts and comments
+// modules.
+const (
+	PostsCollection    = "posts"
+	CommentsCollection = "comments"
+)
+
+type postRecord struct {
+	Username    string    `firestore:"username"`
+	Title       string    `firestore:"title"`
+	Description string    `firestore:"description"`
+	Tags        []string  `firestore:"tags"`
+	CreatedAt   time.Time `firestore:"created_at"`
+}
+
+type commentRecord struct {
+	Username  string    `firestore:"username"`
+	PostID    string    `firestore:"post_id"`
+	Comment   string    `firestore:"comment"`
+	Deleted   bool      `firestore:"deleted"`
+	CreatedAt time.Time `firestore:"created_at"`
+}
+
+type sourceFirestore struct {
+	client *firestore.Client
+}
+
+func NewSourceRepository(client *firestore.Client) SourceRepository {
+	return &sourceFirestore{
+		client: client,
+	}
+}
+
+// Scan reads the posts first, so comments left behind by deleted posts can be
+// skipped
+func (r *sourceFirestore) Scan(ctx context.Context, fn func(domain.Document)) error {
+	posts := make(map[string]bool)
+	err := r.scan(ctx, PostsCollection, func(doc *firestore.DocumentSnapshot) error {
+		var record postRecord
+		if err := doc.DataTo(&record); err != nil {
+			return err
+		}
+		posts[doc.Ref.ID] = true
+		fn(domain.PostEvent{
+			ID:          doc.Ref.ID,
+			Username:    record.Username,
+			Title:       record.Title,
+			Description: record.Description,
+			Tags:        record.Tags,
+			CreatedAt:   record.CreatedAt,
+		}.Document())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.scan(ctx, CommentsCollection, func(doc *firestore.DocumentSnapshot) error {
+		var record commentRecord
+		if err := doc.DataTo(&record); err != nil {
+			return err
+		}
+		if record.Deleted || record.Username == "" || !posts[record.PostID] {
+			return nil
+		}
+		fn(domain.CommentEvent{
+			ID:        doc.Ref.ID,
+			PostID:    record.PostID,
+			Username:  record.Username,
+			Comment:   record.Comment,
+			CreatedAt: record.CreatedAt,
+		}.Document())
+		return nil
+	})
+}
+
+func (r *sourceFirestore) scan(ctx context.Context, collection string, fn func(doc *firestore.DocumentSnapshot) error) error {
+	iter := r.client.Collection(collection).Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+}
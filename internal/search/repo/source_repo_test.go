@@ -0,0 +1,59 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ynwd/awesome-blog/internal/search/domain"
+	"github.com/ynwd/awesome-blog/tests/helper"
+)
+
+func TestSourceRepository_Scan(t *testing.T) {
+	ctx := context.Background()
+	client := helper.SetupRepoClient(t)
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	err := helper.CleanDatabase()
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	_, err = client.Collection(PostsCollection).Doc("post-1").Set(ctx, map[string]interface{}{
+		"username":    "alice",
+		"title":       "Searching posts",
+		"description": "An inverted index",
+		"tags":        []string{"go"},
+		"created_at":  now,
+	})
+	require.NoError(t, err)
+
+	comments := map[string]map[string]interface{}{
+		"live":     {"username": "bob", "post_id": "post-1", "comment": "Nice", "created_at": now},
+		"deleted":  {"username": "", "post_id": "post-1", "comment": "", "deleted": true, "created_at": now},
+		"orphaned": {"username": "bob", "post_id": "gone", "comment": "Lost", "created_at": now},
+	}
+	for id, data := range comments {
+		_, err := client.Collection(CommentsCollection).Doc(id).Set(ctx, data)
+		require.NoError(t, err)
+	}
+
+	var docs []domain.Document
+	err = NewSourceRepository(client).Scan(ctx, func(doc domain.Document) {
+		docs = append(docs, doc)
+	})
+	require.NoError(t, err)
+
+	require.Len(t, docs, 2)
+	assert.Equal(t, domain.Document{
+		Kind: domain.KindPost, ID: "post-1", PostID: "post-1", Author: "alice",
+		Title: "Searching posts", Body: "An inverted index", Tags: []string{"go"}, CreatedAt: now,
+	}, docs[0])
+	assert.Equal(t, domain.Document{
+		Kind: domain.KindComment, ID: "live", PostID: "post-1", Author: "bob", Body: "Nice", CreatedAt: now,
+	}, docs[1])
+}
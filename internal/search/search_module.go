@@ -0,0 +1,51 @@
+package search
+
+import (
+	"cloud.google.com/go/firestore"
+	"github.com/ynwd/awesome-blog/internal/search/handler"
+	"github.com/ynwd/awesome-blog/internal/search/index"
+	"github.com/ynwd/awesome-blog/internal/search/repo"
+	"github.com/ynwd/awesome-blog/internal/search/service"
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+type Module struct {
+	handler      *handler.SearchHandler
+	eventHandler *handler.SearchEventHandler
+	service      service.SearchService
+}
+
+func NewModule(client *firestore.Client, config service.SearchConfig) *Module {
+	// Initialize the index of this replica and where it is rebuilt from
+	sourceRepo := repo.NewSourceRepository(client)
+	searchService := service.NewSearchService(index.New(), sourceRepo, config)
+
+	// Initialize handlers
+	searchHandler := handler.NewSearchHandler(searchService)
+	eventHandler := handler.NewSearchEventHandler(searchService)
+
+	return &Module{
+		handler:      searchHandler,
+		eventHandler: eventHandler,
+		service:      searchService,
+	}
+}
+
+// Service lets the app restore, rebuild and save the index
+func (m *Module) Service() service.SearchService {
+	return m.service
+}
+
+// RegisterEvents registers nothing: the index of each replica is kept
+// current from its own subscription by RegisterReplicaEvents
+func (m *Module) RegisterEvents(registry *module.EventRegistry) {}
+
+// RegisterReplicaEvents keeps the index current with the posts and comments
+func (m *Module) RegisterReplicaEvents(registry *module.EventRegistry) {
+	module.Handle(registry, module.PostCreatedEvent, module.PostCreatedEventVersion, m.eventHandler.HandlePost)
+	module.Handle(registry, module.PostUpdatedEvent, module.PostUpdatedEventVersion, m.eventHandler.HandlePost)
+	module.Handle(registry, module.PostDeletedEvent, module.PostDeletedEventVersion, m.eventHandler.HandlePost)
+	module.Handle(registry, module.CommentCreatedEvent, module.CommentCreatedEventVersion, m.eventHandler.HandleComment)
+	module.Handle(registry, module.CommentUpdatedEvent, module.CommentUpdatedEventVersion, m.eventHandler.HandleComment)
+	module.Handle(registry, module.CommentDeletedEvent, module.CommentDeletedEventVersion, m.eventHandler.HandleComment)
+}
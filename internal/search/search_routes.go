@@ -0,0 +1,13 @@
+package search
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ynwd/awesome-blog/pkg/middleware"
+)
+
+func (m *Module) RegisterRoutes(router *gin.Engine) {
+	router.GET("/search", m.handler.Search)
+
+	admin := router.Group("/admin", middleware.RequireAdmin())
+	admin.POST("/search/reindex", m.handler.Reindex)
+}
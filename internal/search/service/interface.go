@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ynwd/awesome-blog/internal/search/domain"
+)
+
+type SearchService interface {
+	Search(ctx context.Context, query domain.Query) (domain.Result, error)
+	Index(doc domain.Document)
+	Remove(kind, id string)
+	RemovePost(postID string)
+	// Reindex rebuilds the index from the stored posts and comments and
+	// returns the number of documents indexed
+	Reindex(ctx context.Context) (int, error)
+	// LoadSnapshot and SaveSnapshot read and write the configured snapshot
+	// file. Both do nothing when no snapshot path is configured.
+	LoadSnapshot() (int, error)
+	SaveSnapshot() error
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/ynwd/awesome-blog/internal/search/domain"
+	"github.com/ynwd/awesome-blog/internal/search/index"
+	"github.com/ynwd/awesome-blog/internal/search/repo"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// SearchConfig controls the snapshots and snippets of the search index
+type SearchConfig struct {
+	// SnapshotPath is the file the index is saved to and restored from. The
+	// index only lives in memory when it is empty.
+	SnapshotPath string
+
+	// SnippetWords is the length in words of the snippet of each hit
+	SnippetWords int
+}
+
+func DefaultSearchConfig() SearchConfig {
+	return SearchConfig{
+		SnippetWords: 30,
+	}
+}
+
+type searchService struct {
+	index  *index.Index
+	source repo.SourceRepository
+	config SearchConfig
+}
+
+func NewSearchService(idx *index.Index, source repo.SourceRepository, config SearchConfig) SearchService {
+	return &searchService{
+		index:  idx,
+		source: source,
+		config: config,
+	}
+}
+
+// Search ranks the matching documents and returns one page of them. The
+// cursor is an offset into the ranking, so pages can shift when the index
+// changes between requests.
+func (s *searchService) Search(ctx context.Context, query domain.Query) (domain.Result, error) {
+	if len(query.Text) > domain.MaxQueryLength {
+		return domain.Result{}, domain.ErrQueryTooLong
+	}
+	terms := index.Analyze(query.Text)
+	if len(terms) == 0 {
+		return domain.Result{}, domain.ErrEmptyQuery
+	}
+	switch query.Kind {
+	case "", domain.KindPost, domain.KindComment:
+	default:
+		return domain.Result{}, domain.ErrInvalidKind
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.From.After(query.To) {
+		return domain.Result{}, domain.ErrInvalidDateRange
+	}
+	query.Author = strings.TrimSpace(query.Author)
+	query.Tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(query.Tag), "#"))
+
+	offset, err := decodeOffset(query.Cursor)
+	if err != nil {
+		return domain.Result{}, err
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultPageSize
+	}
+	if query.Limit > MaxPageSize {
+		query.Limit = MaxPageSize
+	}
+
+	hits := s.index.Search(terms, query.Matches)
+	result := domain.Result{Total: len(hits), Hits: []domain.Hit{}}
+	if offset >= len(hits) {
+		return result, nil
+	}
+
+	end := offset + query.Limit
+	if end < len(hits) {
+		result.NextCursor = encodeOffset(end)
+	} else {
+		end = len(hits)
+	}
+	for _, hit := range hits[offset:end] {
+		hit.Snippet = index.Snippet(hit.Body, terms, s.config.SnippetWords)
+		result.Hits = append(result.Hits, hit)
+	}
+	return result, nil
+}
+
+func (s *searchService) Index(doc domain.Document) {
+	s.index.Put(doc)
+}
+
+func (s *searchService) Remove(kind, id string) {
+	s.index.Remove(kind, id)
+}
+
+func (s *searchService) RemovePost(postID string) {
+	s.index.RemovePost(postID)
+}
+
+func (s *searchService) Reindex(ctx context.Context) (int, error) {
+	return s.index.Rebuild(func(put func(domain.Document)) error {
+		return s.source.Scan(ctx, put)
+	})
+}
+
+func (s *searchService) LoadSnapshot() (int, error) {
+	if s.config.SnapshotPath == "" {
+		return 0, nil
+	}
+	return s.index.LoadFile(s.config.SnapshotPath)
+}
+
+func (s *searchService) SaveSnapshot() error {
+	if s.config.SnapshotPath == "" {
+		return nil
+	}
+	return s.index.SaveFile(s.config.SnapshotPath)
+}
+
+func encodeOffset(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeOffset(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, domain.ErrInvalidCursor
+	}
+	offset, err := strconv.Atoi(string(data))
+	if err != nil || offset < 0 {
+		return 0, domain.ErrInvalidCursor
+	}
+	return offset, nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ynwd/awesome-blog/internal/search/domain"
+	"github.com/ynwd/awesome-blog/internal/search/index"
+)
+
+// fakeSource returns fixed documents
+type fakeSource struct {
+	docs []domain.Document
+	err  error
+}
+
+func (f *fakeSource) Scan(ctx context.Context, fn func(domain.Document)) error {
+	for _, doc := range f.docs {
+		fn(doc)
+	}
+	return f.err
+}
+
+var day = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestService(t *testing.T, config SearchConfig) (SearchService, *fakeSource) {
+	source := &fakeSource{}
+	svc := NewSearchService(index.New(), source, config)
+	for i := 0; i < 5; i++ {
+		svc.Index(domain.PostEvent{
+			ID:          fmt.Sprintf("post-%d", i),
+			Username:    "alice",
+			Title:       fmt.Sprintf("Post %d", i),
+			Description: "All about full text search",
+			Tags:        []string{"go"},
+			CreatedAt:   day.Add(time.Duration(i) * 24 * time.Hour),
+		}.Document())
+	}
+	svc.Index(domain.CommentEvent{ID: "comment-1", PostID: "post-0", Username: "bob", Comment: "Search is hard", CreatedAt: day}.Document())
+	return svc, source
+}
+
+func ids(result domain.Result) []string {
+	ids := make([]string, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids
+}
+
+func TestSearchService_Search(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t, DefaultSearchConfig())
+
+	t.Run("paginates the ranking", func(t *testing.T) {
+		result, err := svc.Search(ctx, domain.Query{Text: "searching", Kind: domain.KindPost, Limit: 3})
+		require.NoError(t, err)
+		assert.Equal(t, 5, result.Total)
+		assert.Equal(t, []string{"post-4", "post-3", "post-2"}, ids(result))
+		assert.Equal(t, "All about full text <mark>search</mark>", result.Hits[0].Snippet)
+		require.NotEmpty(t, result.NextCursor)
+
+		result, err = svc.Search(ctx, domain.Query{Text: "searching", Kind: domain.KindPost, Limit: 3, Cursor: result.NextCursor})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"post-1", "post-0"}, ids(result))
+		assert.Empty(t, result.NextCursor)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		result, err := svc.Search(ctx, domain.Query{Text: "search", Author: " bob "})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"comment-1"}, ids(result))
+
+		result, err = svc.Search(ctx, domain.Query{Text: "search", Tag: "#Go", From: day.Add(24 * time.Hour), To: day.Add(2 * 24 * time.Hour)})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"post-2", "post-1"}, ids(result))
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		result, err := svc.Search(ctx, domain.Query{Text: "kubernetes"})
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.Total)
+		assert.NotNil(t, result.Hits)
+	})
+
+	t.Run("invalid queries", func(t *testing.T) {
+		tests := []struct {
+			query domain.Query
+			err   error
+		}{
+			{query: domain.Query{Text: "  "}, err: domain.ErrEmptyQuery},
+			{query: domain.Query{Text: "the and of"}, err: domain.ErrEmptyQuery},
+			{query: domain.Query{Text: strings.Repeat("search ", 40)}, err: domain.ErrQueryTooLong},
+			{query: domain.Query{Text: "search", Kind: "user"}, err: domain.ErrInvalidKind},
+			{query: domain.Query{Text: "search", From: day, To: day.Add(-time.Hour)}, err: domain.ErrInvalidDateRange},
+			{query: domain.Query{Text: "search", Cursor: "not a cursor"}, err: domain.ErrInvalidCursor},
+			{query: domain.Query{Text: "search", Cursor: encodeOffset(-1)}, err: domain.ErrInvalidCursor},
+		}
+		for _, tt := range tests {
+			_, err := svc.Search(ctx, tt.query)
+			assert.ErrorIs(t, err, tt.err)
+		}
+	})
+}
+
+func TestSearchService_RemovePost(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t, DefaultSearchConfig())
+
+	svc.RemovePost("post-0")
+	svc.Remove(domain.KindPost, "post-1")
+
+	result, err := svc.Search(ctx, domain.Query{Text: "search"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"post-4", "post-3", "post-2"}, ids(result))
+}
+
+func TestSearchService_Reindex(t *testing.T) {
+	ctx := context.Background()
+	svc, source := newTestService(t, DefaultSearchConfig())
+
+	source.err = errors.New("firestore unavailable")
+	_, err := svc.Reindex(ctx)
+	assert.ErrorIs(t, err, source.err)
+
+	source.err = nil
+	source.docs = []domain.Document{domain.PostEvent{ID: "post-9", Username: "carol", Title: "Search engines", CreatedAt: day}.Document()}
+	n, err := svc.Reindex(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	result, err := svc.Search(ctx, domain.Query{Text: "search"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"post-9"}, ids(result))
+}
+
+func TestSearchService_Snapshot(t *testing.T) {
+	ctx := context.Background()
+
+	memoryOnly, _ := newTestService(t, DefaultSearchConfig())
+	n, err := memoryOnly.LoadSnapshot()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.NoError(t, memoryOnly.SaveSnapshot())
+
+	config := DefaultSearchConfig()
+	config.SnapshotPath = filepath.Join(t.TempDir(), "search.snapshot")
+	svc, _ := newTestService(t, config)
+	require.NoError(t, svc.SaveSnapshot())
+
+	restored := NewSearchService(index.New(), &fakeSource{}, config)
+	n, err = restored.LoadSnapshot()
+	require.NoError(t, err)
+	assert.Equal(t, 6, n)
+
+	result, err := restored.Search(ctx, domain.Query{Text: "search"})
+	require.NoError(t, err)
+	assert.Equal(t, 6, result.Total)
+}
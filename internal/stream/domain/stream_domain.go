@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+var (
+	ErrInvalidPostID      = errors.New("invalid post id")
+	ErrPostNotFound       = errors.New("post not found")
+	ErrSlowConsumer       = errors.New("stream fell behind and was closed")
+	ErrTooManyConnections = errors.New("too many open streams")
+	ErrTooManyTopics      = errors.New("too many subscriptions on this connection")
+)
+
+// Message types pushed to the streams
+const (
+	MessageCommentCreated = "comment.created"
+	MessageCommentUpdated = "comment.updated"
+	MessageCommentDeleted = "comment.deleted"
+	MessageLikesCount     = "likes.count"
+	MessagePostUpdated    = "post.updated"
+	MessagePostDeleted    = "post.deleted"
+
+	// Replies to WebSocket commands and keep-alives
+	MessageSubscribed   = "subscribed"
+	MessageUnsubscribed = "unsubscribed"
+	MessageError        = "error"
+	MessagePing         = "ping"
+)
+
+// Message is a single update pushed to a stream. ID is the ID of the event
+// that caused it, so clients can drop the duplicates of a redelivered event.
+type Message struct {
+	ID    string          `json:"id,omitempty"`
+	Type  string          `json:"type"`
+	Topic string          `json:"topic,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+// NewMessage builds a message with data encoded as JSON
+func NewMessage(id, messageType, topic string, data any) (Message, error) {
+	msg := Message{ID: id, Type: messageType, Topic: topic}
+	if data == nil {
+		return msg, nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Message{}, err
+	}
+	msg.Data = raw
+	return msg, nil
+}
+
+// LikesCount is the data of a likes.count message
+type LikesCount struct {
+	PostID string `json:"post_id"`
+	Count  int64  `json:"count"`
+}
+
+// ErrorData is the data of an error message
+type ErrorData struct {
+	Error string `json:"error"`
+}
+
+// ActivityEvent holds the fields of the post, comment and like event payloads
+// the stream routes on
+type ActivityEvent struct {
+	ID     string `json:"id,omitempty"`
+	PostID string `json:"post_id,omitempty"`
+}
+
+// UserMessage is the payload of a USER_MESSAGE event: a message for the
+// streams of a user
+type UserMessage struct {
+	Username string          `json:"username"`
+	ID       string          `json:"id"`
+	Type     string          `json:"type"`
+	Data     json.RawMessage `json:"data,omitempty"`
+}
+
+// PostTopic is the topic carrying the activity on a post
+func PostTopic(postID string) string {
+	return "post:" + postID
+}
+
+// UserTopic is the topic carrying a user's notifications
+func UserTopic(username string) string {
+	return "user:" + username
+}
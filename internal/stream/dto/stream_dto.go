@@ -0,0 +1,14 @@
+package dto
+
+// WebSocket command actions
+const (
+	ActionSubscribe   = "subscribe"
+	ActionUnsubscribe = "unsubscribe"
+)
+
+// StreamCommand is sent by WebSocket clients to follow or stop following
+// the activity on a post
+type StreamCommand struct {
+	Action string `json:"action"`
+	PostID string `json:"post_id"`
+}
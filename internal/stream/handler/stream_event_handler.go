@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/ynwd/awesome-blog/internal/stream/domain"
+	"github.com/ynwd/awesome-blog/internal/stream/service"
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+// messageTypes maps the domain events pushed to the post streams to the
+// message type clients receive
+var messageTypes = map[module.EventType]string{
+	module.CommentCreatedEvent: domain.MessageCommentCreated,
+	module.CommentUpdatedEvent: domain.MessageCommentUpdated,
+	module.CommentDeletedEvent: domain.MessageCommentDeleted,
+	module.PostUpdatedEvent:    domain.MessagePostUpdated,
+	module.PostDeletedEvent:    domain.MessagePostDeleted,
+}
+
+// StreamEventHandler pushes domain events to the open post streams, and user
+// messages to the user streams, of this replica. Streams are
+// best effort: a failure is logged and never causes the event to be
+// redelivered, and a redelivered event is pushed again with the same ID.
+type StreamEventHandler struct {
+	hub   service.Hub
+	likes module.LikeCounter
+}
+
+func NewStreamEventHandler(hub service.Hub, likes module.LikeCounter) *StreamEventHandler {
+	return &StreamEventHandler{
+		hub:   hub,
+		likes: likes,
+	}
+}
+
+// Handle pushes comment and post changes to the post's stream, and the new
+// like count after a like or unlike
+func (h *StreamEventHandler) Handle(ctx context.Context, event module.Event[json.RawMessage]) error {
+	var payload domain.ActivityEvent
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		log.Printf("Error decoding %s event %s for streams: %v", event.Type, event.ID, err)
+		return nil
+	}
+
+	switch event.Type {
+	case module.CommentCreatedEvent, module.CommentUpdatedEvent, module.CommentDeletedEvent:
+		h.push(domain.PostTopic(payload.PostID), event)
+	case module.PostUpdatedEvent, module.PostDeletedEvent:
+		h.push(domain.PostTopic(payload.ID), event)
+	case module.LikeCreatedEvent, module.LikeDeletedEvent:
+		h.pushLikesCount(ctx, payload.PostID, event)
+	}
+	return nil
+}
+
+// HandleUserMessage pushes the message to the user's streams on this replica
+func (h *StreamEventHandler) HandleUserMessage(ctx context.Context, event module.Event[domain.UserMessage]) error {
+	msg := event.Payload
+	topic := domain.UserTopic(msg.Username)
+	h.hub.Publish(topic, domain.Message{
+		ID:    msg.ID,
+		Type:  msg.Type,
+		Topic: topic,
+		Data:  msg.Data,
+	})
+	return nil
+}
+
+// push forwards the event payload unchanged to the topic
+func (h *StreamEventHandler) push(topic string, event module.Event[json.RawMessage]) {
+	h.hub.Publish(topic, domain.Message{
+		ID:    event.ID,
+		Type:  messageTypes[event.Type],
+		Topic: topic,
+		Data:  event.Payload,
+	})
+}
+
+// pushLikesCount sends the post's current like count, so clients never have
+// to apply increments in order
+func (h *StreamEventHandler) pushLikesCount(ctx context.Context, postID string, event module.Event[json.RawMessage]) {
+	count, err := h.likes.LikeCount(ctx, postID)
+	if err != nil {
+		log.Printf("Error counting likes of post %s for streams: %v", postID, err)
+		return
+	}
+
+	topic := domain.PostTopic(postID)
+	msg, err := domain.NewMessage(event.ID, domain.MessageLikesCount, topic, domain.LikesCount{
+		PostID: postID,
+		Count:  count,
+	})
+	if err != nil {
+		log.Printf("Error encoding like count of post %s: %v", postID, err)
+		return
+	}
+	h.hub.Publish(topic, msg)
+}
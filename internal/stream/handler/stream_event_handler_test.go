@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ynwd/awesome-blog/internal/stream/domain"
+	"github.com/ynwd/awesome-blog/internal/stream/service"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/tests/helper"
+)
+
+type published struct {
+	topic string
+	msg   domain.Message
+}
+
+type mockHub struct {
+	published []published
+}
+
+func (m *mockHub) Subscribe(username string, topics ...string) (*service.Subscription, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockHub) Publish(topic string, msg domain.Message) {
+	m.published = append(m.published, published{topic: topic, msg: msg})
+}
+
+func TestStreamEventHandler_Handle(t *testing.T) {
+	likes := &helper.MockLikeCounter{
+		LikeCountFunc: func(ctx context.Context, postID string) (int64, error) {
+			assert.Equal(t, "post1", postID)
+			return 3, nil
+		},
+	}
+
+	tests := []struct {
+		name      string
+		eventType module.EventType
+		payload   string
+		want      []published
+	}{
+		{
+			name:      "new comment",
+			eventType: module.CommentCreatedEvent,
+			payload:   `{"id":"c1","post_id":"post1","username":"reader","comment":"hi"}`,
+			want: []published{
+				{topic: "post:post1", msg: domain.Message{Type: domain.MessageCommentCreated}},
+			},
+		},
+		{
+			name:      "edited comment",
+			eventType: module.CommentUpdatedEvent,
+			payload:   `{"id":"c1","post_id":"post1","username":"reader","comment":"edited"}`,
+			want: []published{
+				{topic: "post:post1", msg: domain.Message{Type: domain.MessageCommentUpdated}},
+			},
+		},
+		{
+			name:      "edited post",
+			eventType: module.PostUpdatedEvent,
+			payload:   `{"id":"post1","username":"author","title":"new title"}`,
+			want: []published{
+				{topic: "post:post1", msg: domain.Message{Type: domain.MessagePostUpdated}},
+			},
+		},
+		{
+			name:      "like sends the count",
+			eventType: module.LikeCreatedEvent,
+			payload:   `{"post_id":"post1","username_from":"reader"}`,
+			want: []published{
+				{topic: "post:post1", msg: domain.Message{Type: domain.MessageLikesCount, Data: []byte(`{"post_id":"post1","count":3}`)}},
+			},
+		},
+		{
+			name:      "unlike sends the count",
+			eventType: module.LikeDeletedEvent,
+			payload:   `{"post_id":"post1","username_from":"reader"}`,
+			want: []published{
+				{topic: "post:post1", msg: domain.Message{Type: domain.MessageLikesCount, Data: []byte(`{"post_id":"post1","count":3}`)}},
+			},
+		},
+		{
+			name:      "undecodable payload is dropped",
+			eventType: module.CommentCreatedEvent,
+			payload:   `"not an object"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := &mockHub{}
+			h := NewStreamEventHandler(hub, likes)
+
+			err := h.Handle(context.Background(), module.Event[json.RawMessage]{
+				ID:      "event-1",
+				Type:    tt.eventType,
+				Payload: json.RawMessage(tt.payload),
+			})
+			assert.NoError(t, err)
+
+			if !assert.Len(t, hub.published, len(tt.want)) {
+				return
+			}
+			for i, want := range tt.want {
+				got := hub.published[i]
+				assert.Equal(t, want.topic, got.topic)
+				assert.Equal(t, want.topic, got.msg.Topic)
+				assert.Equal(t, "event-1", got.msg.ID)
+				assert.Equal(t, want.msg.Type, got.msg.Type)
+				if want.msg.Data != nil {
+					assert.JSONEq(t, string(want.msg.Data), string(got.msg.Data))
+				} else {
+					// Comments and posts forward the payload as published
+					assert.JSONEq(t, tt.payload, string(got.msg.Data))
+				}
+			}
+		})
+	}
+}
+
+func TestStreamEventHandler_Handle_CountError(t *testing.T) {
+	hub := &mockHub{}
+	likes := &helper.MockLikeCounter{
+		LikeCountFunc: func(ctx context.Context, postID string) (int64, error) {
+			return 0, errors.New("firestore error")
+		},
+	}
+	h := NewStreamEventHandler(hub, likes)
+
+	// Streams are best effort, so failures never redeliver the event
+	err := h.Handle(context.Background(), module.Event[json.RawMessage]{
+		ID:      "event-1",
+		Type:    module.LikeCreatedEvent,
+		Payload: json.RawMessage(`{"post_id":"post1","username_from":"reader"}`),
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, hub.published)
+}
+
+func TestStreamEventHandler_HandleUserMessage(t *testing.T) {
+	hub := &mockHub{}
+	h := NewStreamEventHandler(hub, &helper.MockLikeCounter{})
+
+	err := h.HandleUserMessage(context.Background(), module.Event[domain.UserMessage]{
+		ID:   "event-1",
+		Type: module.UserMessageEvent,
+		Payload: domain.UserMessage{
+			Username: "alice",
+			ID:       "n1",
+			Type:     "notification",
+			Data:     json.RawMessage(`{"count":2}`),
+		},
+	})
+	assert.NoError(t, err)
+
+	// The message keeps the notification ID, not the event ID
+	assert.Equal(t, []published{{
+		topic: "user:alice",
+		msg:   domain.Message{ID: "n1", Type: "notification", Topic: "user:alice", Data: json.RawMessage(`{"count":2}`)},
+	}}, hub.published)
+}
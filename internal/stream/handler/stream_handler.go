@@ -0,0 +1,311 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ynwd/awesome-blog/internal/stream/domain"
+	"github.com/ynwd/awesome-blog/internal/stream/dto"
+	"github.com/ynwd/awesome-blog/internal/stream/service"
+	"github.com/ynwd/awesome-blog/pkg/middleware"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/res"
+	"golang.org/x/net/websocket"
+)
+
+// maxCommandBytes bounds a single command sent by a WebSocket client
+const maxCommandBytes = 4 << 10
+
+type StreamHandler struct {
+	hub    service.Hub
+	posts  module.PostLookup
+	config service.StreamConfig
+}
+
+func NewStreamHandler(hub service.Hub, posts module.PostLookup, config service.StreamConfig) *StreamHandler {
+	return &StreamHandler{
+		hub:    hub,
+		posts:  posts,
+		config: config,
+	}
+}
+
+// PostStream streams the new comments, like counts and edits of a post as
+// Server-Sent Events
+func (h *StreamHandler) PostStream(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	postID := c.Param("id")
+	if err := h.checkPost(c.Request.Context(), postID); err != nil {
+		c.JSON(streamErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	sub, err := h.hub.Subscribe(username, domain.PostTopic(postID))
+	if err != nil {
+		c.JSON(streamErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+	defer sub.Close()
+
+	h.serveEvents(c, sub)
+}
+
+// NotificationStream streams the authenticated user's notifications as
+// Server-Sent Events
+func (h *StreamHandler) NotificationStream(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	sub, err := h.hub.Subscribe(username, domain.UserTopic(username))
+	if err != nil {
+		c.JSON(streamErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+	defer sub.Close()
+
+	h.serveEvents(c, sub)
+}
+
+// serveEvents writes the subscription's messages until the client goes away
+// or the subscription is closed. A write that does not finish within the
+// write timeout ends the stream.
+func (h *StreamHandler) serveEvents(c *gin.Context, sub *service.Subscription) {
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	controller := http.NewResponseController(c.Writer)
+	write := func(fn func(w io.Writer) error) bool {
+		// Not every writer supports deadlines; the write is then unbounded
+		_ = controller.SetWriteDeadline(time.Now().Add(h.config.WriteTimeout))
+		if err := fn(c.Writer); err != nil {
+			return false
+		}
+		return controller.Flush() == nil
+	}
+
+	// Send the headers right away so the client knows the stream is open
+	if !write(writeComment("connected")) {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.config.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-sub.Done():
+			if err := sub.Err(); err != nil {
+				write(writeEvent(errorMessage("", err)))
+			}
+			return
+		case msg := <-sub.Messages():
+			if !write(writeEvent(msg)) {
+				return
+			}
+		case <-heartbeat.C:
+			if !write(writeComment("ping")) {
+				return
+			}
+		}
+	}
+}
+
+// writeEvent encodes the message as a Server-Sent Event. The JSON encoding of
+// the message never contains a newline, so it fits on a single data line.
+func writeEvent(msg domain.Message) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		if msg.ID != "" {
+			if _, err := fmt.Fprintf(w, "id: %s\n", msg.ID); err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.Type, data)
+		return err
+	}
+}
+
+// writeComment writes an SSE comment, which clients ignore
+func writeComment(text string) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, ": %s\n\n", text)
+		return err
+	}
+}
+
+// WebSocket pushes the authenticated user's notifications over a WebSocket
+// and the activity of the posts the client subscribes to with
+// {"action":"subscribe","post_id":"..."} commands
+func (h *StreamHandler) WebSocket(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	sub, err := h.hub.Subscribe(username, domain.UserTopic(username))
+	if err != nil {
+		c.JSON(streamErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+	defer sub.Close()
+
+	// The token authenticates the connection rather than a cookie, so
+	// requests from any origin are accepted
+	server := websocket.Server{
+		Handler: func(conn *websocket.Conn) {
+			h.serveWebSocket(c.Request.Context(), conn, sub)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// serveWebSocket reads commands in a separate goroutine and writes every
+// message from a single loop, so writes never interleave
+func (h *StreamHandler) serveWebSocket(ctx context.Context, conn *websocket.Conn, sub *service.Subscription) {
+	conn.MaxPayloadBytes = maxCommandBytes
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	replies := make(chan domain.Message)
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		h.readCommands(ctx, conn, sub, replies)
+	}()
+
+	write := func(msg domain.Message) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(h.config.WriteTimeout))
+		return websocket.JSON.Send(conn, msg) == nil
+	}
+
+	heartbeat := time.NewTicker(h.config.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-closed:
+			return
+		case <-sub.Done():
+			if err := sub.Err(); err != nil {
+				write(errorMessage("", err))
+			}
+			return
+		case msg := <-sub.Messages():
+			if !write(msg) {
+				return
+			}
+		case msg := <-replies:
+			if !write(msg) {
+				return
+			}
+		case <-heartbeat.C:
+			if !write(domain.Message{Type: domain.MessagePing}) {
+				return
+			}
+		}
+	}
+}
+
+// readCommands applies the client's commands until the connection is closed
+func (h *StreamHandler) readCommands(ctx context.Context, conn *websocket.Conn, sub *service.Subscription, replies chan<- domain.Message) {
+	for {
+		// Receive fails once the client disconnects or the connection is closed
+		var data string
+		if err := websocket.Message.Receive(conn, &data); err != nil {
+			return
+		}
+
+		reply := h.apply(ctx, sub, data)
+		select {
+		case replies <- reply:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// apply runs a single command and returns the reply to send
+func (h *StreamHandler) apply(ctx context.Context, sub *service.Subscription, data string) domain.Message {
+	var cmd dto.StreamCommand
+	if err := json.Unmarshal([]byte(data), &cmd); err != nil {
+		return errorMessage("", errors.New("invalid command"))
+	}
+	topic := domain.PostTopic(cmd.PostID)
+
+	switch cmd.Action {
+	case dto.ActionSubscribe:
+		if err := h.checkPost(ctx, cmd.PostID); err != nil {
+			return errorMessage(topic, err)
+		}
+		if err := sub.AddTopic(topic); err != nil {
+			return errorMessage(topic, err)
+		}
+		return domain.Message{Type: domain.MessageSubscribed, Topic: topic}
+	case dto.ActionUnsubscribe:
+		sub.RemoveTopic(topic)
+		return domain.Message{Type: domain.MessageUnsubscribed, Topic: topic}
+	default:
+		return errorMessage("", fmt.Errorf("unknown action %q", cmd.Action))
+	}
+}
+
+// checkPost returns domain.ErrPostNotFound when the post does not exist
+func (h *StreamHandler) checkPost(ctx context.Context, postID string) error {
+	if postID == "" {
+		return domain.ErrInvalidPostID
+	}
+	exists, err := h.posts.PostExists(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrPostNotFound
+	}
+	return nil
+}
+
+func errorMessage(topic string, err error) domain.Message {
+	msg, _ := domain.NewMessage("", domain.MessageError, topic, domain.ErrorData{Error: err.Error()})
+	return msg
+}
+
+// streamErrorStatus maps stream errors to HTTP status codes
+func streamErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidPostID):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrPostNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrTooManyConnections), errors.Is(err, domain.ErrTooManyTopics):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
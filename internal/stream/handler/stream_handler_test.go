@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ynwd/awesome-blog/internal/stream/domain"
+	"github.com/ynwd/awesome-blog/internal/stream/dto"
+	"github.com/ynwd/awesome-blog/internal/stream/service"
+	"github.com/ynwd/awesome-blog/tests/helper"
+	"golang.org/x/net/websocket"
+)
+
+func setupRouter(hub service.Hub, username string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if username != "" {
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", username)
+		})
+	}
+	posts := &helper.MockPostLookup{
+		PostExistsFunc: func(ctx context.Context, postID string) (bool, error) {
+			return postID != "missing", nil
+		},
+	}
+	h := NewStreamHandler(hub, posts, service.DefaultStreamConfig())
+	router.GET("/posts/:id/stream", h.PostStream)
+	router.GET("/notifications/stream", h.NotificationStream)
+	router.GET("/ws", h.WebSocket)
+	return router
+}
+
+func testHub(maxConnections int) service.Hub {
+	config := service.DefaultStreamConfig()
+	config.MaxConnectionsPerUser = maxConnections
+	return service.NewHub(config)
+}
+
+// readUntil reads SSE lines until one starts with prefix
+func readUntil(t *testing.T, reader *bufio.Reader, prefix string) string {
+	t.Helper()
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimSpace(line)
+		}
+	}
+}
+
+func TestStreamHandler_PostStream_Errors(t *testing.T) {
+	hub := testHub(1)
+	held, err := hub.Subscribe("busy", domain.UserTopic("busy"))
+	require.NoError(t, err)
+	defer held.Close()
+
+	tests := []struct {
+		name       string
+		username   string
+		path       string
+		wantStatus int
+	}{
+		{name: "unauthenticated", path: "/posts/post1/stream", wantStatus: http.StatusUnauthorized},
+		{name: "missing post", username: "alice", path: "/posts/missing/stream", wantStatus: http.StatusNotFound},
+		{name: "too many connections", username: "busy", path: "/posts/post1/stream", wantStatus: http.StatusTooManyRequests},
+		{name: "too many notification streams", username: "busy", path: "/notifications/stream", wantStatus: http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupRouter(hub, tt.username)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestStreamHandler_PostStream(t *testing.T) {
+	hub := testHub(5)
+	server := httptest.NewServer(setupRouter(hub, "alice"))
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(server.URL + "/posts/post1/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	readUntil(t, reader, ": connected")
+
+	// Messages of other posts are not streamed
+	hub.Publish(domain.PostTopic("post2"), domain.Message{ID: "other", Type: domain.MessageCommentCreated})
+	hub.Publish(domain.PostTopic("post1"), domain.Message{
+		ID:    "event-1",
+		Type:  domain.MessageCommentCreated,
+		Topic: domain.PostTopic("post1"),
+		Data:  []byte(`{"id":"c1","post_id":"post1"}`),
+	})
+
+	assert.Equal(t, "id: event-1", readUntil(t, reader, "id:"))
+	assert.Equal(t, "event: comment.created", readUntil(t, reader, "event:"))
+	assert.JSONEq(t,
+		`{"id":"event-1","type":"comment.created","topic":"post:post1","data":{"id":"c1","post_id":"post1"}}`,
+		strings.TrimPrefix(readUntil(t, reader, "data:"), "data: "),
+	)
+}
+
+func TestStreamHandler_NotificationStream(t *testing.T) {
+	hub := testHub(5)
+	server := httptest.NewServer(setupRouter(hub, "alice"))
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(server.URL + "/notifications/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	readUntil(t, reader, ": connected")
+
+	hub.Publish(domain.UserTopic("bob"), domain.Message{ID: "other", Type: "notification"})
+	hub.Publish(domain.UserTopic("alice"), domain.Message{ID: "event-1", Type: "notification"})
+
+	assert.Equal(t, "id: event-1", readUntil(t, reader, "id:"))
+	assert.Equal(t, "event: notification", readUntil(t, reader, "event:"))
+}
+
+func TestStreamHandler_WebSocket(t *testing.T) {
+	hub := testHub(5)
+	server := httptest.NewServer(setupRouter(hub, "alice"))
+	defer server.Close()
+
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", "", server.URL)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	receive := func() domain.Message {
+		var msg domain.Message
+		require.NoError(t, websocket.JSON.Receive(conn, &msg))
+		return msg
+	}
+
+	// Subscribing to a missing post fails
+	require.NoError(t, websocket.JSON.Send(conn, dto.StreamCommand{Action: dto.ActionSubscribe, PostID: "missing"}))
+	msg := receive()
+	assert.Equal(t, domain.MessageError, msg.Type)
+	assert.JSONEq(t, `{"error":"post not found"}`, string(msg.Data))
+
+	require.NoError(t, websocket.JSON.Send(conn, dto.StreamCommand{Action: dto.ActionSubscribe, PostID: "post1"}))
+	msg = receive()
+	assert.Equal(t, domain.MessageSubscribed, msg.Type)
+	assert.Equal(t, domain.PostTopic("post1"), msg.Topic)
+
+	// Post activity and the user's notifications share the connection
+	hub.Publish(domain.PostTopic("post1"), domain.Message{ID: "event-1", Type: domain.MessagePostUpdated})
+	assert.Equal(t, "event-1", receive().ID)
+	hub.Publish(domain.UserTopic("alice"), domain.Message{ID: "event-2", Type: "notification"})
+	assert.Equal(t, "event-2", receive().ID)
+
+	require.NoError(t, websocket.JSON.Send(conn, dto.StreamCommand{Action: dto.ActionUnsubscribe, PostID: "post1"}))
+	assert.Equal(t, domain.MessageUnsubscribed, receive().Type)
+
+	hub.Publish(domain.PostTopic("post1"), domain.Message{ID: "event-3", Type: domain.MessagePostUpdated})
+	hub.Publish(domain.UserTopic("alice"), domain.Message{ID: "event-4", Type: "notification"})
+	assert.Equal(t, "event-4", receive().ID)
+
+	require.NoError(t, websocket.Message.Send(conn, "not json"))
+	assert.Equal(t, domain.MessageError, receive().Type)
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/stream/domain"
+)
+
+// StreamConfig controls the buffering and limits of the streams
+type StreamConfig struct {
+	// BufferSize is the number of messages held for a subscription before
+	// it is considered too slow and closed
+	BufferSize int
+
+	MaxConnectionsPerUser  int
+	MaxTopicsPerConnection int
+
+	// HeartbeatInterval is how often an idle stream is pinged so proxies
+	// keep it open
+	HeartbeatInterval time.Duration
+
+	// WriteTimeout bounds a single write to a client
+	WriteTimeout time.Duration
+}
+
+func DefaultStreamConfig() StreamConfig {
+	return StreamConfig{
+		BufferSize:             64,
+		MaxConnectionsPerUser:  5,
+		MaxTopicsPerConnection: 20,
+		HeartbeatInterval:      15 * time.Second,
+		WriteTimeout:           10 * time.Second,
+	}
+}
+
+// streamMetrics are published under "stream" in /debug/vars
+var streamMetrics = struct {
+	connections *expvar.Int
+	published   *expvar.Int
+	dropped     *expvar.Int
+}{
+	connections: new(expvar.Int),
+	published:   new(expvar.Int),
+	dropped:     new(expvar.Int),
+}
+
+func init() {
+	metrics := expvar.NewMap("stream")
+	metrics.Set("connections", streamMetrics.connections)
+	metrics.Set("messages_total", streamMetrics.published)
+	metrics.Set("slow_consumers_total", streamMetrics.dropped)
+}
+
+type hub struct {
+	config StreamConfig
+
+	mu     sync.RWMutex
+	topics map[string]map[*Subscription]struct{}
+	users  map[string]int
+}
+
+func NewHub(config StreamConfig) Hub {
+	return &hub{
+		config: config,
+		topics: make(map[string]map[*Subscription]struct{}),
+		users:  make(map[string]int),
+	}
+}
+
+func (h *hub) Subscribe(username string, topics ...string) (*Subscription, error) {
+	if len(topics) > h.config.MaxTopicsPerConnection {
+		return nil, domain.ErrTooManyTopics
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.users[username] >= h.config.MaxConnectionsPerUser {
+		return nil, domain.ErrTooManyConnections
+	}
+	h.users[username]++
+	streamMetrics.connections.Add(1)
+
+	sub := &Subscription{
+		hub:      h,
+		username: username,
+		messages: make(chan domain.Message, h.config.BufferSize),
+		done:     make(chan struct{}),
+		topics:   make(map[string]struct{}),
+	}
+	for _, topic := range topics {
+		h.add(sub, topic)
+	}
+	return sub, nil
+}
+
+func (h *hub) Publish(topic string, msg domain.Message) {
+	var slow []*Subscription
+
+	h.mu.RLock()
+	for sub := range h.topics[topic] {
+		select {
+		case sub.messages <- msg:
+			streamMetrics.published.Add(1)
+		default:
+			slow = append(slow, sub)
+		}
+	}
+	h.mu.RUnlock()
+
+	// Closing takes the write lock, so it waits until the read lock is released
+	for _, sub := range slow {
+		streamMetrics.dropped.Add(1)
+		sub.close(domain.ErrSlowConsumer)
+	}
+}
+
+// add and remove must be called with h.mu held for writing
+func (h *hub) add(sub *Subscription, topic string) {
+	subs, ok := h.topics[topic]
+	if !ok {
+		subs = make(map[*Subscription]struct{})
+		h.topics[topic] = subs
+	}
+	subs[sub] = struct{}{}
+	sub.topics[topic] = struct{}{}
+}
+
+func (h *hub) remove(sub *Subscription, topic string) {
+	delete(sub.topics, topic)
+	subs := h.topics[topic]
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.topics, topic)
+	}
+}
+
+// Subscription receives the messages published to its topics until it is
+// closed. The messages channel is never closed; wait on Done instead.
+type Subscription struct {
+	hub      *hub
+	username string
+	messages chan domain.Message
+	done     chan struct{}
+	once     sync.Once
+
+	// topics, closed and err are guarded by hub.mu
+	topics map[string]struct{}
+	closed bool
+	err    error
+}
+
+// Messages returns the channel the subscription's messages arrive on
+func (s *Subscription) Messages() <-chan domain.Message {
+	return s.messages
+}
+
+// Done is closed when the subscription is closed
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why the subscription was closed by the hub, or nil when it is
+// open or was closed by its owner
+func (s *Subscription) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// AddTopic subscribes to another topic
+func (s *Subscription) AddTopic(topic string) error {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	if s.closed {
+		return s.err
+	}
+	if _, ok := s.topics[topic]; ok {
+		return nil
+	}
+	if len(s.topics) >= s.hub.config.MaxTopicsPerConnection {
+		return domain.ErrTooManyTopics
+	}
+	s.hub.add(s, topic)
+	return nil
+}
+
+// RemoveTopic stops receiving the messages of a topic
+func (s *Subscription) RemoveTopic(topic string) {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	if _, ok := s.topics[topic]; ok {
+		s.hub.remove(s, topic)
+	}
+}
+
+// Close removes the subscription from the hub. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.close(nil)
+}
+
+func (s *Subscription) close(err error) {
+	s.once.Do(func() {
+		s.hub.mu.Lock()
+		s.closed = true
+		s.err = err
+		for topic := range s.topics {
+			s.hub.remove(s, topic)
+		}
+		s.hub.users[s.username]--
+		if s.hub.users[s.username] <= 0 {
+			delete(s.hub.users, s.username)
+		}
+		s.hub.mu.Unlock()
+		streamMetrics.connections.Add(-1)
+		close(s.done)
+	})
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ynwd/awesome-blog/internal/stream/domain"
+)
+
+func testConfig() StreamConfig {
+	config := DefaultStreamConfig()
+	config.BufferSize = 2
+	config.MaxConnectionsPerUser = 2
+	config.MaxTopicsPerConnection = 2
+	return config
+}
+
+func TestHub_PublishReachesSubscribersOfTheTopic(t *testing.T) {
+	hub := NewHub(testConfig())
+
+	post1, err := hub.Subscribe("alice", domain.PostTopic("post1"))
+	require.NoError(t, err)
+	defer post1.Close()
+	post2, err := hub.Subscribe("bob", domain.PostTopic("post2"))
+	require.NoError(t, err)
+	defer post2.Close()
+
+	hub.Publish(domain.PostTopic("post1"), domain.Message{ID: "event-1", Type: domain.MessageCommentCreated})
+
+	select {
+	case msg := <-post1.Messages():
+		assert.Equal(t, "event-1", msg.ID)
+	default:
+		t.Fatal("subscriber of the topic received nothing")
+	}
+	assert.Empty(t, post2.Messages())
+}
+
+func TestHub_SlowConsumerIsClosed(t *testing.T) {
+	hub := NewHub(testConfig())
+	topic := domain.PostTopic("post1")
+
+	slow, err := hub.Subscribe("alice", topic)
+	require.NoError(t, err)
+	fast, err := hub.Subscribe("bob", topic)
+	require.NoError(t, err)
+	defer fast.Close()
+
+	for i := 0; i < 3; i++ {
+		hub.Publish(topic, domain.Message{Type: domain.MessageCommentCreated})
+		<-fast.Messages()
+	}
+
+	select {
+	case <-slow.Done():
+	default:
+		t.Fatal("subscription with a full buffer was not closed")
+	}
+	assert.ErrorIs(t, slow.Err(), domain.ErrSlowConsumer)
+	assert.ErrorIs(t, slow.AddTopic(domain.PostTopic("post2")), domain.ErrSlowConsumer)
+
+	// The fast subscriber keeps receiving
+	hub.Publish(topic, domain.Message{Type: domain.MessageCommentCreated})
+	assert.Len(t, fast.Messages(), 1)
+	assert.NoError(t, fast.Err())
+}
+
+func TestHub_ConnectionLimit(t *testing.T) {
+	hub := NewHub(testConfig())
+
+	first, err := hub.Subscribe("alice", domain.UserTopic("alice"))
+	require.NoError(t, err)
+	_, err = hub.Subscribe("alice", domain.UserTopic("alice"))
+	require.NoError(t, err)
+
+	_, err = hub.Subscribe("alice", domain.UserTopic("alice"))
+	assert.ErrorIs(t, err, domain.ErrTooManyConnections)
+
+	// Other users are not affected
+	other, err := hub.Subscribe("bob", domain.UserTopic("bob"))
+	require.NoError(t, err)
+	other.Close()
+
+	// Closing frees a slot, and closing twice frees only one
+	first.Close()
+	first.Close()
+	third, err := hub.Subscribe("alice", domain.UserTopic("alice"))
+	require.NoError(t, err)
+	defer third.Close()
+	_, err = hub.Subscribe("alice", domain.UserTopic("alice"))
+	assert.ErrorIs(t, err, domain.ErrTooManyConnections)
+}
+
+func TestSubscription_Topics(t *testing.T) {
+	hub := NewHub(testConfig())
+
+	sub, err := hub.Subscribe("alice", domain.UserTopic("alice"))
+	require.NoError(t, err)
+	defer sub.Close()
+
+	require.NoError(t, sub.AddTopic(domain.PostTopic("post1")))
+	require.NoError(t, sub.AddTopic(domain.PostTopic("post1")))
+	assert.ErrorIs(t, sub.AddTopic(domain.PostTopic("post2")), domain.ErrTooManyTopics)
+
+	hub.Publish(domain.PostTopic("post1"), domain.Message{Type: domain.MessageCommentCreated})
+	assert.Len(t, sub.Messages(), 1)
+	<-sub.Messages()
+
+	sub.RemoveTopic(domain.PostTopic("post1"))
+	hub.Publish(domain.PostTopic("post1"), domain.Message{Type: domain.MessageCommentCreated})
+	assert.Empty(t, sub.Messages())
+
+	_, err = hub.Subscribe("bob", "a", "b", "c")
+	assert.ErrorIs(t, err, domain.ErrTooManyTopics)
+}
+
+func TestHub_ConcurrentPublishAndClose(t *testing.T) {
+	hub := NewHub(testConfig())
+	topic := domain.PostTopic("post1")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			sub, err := hub.Subscribe("alice", topic)
+			if err != nil {
+				return
+			}
+			_ = sub.AddTopic(domain.PostTopic("post2"))
+			sub.Close()
+		}()
+		go func() {
+			defer wg.Done()
+			hub.Publish(topic, domain.Message{Type: domain.MessageCommentCreated})
+		}()
+	}
+	wg.Wait()
+
+	// Every subscription was released
+	for i := 0; i < 2; i++ {
+		_, err := hub.Subscribe("alice", topic)
+		assert.NoError(t, err)
+	}
+}
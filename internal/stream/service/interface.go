@@ -0,0 +1,16 @@
+package service
+
+import "github.com/ynwd/awesome-blog/internal/stream/domain"
+
+// Hub fans messages out to the open streams of this replica
+type Hub interface {
+	// Subscribe opens a subscription for the user to the given topics. It
+	// fails with domain.ErrTooManyConnections when the user already has the
+	// maximum number of open subscriptions.
+	Subscribe(username string, topics ...string) (*Subscription, error)
+
+	// Publish delivers the message to every subscription to the topic
+	// without blocking. A subscription whose buffer is full is closed with
+	// domain.ErrSlowConsumer.
+	Publish(topic string, msg domain.Message)
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/stream/domain"
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+// notifyTimeout bounds publishing an update for a user's streams
+const notifyTimeout = 5 * time.Second
+
+type notifier struct {
+	publisher module.Publisher
+}
+
+// NewNotifier publishes updates for other modules as USER_MESSAGE events,
+// which every replica pushes to the user's streams it holds
+func NewNotifier(publisher module.Publisher) module.UserNotifier {
+	return &notifier{
+		publisher: publisher,
+	}
+}
+
+func (n *notifier) NotifyUser(username, id, messageType string, data any) {
+	msg := domain.UserMessage{Username: username, ID: id, Type: messageType}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			log.Printf("Error encoding %s message for %s: %v", messageType, username, err)
+			return
+		}
+		msg.Data = raw
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	if err := n.publisher.Publish(ctx, module.NewEvent(module.UserMessageEvent, msg)); err != nil {
+		log.Printf("Error publishing %s message for %s: %v", messageType, username, err)
+	}
+}
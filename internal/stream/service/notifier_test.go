@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ynwd/awesome-blog/internal/stream/domain"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/tests/helper"
+)
+
+func TestNotifier_NotifyUser(t *testing.T) {
+	var events []module.BaseEvent
+	publisher := &helper.MockPubSub{
+		PublishFunc: func(ctx context.Context, event interface{}) error {
+			events = append(events, event.(module.BaseEvent))
+			return nil
+		},
+	}
+
+	notifier := NewNotifier(publisher)
+	notifier.NotifyUser("alice", "n1", "notification", map[string]int{"count": 2})
+
+	require.Len(t, events, 1)
+	assert.Equal(t, module.UserMessageEvent, events[0].Type)
+	assert.Equal(t, module.UserMessageEventVersion, events[0].Version)
+	msg := events[0].Payload.(domain.UserMessage)
+	assert.Equal(t, "alice", msg.Username)
+	assert.Equal(t, "n1", msg.ID)
+	assert.Equal(t, "notification", msg.Type)
+	assert.Equal(t, json.RawMessage(`{"count":2}`), msg.Data)
+}
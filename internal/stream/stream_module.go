@@ -0,0 +1,50 @@
+package stream
+
+import (
+	"github.com/ynwd/awesome-blog/internal/stream/handler"
+	"github.com/ynwd/awesome-blog/internal/stream/service"
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+type Module struct {
+	handler      *handler.StreamHandler
+	eventHandler *handler.StreamEventHandler
+	notifier     module.UserNotifier
+}
+
+func NewModule(posts module.PostLookup, likes module.LikeCounter, publisher module.Publisher, config service.StreamConfig) *Module {
+	// Initialize the hub the streams of this replica subscribe to
+	hub := service.NewHub(config)
+
+	// Initialize handlers
+	streamHandler := handler.NewStreamHandler(hub, posts, config)
+	eventHandler := handler.NewStreamEventHandler(hub, likes)
+
+	return &Module{
+		handler:      streamHandler,
+		eventHandler: eventHandler,
+		notifier:     service.NewNotifier(publisher),
+	}
+}
+
+// Notifier lets other modules push updates to the users' notification streams
+func (m *Module) Notifier() module.UserNotifier {
+	return m.notifier
+}
+
+// RegisterEvents registers nothing: every replica holds streams, so events
+// are pushed from the replica subscription by RegisterReplicaEvents
+func (m *Module) RegisterEvents(registry *module.EventRegistry) {}
+
+// RegisterReplicaEvents pushes the domain events that change what a reader of
+// a post sees, and the messages other modules send users
+func (m *Module) RegisterReplicaEvents(registry *module.EventRegistry) {
+	module.Handle(registry, module.PostUpdatedEvent, module.PostUpdatedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.PostDeletedEvent, module.PostDeletedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.CommentCreatedEvent, module.CommentCreatedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.CommentUpdatedEvent, module.CommentUpdatedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.CommentDeletedEvent, module.CommentDeletedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.LikeCreatedEvent, module.LikeCreatedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.LikeDeletedEvent, module.LikeDeletedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.UserMessageEvent, module.UserMessageEventVersion, m.eventHandler.HandleUserMessage)
+}
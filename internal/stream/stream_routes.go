@@ -0,0 +1,9 @@
+package stream
+
+import "github.com/gin-gonic/gin"
+
+func (m *Module) RegisterRoutes(router *gin.Engine) {
+	router.GET("/posts/:id/stream", m.handler.PostStream)
+	router.GET("/notifications/stream", m.handler.NotificationStream)
+	router.GET("/ws", m.handler.WebSocket)
+}
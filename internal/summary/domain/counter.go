@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// CounterResolution is the width of the time slot an activity counter covers.
+// Hourly UTC slots can be regrouped into day, week, month and year buckets in
+// any timezone whose offset is a whole number of hours; ParseSummaryQuery
+// rejects the others.
+const CounterResolution = time.Hour
+
+var (
+	ErrPostNotFound = errors.New("post not found")
+)
+
+// ActivityCounter holds one user's activity during one hour, kept up to date
+// from the event stream so summaries do not have to scan the raw collections
+type ActivityCounter struct {
+	Username         string    `firestore:"username"`
+	Hour             time.Time `firestore:"hour"`
+	Likes            int64     `firestore:"likes"`
+	Comments         int64     `firestore:"comments"`
+	Posts            int64     `firestore:"posts"`
+	ReceivedLikes    int64     `firestore:"received_likes"`
+	ReceivedComments int64     `firestore:"received_comments"`
+}
+
+// CountedLike records that a like was added to the counters, so repeated
+// likes are counted once and an unlike knows which counters to decrement
+type CountedLike struct {
+	PostID   string    `firestore:"post_id"`
+	Username string    `firestore:"username"`
+	Author   string    `firestore:"author"`
+	Hour     time.Time `firestore:"hour"`
+}
+
+// CountedPost records that a post was added to the counters, so a deleted
+// post can be taken out of them once
+type CountedPost struct {
+	Username string    `firestore:"username"`
+	Hour     time.Time `firestore:"hour"`
+}
+
+// CountedComment records that a comment was added to the counters, so a
+// deleted comment can be taken out of them once. Tombstoned comments no longer
+// name their writer, so it is kept here.
+type CountedComment struct {
+	PostID   string    `firestore:"post_id"`
+	Username string    `firestore:"username"`
+	Author   string    `firestore:"author"`
+	Hour     time.Time `firestore:"hour"`
+}
+
+// CounterHour returns the counter slot t falls into
+func CounterHour(t time.Time) time.Time {
+	return t.UTC().Truncate(CounterResolution)
+}
+
+// CounterID returns the document ID of the user's counter for the hour. The
+// fixed-width hour comes first so usernames cannot make two IDs collide.
+func CounterID(username string, hour time.Time) string {
+	return CounterHour(hour).Format("2006010215") + "_" + username
+}
+
+// CountedLikeID returns the document ID recording a user's like of a post
+func CountedLikeID(postID, username string) string {
+	sum := sha256.Sum256([]byte(postID + "\x00" + username))
+	return hex.EncodeToString(sum[:])
+}
+
+// Add accumulates the counter into the summary buckets of the query. Counters
+// outside the query's buckets are ignored.
+func (c ActivityCounter) Add(query SummaryQuery, data *SummaryData) {
+	bucket := query.BucketKey(c.Hour)
+	if _, ok := data.Likes[bucket]; !ok {
+		return
+	}
+
+	data.Likes[bucket] += c.Likes
+	data.Comments[bucket] += c.Comments
+	data.Posts[bucket] += c.Posts
+
+	if data.Received != nil {
+		data.Received.Likes[bucket] += c.ReceivedLikes
+		data.Received.Comments[bucket] += c.ReceivedComments
+	}
+}
+
+// ActivityEvent holds the fields of the post, comment and like event payloads
+// that the counters need
+type ActivityEvent struct {
+	ID           string    `json:"id"`
+	PostID       string    `json:"post_id"`
+	Username     string    `json:"username"`
+	UsernameFrom string    `json:"username_from"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterID(t *testing.T) {
+	at := time.Date(2025, 2, 1, 10, 30, 0, 0, time.FixedZone("WIB", 7*60*60))
+
+	assert.Equal(t, "2025020103_alice", CounterID("alice", at))
+	assert.Equal(t, time.Date(2025, 2, 1, 3, 0, 0, 0, time.UTC), CounterHour(at))
+	assert.NotEqual(t, CounterID("a_b", at), CounterID("b", at.Add(time.Hour)))
+}
+
+func TestCountedLikeID(t *testing.T) {
+	assert.Equal(t, CountedLikeID("post1", "alice"), CountedLikeID("post1", "alice"))
+	assert.NotEqual(t, CountedLikeID("post1", "alice"), CountedLikeID("post1a", "lice"))
+}
+
+func TestActivityCounter_Add(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	assert.NoError(t, err)
+	query := SummaryQuery{
+		From:        time.Date(2025, 2, 1, 0, 0, 0, 0, jakarta),
+		To:          time.Date(2025, 2, 2, 0, 0, 0, 0, jakarta),
+		Granularity: GranularityDay,
+		Location:    jakarta,
+	}
+	data := NewSummaryData(query)
+	data.Received = NewEngagementSummary(query)
+
+	// 20:00 UTC on Feb 1 is already Feb 2 in Jakarta
+	ActivityCounter{Hour: time.Date(2025, 2, 1, 20, 0, 0, 0, time.UTC), Likes: 2, Posts: 1, ReceivedComments: 3}.Add(query, data)
+	ActivityCounter{Hour: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), Comments: 1, ReceivedLikes: 4}.Add(query, data)
+	// Outside the period
+	ActivityCounter{Hour: time.Date(2025, 2, 5, 0, 0, 0, 0, time.UTC), Likes: 9}.Add(query, data)
+
+	assert.Equal(t, map[string]int64{"2025-02-01": 0, "2025-02-02": 2}, data.Likes)
+	assert.Equal(t, map[string]int64{"2025-02-01": 1, "2025-02-02": 0}, data.Comments)
+	assert.Equal(t, map[string]int64{"2025-02-01": 0, "2025-02-02": 1}, data.Posts)
+	assert.Equal(t, map[string]int64{"2025-02-01": 4, "2025-02-02": 0}, data.Received.Likes)
+	assert.Equal(t, map[string]int64{"2025-02-01": 0, "2025-02-02": 3}, data.Received.Comments)
+}
@@ -5,7 +5,75 @@ type Summary struct {
 }
 
 type SummaryData struct {
-	Likes    map[string]int64 `json:"likes"`
-	Comments map[string]int64 `json:"comments"`
-	Posts    map[string]int64 `json:"posts"`
+	From        string           `json:"from,omitempty"`
+	To          string           `json:"to,omitempty"`
+	Granularity Granularity      `json:"granularity,omitempty"`
+	Timezone    string           `json:"timezone,omitempty"`
+	Buckets     []string         `json:"buckets,omitempty"`
+	Likes       map[string]int64 `json:"likes"`
+	Comments    map[string]int64 `json:"comments"`
+	Posts       map[string]int64 `json:"posts"`
+
+	// Received is the engagement other users gave the user's posts
+	Received *EngagementSummary `json:"received,omitempty"`
+}
+
+// EngagementSummary counts likes and comments other users left on an
+// author's posts during the period. The author's own activity is excluded.
+type EngagementSummary struct {
+	Likes          map[string]int64 `json:"likes"`
+	Comments       map[string]int64 `json:"comments"`
+	TopPosts       []PostEngagement `json:"top_posts"`
+	UniqueEngagers int64            `json:"unique_engagers"`
+}
+
+// PostEngagement is the engagement a single post received during the period
+type PostEngagement struct {
+	PostID   string `json:"post_id"`
+	Title    string `json:"title"`
+	Likes    int64  `json:"likes"`
+	Comments int64  `json:"comments"`
+}
+
+// Total is the number of likes and comments the post received
+func (p PostEngagement) Total() int64 {
+	return p.Likes + p.Comments
+}
+
+// NewSummaryData returns empty summary data for the query with every bucket
+// set to zero, so charts have no gaps
+func NewSummaryData(query SummaryQuery) *SummaryData {
+	data := &SummaryData{
+		From:        query.From.Format(DateLayout),
+		To:          query.To.Format(DateLayout),
+		Granularity: query.Granularity,
+		Timezone:    query.Location.String(),
+		Buckets:     query.Buckets(),
+		Likes:       make(map[string]int64),
+		Comments:    make(map[string]int64),
+		Posts:       make(map[string]int64),
+	}
+
+	for _, bucket := range data.Buckets {
+		data.Likes[bucket] = 0
+		data.Comments[bucket] = 0
+		data.Posts[bucket] = 0
+	}
+	return data
+}
+
+// NewEngagementSummary returns empty engagement data for the query with every
+// bucket set to zero
+func NewEngagementSummary(query SummaryQuery) *EngagementSummary {
+	data := &EngagementSummary{
+		Likes:    make(map[string]int64),
+		Comments: make(map[string]int64),
+		TopPosts: []PostEngagement{},
+	}
+
+	for _, bucket := range query.Buckets() {
+		data.Likes[bucket] = 0
+		data.Comments[bucket] = 0
+	}
+	return data
 }
@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type Granularity string
+
+const (
+	GranularityDay   Granularity = "day"
+	GranularityWeek  Granularity = "week"
+	GranularityMonth Granularity = "month"
+	GranularityYear  Granularity = "year"
+)
+
+const (
+	// DateLayout is the format of the from and to dates
+	DateLayout = "2006-01-02"
+
+	// MaxBuckets limits how many buckets a single summary may span
+	MaxBuckets = 1000
+)
+
+var (
+	ErrInvalidGranularity = errors.New("invalid granularity: must be day, week, month or year")
+	ErrInvalidTimezone    = errors.New("invalid timezone: must be an IANA time zone name")
+	ErrPartialHourOffset  = errors.New("invalid timezone: its UTC offset must be a whole number of hours during the period")
+	ErrInvalidDate        = errors.New("invalid date: must be formatted as YYYY-MM-DD")
+	ErrInvalidPeriod      = errors.New("invalid period: from must not be after to")
+	ErrTooManyBuckets     = fmt.Errorf("invalid period: a summary may span at most %d buckets", MaxBuckets)
+)
+
+// SummaryQuery is the period a summary covers and how it is bucketed.
+// From and To are whole days in Location, both inclusive.
+type SummaryQuery struct {
+	From        time.Time
+	To          time.Time
+	Granularity Granularity
+	Location    *time.Location
+}
+
+// ParseSummaryQuery builds a query from request values. Empty values fall back
+// to the current calendar year in the given timezone, monthly buckets and UTC.
+// Timezones whose offset is not a whole number of hours at some point of the
+// period are rejected.
+func ParseSummaryQuery(from, to, granularity, timezone string, now time.Time) (SummaryQuery, error) {
+	query := SummaryQuery{
+		Granularity: GranularityMonth,
+		Location:    time.UTC,
+	}
+
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return SummaryQuery{}, ErrInvalidTimezone
+		}
+		query.Location = loc
+	}
+
+	if granularity != "" {
+		query.Granularity = Granularity(granularity)
+		switch query.Granularity {
+		case GranularityDay, GranularityWeek, GranularityMonth, GranularityYear:
+		default:
+			return SummaryQuery{}, ErrInvalidGranularity
+		}
+	}
+
+	year := now.In(query.Location).Year()
+	query.From = time.Date(year, time.January, 1, 0, 0, 0, 0, query.Location)
+	query.To = time.Date(year, time.December, 31, 0, 0, 0, 0, query.Location)
+
+	var err error
+	if from != "" {
+		if query.From, err = time.ParseInLocation(DateLayout, from, query.Location); err != nil {
+			return SummaryQuery{}, ErrInvalidDate
+		}
+	}
+	if to != "" {
+		if query.To, err = time.ParseInLocation(DateLayout, to, query.Location); err != nil {
+			return SummaryQuery{}, ErrInvalidDate
+		}
+	}
+
+	if query.From.After(query.To) {
+		return SummaryQuery{}, ErrInvalidPeriod
+	}
+	// Counters cover UTC hours, which a partial-hour offset splits across
+	// days, so such zones could not be bucketed exactly
+	if !query.wholeHourOffsets() {
+		return SummaryQuery{}, ErrPartialHourOffset
+	}
+	if len(query.Buckets()) > MaxBuckets {
+		return SummaryQuery{}, ErrTooManyBuckets
+	}
+	return query, nil
+}
+
+// Range returns the first and last instant covered by the query
+func (q SummaryQuery) Range() (time.Time, time.Time) {
+	start := time.Date(q.From.Year(), q.From.Month(), q.From.Day(), 0, 0, 0, 0, q.Location)
+	end := time.Date(q.To.Year(), q.To.Month(), q.To.Day()+1, 0, 0, 0, 0, q.Location)
+	return start, end.Add(-time.Nanosecond)
+}
+
+// wholeHourOffsets reports whether every offset the location uses during the
+// period is a whole number of hours
+func (q SummaryQuery) wholeHourOffsets() bool {
+	start, end := q.Range()
+	for t := start; !t.After(end); {
+		if _, offset := t.Zone(); offset%int(CounterResolution/time.Second) != 0 {
+			return false
+		}
+		_, next := t.ZoneBounds()
+		if next.IsZero() {
+			break
+		}
+		t = next
+	}
+	return true
+}
+
+// BucketKey returns the bucket t falls into, evaluated in the query's location.
+// Weeks are ISO 8601 weeks starting on Monday.
+func (q SummaryQuery) BucketKey(t time.Time) string {
+	t = t.In(q.Location)
+	switch q.Granularity {
+	case GranularityDay:
+		return t.Format(DateLayout)
+	case GranularityWeek:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	case GranularityYear:
+		return t.Format("2006")
+	default:
+		return t.Format("2006-01")
+	}
+}
+
+// Buckets returns every bucket key in the period in chronological order
+func (q SummaryQuery) Buckets() []string {
+	start, end := q.Range()
+
+	cursor := start
+	step := func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	switch q.Granularity {
+	case GranularityWeek:
+		offset := (int(cursor.Weekday()) + 6) % 7 // days since Monday
+		cursor = cursor.AddDate(0, 0, -offset)
+		step = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	case GranularityMonth:
+		cursor = time.Date(cursor.Year(), cursor.Month(), 1, 0, 0, 0, 0, q.Location)
+		step = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	case GranularityYear:
+		cursor = time.Date(cursor.Year(), time.January, 1, 0, 0, 0, 0, q.Location)
+		step = func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }
+	}
+
+	var buckets []string
+	for ; !cursor.After(end); cursor = step(cursor) {
+		buckets = append(buckets, q.BucketKey(cursor))
+		if len(buckets) > MaxBuckets {
+			break
+		}
+	}
+	return buckets
+}
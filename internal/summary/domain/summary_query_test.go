@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSummaryQuery_Defaults(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	query, err := ParseSummaryQuery("", "", "", "", now)
+	assert.NoError(t, err)
+	assert.Equal(t, GranularityMonth, query.Granularity)
+	assert.Equal(t, time.UTC, query.Location)
+
+	start, end := query.Range()
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2025, 12, 31, 23, 59, 59, 999999999, time.UTC), end)
+	assert.Len(t, query.Buckets(), 12)
+}
+
+func TestParseSummaryQuery_Errors(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		from, to    string
+		granularity string
+		timezone    string
+		wantErr     error
+	}{
+		{name: "bad granularity", granularity: "hour", wantErr: ErrInvalidGranularity},
+		{name: "bad timezone", timezone: "Mars/Olympus", wantErr: ErrInvalidTimezone},
+		{name: "half hour offset", timezone: "Asia/Kolkata", wantErr: ErrPartialHourOffset},
+		{name: "quarter hour offset", timezone: "Asia/Kathmandu", wantErr: ErrPartialHourOffset},
+		{name: "half hour offset with daylight saving", from: "2025-01-01", to: "2025-01-31", timezone: "Australia/Adelaide", wantErr: ErrPartialHourOffset},
+		{name: "bad from", from: "2025/01/01", wantErr: ErrInvalidDate},
+		{name: "bad to", to: "tomorrow", wantErr: ErrInvalidDate},
+		{name: "from after to", from: "2025-02-01", to: "2025-01-01", wantErr: ErrInvalidPeriod},
+		{name: "too many buckets", from: "2000-01-01", to: "2025-01-01", granularity: "day", wantErr: ErrTooManyBuckets},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSummaryQuery(tt.from, tt.to, tt.granularity, tt.timezone, now)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestSummaryQuery_Buckets(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		from, to    string
+		granularity string
+		want        []string
+	}{
+		{
+			name: "days", from: "2025-02-27", to: "2025-03-02", granularity: "day",
+			want: []string{"2025-02-27", "2025-02-28", "2025-03-01", "2025-03-02"},
+		},
+		{
+			name: "iso weeks across new year", from: "2024-12-25", to: "2025-01-08", granularity: "week",
+			want: []string{"2024-W52", "2025-W01", "2025-W02"},
+		},
+		{
+			name: "months", from: "2024-11-15", to: "2025-02-01", granularity: "month",
+			want: []string{"2024-11", "2024-12", "2025-01", "2025-02"},
+		},
+		{
+			name: "years", from: "2023-06-01", to: "2025-01-01", granularity: "year",
+			want: []string{"2023", "2024", "2025"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := ParseSummaryQuery(tt.from, tt.to, tt.granularity, "", now)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, query.Buckets())
+		})
+	}
+}
+
+func TestSummaryQuery_BucketKeyUsesTimezone(t *testing.T) {
+	query, err := ParseSummaryQuery("2025-01-01", "2025-12-31", "day", "Asia/Jakarta", time.Now())
+	assert.NoError(t, err)
+
+	// 20:00 UTC on Jan 31 is already Feb 1 in Jakarta (UTC+7)
+	createdAt := time.Date(2025, 1, 31, 20, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2025-02-01", query.BucketKey(createdAt))
+
+	start, _ := query.Range()
+	assert.Equal(t, time.Date(2024, 12, 31, 17, 0, 0, 0, time.UTC), start.UTC())
+}
+
+func TestSummaryQuery_PartialHourOffsetBuckets(t *testing.T) {
+	// The counter for 18:00 UTC on Jan 31 covers 23:30 to 00:30 in Kolkata
+	// (UTC+5:30), so it belongs to two days
+	_, err := ParseSummaryQuery("2025-01-31", "2025-02-01", "day", "Asia/Kolkata", time.Now())
+	assert.ErrorIs(t, err, ErrPartialHourOffset)
+
+	// Newfoundland is on a half-hour offset in winter and summer alike
+	_, err = ParseSummaryQuery("2025-07-01", "2025-07-31", "day", "America/St_Johns", time.Now())
+	assert.ErrorIs(t, err, ErrPartialHourOffset)
+
+	// Whole-hour zones are accepted across daylight saving changes
+	query, err := ParseSummaryQuery("2025-01-01", "2025-12-31", "month", "America/New_York", time.Now())
+	assert.NoError(t, err)
+	assert.Len(t, query.Buckets(), 12)
+}
+
+func TestNewSummaryData_ZeroFilled(t *testing.T) {
+	query, err := ParseSummaryQuery("2025-01-01", "2025-03-31", "month", "", time.Now())
+	assert.NoError(t, err)
+
+	data := NewSummaryData(query)
+	assert.Equal(t, []string{"2025-01", "2025-02", "2025-03"}, data.Buckets)
+	assert.Equal(t, map[string]int64{"2025-01": 0, "2025-02": 0, "2025-03": 0}, data.Likes)
+	assert.Equal(t, "2025-01-01", data.From)
+	assert.Equal(t, "2025-03-31", data.To)
+	assert.Equal(t, "UTC", data.Timezone)
+}
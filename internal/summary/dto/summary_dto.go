@@ -1,5 +1,9 @@
 package dto
 
 type SummaryRequest struct {
-	Username string `json:"username" binding:"required"`
+	Username    string `json:"username"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Granularity string `json:"granularity"`
+	Timezone    string `json:"timezone"`
 }
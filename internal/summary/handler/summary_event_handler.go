@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/ynwd/awesome-blog/internal/summary/domain"
+	"github.com/ynwd/awesome-blog/internal/summary/service"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/pubsub"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+)
+
+// SummaryEventHandler keeps the activity counters up to date from the
+// created and deleted events of posts, comments and likes. Tombstoned
+// comments are reported as COMMENT_DELETED too.
+type SummaryEventHandler struct {
+	service   service.CounterService
+	processed utils.ProcessedEvents
+}
+
+func NewSummaryEventHandler(service service.CounterService, processed utils.ProcessedEvents) *SummaryEventHandler {
+	return &SummaryEventHandler{
+		service:   service,
+		processed: processed,
+	}
+}
+
+func (h *SummaryEventHandler) Handle(ctx context.Context, event module.Event[domain.ActivityEvent]) error {
+	return utils.HandleOnce(ctx, h.processed, "summary", event.ID, func() error {
+		return h.handle(ctx, event)
+	})
+}
+
+func (h *SummaryEventHandler) handle(ctx context.Context, event module.Event[domain.ActivityEvent]) error {
+	payload := event.Payload
+
+	// Count the activity when it happened rather than when it was reported
+	at := payload.CreatedAt
+	if at.IsZero() {
+		at = event.Timestamp
+	}
+
+	var err error
+	switch event.Type {
+	case module.PostCreatedEvent:
+		err = h.service.RecordPost(ctx, payload.ID, payload.Username, at)
+	case module.PostDeletedEvent:
+		err = h.service.RemovePost(ctx, payload.ID)
+	case module.CommentCreatedEvent:
+		err = h.service.RecordComment(ctx, payload.ID, payload.PostID, payload.Username, at)
+	case module.CommentDeletedEvent:
+		err = h.service.RemoveComment(ctx, payload.ID)
+	case module.LikeCreatedEvent:
+		err = h.service.RecordLike(ctx, payload.PostID, payload.UsernameFrom, at)
+	case module.LikeDeletedEvent:
+		err = h.service.RemoveLike(ctx, payload.PostID, payload.UsernameFrom)
+	}
+	if errors.Is(err, domain.ErrPostNotFound) {
+		log.Printf("Not counting %s event for missing post %s", event.Type, payload.PostID)
+		// Retrying cannot make the post appear
+		return pubsub.Permanent(err)
+	}
+	if errors.Is(err, service.ErrInvalidID) || errors.Is(err, service.ErrInvalidUsername) {
+		log.Printf("Not counting invalid %s event %s: %v", event.Type, event.ID, err)
+		// The payload is the same on every delivery
+		return pubsub.Permanent(err)
+	}
+	if err != nil {
+		log.Printf("Error updating activity counters: %v", err)
+		return err
+	}
+
+	return nil
+}
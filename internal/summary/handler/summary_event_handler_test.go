@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ynwd/awesome-blog/internal/summary/domain"
+	"github.com/ynwd/awesome-blog/internal/summary/service"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/pubsub"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+)
+
+type mockCounterService struct {
+	calls []string
+	err   error
+}
+
+func (m *mockCounterService) RecordPost(ctx context.Context, postID, username string, at time.Time) error {
+	m.calls = append(m.calls, "post:"+username+":"+at.Format(time.RFC3339))
+	return m.err
+}
+
+func (m *mockCounterService) RemovePost(ctx context.Context, postID string) error {
+	m.calls = append(m.calls, "unpost:"+postID)
+	return m.err
+}
+
+func (m *mockCounterService) RecordComment(ctx context.Context, commentID, postID, username string, at time.Time) error {
+	m.calls = append(m.calls, "comment:"+commentID+":"+postID+":"+username)
+	return m.err
+}
+
+func (m *mockCounterService) RemoveComment(ctx context.Context, commentID string) error {
+	m.calls = append(m.calls, "uncomment:"+commentID)
+	return m.err
+}
+
+func (m *mockCounterService) RecordLike(ctx context.Context, postID, username string, at time.Time) error {
+	m.calls = append(m.calls, "like:"+postID+":"+username)
+	return m.err
+}
+
+func (m *mockCounterService) RemoveLike(ctx context.Context, postID, username string) error {
+	m.calls = append(m.calls, "unlike:"+postID+":"+username)
+	return m.err
+}
+
+func TestSummaryEventHandler_Handle(t *testing.T) {
+	timestamp := time.Date(2025, 2, 1, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		event         module.Event[domain.ActivityEvent]
+		err           error
+		wantCalls     []string
+		wantErr       bool
+		wantPermanent bool
+	}{
+		{
+			name: "post event counts a post",
+			event: module.Event[domain.ActivityEvent]{
+				Type:      module.PostCreatedEvent,
+				Payload:   domain.ActivityEvent{Username: "alice"},
+				Timestamp: timestamp,
+			},
+			wantCalls: []string{"post:alice:2025-02-01T10:30:00Z"},
+		},
+		{
+			name: "post is counted when it was created",
+			event: module.Event[domain.ActivityEvent]{
+				Type:      module.PostCreatedEvent,
+				Payload:   domain.ActivityEvent{Username: "alice", CreatedAt: timestamp.Add(-time.Hour)},
+				Timestamp: timestamp,
+			},
+			wantCalls: []string{"post:alice:2025-02-01T09:30:00Z"},
+		},
+		{
+			name: "comment event counts a comment",
+			event: module.Event[domain.ActivityEvent]{
+				Type:      module.CommentCreatedEvent,
+				Payload:   domain.ActivityEvent{ID: "comment1", PostID: "post1", Username: "bob"},
+				Timestamp: timestamp,
+			},
+			wantCalls: []string{"comment:comment1:post1:bob"},
+		},
+		{
+			name: "deleted post is uncounted",
+			event: module.Event[domain.ActivityEvent]{
+				Type:      module.PostDeletedEvent,
+				Payload:   domain.ActivityEvent{ID: "post1", Username: "alice"},
+				Timestamp: timestamp,
+			},
+			wantCalls: []string{"unpost:post1"},
+		},
+		{
+			name: "deleted comment is uncounted",
+			event: module.Event[domain.ActivityEvent]{
+				Type:      module.CommentDeletedEvent,
+				Payload:   domain.ActivityEvent{ID: "comment1", PostID: "post1"},
+				Timestamp: timestamp,
+			},
+			wantCalls: []string{"uncomment:comment1"},
+		},
+		{
+			name: "like event counts a like",
+			event: module.Event[domain.ActivityEvent]{
+				Type:      module.LikeCreatedEvent,
+				Payload:   domain.ActivityEvent{PostID: "post1", UsernameFrom: "bob"},
+				Timestamp: timestamp,
+			},
+			wantCalls: []string{"like:post1:bob"},
+		},
+		{
+			name: "unlike event removes a like",
+			event: module.Event[domain.ActivityEvent]{
+				Type:      module.LikeDeletedEvent,
+				Payload:   domain.ActivityEvent{PostID: "post1", UsernameFrom: "bob"},
+				Timestamp: timestamp,
+			},
+			wantCalls: []string{"unlike:post1:bob"},
+		},
+		{
+			name: "missing post returns error",
+			event: module.Event[domain.ActivityEvent]{
+				Type:      module.LikeCreatedEvent,
+				Payload:   domain.ActivityEvent{PostID: "gone", UsernameFrom: "bob"},
+				Timestamp: timestamp,
+			},
+			err:           domain.ErrPostNotFound,
+			wantCalls:     []string{"like:gone:bob"},
+			wantErr:       true,
+			wantPermanent: true,
+		},
+		{
+			name: "event without an ID is dead-lettered",
+			event: module.Event[domain.ActivityEvent]{
+				Type:      module.PostDeletedEvent,
+				Timestamp: timestamp,
+			},
+			err:           service.ErrInvalidID,
+			wantCalls:     []string{"unpost:"},
+			wantErr:       true,
+			wantPermanent: true,
+		},
+		{
+			name: "event without a user is dead-lettered",
+			event: module.Event[domain.ActivityEvent]{
+				Type:      module.LikeCreatedEvent,
+				Payload:   domain.ActivityEvent{PostID: "post1"},
+				Timestamp: timestamp,
+			},
+			err:           service.ErrInvalidUsername,
+			wantCalls:     []string{"like:post1:"},
+			wantErr:       true,
+			wantPermanent: true,
+		},
+		{
+			name: "service error returns error",
+			event: module.Event[domain.ActivityEvent]{
+				Type:      module.PostCreatedEvent,
+				Payload:   domain.ActivityEvent{Username: "alice"},
+				Timestamp: timestamp,
+			},
+			err:       errors.New("service error"),
+			wantCalls: []string{"post:alice:2025-02-01T10:30:00Z"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &mockCounterService{err: tt.err}
+			handler := NewSummaryEventHandler(mockService, utils.NewMemoryProcessedEvents(time.Hour))
+
+			err := handler.Handle(context.Background(), tt.event)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.wantPermanent, pubsub.IsPermanent(err))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantCalls, mockService.calls)
+		})
+	}
+}
+
+type recordingSink struct {
+	letters chan pubsub.DeadLetter
+}
+
+func (s *recordingSink) Put(ctx context.Context, letter pubsub.DeadLetter) error {
+	s.letters <- letter
+	return nil
+}
+
+func TestSummaryEventHandler_MalformedEventDeadLettersAtOnce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sink := &recordingSink{letters: make(chan pubsub.DeadLetter, 1)}
+	client := pubsub.NewMemoryClient(
+		pubsub.WithRetryPolicy(pubsub.RetryPolicy{MaxAttempts: 5, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}),
+		pubsub.WithDeadLetterSink(sink),
+	)
+	defer client.Close()
+
+	mockService := &mockCounterService{err: service.ErrInvalidID}
+	handler := NewSummaryEventHandler(mockService, utils.NewMemoryProcessedEvents(time.Hour))
+	registry := module.NewEventRegistry()
+	module.Handle(registry, module.PostDeletedEvent, module.PostDeletedEventVersion, handler.Handle)
+
+	go client.Subscribe(ctx, "sub", func(data []byte) error {
+		return registry.Dispatch(ctx, data)
+	})
+	time.Sleep(10 * time.Millisecond)
+
+	// A post deleted event without the post ID
+	assert.NoError(t, client.Publish(ctx, module.NewEvent(module.PostDeletedEvent, domain.ActivityEvent{})))
+
+	select {
+	case letter := <-sink.letters:
+		assert.Equal(t, 1, letter.Attempts)
+		assert.Contains(t, letter.Error, service.ErrInvalidID.Error())
+	case <-time.After(2 * time.Second):
+		t.Fatal("malformed event was not dead-lettered")
+	}
+	assert.Len(t, mockService.calls, 1)
+}
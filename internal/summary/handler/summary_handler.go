@@ -2,10 +2,13 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ynwd/awesome-blog/internal/summary/domain"
 	"github.com/ynwd/awesome-blog/internal/summary/dto"
 	"github.com/ynwd/awesome-blog/internal/summary/service"
+	"github.com/ynwd/awesome-blog/pkg/middleware"
 	"github.com/ynwd/awesome-blog/pkg/res"
 )
 
@@ -19,18 +22,32 @@ func NewSummaryHandler(summaryService service.SummaryService) *SummaryHandler {
 	}
 }
 
-func (h *SummaryHandler) GetYearlySummary(c *gin.Context) {
+// GetSummary returns the user's activity for the requested period. Without a
+// period it covers the current calendar year in monthly UTC buckets.
+func (h *SummaryHandler) GetSummary(c *gin.Context) {
 	var req dto.SummaryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, res.Error(err.Error()))
 		return
 	}
 
-	summary, err := h.summaryService.GetYearlySummary(c.Request.Context(), req.Username)
+	username, err := middleware.ActingUser(c, req.Username)
+	if err != nil {
+		c.JSON(middleware.IdentityErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	query, err := domain.ParseSummaryQuery(req.From, req.To, req.Granularity, req.Timezone, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, res.Error(err.Error()))
+		return
+	}
+
+	summary, err := h.summaryService.GetSummary(c.Request.Context(), username, query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, res.Error(err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, res.Success(summary, "Yearly summary retrieved successfully"))
+	c.JSON(http.StatusOK, res.Success(summary, "Summary retrieved successfully"))
 }
@@ -17,17 +17,17 @@ import (
 )
 
 type mockSummaryService struct {
-	getYearlySummaryFunc func(ctx context.Context, username string) (*domain.SummaryData, error)
+	getSummaryFunc func(ctx context.Context, username string, query domain.SummaryQuery) (*domain.SummaryData, error)
 }
 
-func (m *mockSummaryService) GetYearlySummary(ctx context.Context, username string) (*domain.SummaryData, error) {
-	if m.getYearlySummaryFunc != nil {
-		return m.getYearlySummaryFunc(ctx, username)
+func (m *mockSummaryService) GetSummary(ctx context.Context, username string, query domain.SummaryQuery) (*domain.SummaryData, error) {
+	if m.getSummaryFunc != nil {
+		return m.getSummaryFunc(ctx, username, query)
 	}
 	return nil, nil
 }
 
-func TestSummaryHandler_GetYearlySummary(t *testing.T) {
+func TestSummaryHandler_GetSummary(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	tests := []struct {
@@ -53,7 +53,7 @@ func TestSummaryHandler_GetYearlySummary(t *testing.T) {
 				Username: "testuser",
 			},
 			setupMock: func(m *mockSummaryService) {
-				m.getYearlySummaryFunc = func(ctx context.Context, username string) (*domain.SummaryData, error) {
+				m.getSummaryFunc = func(ctx context.Context, username string, query domain.SummaryQuery) (*domain.SummaryData, error) {
 					return nil, errors.New("service error")
 				}
 			},
@@ -63,13 +63,80 @@ func TestSummaryHandler_GetYearlySummary(t *testing.T) {
 				Message: "service error",
 			},
 		},
+		{
+			name: "username mismatch",
+			payload: dto.SummaryRequest{
+				Username: "someone-else",
+			},
+			setupMock:  func(m *mockSummaryService) {},
+			wantStatus: http.StatusForbidden,
+			wantRes: res.Response{
+				Status:  "error",
+				Message: "username does not match the authenticated user",
+			},
+		},
+		{
+			name: "invalid granularity",
+			payload: dto.SummaryRequest{
+				Granularity: "hour",
+			},
+			setupMock:  func(m *mockSummaryService) {},
+			wantStatus: http.StatusBadRequest,
+			wantRes: res.Response{
+				Status:  "error",
+				Message: "invalid granularity: must be day, week, month or year",
+			},
+		},
+		{
+			name: "invalid timezone",
+			payload: dto.SummaryRequest{
+				Timezone: "Mars/Olympus",
+			},
+			setupMock:  func(m *mockSummaryService) {},
+			wantStatus: http.StatusBadRequest,
+			wantRes: res.Response{
+				Status:  "error",
+				Message: "invalid timezone: must be an IANA time zone name",
+			},
+		},
+		{
+			name: "custom period is passed to the service",
+			payload: dto.SummaryRequest{
+				From:        "2025-01-01",
+				To:          "2025-01-02",
+				Granularity: "day",
+				Timezone:    "Asia/Jakarta",
+			},
+			setupMock: func(m *mockSummaryService) {
+				m.getSummaryFunc = func(ctx context.Context, username string, query domain.SummaryQuery) (*domain.SummaryData, error) {
+					assert.Equal(t, domain.GranularityDay, query.Granularity)
+					assert.Equal(t, "Asia/Jakarta", query.Location.String())
+					return domain.NewSummaryData(query), nil
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantRes: res.Response{
+				Status:  "success",
+				Message: "Summary retrieved successfully",
+				Data: map[string]interface{}{
+					"from":        "2025-01-01",
+					"to":          "2025-01-02",
+					"granularity": "day",
+					"timezone":    "Asia/Jakarta",
+					"buckets":     []interface{}{"2025-01-01", "2025-01-02"},
+					"likes":       map[string]interface{}{"2025-01-01": float64(0), "2025-01-02": float64(0)},
+					"comments":    map[string]interface{}{"2025-01-01": float64(0), "2025-01-02": float64(0)},
+					"posts":       map[string]interface{}{"2025-01-01": float64(0), "2025-01-02": float64(0)},
+				},
+			},
+		},
 		{
 			name: "success get summary",
 			payload: dto.SummaryRequest{
 				Username: "testuser",
 			},
 			setupMock: func(m *mockSummaryService) {
-				m.getYearlySummaryFunc = func(ctx context.Context, username string) (*domain.SummaryData, error) {
+				m.getSummaryFunc = func(ctx context.Context, username string, query domain.SummaryQuery) (*domain.SummaryData, error) {
 					return &domain.SummaryData{
 						Likes:    map[string]int64{"2025-02": 1},
 						Comments: map[string]int64{"2025-02": 1},
@@ -80,7 +147,7 @@ func TestSummaryHandler_GetYearlySummary(t *testing.T) {
 			wantStatus: http.StatusOK,
 			wantRes: res.Response{
 				Status:  "success",
-				Message: "Yearly summary retrieved successfully",
+				Message: "Summary retrieved successfully",
 				Data: map[string]interface{}{
 					"likes":    map[string]interface{}{"2025-02": float64(1)},
 					"comments": map[string]interface{}{"2025-02": float64(1)},
@@ -93,11 +160,14 @@ func TestSummaryHandler_GetYearlySummary(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user_id", "testuser")
+			})
 			mockService := &mockSummaryService{}
 			tt.setupMock(mockService)
 
 			handler := NewSummaryHandler(mockService)
-			router.POST("/summary", handler.GetYearlySummary)
+			router.POST("/summary", handler.GetSummary)
 
 			payloadBytes, _ := json.Marshal(tt.payload)
 			req := httptest.NewRequest(http.MethodPost, "/summary", bytes.NewBuffer(payloadBytes))
@@ -0,0 +1,399 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/ynwd/awesome-blog/internal/summary/domain"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// CountersCollection holds one domain.ActivityCounter per user and hour
+	CountersCollection = "activity_counters"
+
+	// CountedPostsCollection holds one domain.CountedPost per post in the counters
+	CountedPostsCollection = "activity_counted_posts"
+
+	// CountedCommentsCollection holds one domain.CountedComment per comment in the counters
+	CountedCommentsCollection = "activity_counted_comments"
+
+	// CountedLikesCollection holds one domain.CountedLike per like in the counters
+	CountedLikesCollection = "activity_counted_likes"
+)
+
+// CounterRepository maintains the hourly activity counters the summaries are
+// read from. An empty author means the activity is not credited to anyone
+// as received engagement. Each post, comment and like is counted once and
+// removed once, however often its events are delivered.
+type CounterRepository interface {
+	AddPost(ctx context.Context, postID, username string, at time.Time) error
+	RemovePost(ctx context.Context, postID string) error
+	AddComment(ctx context.Context, commentID, postID, username, author string, at time.Time) error
+	RemoveComment(ctx context.Context, commentID string) error
+	AddLike(ctx context.Context, postID, username, author string, at time.Time) error
+	RemoveLike(ctx context.Context, postID, username string) error
+	Rebuild(ctx context.Context) (int, error)
+}
+
+type counterFirestore struct {
+	client *firestore.Client
+}
+
+func NewCounterRepository(client *firestore.Client) CounterRepository {
+	return &counterFirestore{
+		client: client,
+	}
+}
+
+func (r *counterFirestore) AddPost(ctx context.Context, postID, username string, at time.Time) error {
+	counted := domain.CountedPost{Username: username, Hour: domain.CounterHour(at)}
+	ref := r.client.Collection(CountedPostsCollection).Doc(postID)
+
+	return r.countOnce(ctx, ref, counted, func(tx *firestore.Transaction) error {
+		return tx.Set(r.counterRef(username, at), increment(username, at, "posts", 1), firestore.MergeAll)
+	})
+}
+
+// RemovePost reverses a counted post, and takes the likes and comments it
+// received out of its author's received engagement, as a rebuild no longer
+// credits engagement on a missing post. The likes and comments themselves
+// still count for the users who wrote them.
+func (r *counterFirestore) RemovePost(ctx context.Context, postID string) error {
+	ref := r.client.Collection(CountedPostsCollection).Doc(postID)
+
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		postCounted := err == nil
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		likes, err := tx.Documents(r.client.Collection(CountedLikesCollection).Where("post_id", "==", postID)).GetAll()
+		if err != nil {
+			return err
+		}
+		comments, err := tx.Documents(r.client.Collection(CountedCommentsCollection).Where("post_id", "==", postID)).GetAll()
+		if err != nil {
+			return err
+		}
+
+		if postCounted {
+			var counted domain.CountedPost
+			if err := doc.DataTo(&counted); err != nil {
+				return err
+			}
+			if err := tx.Delete(ref); err != nil {
+				return err
+			}
+			if err := tx.Set(r.counterRef(counted.Username, counted.Hour), increment(counted.Username, counted.Hour, "posts", -1), firestore.MergeAll); err != nil {
+				return err
+			}
+		}
+		if err := r.uncredit(tx, likes, "received_likes"); err != nil {
+			return err
+		}
+		return r.uncredit(tx, comments, "received_comments")
+	})
+}
+
+// uncredit takes the counted likes or comments out of their post author's
+// received engagement and clears the author on them, so a later unlike or
+// comment deletion does not take them out again
+func (r *counterFirestore) uncredit(tx *firestore.Transaction, docs []*firestore.DocumentSnapshot, field string) error {
+	for _, doc := range docs {
+		author, _ := doc.Data()["author"].(string)
+		hour, _ := doc.Data()["hour"].(time.Time)
+		if author == "" {
+			continue
+		}
+		if err := tx.Update(doc.Ref, []firestore.Update{{Path: "author", Value: ""}}); err != nil {
+			return err
+		}
+		if err := tx.Set(r.counterRef(author, hour), increment(author, hour, field, -1), firestore.MergeAll); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *counterFirestore) AddComment(ctx context.Context, commentID, postID, username, author string, at time.Time) error {
+	counted := domain.CountedComment{
+		PostID:   postID,
+		Username: username,
+		Author:   author,
+		Hour:     domain.CounterHour(at),
+	}
+	ref := r.client.Collection(CountedCommentsCollection).Doc(commentID)
+
+	return r.countOnce(ctx, ref, counted, func(tx *firestore.Transaction) error {
+		return r.applyComment(tx, counted, 1)
+	})
+}
+
+// RemoveComment reverses a counted comment in the hour it was counted.
+// Comments that were never counted are ignored.
+func (r *counterFirestore) RemoveComment(ctx context.Context, commentID string) error {
+	ref := r.client.Collection(CountedCommentsCollection).Doc(commentID)
+
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var counted domain.CountedComment
+		if err := doc.DataTo(&counted); err != nil {
+			return err
+		}
+		if err := tx.Delete(ref); err != nil {
+			return err
+		}
+		return r.applyComment(tx, counted, -1)
+	})
+}
+
+func (r *counterFirestore) applyComment(tx *firestore.Transaction, counted domain.CountedComment, delta int64) error {
+	err := tx.Set(r.counterRef(counted.Username, counted.Hour), increment(counted.Username, counted.Hour, "comments", delta), firestore.MergeAll)
+	if err != nil {
+		return err
+	}
+	if counted.Author == "" {
+		return nil
+	}
+	return tx.Set(r.counterRef(counted.Author, counted.Hour), increment(counted.Author, counted.Hour, "received_comments", delta), firestore.MergeAll)
+}
+
+// AddLike counts the like unless it has already been counted, so repeated
+// LIKE events for the same post and user leave the counters unchanged
+func (r *counterFirestore) AddLike(ctx context.Context, postID, username, author string, at time.Time) error {
+	counted := domain.CountedLike{
+		PostID:   postID,
+		Username: username,
+		Author:   author,
+		Hour:     domain.CounterHour(at),
+	}
+	ref := r.client.Collection(CountedLikesCollection).Doc(domain.CountedLikeID(postID, username))
+
+	return r.countOnce(ctx, ref, counted, func(tx *firestore.Transaction) error {
+		return r.applyLike(tx, counted, 1)
+	})
+}
+
+// countOnce creates the marker and applies the counts in one transaction,
+// unless the marker already exists
+func (r *counterFirestore) countOnce(ctx context.Context, ref *firestore.DocumentRef, marker interface{}, apply func(tx *firestore.Transaction) error) error {
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		_, err := tx.Get(ref)
+		if err == nil {
+			return nil
+		}
+		if status.Code(err) != codes.NotFound {
+			return err
+		}
+
+		if err := tx.Create(ref, marker); err != nil {
+			return err
+		}
+		return apply(tx)
+	})
+}
+
+// RemoveLike reverses a counted like in the hour it was counted. Likes that
+// were never counted are ignored.
+func (r *counterFirestore) RemoveLike(ctx context.Context, postID, username string) error {
+	ref := r.client.Collection(CountedLikesCollection).Doc(domain.CountedLikeID(postID, username))
+
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var counted domain.CountedLike
+		if err := doc.DataTo(&counted); err != nil {
+			return err
+		}
+		if err := tx.Delete(ref); err != nil {
+			return err
+		}
+		return r.applyLike(tx, counted, -1)
+	})
+}
+
+func (r *counterFirestore) applyLike(tx *firestore.Transaction, counted domain.CountedLike, delta int64) error {
+	err := tx.Set(r.counterRef(counted.Username, counted.Hour), increment(counted.Username, counted.Hour, "likes", delta), firestore.MergeAll)
+	if err != nil {
+		return err
+	}
+	if counted.Author == "" {
+		return nil
+	}
+	return tx.Set(r.counterRef(counted.Author, counted.Hour), increment(counted.Author, counted.Hour, "received_likes", delta), firestore.MergeAll)
+}
+
+// Rebuild discards every counter and recomputes them from the posts, comments
+// and likes collections. It returns the number of counter documents written.
+// Events consumed while a rebuild runs may be lost or counted twice, so the
+// event subscription should be stopped first.
+func (r *counterFirestore) Rebuild(ctx context.Context) (int, error) {
+	for _, collection := range []string{CountersCollection, CountedPostsCollection, CountedCommentsCollection, CountedLikesCollection} {
+		if err := r.deleteAll(ctx, collection); err != nil {
+			return 0, err
+		}
+	}
+
+	counters := make(map[string]*domain.ActivityCounter)
+	counter := func(username string, at time.Time) *domain.ActivityCounter {
+		id := domain.CounterID(username, at)
+		c, ok := counters[id]
+		if !ok {
+			c = &domain.ActivityCounter{Username: username, Hour: domain.CounterHour(at)}
+			counters[id] = c
+		}
+		return c
+	}
+
+	bulk := r.client.BulkWriter(ctx)
+
+	// Posts, remembering their authors for the engagement they received.
+	// Posts, comments and likes are each recorded as counted so their
+	// deletion can reverse them.
+	authors := make(map[string]string)
+	err := r.scan(ctx, "posts", "username", func(doc *firestore.DocumentSnapshot, username string, at time.Time) {
+		authors[doc.Ref.ID] = username
+		counter(username, at).Posts++
+		bulk.Set(r.client.Collection(CountedPostsCollection).Doc(doc.Ref.ID), domain.CountedPost{Username: username, Hour: domain.CounterHour(at)})
+	})
+	if err != nil {
+		bulk.End()
+		return 0, err
+	}
+
+	// Comments, skipping deleted ones that no longer have a user
+	err = r.scan(ctx, "comments", "username", func(doc *firestore.DocumentSnapshot, username string, at time.Time) {
+		counted := domain.CountedComment{
+			Username: username,
+			Author:   receivedBy(authors, doc, username),
+			Hour:     domain.CounterHour(at),
+		}
+		counted.PostID, _ = doc.Data()["post_id"].(string)
+
+		counter(username, at).Comments++
+		if counted.Author != "" {
+			counter(counted.Author, at).ReceivedComments++
+		}
+		bulk.Set(r.client.Collection(CountedCommentsCollection).Doc(doc.Ref.ID), counted)
+	})
+	if err != nil {
+		bulk.End()
+		return 0, err
+	}
+
+	// Likes
+	err = r.scan(ctx, "likes", "username_from", func(doc *firestore.DocumentSnapshot, username string, at time.Time) {
+		counted := domain.CountedLike{
+			Username: username,
+			Author:   receivedBy(authors, doc, username),
+			Hour:     domain.CounterHour(at),
+		}
+		counted.PostID, _ = doc.Data()["post_id"].(string)
+
+		counter(username, at).Likes++
+		if counted.Author != "" {
+			counter(counted.Author, at).ReceivedLikes++
+		}
+		bulk.Set(r.client.Collection(CountedLikesCollection).Doc(domain.CountedLikeID(counted.PostID, username)), counted)
+	})
+	if err != nil {
+		bulk.End()
+		return 0, err
+	}
+
+	for id, c := range counters {
+		if _, err := bulk.Set(r.client.Collection(CountersCollection).Doc(id), c); err != nil {
+			bulk.End()
+			return 0, err
+		}
+	}
+	bulk.End()
+
+	return len(counters), nil
+}
+
+// scan calls fn for every document in the collection that has a user and a
+// creation time
+func (r *counterFirestore) scan(ctx context.Context, collection, userField string, fn func(doc *firestore.DocumentSnapshot, username string, at time.Time)) error {
+	iter := r.client.Collection(collection).Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		docData := doc.Data()
+		username, _ := docData[userField].(string)
+		createdAt, ok := docData["created_at"].(time.Time)
+		if username == "" || !ok {
+			continue
+		}
+		fn(doc, username, createdAt)
+	}
+}
+
+func (r *counterFirestore) deleteAll(ctx context.Context, collection string) error {
+	iter := r.client.Collection(collection).Select().Documents(ctx)
+	defer iter.Stop()
+
+	bulk := r.client.BulkWriter(ctx)
+	defer bulk.End()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := bulk.Delete(doc.Ref); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *counterFirestore) counterRef(username string, at time.Time) *firestore.DocumentRef {
+	return r.client.Collection(CountersCollection).Doc(domain.CounterID(username, at))
+}
+
+// increment returns the merge data adding delta to one counter field
+func increment(username string, at time.Time, field string, delta int64) map[string]interface{} {
+	return map[string]interface{}{
+		"username": username,
+		"hour":     domain.CounterHour(at),
+		field:      firestore.Increment(delta),
+	}
+}
+
+// receivedBy returns the author of the document's post when that is someone
+// other than username
+func receivedBy(authors map[string]string, doc *firestore.DocumentSnapshot, username string) string {
+	postID, _ := doc.Data()["post_id"].(string)
+	if author := authors[postID]; author != username {
+		return author
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ynwd/awesome-blog/internal/summary/domain"
+	"github.com/ynwd/awesome-blog/tests/helper"
+)
+
+func TestCounterRepository_Likes(t *testing.T) {
+	ctx := context.Background()
+	client := helper.SetupRepoClient(t)
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	testDate := time.Date(2025, 2, 1, 10, 30, 0, 0, time.UTC)
+	counters := NewCounterRepository(client)
+	summaries := NewSummaryRepository(client)
+	query, err := domain.ParseSummaryQuery("2025-02-01", "2025-02-01", "day", "", testDate)
+	assert.NoError(t, err)
+
+	// A repeated like is counted once
+	assert.NoError(t, counters.AddLike(ctx, "post1", "fan", "author", testDate))
+	assert.NoError(t, counters.AddLike(ctx, "post1", "fan", "author", testDate.Add(time.Hour)))
+
+	fan, err := summaries.GetUserSummary(ctx, "fan", query)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), fan.Likes["2025-02-01"])
+
+	author, err := summaries.GetUserSummary(ctx, "author", query)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), author.Received.Likes["2025-02-01"])
+
+	// Unliking reverses the like, and unliking again changes nothing
+	assert.NoError(t, counters.RemoveLike(ctx, "post1", "fan"))
+	assert.NoError(t, counters.RemoveLike(ctx, "post1", "fan"))
+
+	fan, err = summaries.GetUserSummary(ctx, "fan", query)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), fan.Likes["2025-02-01"])
+
+	author, err = summaries.GetUserSummary(ctx, "author", query)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), author.Received.Likes["2025-02-01"])
+}
+
+func TestCounterRepository_PostsAndComments(t *testing.T) {
+	ctx := context.Background()
+	client := helper.SetupRepoClient(t)
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	testDate := time.Date(2025, 2, 1, 10, 30, 0, 0, time.UTC)
+	counters := NewCounterRepository(client)
+
+	summaries := NewSummaryRepository(client)
+	query, err := domain.ParseSummaryQuery("2025-02-01", "2025-02-01", "day", "", testDate)
+	assert.NoError(t, err)
+
+	// Repeated events are counted once
+	assert.NoError(t, counters.AddPost(ctx, "post1", "author", testDate))
+	assert.NoError(t, counters.AddPost(ctx, "post1", "author", testDate))
+	assert.NoError(t, counters.AddComment(ctx, "comment1", "post1", "fan", "author", testDate))
+	assert.NoError(t, counters.AddComment(ctx, "comment1", "post1", "fan", "author", testDate))
+	assert.NoError(t, counters.AddComment(ctx, "comment2", "post1", "author", "", testDate))
+	assert.NoError(t, counters.AddComment(ctx, "comment3", "post1", "fan", "author", testDate))
+	assert.NoError(t, counters.AddLike(ctx, "post1", "fan", "author", testDate))
+
+	got, err := summaries.GetUserSummary(ctx, "author", query)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), got.Posts["2025-02-01"])
+	assert.Equal(t, int64(1), got.Comments["2025-02-01"])
+	assert.Equal(t, int64(2), got.Received.Comments["2025-02-01"])
+
+	// Deleting a comment reverses it once
+	assert.NoError(t, counters.RemoveComment(ctx, "comment1"))
+	assert.NoError(t, counters.RemoveComment(ctx, "comment1"))
+
+	fan, err := summaries.GetUserSummary(ctx, "fan", query)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), fan.Comments["2025-02-01"])
+
+	// Deleting the post uncounts it and the engagement it received, while the
+	// remaining comment and like still count for the fan
+	assert.NoError(t, counters.RemovePost(ctx, "post1"))
+	assert.NoError(t, counters.RemovePost(ctx, "post1"))
+
+	got, err = summaries.GetUserSummary(ctx, "author", query)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), got.Posts["2025-02-01"])
+	assert.Equal(t, int64(1), got.Comments["2025-02-01"])
+	assert.Equal(t, int64(0), got.Received.Comments["2025-02-01"])
+	assert.Equal(t, int64(0), got.Received.Likes["2025-02-01"])
+
+	// Later deletions no longer touch the author's received engagement
+	assert.NoError(t, counters.RemoveComment(ctx, "comment3"))
+	assert.NoError(t, counters.RemoveLike(ctx, "post1", "fan"))
+
+	got, err = summaries.GetUserSummary(ctx, "author", query)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), got.Received.Comments["2025-02-01"])
+	assert.Equal(t, int64(0), got.Received.Likes["2025-02-01"])
+
+	fan, err = summaries.GetUserSummary(ctx, "fan", query)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), fan.Comments["2025-02-01"])
+	assert.Equal(t, int64(0), fan.Likes["2025-02-01"])
+}
@@ -0,0 +1,130 @@
+package repo
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/ynwd/awesome-blog/internal/summary/domain"
+	"google.golang.org/api/iterator"
+)
+
+// inQueryLimit is the maximum number of values Firestore accepts in an "in" filter
+const inQueryLimit = 30
+
+// GetPostEngagement counts the likes and comments other users left on each of
+// the author's posts during the period, and how many distinct users left them.
+// Neither can be derived from the hourly counters, so this still reads the raw
+// likes and comments of the author's posts. Posts are returned in no particular
+// order. Querying likes and comments by post_id and created_at requires a
+// composite index on (post_id, created_at).
+func (r *summaryFirestore) GetPostEngagement(ctx context.Context, username string, query domain.SummaryQuery) ([]domain.PostEngagement, int64, error) {
+	titles, err := r.postTitles(ctx, username)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	postIDs := make([]string, 0, len(titles))
+	for id := range titles {
+		postIDs = append(postIDs, id)
+	}
+
+	perPost := make(map[string]*domain.PostEngagement)
+	engagers := make(map[string]struct{})
+	startDate, endDate := query.Range()
+
+	for start := 0; start < len(postIDs); start += inQueryLimit {
+		end := min(start+inQueryLimit, len(postIDs))
+		chunk := postIDs[start:end]
+
+		likesIter := r.client.Collection("likes").
+			Where("post_id", "in", chunk).
+			Where("created_at", ">=", startDate).
+			Where("created_at", "<=", endDate).
+			Documents(ctx)
+		err := r.processEngagement(likesIter, "username_from", username, func(postID, from string) {
+			postEngagement(perPost, postID, titles).Likes++
+			engagers[from] = struct{}{}
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+
+		commentsIter := r.client.Collection("comments").
+			Where("post_id", "in", chunk).
+			Where("created_at", ">=", startDate).
+			Where("created_at", "<=", endDate).
+			Documents(ctx)
+		err = r.processEngagement(commentsIter, "username", username, func(postID, from string) {
+			postEngagement(perPost, postID, titles).Comments++
+			engagers[from] = struct{}{}
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	posts := make([]domain.PostEngagement, 0, len(perPost))
+	for _, post := range perPost {
+		posts = append(posts, *post)
+	}
+	return posts, int64(len(engagers)), nil
+}
+
+// postTitles returns the titles of all posts by the author keyed by post ID
+func (r *summaryFirestore) postTitles(ctx context.Context, username string) (map[string]string, error) {
+	iter := r.client.Collection("posts").
+		Where("username", "==", username).
+		Select("title").
+		Documents(ctx)
+	defer iter.Stop()
+
+	titles := make(map[string]string)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		title, _ := doc.Data()["title"].(string)
+		titles[doc.Ref.ID] = title
+	}
+	return titles, nil
+}
+
+// processEngagement calls count for every document left by someone other
+// than the author. Documents without a user, such as deleted comments, are skipped.
+func (r *summaryFirestore) processEngagement(iter *firestore.DocumentIterator, userField, author string, count func(postID, from string)) error {
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		docData := doc.Data()
+		from, _ := docData[userField].(string)
+		if from == "" || from == author {
+			continue
+		}
+		postID, _ := docData["post_id"].(string)
+
+		count(postID, from)
+	}
+	return nil
+}
+
+func postEngagement(perPost map[string]*domain.PostEngagement, postID string, titles map[string]string) *domain.PostEngagement {
+	post, ok := perPost[postID]
+	if !ok {
+		post = &domain.PostEngagement{PostID: postID, Title: titles[postID]}
+		perPost[postID] = post
+	}
+	return post
+}
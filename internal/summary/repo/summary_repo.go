@@ -2,7 +2,6 @@ package repo
 
 import (
 	"context"
-	"time"
 
 	"cloud.google.com/go/firestore"
 	"github.com/ynwd/awesome-blog/internal/summary/domain"
@@ -10,7 +9,8 @@ import (
 )
 
 type SummaryRepository interface {
-	GetUserSummary(ctx context.Context, username string, startDate, endDate time.Time) (*domain.SummaryData, error)
+	GetUserSummary(ctx context.Context, username string, query domain.SummaryQuery) (*domain.SummaryData, error)
+	GetPostEngagement(ctx context.Context, username string, query domain.SummaryQuery) ([]domain.PostEngagement, int64, error)
 }
 
 type summaryFirestore struct {
@@ -23,71 +23,38 @@ func NewSummaryRepository(client *firestore.Client) SummaryRepository {
 	}
 }
 
-func (r *summaryFirestore) GetUserSummary(ctx context.Context, username string, startDate, endDate time.Time) (*domain.SummaryData, error) {
-	// Initialize summary data
-	data := &domain.SummaryData{
-		Likes:    make(map[string]int64),
-		Comments: make(map[string]int64),
-		Posts:    make(map[string]int64),
-	}
-
-	// Get likes
-	likesIter := r.client.Collection("likes").
-		Where("created_at", ">=", startDate).
-		Where("created_at", "<=", endDate).
-		Where("username_from", "==", username).
-		Documents(ctx)
-
-	err := r.processDocuments(likesIter, data.Likes)
-	if err != nil {
-		return nil, err
-	}
-
-	// Get comments
-	commentsIter := r.client.Collection("comments").
-		Where("created_at", ">=", startDate).
-		Where("created_at", "<=", endDate).
+// GetUserSummary reads the user's hourly activity counters in the period and
+// groups them into the query's buckets, including the likes and comments the
+// user's posts received. Querying counters by username and hour requires a
+// composite index on (username, hour).
+func (r *summaryFirestore) GetUserSummary(ctx context.Context, username string, query domain.SummaryQuery) (*domain.SummaryData, error) {
+	// Initialize summary data with zero-filled buckets
+	data := domain.NewSummaryData(query)
+	data.Received = domain.NewEngagementSummary(query)
+	startDate, endDate := query.Range()
+
+	iter := r.client.Collection(CountersCollection).
 		Where("username", "==", username).
+		Where("hour", ">=", domain.CounterHour(startDate)).
+		Where("hour", "<=", endDate).
 		Documents(ctx)
+	defer iter.Stop()
 
-	err = r.processDocuments(commentsIter, data.Comments)
-	if err != nil {
-		return nil, err
-	}
-
-	// Get posts
-	postsIter := r.client.Collection("posts").
-		Where("created_at", ">=", startDate).
-		Where("created_at", "<=", endDate).
-		Where("username", "==", username).
-		Documents(ctx)
-
-	err = r.processDocuments(postsIter, data.Posts)
-	if err != nil {
-		return nil, err
-	}
-
-	return data, nil
-}
-
-func (r *summaryFirestore) processDocuments(iter *firestore.DocumentIterator, data map[string]int64) error {
 	for {
 		doc, err := iter.Next()
 		if err == iterator.Done {
 			break
 		}
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		docData := doc.Data()
-		createdAt, ok := docData["created_at"].(time.Time)
-		if !ok {
-			continue // Skip if created_at is not a valid time
+		var counter domain.ActivityCounter
+		if err := doc.DataTo(&counter); err != nil {
+			return nil, err
 		}
-
-		month := createdAt.Format("2006-01")
-		data[month]++
+		counter.Add(query, data)
 	}
-	return nil
+
+	return data, nil
 }
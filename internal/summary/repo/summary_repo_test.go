@@ -75,57 +75,73 @@ func TestSummaryRepository_GetUserSummary(t *testing.T) {
 	err = createTestData(ctx, client, testDate)
 	assert.NoError(t, err)
 
+	// Summaries are read from the counters, so derive them from the raw data
+	_, err = NewCounterRepository(client).Rebuild(ctx)
+	assert.NoError(t, err)
+
 	repo := NewSummaryRepository(client)
 
+	// summaryFor builds the expected zero-filled data with one activity of
+	// each kind in the given bucket
+	summaryFor := func(query domain.SummaryQuery, bucket string) *domain.SummaryData {
+		data := domain.NewSummaryData(query)
+		data.Received = domain.NewEngagementSummary(query)
+		if bucket != "" {
+			data.Likes[bucket] = 1
+			data.Comments[bucket] = 1
+			data.Posts[bucket] = 1
+		}
+		return data
+	}
+
+	aroundTestDate, err := domain.ParseSummaryQuery("2025-01-31", "2025-02-02", "month", "", testDate)
+	assert.NoError(t, err)
+	monthBefore, err := domain.ParseSummaryQuery("2025-01-01", "2025-01-02", "month", "", testDate)
+	assert.NoError(t, err)
+	// Midnight UTC on Feb 1 is still Jan 31 in New York
+	newYork, err := domain.ParseSummaryQuery("2025-01-30", "2025-02-01", "day", "America/New_York", testDate)
+	assert.NoError(t, err)
+
 	tests := []struct {
-		name      string
-		username  string
-		startDate time.Time
-		endDate   time.Time
-		want      *domain.SummaryData
-		wantErr   bool
+		name     string
+		username string
+		query    domain.SummaryQuery
+		want     *domain.SummaryData
+		wantErr  bool
 	}{
 		{
-			name:      "get summary for existing user",
-			username:  "testuser",
-			startDate: testDate.AddDate(0, 0, -1), // day before
-			endDate:   testDate.AddDate(0, 0, 1),  // day after
-			want: &domain.SummaryData{
-				Likes:    map[string]int64{"2025-02": 1},
-				Comments: map[string]int64{"2025-02": 1},
-				Posts:    map[string]int64{"2025-02": 1},
-			},
-			wantErr: false,
+			name:     "get summary for existing user",
+			username: "testuser",
+			query:    aroundTestDate,
+			want:     summaryFor(aroundTestDate, "2025-02"),
+			wantErr:  false,
 		},
 		{
-			name:      "get summary for non-existing user",
-			username:  "nonexistent",
-			startDate: testDate.AddDate(0, 0, -1),
-			endDate:   testDate.AddDate(0, 0, 1),
-			want: &domain.SummaryData{
-				Likes:    map[string]int64{},
-				Comments: map[string]int64{},
-				Posts:    map[string]int64{},
-			},
-			wantErr: false,
+			name:     "get summary for non-existing user",
+			username: "nonexistent",
+			query:    aroundTestDate,
+			want:     summaryFor(aroundTestDate, ""),
+			wantErr:  false,
 		},
 		{
-			name:      "get summary with no data in range",
-			username:  "testuser",
-			startDate: testDate.AddDate(0, -1, 0), // month before
-			endDate:   testDate.AddDate(0, -1, 1),
-			want: &domain.SummaryData{
-				Likes:    map[string]int64{},
-				Comments: map[string]int64{},
-				Posts:    map[string]int64{},
-			},
-			wantErr: false,
+			name:     "get summary with no data in range",
+			username: "testuser",
+			query:    monthBefore,
+			want:     summaryFor(monthBefore, ""),
+			wantErr:  false,
+		},
+		{
+			name:     "buckets in the requested timezone",
+			username: "testuser",
+			query:    newYork,
+			want:     summaryFor(newYork, "2025-01-31"),
+			wantErr:  false,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := repo.GetUserSummary(ctx, tt.username, tt.startDate, tt.endDate)
+			got, err := repo.GetUserSummary(ctx, tt.username, tt.query)
 			if tt.wantErr {
 				assert.Error(t, err)
 				return
@@ -135,3 +151,61 @@ func TestSummaryRepository_GetUserSummary(t *testing.T) {
 		})
 	}
 }
+
+func TestSummaryRepository_GetPostEngagement(t *testing.T) {
+	ctx := context.Background()
+	client := helper.SetupRepoClient(t)
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	testDate := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	// testuser's own like and comment on post1 are not counted
+	err = createTestData(ctx, client, testDate)
+	assert.NoError(t, err)
+
+	engagement := []struct {
+		collection string
+		id         string
+		data       map[string]interface{}
+	}{
+		{"posts", "post2", map[string]interface{}{"username": "testuser", "title": "Title 2", "created_at": testDate}},
+		{"posts", "post3", map[string]interface{}{"username": "otheruser", "title": "Not mine", "created_at": testDate}},
+		{"likes", "like2", map[string]interface{}{"username_from": "fan1", "post_id": "post1", "created_at": testDate}},
+		{"likes", "like3", map[string]interface{}{"username_from": "fan2", "post_id": "post1", "created_at": testDate.AddDate(0, 1, 0)}},
+		{"likes", "like4", map[string]interface{}{"username_from": "fan1", "post_id": "post3", "created_at": testDate}},
+		{"comments", "comment2", map[string]interface{}{"username": "fan1", "post_id": "post2", "created_at": testDate}},
+		{"comments", "comment3", map[string]interface{}{"username": "", "post_id": "post2", "created_at": testDate, "deleted": true}},
+	}
+	for _, e := range engagement {
+		_, err := client.Collection(e.collection).Doc(e.id).Set(ctx, e.data)
+		assert.NoError(t, err)
+	}
+
+	repo := NewSummaryRepository(client)
+	query, err := domain.ParseSummaryQuery("2025-01-01", "2025-03-31", "month", "", testDate)
+	assert.NoError(t, err)
+
+	posts, engagers, err := repo.GetPostEngagement(ctx, "testuser", query)
+	assert.NoError(t, err)
+
+	assert.Equal(t, int64(2), engagers)
+	assert.ElementsMatch(t, []domain.PostEngagement{
+		{PostID: "post1", Title: "Title 1", Likes: 2},
+		{PostID: "post2", Title: "Title 2", Comments: 1},
+	}, posts)
+
+	// The received counts per bucket come from the counters
+	_, err = NewCounterRepository(client).Rebuild(ctx)
+	assert.NoError(t, err)
+
+	summary, err := repo.GetUserSummary(ctx, "testuser", query)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"2025-01": 0, "2025-02": 1, "2025-03": 1}, summary.Received.Likes)
+	assert.Equal(t, map[string]int64{"2025-01": 0, "2025-02": 1, "2025-03": 0}, summary.Received.Comments)
+}
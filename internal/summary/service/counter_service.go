@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/summary/domain"
+	"github.com/ynwd/awesome-blog/internal/summary/repo"
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+type counterService struct {
+	counterRepo repo.CounterRepository
+	posts       module.PostLookup
+}
+
+func NewCounterService(counterRepo repo.CounterRepository, posts module.PostLookup) CounterService {
+	return &counterService{
+		counterRepo: counterRepo,
+		posts:       posts,
+	}
+}
+
+func (s *counterService) RecordPost(ctx context.Context, postID, username string, at time.Time) error {
+	if postID == "" {
+		return ErrInvalidID
+	}
+	if username == "" {
+		return ErrInvalidUsername
+	}
+	return s.counterRepo.AddPost(ctx, postID, username, at)
+}
+
+// RemovePost uncounts the post and the engagement its author received on it
+func (s *counterService) RemovePost(ctx context.Context, postID string) error {
+	if postID == "" {
+		return ErrInvalidID
+	}
+	return s.counterRepo.RemovePost(ctx, postID)
+}
+
+// RecordComment counts the comment for its writer and, unless they commented
+// on their own post, as received by the post's author
+func (s *counterService) RecordComment(ctx context.Context, commentID, postID, username string, at time.Time) error {
+	if commentID == "" {
+		return ErrInvalidID
+	}
+	if username == "" {
+		return ErrInvalidUsername
+	}
+	author, err := s.receivingAuthor(ctx, postID, username)
+	if err != nil {
+		return err
+	}
+	return s.counterRepo.AddComment(ctx, commentID, postID, username, author, at)
+}
+
+func (s *counterService) RemoveComment(ctx context.Context, commentID string) error {
+	if commentID == "" {
+		return ErrInvalidID
+	}
+	return s.counterRepo.RemoveComment(ctx, commentID)
+}
+
+// RecordLike counts the like for the liker and, unless they liked their own
+// post, as received by the post's author. Repeated likes are counted once.
+func (s *counterService) RecordLike(ctx context.Context, postID, username string, at time.Time) error {
+	if username == "" {
+		return ErrInvalidUsername
+	}
+	author, err := s.receivingAuthor(ctx, postID, username)
+	if err != nil {
+		return err
+	}
+	return s.counterRepo.AddLike(ctx, postID, username, author, at)
+}
+
+func (s *counterService) RemoveLike(ctx context.Context, postID, username string) error {
+	if username == "" {
+		return ErrInvalidUsername
+	}
+	return s.counterRepo.RemoveLike(ctx, postID, username)
+}
+
+// receivingAuthor returns the author credited with engagement on the post,
+// which is empty when username wrote the post themselves
+func (s *counterService) receivingAuthor(ctx context.Context, postID, username string) (string, error) {
+	author, err := s.posts.PostAuthor(ctx, postID)
+	if err != nil {
+		return "", err
+	}
+	if author == "" {
+		return "", domain.ErrPostNotFound
+	}
+	if author == username {
+		return "", nil
+	}
+	return author, nil
+}
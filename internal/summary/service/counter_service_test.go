@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ynwd/awesome-blog/internal/summary/domain"
+	"github.com/ynwd/awesome-blog/tests/helper"
+)
+
+type mockCounterRepository struct {
+	addPostFunc       func(ctx context.Context, postID, username string, at time.Time) error
+	removePostFunc    func(ctx context.Context, postID string) error
+	addCommentFunc    func(ctx context.Context, commentID, postID, username, author string, at time.Time) error
+	removeCommentFunc func(ctx context.Context, commentID string) error
+	addLikeFunc       func(ctx context.Context, postID, username, author string, at time.Time) error
+	removeLikeFunc    func(ctx context.Context, postID, username string) error
+	rebuildFunc       func(ctx context.Context) (int, error)
+}
+
+func (m *mockCounterRepository) AddPost(ctx context.Context, postID, username string, at time.Time) error {
+	if m.addPostFunc != nil {
+		return m.addPostFunc(ctx, postID, username, at)
+	}
+	return nil
+}
+
+func (m *mockCounterRepository) RemovePost(ctx context.Context, postID string) error {
+	if m.removePostFunc != nil {
+		return m.removePostFunc(ctx, postID)
+	}
+	return nil
+}
+
+func (m *mockCounterRepository) AddComment(ctx context.Context, commentID, postID, username, author string, at time.Time) error {
+	if m.addCommentFunc != nil {
+		return m.addCommentFunc(ctx, commentID, postID, username, author, at)
+	}
+	return nil
+}
+
+func (m *mockCounterRepository) RemoveComment(ctx context.Context, commentID string) error {
+	if m.removeCommentFunc != nil {
+		return m.removeCommentFunc(ctx, commentID)
+	}
+	return nil
+}
+
+func (m *mockCounterRepository) AddLike(ctx context.Context, postID, username, author string, at time.Time) error {
+	if m.addLikeFunc != nil {
+		return m.addLikeFunc(ctx, postID, username, author, at)
+	}
+	return nil
+}
+
+func (m *mockCounterRepository) RemoveLike(ctx context.Context, postID, username string) error {
+	if m.removeLikeFunc != nil {
+		return m.removeLikeFunc(ctx, postID, username)
+	}
+	return nil
+}
+
+func (m *mockCounterRepository) Rebuild(ctx context.Context) (int, error) {
+	if m.rebuildFunc != nil {
+		return m.rebuildFunc(ctx)
+	}
+	return 0, nil
+}
+
+func TestCounterService_RecordPost(t *testing.T) {
+	at := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	var recorded string
+	repo := &mockCounterRepository{
+		addPostFunc: func(ctx context.Context, postID, username string, got time.Time) error {
+			recorded = postID + ":" + username
+			assert.Equal(t, at, got)
+			return nil
+		},
+	}
+	service := NewCounterService(repo, &helper.MockPostLookup{})
+
+	assert.NoError(t, service.RecordPost(context.Background(), "post1", "alice", at))
+	assert.Equal(t, "post1:alice", recorded)
+
+	assert.ErrorIs(t, service.RecordPost(context.Background(), "post1", "", at), ErrInvalidUsername)
+	assert.ErrorIs(t, service.RecordPost(context.Background(), "", "alice", at), ErrInvalidID)
+}
+
+func TestCounterService_RemovePostAndComment(t *testing.T) {
+	var removed []string
+	repo := &mockCounterRepository{
+		removePostFunc: func(ctx context.Context, postID string) error {
+			removed = append(removed, "post:"+postID)
+			return nil
+		},
+		removeCommentFunc: func(ctx context.Context, commentID string) error {
+			removed = append(removed, "comment:"+commentID)
+			return nil
+		},
+	}
+	service := NewCounterService(repo, &helper.MockPostLookup{})
+
+	assert.NoError(t, service.RemovePost(context.Background(), "post1"))
+	assert.NoError(t, service.RemoveComment(context.Background(), "comment1"))
+	assert.Equal(t, []string{"post:post1", "comment:comment1"}, removed)
+
+	assert.ErrorIs(t, service.RemovePost(context.Background(), ""), ErrInvalidID)
+	assert.ErrorIs(t, service.RemoveComment(context.Background(), ""), ErrInvalidID)
+}
+
+func TestCounterService_RecordComment(t *testing.T) {
+	at := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	posts := &helper.MockPostLookup{
+		PostAuthorFunc: func(ctx context.Context, postID string) (string, error) {
+			switch postID {
+			case "post1":
+				return "author", nil
+			case "broken":
+				return "", errors.New("lookup error")
+			}
+			return "", nil
+		},
+	}
+
+	tests := []struct {
+		name       string
+		postID     string
+		username   string
+		wantAuthor string
+		wantErr    error
+	}{
+		{name: "credits the post author", postID: "post1", username: "fan", wantAuthor: "author"},
+		{name: "own post is not received engagement", postID: "post1", username: "author", wantAuthor: ""},
+		{name: "missing post", postID: "missing", username: "fan", wantErr: domain.ErrPostNotFound},
+		{name: "lookup error", postID: "broken", username: "fan", wantErr: errors.New("lookup error")},
+		{name: "empty username", postID: "post1", username: "", wantErr: ErrInvalidUsername},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			repo := &mockCounterRepository{
+				addCommentFunc: func(ctx context.Context, commentID, postID, username, author string, got time.Time) error {
+					called = true
+					assert.Equal(t, "comment1", commentID)
+					assert.Equal(t, tt.postID, postID)
+					assert.Equal(t, tt.username, username)
+					assert.Equal(t, tt.wantAuthor, author)
+					return nil
+				},
+			}
+
+			err := NewCounterService(repo, posts).RecordComment(context.Background(), "comment1", tt.postID, tt.username, at)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+				assert.False(t, called)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, called)
+		})
+	}
+
+	err := NewCounterService(&mockCounterRepository{}, posts).RecordComment(context.Background(), "", "post1", "fan", at)
+	assert.ErrorIs(t, err, ErrInvalidID)
+}
+
+func TestCounterService_RecordLike(t *testing.T) {
+	at := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	var gotPostID, gotAuthor string
+	repo := &mockCounterRepository{
+		addLikeFunc: func(ctx context.Context, postID, username, author string, got time.Time) error {
+			gotPostID, gotAuthor = postID, author
+			return nil
+		},
+	}
+	service := NewCounterService(repo, &helper.MockPostLookup{})
+
+	assert.NoError(t, service.RecordLike(context.Background(), "post1", "fan", at))
+	assert.Equal(t, "post1", gotPostID)
+	assert.Equal(t, "author", gotAuthor)
+
+	missing := NewCounterService(repo, &helper.MockPostLookup{
+		PostAuthorFunc: func(ctx context.Context, postID string) (string, error) {
+			return "", nil
+		},
+	})
+	assert.ErrorIs(t, missing.RecordLike(context.Background(), "gone", "fan", at), domain.ErrPostNotFound)
+}
+
+func TestCounterService_RemoveLike(t *testing.T) {
+	repo := &mockCounterRepository{
+		removeLikeFunc: func(ctx context.Context, postID, username string) error {
+			return errors.New("repository error")
+		},
+	}
+	service := NewCounterService(repo, &helper.MockPostLookup{})
+
+	assert.EqualError(t, service.RemoveLike(context.Background(), "post1", "fan"), "repository error")
+	assert.ErrorIs(t, service.RemoveLike(context.Background(), "post1", ""), ErrInvalidUsername)
+}
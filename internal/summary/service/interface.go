@@ -2,10 +2,21 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/ynwd/awesome-blog/internal/summary/domain"
 )
 
 type SummaryService interface {
-	GetYearlySummary(ctx context.Context, username string) (*domain.SummaryData, error)
+	GetSummary(ctx context.Context, username string, query domain.SummaryQuery) (*domain.SummaryData, error)
+}
+
+// CounterService keeps the activity counters in step with the event stream
+type CounterService interface {
+	RecordPost(ctx context.Context, postID, username string, at time.Time) error
+	RemovePost(ctx context.Context, postID string) error
+	RecordComment(ctx context.Context, commentID, postID, username string, at time.Time) error
+	RemoveComment(ctx context.Context, commentID string) error
+	RecordLike(ctx context.Context, postID, username string, at time.Time) error
+	RemoveLike(ctx context.Context, postID, username string) error
 }
@@ -3,14 +3,18 @@ package service
 import (
 	"context"
 	"errors"
-	"time"
+	"sort"
 
 	"github.com/ynwd/awesome-blog/internal/summary/domain"
 	"github.com/ynwd/awesome-blog/internal/summary/repo"
 )
 
+// TopPostsLimit is the number of posts listed in the received engagement
+const TopPostsLimit = 5
+
 var (
 	ErrInvalidUsername = errors.New("invalid username: cannot be empty")
+	ErrInvalidID       = errors.New("invalid id: cannot be empty")
 )
 
 type summaryService struct {
@@ -23,14 +27,46 @@ func NewSummaryService(summaryRepo repo.SummaryRepository) SummaryService {
 	}
 }
 
-func (s *summaryService) GetYearlySummary(ctx context.Context, username string) (*domain.SummaryData, error) {
+// GetSummary returns the user's activity in the query period, and the
+// engagement their posts received, bucketed by the query's granularity and timezone
+func (s *summaryService) GetSummary(ctx context.Context, username string, query domain.SummaryQuery) (*domain.SummaryData, error) {
 	if username == "" {
 		return nil, ErrInvalidUsername
 	}
 
-	currentYear := time.Now().Year()
-	startDate := time.Date(currentYear, time.January, 1, 0, 0, 0, 0, time.UTC)
-	endDate := time.Date(currentYear, time.December, 31, 23, 59, 59, 999999999, time.UTC)
+	summary, err := s.summaryRepo.GetUserSummary(ctx, username, query)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, engagers, err := s.summaryRepo.GetPostEngagement(ctx, username, query)
+	if err != nil {
+		return nil, err
+	}
+	if summary.Received == nil {
+		summary.Received = domain.NewEngagementSummary(query)
+	}
+	summary.Received.TopPosts = topPosts(posts, TopPostsLimit)
+	summary.Received.UniqueEngagers = engagers
 
-	return s.summaryRepo.GetUserSummary(ctx, username, startDate, endDate)
+	return summary, nil
+}
+
+// topPosts orders posts by total engagement, then likes, then post ID, and
+// keeps the first limit of them
+func topPosts(posts []domain.PostEngagement, limit int) []domain.PostEngagement {
+	sort.Slice(posts, func(i, j int) bool {
+		if posts[i].Total() != posts[j].Total() {
+			return posts[i].Total() > posts[j].Total()
+		}
+		if posts[i].Likes != posts[j].Likes {
+			return posts[i].Likes > posts[j].Likes
+		}
+		return posts[i].PostID < posts[j].PostID
+	})
+
+	if len(posts) > limit {
+		posts = posts[:limit]
+	}
+	return posts
 }
@@ -11,17 +11,28 @@ import (
 )
 
 type mockSummaryRepository struct {
-	getUserSummaryFunc func(ctx context.Context, username string, startDate, endDate time.Time) (*domain.SummaryData, error)
+	getUserSummaryFunc    func(ctx context.Context, username string, query domain.SummaryQuery) (*domain.SummaryData, error)
+	getPostEngagementFunc func(ctx context.Context, username string, query domain.SummaryQuery) ([]domain.PostEngagement, int64, error)
 }
 
-func (m *mockSummaryRepository) GetUserSummary(ctx context.Context, username string, startDate, endDate time.Time) (*domain.SummaryData, error) {
+func (m *mockSummaryRepository) GetUserSummary(ctx context.Context, username string, query domain.SummaryQuery) (*domain.SummaryData, error) {
 	if m.getUserSummaryFunc != nil {
-		return m.getUserSummaryFunc(ctx, username, startDate, endDate)
+		return m.getUserSummaryFunc(ctx, username, query)
 	}
 	return nil, nil
 }
 
-func TestSummaryService_GetYearlySummary(t *testing.T) {
+func (m *mockSummaryRepository) GetPostEngagement(ctx context.Context, username string, query domain.SummaryQuery) ([]domain.PostEngagement, int64, error) {
+	if m.getPostEngagementFunc != nil {
+		return m.getPostEngagementFunc(ctx, username, query)
+	}
+	return []domain.PostEngagement{}, 0, nil
+}
+
+func TestSummaryService_GetSummary(t *testing.T) {
+	query, err := domain.ParseSummaryQuery("", "", "", "", time.Now())
+	assert.NoError(t, err)
+
 	tests := []struct {
 		name      string
 		username  string
@@ -40,7 +51,7 @@ func TestSummaryService_GetYearlySummary(t *testing.T) {
 			name:     "repository error returns error",
 			username: "testuser",
 			setupMock: func(m *mockSummaryRepository) {
-				m.getUserSummaryFunc = func(ctx context.Context, username string, startDate, endDate time.Time) (*domain.SummaryData, error) {
+				m.getUserSummaryFunc = func(ctx context.Context, username string, query domain.SummaryQuery) (*domain.SummaryData, error) {
 					return nil, errors.New("repository error")
 				}
 			},
@@ -48,14 +59,18 @@ func TestSummaryService_GetYearlySummary(t *testing.T) {
 			wantErr: errors.New("repository error"),
 		},
 		{
-			name:     "success get yearly summary",
+			name:     "success get summary",
 			username: "testuser",
 			setupMock: func(m *mockSummaryRepository) {
-				m.getUserSummaryFunc = func(ctx context.Context, username string, startDate, endDate time.Time) (*domain.SummaryData, error) {
+				m.getUserSummaryFunc = func(ctx context.Context, username string, query domain.SummaryQuery) (*domain.SummaryData, error) {
 					return &domain.SummaryData{
 						Likes:    map[string]int64{"2025-02": 1},
 						Comments: map[string]int64{"2025-02": 1},
 						Posts:    map[string]int64{"2025-02": 1},
+						Received: &domain.EngagementSummary{
+							Likes:    map[string]int64{"2025-02": 2},
+							Comments: map[string]int64{"2025-02": 0},
+						},
 					}, nil
 				}
 			},
@@ -63,6 +78,58 @@ func TestSummaryService_GetYearlySummary(t *testing.T) {
 				Likes:    map[string]int64{"2025-02": 1},
 				Comments: map[string]int64{"2025-02": 1},
 				Posts:    map[string]int64{"2025-02": 1},
+				Received: &domain.EngagementSummary{
+					Likes:    map[string]int64{"2025-02": 2},
+					Comments: map[string]int64{"2025-02": 0},
+					TopPosts: []domain.PostEngagement{},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name:     "received engagement error returns error",
+			username: "testuser",
+			setupMock: func(m *mockSummaryRepository) {
+				m.getUserSummaryFunc = func(ctx context.Context, username string, query domain.SummaryQuery) (*domain.SummaryData, error) {
+					return &domain.SummaryData{}, nil
+				}
+				m.getPostEngagementFunc = func(ctx context.Context, username string, query domain.SummaryQuery) ([]domain.PostEngagement, int64, error) {
+					return nil, 0, errors.New("engagement error")
+				}
+			},
+			want:    nil,
+			wantErr: errors.New("engagement error"),
+		},
+		{
+			name:     "top posts are ranked and trimmed",
+			username: "testuser",
+			setupMock: func(m *mockSummaryRepository) {
+				m.getUserSummaryFunc = func(ctx context.Context, username string, query domain.SummaryQuery) (*domain.SummaryData, error) {
+					return &domain.SummaryData{Received: &domain.EngagementSummary{}}, nil
+				}
+				m.getPostEngagementFunc = func(ctx context.Context, username string, query domain.SummaryQuery) ([]domain.PostEngagement, int64, error) {
+					return []domain.PostEngagement{
+						{PostID: "p1", Likes: 1},
+						{PostID: "p2", Likes: 1, Comments: 4},
+						{PostID: "p3", Comments: 2},
+						{PostID: "p4", Likes: 2},
+						{PostID: "p5", Likes: 3},
+						{PostID: "p6", Comments: 1},
+						{PostID: "p7", Likes: 1},
+					}, 4, nil
+				}
+			},
+			want: &domain.SummaryData{
+				Received: &domain.EngagementSummary{
+					UniqueEngagers: 4,
+					TopPosts: []domain.PostEngagement{
+						{PostID: "p2", Likes: 1, Comments: 4},
+						{PostID: "p5", Likes: 3},
+						{PostID: "p4", Likes: 2},
+						{PostID: "p3", Comments: 2},
+						{PostID: "p1", Likes: 1},
+					},
+				},
 			},
 			wantErr: nil,
 		},
@@ -74,7 +141,7 @@ func TestSummaryService_GetYearlySummary(t *testing.T) {
 			tt.setupMock(mockRepo)
 
 			service := NewSummaryService(mockRepo)
-			got, err := service.GetYearlySummary(context.Background(), tt.username)
+			got, err := service.GetSummary(context.Background(), tt.username, query)
 
 			if tt.wantErr != nil {
 				assert.Error(t, err)
@@ -1,32 +1,45 @@
 package summary
 
 import (
-	"context"
-
 	"cloud.google.com/go/firestore"
 	"github.com/ynwd/awesome-blog/internal/summary/handler"
 	"github.com/ynwd/awesome-blog/internal/summary/repo"
 	"github.com/ynwd/awesome-blog/internal/summary/service"
 	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/utils"
 )
 
 type Module struct {
-	handler *handler.SummaryHandler
+	handler      *handler.SummaryHandler
+	eventHandler *handler.SummaryEventHandler
 }
 
-func NewModule(firestoreClient *firestore.Client) *Module {
-	// Initialize repository
+func NewModule(firestoreClient *firestore.Client, posts module.PostLookup, processed utils.ProcessedEvents) *Module {
+	// Initialize repositories
 	summaryRepo := repo.NewSummaryRepository(firestoreClient)
+	counterRepo := repo.NewCounterRepository(firestoreClient)
 
-	// Initialize service
+	// Initialize services
 	summaryService := service.NewSummaryService(summaryRepo)
+	counterService := service.NewCounterService(counterRepo, posts)
 
-	// Initialize handler
+	// Initialize handlers
 	summaryHandler := handler.NewSummaryHandler(summaryService)
+	eventHandler := handler.NewSummaryEventHandler(counterService, processed)
 
 	return &Module{
-		handler: summaryHandler,
+		handler:      summaryHandler,
+		eventHandler: eventHandler,
 	}
 }
 
-func (m *Module) RegisterEventHandlers(ctx context.Context, event module.BaseEvent) {}
+// RegisterEvents counts writes from the domain events, which are emitted for
+// both the synchronous and the /pubsub endpoints
+func (m *Module) RegisterEvents(registry *module.EventRegistry) {
+	module.Handle(registry, module.PostCreatedEvent, module.PostCreatedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.PostDeletedEvent, module.PostDeletedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.CommentCreatedEvent, module.CommentCreatedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.CommentDeletedEvent, module.CommentDeletedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.LikeCreatedEvent, module.LikeCreatedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.LikeDeletedEvent, module.LikeDeletedEventVersion, m.eventHandler.Handle)
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid")
+	ErrRefreshTokenExpired = errors.New("refresh token has expired")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used")
+)
+
+// RefreshToken is the stored form of a refresh token. Only the SHA-256 hash of
+// the token is persisted; tokens rotated from the same login share a FamilyID.
+type RefreshToken struct {
+	ID         string    `firestore:"-"`
+	UserID     string    `firestore:"user_id"`
+	FamilyID   string    `firestore:"family_id"`
+	ReplacedBy string    `firestore:"replaced_by"`
+	Revoked    bool      `firestore:"revoked"`
+	ExpiresAt  time.Time `firestore:"expires_at"`
+	CreatedAt  time.Time `firestore:"created_at"`
+}
+
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return now.After(t.ExpiresAt)
+}
@@ -2,19 +2,43 @@ package domain
 
 import (
 	"errors"
+	"net/url"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxDisplayNameLength = 50
+	MaxBioLength         = 280
 )
 
 var (
-	ErrUsernameRequired = errors.New("username is required")
-	ErrPasswordRequired = errors.New("password is required")
-	ErrUsernameTaken    = errors.New("username is already taken")
-	ErrInvalidPassword  = errors.New("password must be at least 6 characters")
+	ErrUsernameRequired   = errors.New("username is required")
+	ErrPasswordRequired   = errors.New("password is required")
+	ErrUsernameTaken      = errors.New("username is already taken")
+	ErrInvalidPassword    = errors.New("password must be at least 6 characters")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrDisplayNameTooLong = errors.New("display name must be at most 50 characters")
+	ErrBioTooLong         = errors.New("bio must be at most 280 characters")
+	ErrInvalidAvatarURL   = errors.New("avatar url must be an absolute http or https url")
 )
 
 type User struct {
-	Id       string `firestore:"id,omitempty"`
-	Username string `firestore:"username"`
-	Password string `firestore:"password"`
+	Id          string    `firestore:"id,omitempty"`
+	Username    string    `firestore:"username"`
+	Password    string    `firestore:"password"`
+	DisplayName string    `firestore:"display_name"`
+	Bio         string    `firestore:"bio"`
+	AvatarURL   string    `firestore:"avatar_url"`
+	CreatedAt   time.Time `firestore:"created_at"`
+	UpdatedAt   time.Time `firestore:"updated_at"`
+}
+
+// ProfileUpdate holds the profile fields to change; nil fields are left as is
+type ProfileUpdate struct {
+	DisplayName *string
+	Bio         *string
+	AvatarURL   *string
 }
 
 func (u *User) Validate() error {
@@ -27,5 +51,34 @@ func (u *User) Validate() error {
 	if len(u.Password) < 6 {
 		return ErrInvalidPassword
 	}
+	return u.ValidateProfile()
+}
+
+func (u *User) ValidateProfile() error {
+	if utf8.RuneCountInString(u.DisplayName) > MaxDisplayNameLength {
+		return ErrDisplayNameTooLong
+	}
+	if utf8.RuneCountInString(u.Bio) > MaxBioLength {
+		return ErrBioTooLong
+	}
+	if u.AvatarURL != "" {
+		parsed, err := url.Parse(u.AvatarURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return ErrInvalidAvatarURL
+		}
+	}
 	return nil
 }
+
+// Apply copies the non-nil fields of the update onto the user
+func (u *User) Apply(update ProfileUpdate) {
+	if update.DisplayName != nil {
+		u.DisplayName = *update.DisplayName
+	}
+	if update.Bio != nil {
+		u.Bio = *update.Bio
+	}
+	if update.AvatarURL != nil {
+		u.AvatarURL = *update.AvatarURL
+	}
+}
@@ -1,25 +1,29 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ynwd/awesome-blog/internal/users/domain"
 	"github.com/ynwd/awesome-blog/internal/users/dto"
 	"github.com/ynwd/awesome-blog/internal/users/service"
+	"github.com/ynwd/awesome-blog/pkg/middleware"
 	"github.com/ynwd/awesome-blog/pkg/res"
 	"github.com/ynwd/awesome-blog/pkg/utils"
 )
 
 type UserHandler struct {
-	userService service.UserService
-	jwtToken    utils.JWT
+	userService  service.UserService
+	tokenService service.TokenService
+	jwtToken     utils.JWT
 }
 
-func NewUserHandler(userService service.UserService, jwtToken utils.JWT) *UserHandler {
+func NewUserHandler(userService service.UserService, tokenService service.TokenService, jwtToken utils.JWT) *UserHandler {
 	return &UserHandler{
-		userService: userService,
-		jwtToken:    jwtToken,
+		userService:  userService,
+		tokenService: tokenService,
+		jwtToken:     jwtToken,
 	}
 }
 
@@ -71,6 +75,67 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
+	refreshToken, err := h.tokenService.IssueRefreshToken(c.Request.Context(), user.Username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, res.Response{
+			Status:  "error",
+			Message: "Failed to generate token",
+		})
+		return
+	}
+
+	h.respondWithTokens(c, user.Username, refreshToken, "Login successful")
+}
+
+// Refresh exchanges a refresh token for a new access token and a rotated refresh token
+func (h *UserHandler) Refresh(c *gin.Context) {
+	var req dto.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error("Invalid request format"))
+		return
+	}
+
+	userID, refreshToken, err := h.tokenService.RotateRefreshToken(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		if isRefreshTokenError(err) {
+			c.JSON(http.StatusUnauthorized, res.Error("Invalid refresh token"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, res.Error("Failed to refresh token"))
+		return
+	}
+
+	h.respondWithTokens(c, userID, refreshToken, "Token refreshed successfully")
+}
+
+// Logout revokes the current access token and, when given, the refresh token family
+func (h *UserHandler) Logout(c *gin.Context) {
+	var req dto.LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, res.Error("Invalid request format"))
+			return
+		}
+	}
+
+	if err := h.jwtToken.RevokeToken(c.GetString(middleware.ContextTokenIDKey)); err != nil {
+		c.JSON(http.StatusInternalServerError, res.Error("Failed to revoke token"))
+		return
+	}
+
+	if req.RefreshToken != "" {
+		username, _ := middleware.CurrentUser(c)
+		err := h.tokenService.RevokeRefreshToken(c.Request.Context(), username, req.RefreshToken)
+		if err != nil && !isRefreshTokenError(err) {
+			c.JSON(http.StatusInternalServerError, res.Error("Failed to revoke refresh token"))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, res.Success(nil, "Logout successful"))
+}
+
+func (h *UserHandler) respondWithTokens(c *gin.Context, userID, refreshToken, message string) {
 	// Generate token fingerprint
 	fingerprint := &utils.TokenFingerprint{
 		IP:        c.ClientIP(),
@@ -79,7 +144,7 @@ func (h *UserHandler) Login(c *gin.Context) {
 	}
 
 	// Generate token with appropriate audiences
-	token, err := h.jwtToken.GenerateToken(user.Username, fingerprint)
+	token, err := h.jwtToken.GenerateToken(userID, fingerprint)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, res.Response{
 			Status:  "error",
@@ -89,8 +154,20 @@ func (h *UserHandler) Login(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, res.Response{
-		Message: "Login successful",
+		Message: message,
 		Status:  "success",
-		Data:    token,
+		Data: dto.TokenResponse{
+			AccessToken:  token,
+			RefreshToken: refreshToken,
+			TokenType:    "Bearer",
+			ExpiresIn:    int(utils.AccessTokenTTL.Seconds()),
+		},
 	})
 }
+
+func isRefreshTokenError(err error) bool {
+	return errors.Is(err, domain.ErrRefreshTokenInvalid) ||
+		errors.Is(err, domain.ErrRefreshTokenExpired) ||
+		errors.Is(err, domain.ErrRefreshTokenReused) ||
+		errors.Is(err, service.ErrInvalidInput)
+}
@@ -33,6 +33,50 @@ func (m *MockUserService) AuthenticateUser(ctx context.Context, username, passwo
 	return args.Get(0).(domain.User), args.Error(1)
 }
 
+func (m *MockUserService) GetProfile(ctx context.Context, username string) (domain.User, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(domain.User), args.Error(1)
+}
+
+func (m *MockUserService) UpdateProfile(ctx context.Context, username string, update domain.ProfileUpdate) (domain.User, error) {
+	args := m.Called(ctx, username, update)
+	return args.Get(0).(domain.User), args.Error(1)
+}
+
+func (m *MockUserService) DeleteUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockUserService) UserExists(ctx context.Context, username string) (bool, error) {
+	args := m.Called(ctx, username)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockTokenService struct {
+	mock.Mock
+}
+
+func (m *MockTokenService) IssueRefreshToken(ctx context.Context, userID string) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTokenService) RotateRefreshToken(ctx context.Context, refreshToken string) (string, string, error) {
+	args := m.Called(ctx, refreshToken)
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockTokenService) RevokeRefreshToken(ctx context.Context, userID, refreshToken string) error {
+	args := m.Called(ctx, userID, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockTokenService) RevokeAllRefreshTokens(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
 type MockJWT struct {
 	mock.Mock
 }
@@ -125,7 +169,7 @@ func TestRegister(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := new(MockUserService)
 			tt.mockSetup(mockService)
-			h := NewUserHandler(mockService, nil)
+			h := NewUserHandler(mockService, nil, nil)
 
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
@@ -169,7 +213,7 @@ func TestLogin(t *testing.T) {
 	tests := []struct {
 		name       string
 		reqBody    map[string]string
-		setupMocks func(*MockUserService, *MockJWT)
+		setupMocks func(*MockUserService, *MockTokenService, *MockJWT)
 		wantStatus int
 		wantRes    res.Response
 	}{
@@ -179,9 +223,11 @@ func TestLogin(t *testing.T) {
 				"username": "testuser",
 				"password": "testpass",
 			},
-			setupMocks: func(ms *MockUserService, mj *MockJWT) {
+			setupMocks: func(ms *MockUserService, mt *MockTokenService, mj *MockJWT) {
 				ms.On("AuthenticateUser", mock.Anything, "testuser", "testpass").
 					Return(domain.User{Username: "testuser"}, nil)
+				mt.On("IssueRefreshToken", mock.Anything, "testuser").
+					Return("refresh.token", nil)
 
 				// Update mock expectation with exact fingerprint matching
 				mj.On("GenerateToken", "testuser", mock.MatchedBy(func(f *utils.TokenFingerprint) bool {
@@ -194,7 +240,12 @@ func TestLogin(t *testing.T) {
 			wantRes: res.Response{
 				Status:  "success",
 				Message: "Login successful",
-				Data:    "valid.token",
+				Data: map[string]interface{}{
+					"access_token":  "valid.token",
+					"refresh_token": "refresh.token",
+					"token_type":    "Bearer",
+					"expires_in":    float64(900),
+				},
 			},
 		},
 		{
@@ -202,7 +253,7 @@ func TestLogin(t *testing.T) {
 			reqBody: map[string]string{
 				"username": "",
 			},
-			setupMocks: func(ms *MockUserService, mj *MockJWT) {},
+			setupMocks: func(ms *MockUserService, mt *MockTokenService, mj *MockJWT) {},
 			wantStatus: http.StatusBadRequest,
 			wantRes: res.Response{
 				Status:  "error",
@@ -215,7 +266,7 @@ func TestLogin(t *testing.T) {
 				"username": "testuser",
 				"password": "wrongpass",
 			},
-			setupMocks: func(ms *MockUserService, mj *MockJWT) {
+			setupMocks: func(ms *MockUserService, mt *MockTokenService, mj *MockJWT) {
 				ms.On("AuthenticateUser", mock.Anything, "testuser", "wrongpass").
 					Return(domain.User{}, errors.New("invalid credentials"))
 			},
@@ -231,9 +282,11 @@ func TestLogin(t *testing.T) {
 				"username": "testuser",
 				"password": "testpass",
 			},
-			setupMocks: func(ms *MockUserService, mj *MockJWT) {
+			setupMocks: func(ms *MockUserService, mt *MockTokenService, mj *MockJWT) {
 				ms.On("AuthenticateUser", mock.Anything, "testuser", "testpass").
 					Return(domain.User{Username: "testuser"}, nil)
+				mt.On("IssueRefreshToken", mock.Anything, "testuser").
+					Return("refresh.token", nil)
 				mj.On("GenerateToken", "testuser", mock.Anything).
 					Return("", errors.New("token generation failed"))
 			},
@@ -248,9 +301,10 @@ func TestLogin(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := new(MockUserService)
+			mockTokens := new(MockTokenService)
 			mockJWT := new(MockJWT)
-			tt.setupMocks(mockService, mockJWT)
-			h := NewUserHandler(mockService, mockJWT)
+			tt.setupMocks(mockService, mockTokens, mockJWT)
+			h := NewUserHandler(mockService, mockTokens, mockJWT)
 
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
@@ -277,6 +331,149 @@ func TestLogin(t *testing.T) {
 			assert.Equal(t, tt.wantStatus, w.Code)
 			assert.Equal(t, tt.wantRes, response)
 			mockService.AssertExpectations(t)
+			mockTokens.AssertExpectations(t)
+			mockJWT.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		reqBody    map[string]string
+		setupMocks func(*MockTokenService, *MockJWT)
+		wantStatus int
+	}{
+		{
+			name:    "Success",
+			reqBody: map[string]string{"refresh_token": "old.refresh"},
+			setupMocks: func(mt *MockTokenService, mj *MockJWT) {
+				mt.On("RotateRefreshToken", mock.Anything, "old.refresh").
+					Return("testuser", "new.refresh", nil)
+				mj.On("GenerateToken", "testuser", mock.Anything).
+					Return("new.access", nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Missing Refresh Token",
+			reqBody:    map[string]string{},
+			setupMocks: func(mt *MockTokenService, mj *MockJWT) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "Reused Refresh Token",
+			reqBody: map[string]string{"refresh_token": "rotated.refresh"},
+			setupMocks: func(mt *MockTokenService, mj *MockJWT) {
+				mt.On("RotateRefreshToken", mock.Anything, "rotated.refresh").
+					Return("", "", domain.ErrRefreshTokenReused)
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:    "Storage Error",
+			reqBody: map[string]string{"refresh_token": "old.refresh"},
+			setupMocks: func(mt *MockTokenService, mj *MockJWT) {
+				mt.On("RotateRefreshToken", mock.Anything, "old.refresh").
+					Return("", "", errors.New("firestore unavailable"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokens := new(MockTokenService)
+			mockJWT := new(MockJWT)
+			tt.setupMocks(mockTokens, mockJWT)
+			h := NewUserHandler(new(MockUserService), mockTokens, mockJWT)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			jsonBody, _ := json.Marshal(tt.reqBody)
+			c.Request, _ = http.NewRequest(http.MethodPost, "/api/v1/auth/refresh", bytes.NewBuffer(jsonBody))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			h.Refresh(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var response struct {
+					Data map[string]interface{} `json:"data"`
+				}
+				json.Unmarshal(w.Body.Bytes(), &response)
+				assert.Equal(t, "new.access", response.Data["access_token"])
+				assert.Equal(t, "new.refresh", response.Data["refresh_token"])
+			}
+			mockTokens.AssertExpectations(t)
+			mockJWT.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		reqBody    map[string]string
+		setupMocks func(*MockTokenService, *MockJWT)
+		wantStatus int
+	}{
+		{
+			name:    "Revokes Access And Refresh Token",
+			reqBody: map[string]string{"refresh_token": "refresh.token"},
+			setupMocks: func(mt *MockTokenService, mj *MockJWT) {
+				mj.On("RevokeToken", "jti-123").Return(nil)
+				mt.On("RevokeRefreshToken", mock.Anything, "testuser", "refresh.token").Return(nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "Revokes Access Token Only",
+			reqBody: nil,
+			setupMocks: func(mt *MockTokenService, mj *MockJWT) {
+				mj.On("RevokeToken", "jti-123").Return(nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "Revoke Failure",
+			reqBody: nil,
+			setupMocks: func(mt *MockTokenService, mj *MockJWT) {
+				mj.On("RevokeToken", "jti-123").Return(errors.New("blacklist unavailable"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokens := new(MockTokenService)
+			mockJWT := new(MockJWT)
+			tt.setupMocks(mockTokens, mockJWT)
+			h := NewUserHandler(new(MockUserService), mockTokens, mockJWT)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			body := bytes.NewBuffer(nil)
+			if tt.reqBody != nil {
+				jsonBody, _ := json.Marshal(tt.reqBody)
+				body = bytes.NewBuffer(jsonBody)
+			}
+			c.Request, _ = http.NewRequest(http.MethodPost, "/api/v1/auth/logout", body)
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("user_id", "testuser")
+			c.Set("token_id", "jti-123")
+
+			h.Logout(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockTokens.AssertExpectations(t)
 			mockJWT.AssertExpectations(t)
 		})
 	}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ynwd/awesome-blog/internal/users/domain"
+	"github.com/ynwd/awesome-blog/internal/users/dto"
+	"github.com/ynwd/awesome-blog/internal/users/service"
+	"github.com/ynwd/awesome-blog/pkg/middleware"
+	"github.com/ynwd/awesome-blog/pkg/res"
+)
+
+// GetMe returns the profile of the authenticated user
+func (h *UserHandler) GetMe(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+	h.respondWithProfile(c, username)
+}
+
+// GetUser returns the public profile of the given user
+func (h *UserHandler) GetUser(c *gin.Context) {
+	h.respondWithProfile(c, c.Param("username"))
+}
+
+// UpdateMe updates the profile of the authenticated user
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	var req dto.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error("Invalid request format"))
+		return
+	}
+
+	update := domain.ProfileUpdate{
+		DisplayName: req.DisplayName,
+		Bio:         req.Bio,
+		AvatarURL:   req.AvatarURL,
+	}
+
+	user, err := h.userService.UpdateProfile(c.Request.Context(), username, update)
+	if err != nil {
+		c.JSON(profileErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(toProfileResponse(user), "Profile updated successfully"))
+}
+
+// DeleteMe deletes the authenticated user's account and revokes their tokens
+func (h *UserHandler) DeleteMe(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	if err := h.userService.DeleteUser(c.Request.Context(), username); err != nil {
+		c.JSON(profileErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	if err := h.tokenService.RevokeAllRefreshTokens(c.Request.Context(), username); err != nil {
+		c.JSON(http.StatusInternalServerError, res.Error("Failed to revoke refresh tokens"))
+		return
+	}
+
+	if err := h.jwtToken.RevokeToken(c.GetString(middleware.ContextTokenIDKey)); err != nil {
+		c.JSON(http.StatusInternalServerError, res.Error("Failed to revoke token"))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(nil, "Account deleted successfully"))
+}
+
+func (h *UserHandler) respondWithProfile(c *gin.Context, username string) {
+	user, err := h.userService.GetProfile(c.Request.Context(), username)
+	if err != nil {
+		c.JSON(profileErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(toProfileResponse(user), "Profile retrieved successfully"))
+}
+
+func toProfileResponse(user domain.User) dto.UserProfileResponse {
+	return dto.UserProfileResponse{
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarURL,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+	}
+}
+
+func profileErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidInput),
+		errors.Is(err, domain.ErrDisplayNameTooLong),
+		errors.Is(err, domain.ErrBioTooLong),
+		errors.Is(err, domain.ErrInvalidAvatarURL):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
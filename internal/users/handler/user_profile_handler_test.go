@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/ynwd/awesome-blog/internal/users/domain"
+	"github.com/ynwd/awesome-blog/internal/users/service"
+)
+
+func TestGetUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		setupMocks func(*MockUserService)
+		wantStatus int
+	}{
+		{
+			name: "Success",
+			setupMocks: func(ms *MockUserService) {
+				ms.On("GetProfile", mock.Anything, "testuser").
+					Return(domain.User{Username: "testuser", Password: "hash", Bio: "hello"}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Not Found",
+			setupMocks: func(ms *MockUserService) {
+				ms.On("GetProfile", mock.Anything, "testuser").
+					Return(domain.User{}, service.ErrNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockUserService)
+			tt.setupMocks(mockService)
+			h := NewUserHandler(mockService, nil, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/api/v1/users/testuser", nil)
+			c.Params = gin.Params{{Key: "username", Value: "testuser"}}
+
+			h.GetUser(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.NotContains(t, w.Body.String(), "password")
+			assert.NotContains(t, w.Body.String(), "hash")
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateMe(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		reqBody    interface{}
+		setupMocks func(*MockUserService)
+		wantStatus int
+	}{
+		{
+			name:    "Success",
+			reqBody: map[string]string{"display_name": "Test User"},
+			setupMocks: func(ms *MockUserService) {
+				ms.On("UpdateProfile", mock.Anything, "testuser", mock.MatchedBy(func(u domain.ProfileUpdate) bool {
+					return u.DisplayName != nil && *u.DisplayName == "Test User" && u.Bio == nil
+				})).Return(domain.User{Username: "testuser", DisplayName: "Test User"}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Invalid Request",
+			reqBody:    "invalid",
+			setupMocks: func(ms *MockUserService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "Validation Error",
+			reqBody: map[string]string{"avatar_url": "not-a-url"},
+			setupMocks: func(ms *MockUserService) {
+				ms.On("UpdateProfile", mock.Anything, "testuser", mock.Anything).
+					Return(domain.User{}, domain.ErrInvalidAvatarURL)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "Service Error",
+			reqBody: map[string]string{"bio": "hello"},
+			setupMocks: func(ms *MockUserService) {
+				ms.On("UpdateProfile", mock.Anything, "testuser", mock.Anything).
+					Return(domain.User{}, errors.New("service error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockUserService)
+			tt.setupMocks(mockService)
+			h := NewUserHandler(mockService, nil, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			jsonBody, _ := json.Marshal(tt.reqBody)
+			c.Request, _ = http.NewRequest(http.MethodPatch, "/api/v1/users/me", bytes.NewBuffer(jsonBody))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("user_id", "testuser")
+
+			h.UpdateMe(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteMe(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockUserService)
+	mockTokens := new(MockTokenService)
+	mockJWT := new(MockJWT)
+
+	mockService.On("DeleteUser", mock.Anything, "testuser").Return(nil)
+	mockTokens.On("RevokeAllRefreshTokens", mock.Anything, "testuser").Return(nil)
+	mockJWT.On("RevokeToken", "jti-123").Return(nil)
+
+	h := NewUserHandler(mockService, mockTokens, mockJWT)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodDelete, "/api/v1/users/me", nil)
+	c.Set("user_id", "testuser")
+	c.Set("token_id", "jti-123")
+
+	h.DeleteMe(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+	mockTokens.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+}
@@ -8,6 +8,17 @@ import (
 
 type UserRepository interface {
 	Create(ctx context.Context, user domain.User) error
-	GetByUsernameAndPassword(ctx context.Context, username string, password string) (domain.User, error)
+	GetByUsername(ctx context.Context, username string) (domain.User, error)
+	UpdatePassword(ctx context.Context, id string, passwordHash string) error
+	Update(ctx context.Context, user domain.User) error
+	Delete(ctx context.Context, id string) error
 	IsUsernameExists(ctx context.Context, username string) (bool, error)
 }
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token domain.RefreshToken) error
+	Get(ctx context.Context, id string) (domain.RefreshToken, error)
+	Rotate(ctx context.Context, id string, next domain.RefreshToken) error
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeUser(ctx context.Context, userID string) error
+}
@@ -0,0 +1,119 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/ynwd/awesome-blog/internal/users/domain"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type refreshTokenRepo struct {
+	client     *firestore.Client
+	collection string
+}
+
+func NewRefreshTokenRepository(client *firestore.Client) RefreshTokenRepository {
+	return &refreshTokenRepo{
+		client:     client,
+		collection: "refresh_tokens",
+	}
+}
+
+func (r *refreshTokenRepo) Create(ctx context.Context, token domain.RefreshToken) error {
+	_, err := r.client.Collection(r.collection).
+		Doc(token.ID).
+		Create(ctx, token)
+
+	return err
+}
+
+func (r *refreshTokenRepo) Get(ctx context.Context, id string) (domain.RefreshToken, error) {
+	doc, err := r.client.Collection(r.collection).Doc(id).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return domain.RefreshToken{}, domain.ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return domain.RefreshToken{}, err
+	}
+
+	var token domain.RefreshToken
+	if err := doc.DataTo(&token); err != nil {
+		return domain.RefreshToken{}, err
+	}
+
+	token.ID = doc.Ref.ID
+	return token, nil
+}
+
+// Rotate marks the token as replaced by next and stores next in a single
+// transaction, so two concurrent refreshes with the same token cannot both win.
+func (r *refreshTokenRepo) Rotate(ctx context.Context, id string, next domain.RefreshToken) error {
+	col := r.client.Collection(r.collection)
+
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(col.Doc(id))
+		if status.Code(err) == codes.NotFound {
+			return domain.ErrRefreshTokenInvalid
+		}
+		if err != nil {
+			return err
+		}
+
+		var current domain.RefreshToken
+		if err := doc.DataTo(&current); err != nil {
+			return err
+		}
+
+		switch {
+		case current.Revoked:
+			return domain.ErrRefreshTokenInvalid
+		case current.ReplacedBy != "":
+			return domain.ErrRefreshTokenReused
+		case current.IsExpired(time.Now()):
+			return domain.ErrRefreshTokenExpired
+		}
+
+		if err := tx.Update(col.Doc(id), []firestore.Update{
+			{Path: "replaced_by", Value: next.ID},
+		}); err != nil {
+			return err
+		}
+
+		return tx.Create(col.Doc(next.ID), next)
+	})
+}
+
+func (r *refreshTokenRepo) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.revokeWhere(ctx, "family_id", familyID)
+}
+
+func (r *refreshTokenRepo) RevokeUser(ctx context.Context, userID string) error {
+	return r.revokeWhere(ctx, "user_id", userID)
+}
+
+func (r *refreshTokenRepo) revokeWhere(ctx context.Context, path string, value string) error {
+	docs, err := r.client.Collection(r.collection).
+		Where(path, "==", value).
+		Where("revoked", "==", false).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return err
+	}
+
+	bw := r.client.BulkWriter(ctx)
+	for _, doc := range docs {
+		if _, err := bw.Update(doc.Ref, []firestore.Update{
+			{Path: "revoked", Value: true},
+		}); err != nil {
+			bw.End()
+			return err
+		}
+	}
+	bw.End()
+
+	return nil
+}
@@ -29,14 +29,16 @@ func (r *userRepo) Create(ctx context.Context, user domain.User) error {
 	return err
 }
 
-func (r *userRepo) GetByUsernameAndPassword(ctx context.Context, username string, password string) (domain.User, error) {
+func (r *userRepo) GetByUsername(ctx context.Context, username string) (domain.User, error) {
 	iter := r.client.Collection(r.collection).
 		Where("username", "==", username).
-		Where("password", "==", password).
 		Limit(1).
 		Documents(ctx)
 
 	doc, err := iter.Next()
+	if err == iterator.Done {
+		return domain.User{}, domain.ErrUserNotFound
+	}
 	if err != nil {
 		return domain.User{}, err
 	}
@@ -50,6 +52,37 @@ func (r *userRepo) GetByUsernameAndPassword(ctx context.Context, username string
 	return user, nil
 }
 
+func (r *userRepo) UpdatePassword(ctx context.Context, id string, passwordHash string) error {
+	_, err := r.client.Collection(r.collection).
+		Doc(id).
+		Update(ctx, []firestore.Update{
+			{Path: "password", Value: passwordHash},
+		})
+
+	return err
+}
+
+func (r *userRepo) Update(ctx context.Context, user domain.User) error {
+	_, err := r.client.Collection(r.collection).
+		Doc(user.Id).
+		Update(ctx, []firestore.Update{
+			{Path: "display_name", Value: user.DisplayName},
+			{Path: "bio", Value: user.Bio},
+			{Path: "avatar_url", Value: user.AvatarURL},
+			{Path: "updated_at", Value: user.UpdatedAt},
+		})
+
+	return err
+}
+
+func (r *userRepo) Delete(ctx context.Context, id string) error {
+	_, err := r.client.Collection(r.collection).
+		Doc(id).
+		Delete(ctx)
+
+	return err
+}
+
 func (r *userRepo) IsUsernameExists(ctx context.Context, username string) (bool, error) {
 	iter := r.client.Collection(r.collection).
 		Where("username", "==", username).
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/ynwd/awesome-blog/internal/users/domain"
@@ -42,7 +43,7 @@ func TestCreateUser(t *testing.T) {
 	assert.Equal(t, "testpass", retrievedUser.Password)
 }
 
-func TestGetByUsernameAndPassword(t *testing.T) {
+func TestGetByUsername(t *testing.T) {
 	client := helper.SetupRepoClient(t)
 	defer client.Close()
 
@@ -64,11 +65,78 @@ func TestGetByUsernameAndPassword(t *testing.T) {
 	_, _, err = client.Collection("users").Add(context.Background(), user)
 	assert.NoError(t, err)
 
-	// Test GetByUsernameAndPassword
-	retrievedUser, err := repo.GetByUsernameAndPassword(context.Background(), "testuser", "testpass")
+	// Test GetByUsername
+	retrievedUser, err := repo.GetByUsername(context.Background(), "testuser")
 	assert.NoError(t, err)
 	assert.Equal(t, "testuser", retrievedUser.Username)
 	assert.Equal(t, "testpass", retrievedUser.Password)
+
+	// Test GetByUsername for an unknown user
+	_, err = repo.GetByUsername(context.Background(), "unknown")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
+
+func TestUpdatePassword(t *testing.T) {
+	client := helper.SetupRepoClient(t)
+	defer client.Close()
+
+	os.Setenv("GOOGLE_CLOUD_FIRESTORE_COLLECTION_USERS", "users")
+	repo := NewFirestoreUserRepository(client)
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+
+	doc, _, err := client.Collection("users").Add(context.Background(), domain.User{
+		Username: "testuser",
+		Password: "testpass",
+	})
+	assert.NoError(t, err)
+
+	err = repo.UpdatePassword(context.Background(), doc.ID, "new-hash")
+	assert.NoError(t, err)
+
+	retrievedUser, err := repo.GetByUsername(context.Background(), "testuser")
+	assert.NoError(t, err)
+	assert.Equal(t, "new-hash", retrievedUser.Password)
+}
+
+func TestUpdateAndDeleteUser(t *testing.T) {
+	client := helper.SetupRepoClient(t)
+	defer client.Close()
+
+	os.Setenv("GOOGLE_CLOUD_FIRESTORE_COLLECTION_USERS", "users")
+	repo := NewFirestoreUserRepository(client)
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+
+	doc, _, err := client.Collection("users").Add(context.Background(), domain.User{
+		Username: "testuser",
+		Password: "testpass",
+	})
+	assert.NoError(t, err)
+
+	// Test Update
+	err = repo.Update(context.Background(), domain.User{
+		Id:          doc.ID,
+		DisplayName: "Test User",
+		Bio:         "Hello",
+		UpdatedAt:   time.Now(),
+	})
+	assert.NoError(t, err)
+
+	retrievedUser, err := repo.GetByUsername(context.Background(), "testuser")
+	assert.NoError(t, err)
+	assert.Equal(t, "Test User", retrievedUser.DisplayName)
+	assert.Equal(t, "Hello", retrievedUser.Bio)
+	assert.Equal(t, "testpass", retrievedUser.Password)
+
+	// Test Delete
+	err = repo.Delete(context.Background(), doc.ID)
+	assert.NoError(t, err)
+
+	_, err = repo.GetByUsername(context.Background(), "testuser")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
 }
 
 func TestIsUsernameExists(t *testing.T) {
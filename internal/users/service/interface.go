@@ -9,4 +9,15 @@ import (
 type UserService interface {
 	CreateUser(ctx context.Context, user domain.User) error
 	AuthenticateUser(ctx context.Context, username, password string) (domain.User, error)
+	GetProfile(ctx context.Context, username string) (domain.User, error)
+	UpdateProfile(ctx context.Context, username string, update domain.ProfileUpdate) (domain.User, error)
+	DeleteUser(ctx context.Context, username string) error
+	UserExists(ctx context.Context, username string) (bool, error)
+}
+
+type TokenService interface {
+	IssueRefreshToken(ctx context.Context, userID string) (string, error)
+	RotateRefreshToken(ctx context.Context, refreshToken string) (userID string, newRefreshToken string, err error)
+	RevokeRefreshToken(ctx context.Context, userID, refreshToken string) error
+	RevokeAllRefreshTokens(ctx context.Context, userID string) error
 }
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/users/domain"
+	"github.com/ynwd/awesome-blog/internal/users/repo"
+)
+
+const defaultRefreshTokenTTL = 7 * 24 * time.Hour
+
+type tokenService struct {
+	repo repo.RefreshTokenRepository
+	ttl  time.Duration
+}
+
+// NewTokenService creates a refresh token service. The token lifetime is read
+// from REFRESH_TOKEN_TTL (a Go duration such as "168h") and defaults to 7 days.
+func NewTokenService(repo repo.RefreshTokenRepository) TokenService {
+	ttl, err := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TTL"))
+	if err != nil || ttl <= 0 {
+		ttl = defaultRefreshTokenTTL
+	}
+
+	return &tokenService{
+		repo: repo,
+		ttl:  ttl,
+	}
+}
+
+func (s *tokenService) IssueRefreshToken(ctx context.Context, userID string) (string, error) {
+	if userID == "" {
+		return "", ErrInvalidInput
+	}
+
+	familyID, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	raw, token, err := s.newToken(userID, familyID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.repo.Create(ctx, token); err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one in the same family.
+// Presenting a token that was already rotated means it leaked, so the whole
+// family is revoked and the caller has to log in again.
+func (s *tokenService) RotateRefreshToken(ctx context.Context, refreshToken string) (string, string, error) {
+	if refreshToken == "" {
+		return "", "", ErrInvalidInput
+	}
+
+	current, err := s.repo.Get(ctx, hashToken(refreshToken))
+	if err != nil {
+		return "", "", err
+	}
+
+	raw, next, err := s.newToken(current.UserID, current.FamilyID)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = s.repo.Rotate(ctx, current.ID, next)
+	if errors.Is(err, domain.ErrRefreshTokenReused) {
+		if revokeErr := s.repo.RevokeFamily(ctx, current.FamilyID); revokeErr != nil {
+			log.Printf("Error revoking refresh token family %s: %v", current.FamilyID, revokeErr)
+		}
+		return "", "", err
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	return current.UserID, raw, nil
+}
+
+func (s *tokenService) RevokeRefreshToken(ctx context.Context, userID, refreshToken string) error {
+	if refreshToken == "" {
+		return ErrInvalidInput
+	}
+
+	current, err := s.repo.Get(ctx, hashToken(refreshToken))
+	if err != nil {
+		return err
+	}
+	if current.UserID != userID {
+		return domain.ErrRefreshTokenInvalid
+	}
+
+	return s.repo.RevokeFamily(ctx, current.FamilyID)
+}
+
+func (s *tokenService) RevokeAllRefreshTokens(ctx context.Context, userID string) error {
+	if userID == "" {
+		return ErrInvalidInput
+	}
+	return s.repo.RevokeUser(ctx, userID)
+}
+
+func (s *tokenService) newToken(userID, familyID string) (string, domain.RefreshToken, error) {
+	raw, err := randomToken()
+	if err != nil {
+		return "", domain.RefreshToken{}, err
+	}
+
+	now := time.Now().UTC()
+	return raw, domain.RefreshToken{
+		ID:        hashToken(raw),
+		UserID:    userID,
+		FamilyID:  familyID,
+		ExpiresAt: now.Add(s.ttl),
+		CreatedAt: now,
+	}, nil
+}
+
+func randomToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
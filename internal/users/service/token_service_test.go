@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/ynwd/awesome-blog/internal/users/domain"
+)
+
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenRepository) Create(ctx context.Context, token domain.RefreshToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) Get(ctx context.Context, id string) (domain.RefreshToken, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) Rotate(ctx context.Context, id string, next domain.RefreshToken) error {
+	args := m.Called(ctx, id, next)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	args := m.Called(ctx, familyID)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeUser(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func TestIssueRefreshToken(t *testing.T) {
+	mockRepo := new(MockRefreshTokenRepository)
+	service := NewTokenService(mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(token domain.RefreshToken) bool {
+		return token.UserID == "testuser" &&
+			token.FamilyID != "" &&
+			token.ExpiresAt.After(time.Now())
+	})).Return(nil)
+
+	token, err := service.IssueRefreshToken(ctx, "testuser")
+
+	assert.NoError(t, err)
+	assert.Len(t, token, 64)
+	mockRepo.AssertExpectations(t)
+
+	// Only the hash of the token is stored
+	stored := mockRepo.Calls[0].Arguments.Get(1).(domain.RefreshToken)
+	assert.Equal(t, hashToken(token), stored.ID)
+	assert.NotEqual(t, token, stored.ID)
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	current := domain.RefreshToken{
+		ID:        hashToken("old-token"),
+		UserID:    "testuser",
+		FamilyID:  "family-1",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockRefreshTokenRepository)
+		service := NewTokenService(mockRepo)
+
+		mockRepo.On("Get", ctx, current.ID).Return(current, nil)
+		mockRepo.On("Rotate", ctx, current.ID, mock.MatchedBy(func(next domain.RefreshToken) bool {
+			return next.FamilyID == "family-1" && next.UserID == "testuser"
+		})).Return(nil)
+
+		userID, next, err := service.RotateRefreshToken(ctx, "old-token")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "testuser", userID)
+		assert.NotEqual(t, "old-token", next)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reuse Revokes Family", func(t *testing.T) {
+		mockRepo := new(MockRefreshTokenRepository)
+		service := NewTokenService(mockRepo)
+
+		mockRepo.On("Get", ctx, current.ID).Return(current, nil)
+		mockRepo.On("Rotate", ctx, current.ID, mock.Anything).Return(domain.ErrRefreshTokenReused)
+		mockRepo.On("RevokeFamily", ctx, "family-1").Return(nil)
+
+		_, _, err := service.RotateRefreshToken(ctx, "old-token")
+
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenReused)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown Token", func(t *testing.T) {
+		mockRepo := new(MockRefreshTokenRepository)
+		service := NewTokenService(mockRepo)
+
+		mockRepo.On("Get", ctx, hashToken("unknown")).Return(domain.RefreshToken{}, domain.ErrRefreshTokenInvalid)
+
+		_, _, err := service.RotateRefreshToken(ctx, "unknown")
+
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenInvalid)
+		mockRepo.AssertNotCalled(t, "Rotate")
+	})
+
+	t.Run("Empty Token", func(t *testing.T) {
+		service := NewTokenService(new(MockRefreshTokenRepository))
+
+		_, _, err := service.RotateRefreshToken(ctx, "")
+
+		assert.Equal(t, ErrInvalidInput, err)
+	})
+}
+
+func TestRevokeRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	current := domain.RefreshToken{
+		ID:       hashToken("token"),
+		UserID:   "testuser",
+		FamilyID: "family-1",
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockRefreshTokenRepository)
+		service := NewTokenService(mockRepo)
+
+		mockRepo.On("Get", ctx, current.ID).Return(current, nil)
+		mockRepo.On("RevokeFamily", ctx, "family-1").Return(nil)
+
+		err := service.RevokeRefreshToken(ctx, "testuser", "token")
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Token Of Another User", func(t *testing.T) {
+		mockRepo := new(MockRefreshTokenRepository)
+		service := NewTokenService(mockRepo)
+
+		mockRepo.On("Get", ctx, current.ID).Return(current, nil)
+
+		err := service.RevokeRefreshToken(ctx, "otheruser", "token")
+
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenInvalid)
+		mockRepo.AssertNotCalled(t, "RevokeFamily")
+	})
+}
@@ -3,29 +3,35 @@ package service
 import (
 	"context"
 	"errors"
+	"log"
+	"time"
 
 	"github.com/ynwd/awesome-blog/internal/users/domain"
 	"github.com/ynwd/awesome-blog/internal/users/repo"
+	"github.com/ynwd/awesome-blog/pkg/utils"
 )
 
 var (
-	ErrInvalidInput   = errors.New("invalid input")
-	ErrNotFound       = errors.New("user not found")
-	ErrUsernameExists = errors.New("username already exists")
+	ErrInvalidInput       = errors.New("invalid input")
+	ErrNotFound           = errors.New("user not found")
+	ErrUsernameExists     = errors.New("username already exists")
+	ErrInvalidCredentials = errors.New("invalid credentials")
 )
 
 type userService struct {
-	repo repo.UserRepository
+	repo   repo.UserRepository
+	hasher utils.PasswordHasher
 }
 
-func NewUserService(repo repo.UserRepository) UserService {
+func NewUserService(repo repo.UserRepository, hasher utils.PasswordHasher) UserService {
 	return &userService{
-		repo: repo,
+		repo:   repo,
+		hasher: hasher,
 	}
 }
 
 func (s *userService) CreateUser(ctx context.Context, user domain.User) error {
-	if err := validateUser(user); err != nil {
+	if err := user.Validate(); err != nil {
 		return err
 	}
 
@@ -37,6 +43,16 @@ func (s *userService) CreateUser(ctx context.Context, user domain.User) error {
 		return ErrUsernameExists
 	}
 
+	hash, err := s.hasher.Hash(user.Password)
+	if err != nil {
+		return err
+	}
+	user.Password = hash
+
+	now := time.Now().UTC()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
 	return s.repo.Create(ctx, user)
 }
 
@@ -44,12 +60,88 @@ func (s *userService) AuthenticateUser(ctx context.Context, username, password s
 	if username == "" || password == "" {
 		return domain.User{}, ErrInvalidInput
 	}
-	return s.repo.GetByUsernameAndPassword(ctx, username, password)
+
+	user, err := s.repo.GetByUsername(ctx, username)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return domain.User{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if err := s.hasher.Compare(user.Password, password); err != nil {
+		if errors.Is(err, utils.ErrPasswordMismatch) {
+			return domain.User{}, ErrInvalidCredentials
+		}
+		return domain.User{}, err
+	}
+
+	// Migrate legacy plaintext records (or outdated costs) on successful login
+	if s.hasher.NeedsRehash(user.Password) {
+		s.rehashPassword(ctx, &user, password)
+	}
+
+	return user, nil
+}
+
+func (s *userService) GetProfile(ctx context.Context, username string) (domain.User, error) {
+	if username == "" {
+		return domain.User{}, ErrInvalidInput
+	}
+
+	user, err := s.repo.GetByUsername(ctx, username)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return domain.User{}, ErrNotFound
+	}
+	return user, err
 }
 
-func validateUser(user domain.User) error {
-	if user.Username == "" || user.Password == "" {
-		return ErrInvalidInput
+func (s *userService) UpdateProfile(ctx context.Context, username string, update domain.ProfileUpdate) (domain.User, error) {
+	user, err := s.GetProfile(ctx, username)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	user.Apply(update)
+	if err := user.ValidateProfile(); err != nil {
+		return domain.User{}, err
+	}
+
+	user.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Update(ctx, user); err != nil {
+		return domain.User{}, err
+	}
+	return user, nil
+}
+
+func (s *userService) DeleteUser(ctx context.Context, username string) error {
+	user, err := s.GetProfile(ctx, username)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, user.Id)
+}
+
+// UserExists implements module.UserLookup
+func (s *userService) UserExists(ctx context.Context, username string) (bool, error) {
+	if username == "" {
+		return false, nil
+	}
+	return s.repo.IsUsernameExists(ctx, username)
+}
+
+// rehashPassword stores a fresh hash for the user. A failure here must not
+// block the login, so it is only logged and retried on the next login.
+func (s *userService) rehashPassword(ctx context.Context, user *domain.User, password string) {
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		log.Printf("Error rehashing password for user %s: %v", user.Username, err)
+		return
+	}
+
+	if err := s.repo.UpdatePassword(ctx, user.Id, hash); err != nil {
+		log.Printf("Error migrating password for user %s: %v", user.Username, err)
+		return
 	}
-	return nil
+	user.Password = hash
 }
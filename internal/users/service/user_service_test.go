@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/ynwd/awesome-blog/internal/users/domain"
+	"github.com/ynwd/awesome-blog/pkg/utils"
 )
 
 // MockUserRepository is a mock implementation of UserRepository interface
@@ -19,11 +20,31 @@ func (m *MockUserRepository) Create(ctx context.Context, user domain.User) error
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) GetByUsernameAndPassword(ctx context.Context, username string, password string) (domain.User, error) {
-	args := m.Called(ctx, username, password)
+func (m *MockUserRepository) GetByUsername(ctx context.Context, username string) (domain.User, error) {
+	args := m.Called(ctx, username)
 	return args.Get(0).(domain.User), args.Error(1)
 }
 
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, id string, passwordHash string) error {
+	args := m.Called(ctx, id, passwordHash)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Update(ctx context.Context, user domain.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// newTestHasher uses the minimum bcrypt cost to keep tests fast
+func newTestHasher() utils.PasswordHasher {
+	return utils.NewBcryptHasher(4)
+}
+
 func (m *MockUserRepository) IsUsernameExists(ctx context.Context, username string) (bool, error) {
 	args := m.Called(ctx, username)
 	return args.Bool(0), args.Error(1)
@@ -31,7 +52,7 @@ func (m *MockUserRepository) IsUsernameExists(ctx context.Context, username stri
 
 func TestNewUserService(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := NewUserService(mockRepo)
+	service := NewUserService(mockRepo, newTestHasher())
 
 	assert.NotNil(t, service)
 	assert.Equal(t, mockRepo, service.(*userService).repo)
@@ -39,7 +60,7 @@ func TestNewUserService(t *testing.T) {
 
 func TestCreateUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := NewUserService(mockRepo)
+	service := NewUserService(mockRepo, newTestHasher())
 	ctx := context.Background()
 
 	t.Run("Success", func(t *testing.T) {
@@ -49,7 +70,11 @@ func TestCreateUser(t *testing.T) {
 		}
 
 		mockRepo.On("IsUsernameExists", ctx, user.Username).Return(false, nil)
-		mockRepo.On("Create", ctx, user).Return(nil)
+		mockRepo.On("Create", ctx, mock.MatchedBy(func(u domain.User) bool {
+			return u.Username == user.Username &&
+				u.Password != user.Password &&
+				utils.IsPasswordHash(u.Password)
+		})).Return(nil)
 
 		err := service.CreateUser(ctx, user)
 
@@ -64,7 +89,7 @@ func TestCreateUser(t *testing.T) {
 
 		err := service.CreateUser(ctx, user)
 
-		assert.Equal(t, ErrInvalidInput, err)
+		assert.Equal(t, domain.ErrUsernameRequired, err)
 		mockRepo.AssertNotCalled(t, "IsUsernameExists")
 		mockRepo.AssertNotCalled(t, "Create")
 	})
@@ -76,14 +101,25 @@ func TestCreateUser(t *testing.T) {
 
 		err := service.CreateUser(ctx, user)
 
-		assert.Equal(t, ErrInvalidInput, err)
+		assert.Equal(t, domain.ErrPasswordRequired, err)
 		mockRepo.AssertNotCalled(t, "IsUsernameExists")
 		mockRepo.AssertNotCalled(t, "Create")
 	})
 
+	t.Run("Password Too Short", func(t *testing.T) {
+		user := domain.User{
+			Username: "testuser",
+			Password: "abc",
+		}
+
+		err := service.CreateUser(ctx, user)
+
+		assert.Equal(t, domain.ErrInvalidPassword, err)
+	})
+
 	t.Run("Username Already Exists", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, newTestHasher())
 		ctx := context.Background()
 
 		user := domain.User{
@@ -104,24 +140,87 @@ func TestCreateUser(t *testing.T) {
 }
 
 func TestAuthenticateUser(t *testing.T) {
+	hasher := newTestHasher()
+	hash, err := hasher.Hash("testpass")
+	assert.NoError(t, err)
+
 	mockRepo := new(MockUserRepository)
-	service := NewUserService(mockRepo)
+	service := NewUserService(mockRepo, hasher)
 	ctx := context.Background()
 
 	t.Run("Success", func(t *testing.T) {
 		expectedUser := domain.User{
 			Id:       "123",
 			Username: "testuser",
-			Password: "testpass",
+			Password: hash,
 		}
 
-		mockRepo.On("GetByUsernameAndPassword", ctx, "testuser", "testpass").Return(expectedUser, nil)
+		mockRepo.On("GetByUsername", ctx, "testuser").Return(expectedUser, nil).Once()
 
 		user, err := service.AuthenticateUser(ctx, "testuser", "testpass")
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedUser, user)
 		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "UpdatePassword")
+	})
+
+	t.Run("Wrong Password", func(t *testing.T) {
+		mockRepo.On("GetByUsername", ctx, "testuser").Return(domain.User{
+			Id:       "123",
+			Username: "testuser",
+			Password: hash,
+		}, nil).Once()
+
+		user, err := service.AuthenticateUser(ctx, "testuser", "wrongpass")
+
+		assert.Equal(t, ErrInvalidCredentials, err)
+		assert.Empty(t, user)
+	})
+
+	t.Run("Unknown User", func(t *testing.T) {
+		mockRepo.On("GetByUsername", ctx, "nobody").Return(domain.User{}, domain.ErrUserNotFound).Once()
+
+		user, err := service.AuthenticateUser(ctx, "nobody", "testpass")
+
+		assert.Equal(t, ErrInvalidCredentials, err)
+		assert.Empty(t, user)
+	})
+
+	t.Run("Migrates Legacy Plaintext Password", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, hasher)
+
+		mockRepo.On("GetByUsername", ctx, "legacy").Return(domain.User{
+			Id:       "456",
+			Username: "legacy",
+			Password: "testpass",
+		}, nil).Once()
+		mockRepo.On("UpdatePassword", ctx, "456", mock.MatchedBy(func(h string) bool {
+			return hasher.Compare(h, "testpass") == nil && utils.IsPasswordHash(h)
+		})).Return(nil).Once()
+
+		user, err := service.AuthenticateUser(ctx, "legacy", "testpass")
+
+		assert.NoError(t, err)
+		assert.True(t, utils.IsPasswordHash(user.Password))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Legacy Plaintext Password Mismatch", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, hasher)
+
+		mockRepo.On("GetByUsername", ctx, "legacy").Return(domain.User{
+			Id:       "456",
+			Username: "legacy",
+			Password: "testpass",
+		}, nil).Once()
+
+		_, err := service.AuthenticateUser(ctx, "legacy", "wrongpass")
+
+		assert.Equal(t, ErrInvalidCredentials, err)
+		mockRepo.AssertNotCalled(t, "UpdatePassword")
 	})
 
 	t.Run("Empty Username", func(t *testing.T) {
@@ -129,7 +228,6 @@ func TestAuthenticateUser(t *testing.T) {
 
 		assert.Equal(t, ErrInvalidInput, err)
 		assert.Empty(t, user)
-		mockRepo.AssertNotCalled(t, "GetByUsernameAndPassword")
 	})
 
 	t.Run("Empty Password", func(t *testing.T) {
@@ -137,36 +235,106 @@ func TestAuthenticateUser(t *testing.T) {
 
 		assert.Equal(t, ErrInvalidInput, err)
 		assert.Empty(t, user)
-		mockRepo.AssertNotCalled(t, "GetByUsernameAndPassword")
 	})
 }
 
-func TestValidateUser(t *testing.T) {
-	t.Run("Valid User", func(t *testing.T) {
-		user := domain.User{
-			Username: "testuser",
-			Password: "testpass",
-		}
+func TestGetProfile(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, newTestHasher())
+		expectedUser := domain.User{Id: "123", Username: "testuser", Bio: "hello"}
+
+		mockRepo.On("GetByUsername", ctx, "testuser").Return(expectedUser, nil)
+
+		user, err := service.GetProfile(ctx, "testuser")
 
-		err := validateUser(user)
 		assert.NoError(t, err)
+		assert.Equal(t, expectedUser, user)
 	})
 
-	t.Run("Empty Username", func(t *testing.T) {
-		user := domain.User{
-			Password: "testpass",
-		}
+	t.Run("Not Found", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, newTestHasher())
 
-		err := validateUser(user)
-		assert.Equal(t, ErrInvalidInput, err)
+		mockRepo.On("GetByUsername", ctx, "nobody").Return(domain.User{}, domain.ErrUserNotFound)
+
+		_, err := service.GetProfile(ctx, "nobody")
+
+		assert.Equal(t, ErrNotFound, err)
 	})
+}
 
-	t.Run("Empty Password", func(t *testing.T) {
-		user := domain.User{
-			Username: "testuser",
-		}
+func TestUpdateProfile(t *testing.T) {
+	ctx := context.Background()
+	stored := domain.User{Id: "123", Username: "testuser", DisplayName: "Old Name"}
 
-		err := validateUser(user)
-		assert.Equal(t, ErrInvalidInput, err)
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, newTestHasher())
+		bio := "Writing about Go"
+
+		mockRepo.On("GetByUsername", ctx, "testuser").Return(stored, nil)
+		mockRepo.On("Update", ctx, mock.MatchedBy(func(u domain.User) bool {
+			return u.Id == "123" &&
+				u.DisplayName == "Old Name" &&
+				u.Bio == bio &&
+				!u.UpdatedAt.IsZero()
+		})).Return(nil)
+
+		user, err := service.UpdateProfile(ctx, "testuser", domain.ProfileUpdate{Bio: &bio})
+
+		assert.NoError(t, err)
+		assert.Equal(t, bio, user.Bio)
+		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Invalid Avatar URL", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, newTestHasher())
+		avatar := "javascript:alert(1)"
+
+		mockRepo.On("GetByUsername", ctx, "testuser").Return(stored, nil)
+
+		_, err := service.UpdateProfile(ctx, "testuser", domain.ProfileUpdate{AvatarURL: &avatar})
+
+		assert.Equal(t, domain.ErrInvalidAvatarURL, err)
+		mockRepo.AssertNotCalled(t, "Update")
+	})
+}
+
+func TestDeleteUser(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, newTestHasher())
+
+	mockRepo.On("GetByUsername", ctx, "testuser").Return(domain.User{Id: "123", Username: "testuser"}, nil)
+	mockRepo.On("Delete", ctx, "123").Return(nil)
+
+	err := service.DeleteUser(ctx, "testuser")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserExists(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, newTestHasher())
+
+	mockRepo.On("IsUsernameExists", ctx, "testuser").Return(true, nil)
+	mockRepo.On("IsUsernameExists", ctx, "nobody").Return(false, nil)
+
+	exists, err := service.UserExists(ctx, "testuser")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = service.UserExists(ctx, "nobody")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	exists, err = service.UserExists(ctx, "")
+	assert.NoError(t, err)
+	assert.False(t, exists)
 }
@@ -1,8 +1,6 @@
 package users
 
 import (
-	"context"
-
 	"cloud.google.com/go/firestore"
 
 	"github.com/ynwd/awesome-blog/internal/users/handler"
@@ -13,30 +11,32 @@ import (
 )
 
 type Module struct {
-	h *handler.UserHandler
+	h       *handler.UserHandler
+	service service.UserService
 }
 
-func NewModule(firestoreClient *firestore.Client) *Module {
+func NewModule(firestoreClient *firestore.Client, jwt utils.JWT) *Module {
 	// Initialize repository
 	userRepo := repo.NewFirestoreUserRepository(firestoreClient)
 
 	// Initialize service with repository
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, utils.NewPasswordHasher())
 
-	// Initialize blacklist
-	blackList := utils.NewMemoryBlacklist()
+	// Initialize refresh token service
+	tokenService := service.NewTokenService(repo.NewRefreshTokenRepository(firestoreClient))
 
 	// Initialize handler with service
-	jwt, err := utils.NewJWT(blackList)
-	if err != nil {
-		panic(err)
-	}
-
-	userHandler := handler.NewUserHandler(userService, jwt)
+	userHandler := handler.NewUserHandler(userService, tokenService, jwt)
 
 	return &Module{
-		h: userHandler,
+		h:       userHandler,
+		service: userService,
 	}
 }
 
-func (m *Module) RegisterEventHandlers(ctx context.Context, event module.BaseEvent) {}
+// UserLookup exposes user existence checks to other modules
+func (m *Module) UserLookup() module.UserLookup {
+	return m.service
+}
+
+func (m *Module) RegisterEvents(registry *module.EventRegistry) {}
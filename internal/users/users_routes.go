@@ -5,4 +5,11 @@ import "github.com/gin-gonic/gin"
 func (m *Module) RegisterRoutes(r *gin.Engine) {
 	r.POST("/api/v1/auth/register", m.h.Register)
 	r.POST("/api/v1/auth/login", m.h.Login)
+	r.POST("/api/v1/auth/refresh", m.h.Refresh)
+	r.POST("/api/v1/auth/logout", m.h.Logout)
+
+	r.GET("/api/v1/users/me", m.h.GetMe)
+	r.PATCH("/api/v1/users/me", m.h.UpdateMe)
+	r.DELETE("/api/v1/users/me", m.h.DeleteMe)
+	r.GET("/api/v1/users/:username", m.h.GetUser)
 }
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrInvalidURL       = errors.New("invalid url: must be an absolute http or https URL")
+	ErrForbiddenURL     = errors.New("invalid url: must not point at a loopback, private or link-local address")
+	ErrInvalidEventType = errors.New("invalid event type")
+	ErrNoEventTypes     = errors.New("invalid event types: at least one is required")
+	ErrInvalidSecret    = errors.New("invalid secret: must be at least 16 characters")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrDeliveryClaimed  = errors.New("delivery is not due or was claimed by another replica")
+)
+
+// EventTypes are the domain events webhooks can subscribe to
+var EventTypes = []module.EventType{
+	module.PostCreatedEvent,
+	module.PostUpdatedEvent,
+	module.PostDeletedEvent,
+	module.CommentCreatedEvent,
+	module.CommentUpdatedEvent,
+	module.CommentDeletedEvent,
+	module.LikeCreatedEvent,
+	module.LikeDeletedEvent,
+}
+
+// Webhook is an endpoint a user registered to receive the events in
+// EventTypes. It is disabled after too many deliveries in a row failed.
+type Webhook struct {
+	ID                  string    `firestore:"-"`
+	Username            string    `firestore:"username"`
+	URL                 string    `firestore:"url"`
+	EventTypes          []string  `firestore:"event_types"`
+	Secret              string    `firestore:"secret"`
+	Active              bool      `firestore:"active"`
+	ConsecutiveFailures int       `firestore:"consecutive_failures"`
+	DisabledReason      string    `firestore:"disabled_reason"`
+	CreatedAt           time.Time `firestore:"created_at"`
+	UpdatedAt           time.Time `firestore:"updated_at"`
+}
+
+// Subscribes reports whether the webhook receives events of the given type
+func (w Webhook) Subscribes(eventType module.EventType) bool {
+	for _, t := range w.EventTypes {
+		if t == string(eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookUpdate holds the fields to change on a webhook; nil fields are left
+// as is. Setting Active re-enables a disabled webhook.
+type WebhookUpdate struct {
+	URL        *string
+	EventTypes []string
+	Secret     *string
+	Active     *bool
+}
+
+type DeliveryStatus string
+
+const (
+	// DeliveryPending deliveries wait for their next attempt
+	DeliveryPending DeliveryStatus = "pending"
+
+	// DeliverySending deliveries were claimed by a replica that is sending
+	// them, until their lease runs out
+	DeliverySending DeliveryStatus = "sending"
+
+	// DeliverySucceeded deliveries got a 2xx response
+	DeliverySucceeded DeliveryStatus = "succeeded"
+
+	// DeliveryFailed deliveries failed on every attempt, or their webhook
+	// was disabled or deleted before they were sent
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// Delivery is one event sent to one webhook. Its ID is derived from both, so
+// an event delivered to the subscriber twice is sent once. Payload is the
+// signed request body.
+type Delivery struct {
+	ID             string         `firestore:"-"`
+	WebhookID      string         `firestore:"webhook_id"`
+	EventID        string         `firestore:"event_id"`
+	EventType      string         `firestore:"event_type"`
+	Payload        string         `firestore:"payload"`
+	Status         DeliveryStatus `firestore:"status"`
+	Attempts       int            `firestore:"attempts"`
+	ResponseStatus int            `firestore:"response_status"`
+	Error          string         `firestore:"error"`
+	NextAttemptAt  time.Time      `firestore:"next_attempt_at"`
+	ClaimedUntil   time.Time      `firestore:"claimed_until"`
+	CreatedAt      time.Time      `firestore:"created_at"`
+	UpdatedAt      time.Time      `firestore:"updated_at"`
+}
+
+// Claimable reports whether a replica may claim the delivery at now: it is
+// pending and due, or the replica sending it let its lease run out
+func (d Delivery) Claimable(now time.Time) bool {
+	switch d.Status {
+	case DeliveryPending:
+		return !d.NextAttemptAt.After(now)
+	case DeliverySending:
+		return !d.ClaimedUntil.After(now)
+	}
+	return false
+}
+
+// DeliveryID returns the ID of the delivery of an event to a webhook
+func DeliveryID(webhookID, eventID string) string {
+	return webhookID + "_" + eventID
+}
+
+// DeliveryFilter selects a page of a webhook's deliveries, newest first
+type DeliveryFilter struct {
+	WebhookID string
+	Cursor    string
+	Limit     int
+}
+
+// DeliveryPage is a page of deliveries. NextCursor is empty on the last page.
+type DeliveryPage struct {
+	Deliveries []Delivery
+	NextCursor string
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/ynwd/awesome-blog/internal/webhooks/service"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+)
+
+type WebhooksEventHandler struct {
+	dispatcher service.Dispatcher
+	processed  utils.ProcessedEvents
+}
+
+func NewWebhooksEventHandler(dispatcher service.Dispatcher, processed utils.ProcessedEvents) *WebhooksEventHandler {
+	return &WebhooksEventHandler{
+		dispatcher: dispatcher,
+		processed:  processed,
+	}
+}
+
+// Handle queues the domain event for the webhooks subscribed to it. The
+// payload is forwarded as published.
+func (h *WebhooksEventHandler) Handle(ctx context.Context, event module.Event[json.RawMessage]) error {
+	return utils.HandleOnce(ctx, h.processed, "webhooks", event.ID, func() error {
+		if err := h.dispatcher.Enqueue(ctx, event); err != nil {
+			log.Printf("Error queueing webhook deliveries for %s %s: %v", event.Type, event.ID, err)
+			return err
+		}
+		return nil
+	})
+}
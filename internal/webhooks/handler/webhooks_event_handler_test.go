@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+)
+
+type mockDispatcher struct {
+	enqueueFunc func(ctx context.Context, event module.Event[json.RawMessage]) error
+}
+
+func (m *mockDispatcher) Enqueue(ctx context.Context, event module.Event[json.RawMessage]) error {
+	return m.enqueueFunc(ctx, event)
+}
+
+func (m *mockDispatcher) DeliverDue(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (m *mockDispatcher) Cleanup(ctx context.Context, createdBefore time.Time) error {
+	return nil
+}
+
+func TestWebhooksEventHandler_Handle(t *testing.T) {
+	var enqueued []module.Event[json.RawMessage]
+	fail := true
+	dispatcher := &mockDispatcher{
+		enqueueFunc: func(ctx context.Context, event module.Event[json.RawMessage]) error {
+			if fail {
+				return errors.New("firestore error")
+			}
+			enqueued = append(enqueued, event)
+			return nil
+		},
+	}
+	handler := NewWebhooksEventHandler(dispatcher, utils.NewMemoryProcessedEvents(time.Hour))
+
+	registry := module.NewEventRegistry()
+	module.Handle(registry, module.PostCreatedEvent, module.PostCreatedEventVersion, handler.Handle)
+
+	data, err := json.Marshal(module.NewEvent(module.PostCreatedEvent, map[string]string{"title": "Hello"}))
+	assert.NoError(t, err)
+
+	// A failed enqueue is retried, and a redelivered event is queued once
+	assert.Error(t, registry.Dispatch(context.Background(), data))
+	fail = false
+	assert.NoError(t, registry.Dispatch(context.Background(), data))
+	assert.NoError(t, registry.Dispatch(context.Background(), data))
+
+	assert.Len(t, enqueued, 1)
+	assert.Equal(t, module.PostCreatedEvent, enqueued[0].Type)
+	assert.JSONEq(t, `{"title": "Hello"}`, string(enqueued[0].Payload))
+}
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ynwd/awesome-blog/internal/webhooks/domain"
+	"github.com/ynwd/awesome-blog/internal/webhooks/dto"
+	"github.com/ynwd/awesome-blog/internal/webhooks/service"
+	"github.com/ynwd/awesome-blog/pkg/middleware"
+	"github.com/ynwd/awesome-blog/pkg/res"
+)
+
+type WebhooksHandler struct {
+	service service.WebhooksService
+}
+
+func NewWebhooksHandler(service service.WebhooksService) *WebhooksHandler {
+	return &WebhooksHandler{
+		service: service,
+	}
+}
+
+// CreateWebhook registers a webhook for the authenticated user. The response
+// is the only one that includes the secret.
+func (h *WebhooksHandler) CreateWebhook(c *gin.Context) {
+	var req dto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error(err.Error()))
+		return
+	}
+
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(c.Request.Context(), domain.Webhook{
+		Username:   username,
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     req.Secret,
+	})
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	response := toWebhookResponse(webhook)
+	response.Secret = webhook.Secret
+	c.JSON(http.StatusCreated, res.Success(response, "Webhook created successfully"))
+}
+
+func (h *WebhooksHandler) ListWebhooks(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	webhooks, err := h.service.ListWebhooks(c.Request.Context(), username)
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	response := make([]dto.WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		response = append(response, toWebhookResponse(webhook))
+	}
+	c.JSON(http.StatusOK, res.Success(response, "Webhooks retrieved successfully"))
+}
+
+func (h *WebhooksHandler) GetWebhook(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	webhook, err := h.service.GetWebhook(c.Request.Context(), username, c.Param("id"))
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(toWebhookResponse(webhook), "Webhook retrieved successfully"))
+}
+
+// UpdateWebhook changes a webhook; setting active to true re-enables a
+// webhook that was disabled after failed deliveries
+func (h *WebhooksHandler) UpdateWebhook(c *gin.Context) {
+	var req dto.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error(err.Error()))
+		return
+	}
+
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	webhook, err := h.service.UpdateWebhook(c.Request.Context(), username, c.Param("id"), domain.WebhookUpdate{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     req.Secret,
+		Active:     req.Active,
+	})
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(toWebhookResponse(webhook), "Webhook updated successfully"))
+}
+
+func (h *WebhooksHandler) DeleteWebhook(c *gin.Context) {
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	if err := h.service.DeleteWebhook(c.Request.Context(), username, c.Param("id")); err != nil {
+		c.JSON(webhookErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, res.Success(nil, "Webhook deleted successfully"))
+}
+
+// ListDeliveries returns the webhook's delivery log, newest first
+func (h *WebhooksHandler) ListDeliveries(c *gin.Context) {
+	var query dto.ListDeliveriesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, res.Error("Invalid query parameters"))
+		return
+	}
+
+	username, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, res.Error(middleware.ErrUnauthenticated.Error()))
+		return
+	}
+
+	page, err := h.service.ListDeliveries(c.Request.Context(), username, domain.DeliveryFilter{
+		WebhookID: c.Param("id"),
+		Cursor:    query.Cursor,
+		Limit:     query.Limit,
+	})
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), res.Error(err.Error()))
+		return
+	}
+
+	response := dto.ListDeliveriesResponse{
+		Deliveries: make([]dto.DeliveryResponse, 0, len(page.Deliveries)),
+		NextCursor: page.NextCursor,
+	}
+	for _, delivery := range page.Deliveries {
+		response.Deliveries = append(response.Deliveries, toDeliveryResponse(delivery))
+	}
+
+	c.JSON(http.StatusOK, res.Success(response, "Deliveries retrieved successfully"))
+}
+
+func toWebhookResponse(webhook domain.Webhook) dto.WebhookResponse {
+	return dto.WebhookResponse{
+		ID:                  webhook.ID,
+		URL:                 webhook.URL,
+		EventTypes:          webhook.EventTypes,
+		Active:              webhook.Active,
+		ConsecutiveFailures: webhook.ConsecutiveFailures,
+		DisabledReason:      webhook.DisabledReason,
+		CreatedAt:           webhook.CreatedAt,
+		UpdatedAt:           webhook.UpdatedAt,
+	}
+}
+
+func toDeliveryResponse(delivery domain.Delivery) dto.DeliveryResponse {
+	response := dto.DeliveryResponse{
+		ID:             delivery.ID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        []byte(delivery.Payload),
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		Error:          delivery.Error,
+		CreatedAt:      delivery.CreatedAt,
+		UpdatedAt:      delivery.UpdatedAt,
+	}
+
+	// Only pending deliveries have a next attempt
+	if delivery.Status == domain.DeliveryPending {
+		nextAttemptAt := delivery.NextAttemptAt
+		response.NextAttemptAt = &nextAttemptAt
+	}
+	return response
+}
+
+func webhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrWebhookNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidURL),
+		errors.Is(err, domain.ErrForbiddenURL),
+		errors.Is(err, domain.ErrInvalidEventType),
+		errors.Is(err, domain.ErrNoEventTypes),
+		errors.Is(err, domain.ErrInvalidSecret),
+		errors.Is(err, domain.ErrInvalidCursor):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrInvalidUsername):
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/ynwd/awesome-blog/internal/webhooks/domain"
+)
+
+type mockWebhooksService struct {
+	createWebhookFunc  func(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error)
+	listWebhooksFunc   func(ctx context.Context, username string) ([]domain.Webhook, error)
+	getWebhookFunc     func(ctx context.Context, username, id string) (domain.Webhook, error)
+	updateWebhookFunc  func(ctx context.Context, username, id string, update domain.WebhookUpdate) (domain.Webhook, error)
+	deleteWebhookFunc  func(ctx context.Context, username, id string) error
+	listDeliveriesFunc func(ctx context.Context, username string, filter domain.DeliveryFilter) (domain.DeliveryPage, error)
+}
+
+func (m *mockWebhooksService) CreateWebhook(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	return m.createWebhookFunc(ctx, webhook)
+}
+
+func (m *mockWebhooksService) ListWebhooks(ctx context.Context, username string) ([]domain.Webhook, error) {
+	return m.listWebhooksFunc(ctx, username)
+}
+
+func (m *mockWebhooksService) GetWebhook(ctx context.Context, username, id string) (domain.Webhook, error) {
+	return m.getWebhookFunc(ctx, username, id)
+}
+
+func (m *mockWebhooksService) UpdateWebhook(ctx context.Context, username, id string, update domain.WebhookUpdate) (domain.Webhook, error) {
+	return m.updateWebhookFunc(ctx, username, id, update)
+}
+
+func (m *mockWebhooksService) DeleteWebhook(ctx context.Context, username, id string) error {
+	return m.deleteWebhookFunc(ctx, username, id)
+}
+
+func (m *mockWebhooksService) ListDeliveries(ctx context.Context, username string, filter domain.DeliveryFilter) (domain.DeliveryPage, error) {
+	return m.listDeliveriesFunc(ctx, username, filter)
+}
+
+var createdAt = time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+
+var testWebhook = domain.Webhook{
+	ID:         "hook-1",
+	Username:   "user1",
+	URL:        "https://example.com/hook",
+	EventTypes: []string{"POST_CREATED"},
+	Secret:     "0123456789abcdef",
+	Active:     true,
+	CreatedAt:  createdAt,
+	UpdatedAt:  createdAt,
+}
+
+func setupRouter(service *mockWebhooksService, username string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if username != "" {
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", username)
+		})
+	}
+	h := NewWebhooksHandler(service)
+	router.POST("/webhooks", h.CreateWebhook)
+	router.GET("/webhooks", h.ListWebhooks)
+	router.PATCH("/webhooks/:id", h.UpdateWebhook)
+	router.GET("/webhooks/:id/deliveries", h.ListDeliveries)
+	return router
+}
+
+func TestWebhooksHandler_CreateWebhook(t *testing.T) {
+	service := &mockWebhooksService{
+		createWebhookFunc: func(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+			if webhook.URL == "bad" {
+				return domain.Webhook{}, domain.ErrInvalidURL
+			}
+			assert.Equal(t, "user1", webhook.Username)
+			assert.Equal(t, []string{"POST_CREATED"}, webhook.EventTypes)
+			return testWebhook, nil
+		},
+	}
+
+	tests := []struct {
+		name       string
+		username   string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "created with secret",
+			username:   "user1",
+			body:       `{"url": "https://example.com/hook", "event_types": ["POST_CREATED"]}`,
+			wantStatus: http.StatusCreated,
+			wantBody: `{
+				"status": "success",
+				"message": "Webhook created successfully",
+				"data": {
+					"id": "hook-1",
+					"url": "https://example.com/hook",
+					"event_types": ["POST_CREATED"],
+					"secret": "0123456789abcdef",
+					"active": true,
+					"consecutive_failures": 0,
+					"created_at": "2025-02-01T10:00:00Z",
+					"updated_at": "2025-02-01T10:00:00Z"
+				}
+			}`,
+		},
+		{
+			name:       "invalid url",
+			username:   "user1",
+			body:       `{"url": "bad", "event_types": ["POST_CREATED"]}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"status": "error", "message": "invalid url: must be an absolute http or https URL"}`,
+		},
+		{
+			name:       "missing url",
+			username:   "user1",
+			body:       `{"event_types": ["POST_CREATED"]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unauthenticated",
+			body:       `{"url": "https://example.com/hook", "event_types": ["POST_CREATED"]}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			setupRouter(service, tt.username).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestWebhooksHandler_ListWebhooksHidesSecret(t *testing.T) {
+	service := &mockWebhooksService{
+		listWebhooksFunc: func(ctx context.Context, username string) ([]domain.Webhook, error) {
+			assert.Equal(t, "user1", username)
+			return []domain.Webhook{testWebhook}, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	setupRouter(service, "user1").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/webhooks", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Len(t, got.Data, 1)
+	assert.Equal(t, "hook-1", got.Data[0]["id"])
+	assert.NotContains(t, got.Data[0], "secret")
+}
+
+func TestWebhooksHandler_UpdateWebhook(t *testing.T) {
+	service := &mockWebhooksService{
+		updateWebhookFunc: func(ctx context.Context, username, id string, update domain.WebhookUpdate) (domain.Webhook, error) {
+			if id != "hook-1" {
+				return domain.Webhook{}, domain.ErrWebhookNotFound
+			}
+			assert.Nil(t, update.URL)
+			assert.True(t, *update.Active)
+			return testWebhook, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPatch, "/webhooks/hook-1", bytes.NewBufferString(`{"active": true}`))
+	req.Header.Set("Content-Type", "application/json")
+	setupRouter(service, "user1").ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPatch, "/webhooks/missing", bytes.NewBufferString(`{"active": true}`))
+	req.Header.Set("Content-Type", "application/json")
+	setupRouter(service, "user1").ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWebhooksHandler_ListDeliveries(t *testing.T) {
+	service := &mockWebhooksService{
+		listDeliveriesFunc: func(ctx context.Context, username string, filter domain.DeliveryFilter) (domain.DeliveryPage, error) {
+			assert.Equal(t, domain.DeliveryFilter{WebhookID: "hook-1", Cursor: "abc", Limit: 1}, filter)
+			return domain.DeliveryPage{
+				Deliveries: []domain.Delivery{
+					{
+						ID:             "hook-1_event-2",
+						WebhookID:      "hook-1",
+						EventID:        "event-2",
+						EventType:      "POST_CREATED",
+						Payload:        `{"id":"event-2"}`,
+						Status:         domain.DeliveryPending,
+						Attempts:       1,
+						ResponseStatus: 503,
+						Error:          "unexpected response status 503",
+						NextAttemptAt:  createdAt.Add(time.Minute),
+						CreatedAt:      createdAt,
+						UpdatedAt:      createdAt,
+					},
+				},
+				NextCursor: "next",
+			}, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	setupRouter(service, "user1").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/webhooks/hook-1/deliveries?cursor=abc&limit=1", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"status": "success",
+		"message": "Deliveries retrieved successfully",
+		"data": {
+			"deliveries": [{
+				"id": "hook-1_event-2",
+				"event_id": "event-2",
+				"event_type": "POST_CREATED",
+				"payload": {"id": "event-2"},
+				"status": "pending",
+				"attempts": 1,
+				"response_status": 503,
+				"error": "unexpected response status 503",
+				"next_attempt_at": "2025-02-01T10:01:00Z",
+				"created_at": "2025-02-01T10:00:00Z",
+				"updated_at": "2025-02-01T10:00:00Z"
+			}],
+			"next_cursor": "next"
+		}
+	}`, w.Body.String())
+}
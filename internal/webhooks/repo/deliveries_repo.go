@@ -0,0 +1,207 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/ynwd/awesome-blog/internal/webhooks/domain"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeliveriesCollection holds the delivery log of every webhook
+const DeliveriesCollection = "webhook_deliveries"
+
+type deliveriesFirestore struct {
+	client *firestore.Client
+}
+
+func NewDeliveriesRepository(client *firestore.Client) DeliveriesRepository {
+	return &deliveriesFirestore{
+		client: client,
+	}
+}
+
+func (r *deliveriesFirestore) Create(ctx context.Context, delivery domain.Delivery) error {
+	_, err := r.client.Collection(DeliveriesCollection).Doc(delivery.ID).Create(ctx, delivery)
+	if status.Code(err) == codes.AlreadyExists {
+		return nil
+	}
+	return err
+}
+
+// Due requires composite indexes on (status, next_attempt_at) and
+// (status, claimed_until)
+func (r *deliveriesFirestore) Due(ctx context.Context, now time.Time, limit int) ([]domain.Delivery, error) {
+	deliveries := r.client.Collection(DeliveriesCollection)
+	due, err := r.query(deliveries.
+		Where("status", "==", domain.DeliveryPending).
+		Where("next_attempt_at", "<=", now).
+		OrderBy("next_attempt_at", firestore.Asc).
+		Limit(limit).
+		Documents(ctx))
+	if err != nil || len(due) == limit {
+		return due, err
+	}
+
+	expired, err := r.query(deliveries.
+		Where("status", "==", domain.DeliverySending).
+		Where("claimed_until", "<=", now).
+		OrderBy("claimed_until", firestore.Asc).
+		Limit(limit - len(due)).
+		Documents(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return append(due, expired...), nil
+}
+
+func (r *deliveriesFirestore) Claim(ctx context.Context, id string, now, until time.Time) (domain.Delivery, error) {
+	ref := r.client.Collection(DeliveriesCollection).Doc(id)
+	var delivery domain.Delivery
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return domain.ErrDeliveryClaimed
+		}
+		if err != nil {
+			return err
+		}
+
+		delivery, err = toDelivery(doc)
+		if err != nil {
+			return err
+		}
+		if !delivery.Claimable(now) {
+			return domain.ErrDeliveryClaimed
+		}
+
+		delivery.Status = domain.DeliverySending
+		delivery.ClaimedUntil = until
+		return tx.Update(ref, []firestore.Update{
+			{Path: "status", Value: delivery.Status},
+			{Path: "claimed_until", Value: delivery.ClaimedUntil},
+		})
+	})
+	if err != nil {
+		return domain.Delivery{}, err
+	}
+	return delivery, nil
+}
+
+func (r *deliveriesFirestore) Update(ctx context.Context, delivery domain.Delivery) error {
+	_, err := r.client.Collection(DeliveriesCollection).Doc(delivery.ID).Update(ctx, []firestore.Update{
+		{Path: "status", Value: delivery.Status},
+		{Path: "attempts", Value: delivery.Attempts},
+		{Path: "response_status", Value: delivery.ResponseStatus},
+		{Path: "error", Value: delivery.Error},
+		{Path: "next_attempt_at", Value: delivery.NextAttemptAt},
+		{Path: "claimed_until", Value: delivery.ClaimedUntil},
+		{Path: "updated_at", Value: delivery.UpdatedAt},
+	})
+	return err
+}
+
+// List returns a webhook's deliveries, newest first. It requires a composite
+// index on (webhook_id, created_at desc, __name__ desc).
+func (r *deliveriesFirestore) List(ctx context.Context, filter domain.DeliveryFilter) (domain.DeliveryPage, error) {
+	query := r.client.Collection(DeliveriesCollection).
+		Where("webhook_id", "==", filter.WebhookID).
+		OrderBy("created_at", firestore.Desc).
+		OrderBy(firestore.DocumentID, firestore.Desc)
+
+	if filter.Cursor != "" {
+		c, err := utils.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return domain.DeliveryPage{}, domain.ErrInvalidCursor
+		}
+		query = query.StartAfter(c.CreatedAt, c.ID)
+	}
+
+	// Fetch one extra document to know whether another page exists
+	iter := query.Limit(filter.Limit + 1).Documents(ctx)
+	defer iter.Stop()
+
+	var deliveries []domain.Delivery
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return domain.DeliveryPage{}, err
+		}
+
+		delivery, err := toDelivery(doc)
+		if err != nil {
+			return domain.DeliveryPage{}, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	page := domain.DeliveryPage{Deliveries: deliveries}
+	if len(deliveries) > filter.Limit {
+		page.Deliveries = deliveries[:filter.Limit]
+		last := page.Deliveries[filter.Limit-1]
+		page.NextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+	}
+	return page, nil
+}
+
+func (r *deliveriesFirestore) Cleanup(ctx context.Context, createdBefore time.Time) error {
+	docs, err := r.client.Collection(DeliveriesCollection).
+		Where("created_at", "<", createdBefore).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return err
+	}
+
+	if len(docs) == 0 {
+		return nil
+	}
+
+	bw := r.client.BulkWriter(ctx)
+	for _, doc := range docs {
+		if _, err := bw.Delete(doc.Ref); err != nil {
+			bw.End()
+			return err
+		}
+	}
+	bw.End()
+
+	return nil
+}
+
+func (r *deliveriesFirestore) query(iter *firestore.DocumentIterator) ([]domain.Delivery, error) {
+	defer iter.Stop()
+
+	var deliveries []domain.Delivery
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return deliveries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		delivery, err := toDelivery(doc)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+}
+
+func toDelivery(doc *firestore.DocumentSnapshot) (domain.Delivery, error) {
+	var delivery domain.Delivery
+	if err := doc.DataTo(&delivery); err != nil {
+		return domain.Delivery{}, err
+	}
+	delivery.ID = doc.Ref.ID
+	return delivery, nil
+}
@@ -0,0 +1,47 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/webhooks/domain"
+)
+
+type WebhooksRepository interface {
+	Create(ctx context.Context, webhook domain.Webhook) (string, error)
+	GetByID(ctx context.Context, id string) (domain.Webhook, error)
+	ListByUser(ctx context.Context, username string) ([]domain.Webhook, error)
+	ListActive(ctx context.Context) ([]domain.Webhook, error)
+	Update(ctx context.Context, webhook domain.Webhook) error
+	Delete(ctx context.Context, id string) error
+
+	// RecordSuccess resets the webhook's consecutive failures
+	RecordSuccess(ctx context.Context, id string) error
+
+	// RecordFailure counts a failed delivery and disables the webhook once
+	// disableAfter deliveries in a row failed. It reports whether the webhook
+	// was disabled.
+	RecordFailure(ctx context.Context, id string, disableAfter int, reason string) (bool, error)
+}
+
+type DeliveriesRepository interface {
+	// Create stores a pending delivery. Creating a delivery that already
+	// exists is a no-op.
+	Create(ctx context.Context, delivery domain.Delivery) error
+
+	// Due returns the deliveries that can be claimed at now: pending ones
+	// whose next attempt is due, earliest first, and those whose lease ran out
+	Due(ctx context.Context, now time.Time, limit int) ([]domain.Delivery, error)
+
+	// Claim marks a due delivery as sending until the given time and returns
+	// it as stored. It returns domain.ErrDeliveryClaimed when the delivery is
+	// no longer claimable, such as when another replica claimed it first.
+	Claim(ctx context.Context, id string, now, until time.Time) (domain.Delivery, error)
+
+	// Update stores the outcome of an attempt
+	Update(ctx context.Context, delivery domain.Delivery) error
+	List(ctx context.Context, filter domain.DeliveryFilter) (domain.DeliveryPage, error)
+
+	// Cleanup deletes deliveries created before the given time
+	Cleanup(ctx context.Context, createdBefore time.Time) error
+}
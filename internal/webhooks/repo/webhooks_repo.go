@@ -0,0 +1,155 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/ynwd/awesome-blog/internal/webhooks/domain"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WebhooksCollection holds the registered webhooks
+const WebhooksCollection = "webhooks"
+
+type webhooksFirestore struct {
+	client *firestore.Client
+}
+
+func NewWebhooksRepository(client *firestore.Client) WebhooksRepository {
+	return &webhooksFirestore{
+		client: client,
+	}
+}
+
+func (r *webhooksFirestore) Create(ctx context.Context, webhook domain.Webhook) (string, error) {
+	ref, _, err := r.client.Collection(WebhooksCollection).Add(ctx, webhook)
+	if err != nil {
+		return "", err
+	}
+	return ref.ID, nil
+}
+
+func (r *webhooksFirestore) GetByID(ctx context.Context, id string) (domain.Webhook, error) {
+	doc, err := r.client.Collection(WebhooksCollection).Doc(id).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return domain.Webhook{}, domain.ErrWebhookNotFound
+	}
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	return toWebhook(doc)
+}
+
+// ListByUser returns the user's webhooks, oldest first. It requires a
+// composite index on (username, created_at).
+func (r *webhooksFirestore) ListByUser(ctx context.Context, username string) ([]domain.Webhook, error) {
+	query := r.client.Collection(WebhooksCollection).
+		Where("username", "==", username).
+		OrderBy("created_at", firestore.Asc)
+	return r.list(ctx, query)
+}
+
+func (r *webhooksFirestore) ListActive(ctx context.Context) ([]domain.Webhook, error) {
+	query := r.client.Collection(WebhooksCollection).Where("active", "==", true)
+	return r.list(ctx, query)
+}
+
+func (r *webhooksFirestore) Update(ctx context.Context, webhook domain.Webhook) error {
+	_, err := r.client.Collection(WebhooksCollection).Doc(webhook.ID).Update(ctx, []firestore.Update{
+		{Path: "url", Value: webhook.URL},
+		{Path: "event_types", Value: webhook.EventTypes},
+		{Path: "secret", Value: webhook.Secret},
+		{Path: "active", Value: webhook.Active},
+		{Path: "consecutive_failures", Value: webhook.ConsecutiveFailures},
+		{Path: "disabled_reason", Value: webhook.DisabledReason},
+		{Path: "updated_at", Value: webhook.UpdatedAt},
+	})
+	if status.Code(err) == codes.NotFound {
+		return domain.ErrWebhookNotFound
+	}
+	return err
+}
+
+func (r *webhooksFirestore) Delete(ctx context.Context, id string) error {
+	_, err := r.client.Collection(WebhooksCollection).Doc(id).Delete(ctx)
+	return err
+}
+
+func (r *webhooksFirestore) RecordSuccess(ctx context.Context, id string) error {
+	_, err := r.client.Collection(WebhooksCollection).Doc(id).Update(ctx, []firestore.Update{
+		{Path: "consecutive_failures", Value: 0},
+	})
+	if status.Code(err) == codes.NotFound {
+		return domain.ErrWebhookNotFound
+	}
+	return err
+}
+
+func (r *webhooksFirestore) RecordFailure(ctx context.Context, id string, disableAfter int, reason string) (bool, error) {
+	ref := r.client.Collection(WebhooksCollection).Doc(id)
+	disabled := false
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		disabled = false
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return domain.ErrWebhookNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		webhook, err := toWebhook(doc)
+		if err != nil {
+			return err
+		}
+
+		updates := []firestore.Update{
+			{Path: "consecutive_failures", Value: webhook.ConsecutiveFailures + 1},
+		}
+		if webhook.Active && webhook.ConsecutiveFailures+1 >= disableAfter {
+			disabled = true
+			updates = append(updates,
+				firestore.Update{Path: "active", Value: false},
+				firestore.Update{Path: "disabled_reason", Value: fmt.Sprintf("%d deliveries in a row failed, last: %s", webhook.ConsecutiveFailures+1, reason)},
+				firestore.Update{Path: "updated_at", Value: time.Now().UTC()},
+			)
+		}
+		return tx.Update(ref, updates)
+	})
+	return disabled, err
+}
+
+func (r *webhooksFirestore) list(ctx context.Context, query firestore.Query) ([]domain.Webhook, error) {
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	var webhooks []domain.Webhook
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return webhooks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		webhook, err := toWebhook(doc)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+}
+
+func toWebhook(doc *firestore.DocumentSnapshot) (domain.Webhook, error) {
+	var webhook domain.Webhook
+	if err := doc.DataTo(&webhook); err != nil {
+		return domain.Webhook{}, err
+	}
+	webhook.ID = doc.Ref.ID
+	return webhook, nil
+}
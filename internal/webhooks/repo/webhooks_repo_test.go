@@ -0,0 +1,135 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ynwd/awesome-blog/internal/webhooks/domain"
+	"github.com/ynwd/awesome-blog/tests/helper"
+)
+
+func TestWebhooksRepository(t *testing.T) {
+	ctx := context.Background()
+	client := helper.SetupRepoClient(t)
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	repo := NewWebhooksRepository(client)
+	createdAt := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	id, err := repo.Create(ctx, domain.Webhook{
+		Username:   "user1",
+		URL:        "https://example.com/hook",
+		EventTypes: []string{"POST_CREATED"},
+		Secret:     "0123456789abcdef",
+		Active:     true,
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+	})
+	require.NoError(t, err)
+
+	webhooks, err := repo.ListByUser(ctx, "user1")
+	require.NoError(t, err)
+	assert.Len(t, webhooks, 1)
+
+	_, err = repo.GetByID(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+
+	// The second failure in a row disables the webhook
+	disabled, err := repo.RecordFailure(ctx, id, 2, "timeout")
+	require.NoError(t, err)
+	assert.False(t, disabled)
+	disabled, err = repo.RecordFailure(ctx, id, 2, "timeout")
+	require.NoError(t, err)
+	assert.True(t, disabled)
+
+	webhook, err := repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.False(t, webhook.Active)
+	assert.Equal(t, 2, webhook.ConsecutiveFailures)
+
+	active, err := repo.ListActive(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+
+	require.NoError(t, repo.Delete(ctx, id))
+	_, err = repo.GetByID(ctx, id)
+	assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+}
+
+func TestDeliveriesRepository(t *testing.T) {
+	ctx := context.Background()
+	client := helper.SetupRepoClient(t)
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	repo := NewDeliveriesRepository(client)
+	createdAt := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	for i, eventID := range []string{"event-1", "event-2"} {
+		at := createdAt.Add(time.Duration(i) * time.Minute)
+		delivery := domain.Delivery{
+			ID:            domain.DeliveryID("hook-1", eventID),
+			WebhookID:     "hook-1",
+			EventID:       eventID,
+			EventType:     "POST_CREATED",
+			Payload:       `{}`,
+			Status:        domain.DeliveryPending,
+			NextAttemptAt: at,
+			CreatedAt:     at,
+			UpdatedAt:     at,
+		}
+		require.NoError(t, repo.Create(ctx, delivery))
+		require.NoError(t, repo.Create(ctx, delivery))
+	}
+
+	due, err := repo.Due(ctx, createdAt, 10)
+	require.NoError(t, err)
+	assert.Len(t, due, 1)
+	assert.Equal(t, "hook-1_event-1", due[0].ID)
+
+	// Only one claim succeeds until the lease runs out
+	claimed, err := repo.Claim(ctx, due[0].ID, createdAt, createdAt.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeliverySending, claimed.Status)
+	_, err = repo.Claim(ctx, due[0].ID, createdAt, createdAt.Add(time.Minute))
+	assert.ErrorIs(t, err, domain.ErrDeliveryClaimed)
+
+	due, err = repo.Due(ctx, createdAt.Add(time.Minute), 10)
+	require.NoError(t, err)
+	assert.Len(t, due, 2)
+
+	claimed.Status = domain.DeliverySucceeded
+	claimed.Attempts = 1
+	claimed.ClaimedUntil = time.Time{}
+	require.NoError(t, repo.Update(ctx, claimed))
+
+	// Newest first, across pages
+	page, err := repo.List(ctx, domain.DeliveryFilter{WebhookID: "hook-1", Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "hook-1_event-2", page.Deliveries[0].ID)
+	assert.NotEmpty(t, page.NextCursor)
+
+	page, err = repo.List(ctx, domain.DeliveryFilter{WebhookID: "hook-1", Cursor: page.NextCursor, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeliverySucceeded, page.Deliveries[0].Status)
+	assert.Empty(t, page.NextCursor)
+
+	require.NoError(t, repo.Cleanup(ctx, createdAt.Add(time.Minute)))
+	page, err = repo.List(ctx, domain.DeliveryFilter{WebhookID: "hook-1", Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, page.Deliveries, 1)
+}
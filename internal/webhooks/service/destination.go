@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+
+	"github.com/ynwd/awesome-blog/internal/webhooks/domain"
+)
+
+// blockedPrefixes are the ranges webhooks may not reach besides the ones net.IP
+// classifies: "this network" and the carrier-grade NAT range, where some
+// clouds serve their metadata
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// lookupIPFunc resolves a host name to its addresses
+type lookupIPFunc func(ctx context.Context, host string) ([]net.IP, error)
+
+func lookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	return net.DefaultResolver.LookupIP(ctx, "ip", host)
+}
+
+// publicIP reports whether a webhook may be sent to ip. Loopback, private,
+// link-local (including the 169.254.169.254 metadata address), unspecified
+// and multicast addresses are refused.
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateURL checks that value is an absolute http or https URL whose host
+// only resolves to public addresses
+func validateURL(ctx context.Context, lookup lookupIPFunc, value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return domain.ErrInvalidURL
+	}
+
+	host := u.Hostname()
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		ips, err = lookup(ctx, host)
+		if err != nil || len(ips) == 0 {
+			return fmt.Errorf("%w: cannot resolve %s", domain.ErrInvalidURL, host)
+		}
+	}
+
+	for _, ip := range ips {
+		if !publicIP(ip) {
+			return domain.ErrForbiddenURL
+		}
+	}
+	return nil
+}
+
+// dialPublicOnly is a net.Dialer Control that refuses connections to
+// addresses webhooks may not reach. It runs after the host is resolved, so a
+// name that resolved to a public address at registration and to a private
+// one now is still refused.
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+		return fmt.Errorf("%w: %s", domain.ErrForbiddenURL, host)
+	}
+	return nil
+}
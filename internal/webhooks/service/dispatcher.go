@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/webhooks/domain"
+	"github.com/ynwd/awesome-blog/internal/webhooks/repo"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/pubsub"
+)
+
+// Headers of a webhook request. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the webhook secret, prefixed with "sha256=".
+const (
+	HeaderWebhookID = "X-Webhook-ID"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// DispatcherConfig controls how deliveries are sent and retried
+type DispatcherConfig struct {
+	// Retry bounds the attempts per delivery and the backoff between them
+	Retry pubsub.RetryPolicy
+
+	// DisableAfter is the number of deliveries in a row that may fail before
+	// their webhook is disabled
+	DisableAfter int
+
+	// BatchSize is the number of due deliveries sent per run, at most
+	// Concurrency at a time
+	BatchSize   int
+	Concurrency int
+
+	// Timeout bounds each request
+	Timeout time.Duration
+
+	// Lease is how long a replica holds a delivery it claimed before another
+	// may take it back. It must be longer than Timeout.
+	Lease time.Duration
+}
+
+// DefaultDispatcherConfig makes 6 attempts per delivery over about 15 minutes
+// and disables a webhook after 5 failed deliveries in a row
+func DefaultDispatcherConfig() DispatcherConfig {
+	return DispatcherConfig{
+		Retry: pubsub.RetryPolicy{
+			MaxAttempts: 6,
+			MinBackoff:  30 * time.Second,
+			MaxBackoff:  10 * time.Minute,
+		},
+		DisableAfter: 5,
+		BatchSize:    100,
+		Concurrency:  10,
+		Timeout:      10 * time.Second,
+		Lease:        time.Minute,
+	}
+}
+
+// payload is the body of a webhook request
+type payload struct {
+	ID        string           `json:"id"`
+	Type      module.EventType `json:"type"`
+	Version   int              `json:"version"`
+	Timestamp time.Time        `json:"timestamp"`
+	Data      json.RawMessage  `json:"data"`
+}
+
+type dispatcher struct {
+	webhooks   repo.WebhooksRepository
+	deliveries repo.DeliveriesRepository
+	client     *http.Client
+	config     DispatcherConfig
+	now        func() time.Time
+}
+
+func NewDispatcher(webhooks repo.WebhooksRepository, deliveries repo.DeliveriesRepository, config DispatcherConfig) Dispatcher {
+	return &dispatcher{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+		client:     newClient(config.Timeout, dialPublicOnly),
+		config:     config,
+		now:        func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// newClient returns the client deliveries are sent with. control vets every
+// address the client connects to; requests go out directly rather than through
+// a proxy from the environment, so that the address it sees is the receiver's.
+func newClient(timeout time.Duration, control func(network, address string, c syscall.RawConn) error) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   control,
+	}).DialContext
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		// A redirect is reported as a failed delivery instead of being
+		// followed, since following would turn the POST into a GET
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func (d *dispatcher) Enqueue(ctx context.Context, event module.Event[json.RawMessage]) error {
+	// Domain events always have an ID; receivers rely on it to skip duplicates
+	if event.ID == "" {
+		log.Printf("Not delivering %s event without an ID to webhooks", event.Type)
+		return nil
+	}
+
+	webhooks, err := d.webhooks.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(payload{
+		ID:        event.ID,
+		Type:      event.Type,
+		Version:   event.Version,
+		Timestamp: event.Timestamp,
+		Data:      event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := d.now()
+	var errs []error
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(event.Type) {
+			continue
+		}
+
+		err := d.deliveries.Create(ctx, domain.Delivery{
+			ID:            domain.DeliveryID(webhook.ID, event.ID),
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     string(event.Type),
+			Payload:       string(body),
+			Status:        domain.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	due, err := d.deliveries.Due(ctx, d.now(), d.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	// Load each webhook once per batch; a missing one was deleted
+	webhooks := make(map[string]*domain.Webhook)
+	for _, delivery := range due {
+		if _, ok := webhooks[delivery.WebhookID]; ok {
+			continue
+		}
+		webhook, err := d.webhooks.GetByID(ctx, delivery.WebhookID)
+		if errors.Is(err, domain.ErrWebhookNotFound) {
+			webhooks[delivery.WebhookID] = nil
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		webhooks[delivery.WebhookID] = &webhook
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		attempted int
+		errs      []error
+	)
+	sem := make(chan struct{}, max(d.config.Concurrency, 1))
+	for _, delivery := range due {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(delivery domain.Delivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			// Every replica polls the same deliveries; only the one that
+			// claims a delivery sends it
+			now := d.now()
+			delivery, err := d.deliveries.Claim(ctx, delivery.ID, now, now.Add(d.config.Lease))
+			if errors.Is(err, domain.ErrDeliveryClaimed) {
+				return
+			}
+			if err == nil {
+				err = d.deliver(ctx, delivery, webhooks[delivery.WebhookID])
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			attempted++
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}(delivery)
+	}
+	wg.Wait()
+
+	return attempted, errors.Join(errs...)
+}
+
+func (d *dispatcher) Cleanup(ctx context.Context, createdBefore time.Time) error {
+	return d.deliveries.Cleanup(ctx, createdBefore)
+}
+
+// deliver makes one attempt at a claimed delivery and records its outcome,
+// which releases the claim. A delivery that fails
+// on its last attempt counts towards disabling the webhook.
+func (d *dispatcher) deliver(ctx context.Context, delivery domain.Delivery, webhook *domain.Webhook) error {
+	delivery.UpdatedAt = d.now()
+	delivery.ClaimedUntil = time.Time{}
+
+	switch {
+	case webhook == nil:
+		delivery.Status = domain.DeliveryFailed
+		delivery.Error = "webhook deleted"
+		return d.deliveries.Update(ctx, delivery)
+	case !webhook.Active:
+		delivery.Status = domain.DeliveryFailed
+		delivery.Error = "webhook disabled"
+		return d.deliveries.Update(ctx, delivery)
+	}
+
+	delivery.Attempts++
+	responseStatus, err := d.send(ctx, *webhook, delivery)
+	delivery.ResponseStatus = responseStatus
+
+	if err == nil {
+		delivery.Status = domain.DeliverySucceeded
+		delivery.Error = ""
+		if err := d.deliveries.Update(ctx, delivery); err != nil {
+			return err
+		}
+		if webhook.ConsecutiveFailures > 0 {
+			return d.webhooks.RecordSuccess(ctx, webhook.ID)
+		}
+		return nil
+	}
+
+	delivery.Error = err.Error()
+	if !d.config.Retry.Exhausted(delivery.Attempts) {
+		delivery.Status = domain.DeliveryPending
+		delivery.NextAttemptAt = delivery.UpdatedAt.Add(d.config.Retry.Backoff(delivery.Attempts))
+		return d.deliveries.Update(ctx, delivery)
+	}
+
+	delivery.Status = domain.DeliveryFailed
+	if err := d.deliveries.Update(ctx, delivery); err != nil {
+		return err
+	}
+	disabled, err := d.webhooks.RecordFailure(ctx, webhook.ID, d.config.DisableAfter, delivery.Error)
+	if errors.Is(err, domain.ErrWebhookNotFound) {
+		return nil
+	}
+	if disabled {
+		log.Printf("Disabled webhook %s after %d failed deliveries", webhook.ID, d.config.DisableAfter)
+	}
+	return err
+}
+
+// send posts the signed payload and returns the response status. Any status
+// outside 2xx is an error.
+func (d *dispatcher) send(ctx context.Context, webhook domain.Webhook, delivery domain.Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookID, webhook.ID)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, []byte(delivery.Payload)))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain a bounded part of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature header value of a webhook request body sent at
+// the given Unix timestamp
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,514 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ynwd/awesome-blog/internal/webhooks/domain"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/pubsub"
+)
+
+// fakeWebhooks keeps webhooks in memory
+type fakeWebhooks struct {
+	mu       sync.Mutex
+	webhooks map[string]domain.Webhook
+}
+
+func newFakeWebhooks(webhooks ...domain.Webhook) *fakeWebhooks {
+	f := &fakeWebhooks{webhooks: make(map[string]domain.Webhook)}
+	for _, webhook := range webhooks {
+		f.webhooks[webhook.ID] = webhook
+	}
+	return f
+}
+
+func (f *fakeWebhooks) Create(ctx context.Context, webhook domain.Webhook) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	webhook.ID = "webhook-" + string(rune('a'+len(f.webhooks)))
+	f.webhooks[webhook.ID] = webhook
+	return webhook.ID, nil
+}
+
+func (f *fakeWebhooks) GetByID(ctx context.Context, id string) (domain.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	webhook, ok := f.webhooks[id]
+	if !ok {
+		return domain.Webhook{}, domain.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+func (f *fakeWebhooks) ListByUser(ctx context.Context, username string) ([]domain.Webhook, error) {
+	return f.list(func(w domain.Webhook) bool { return w.Username == username }), nil
+}
+
+func (f *fakeWebhooks) ListActive(ctx context.Context) ([]domain.Webhook, error) {
+	return f.list(func(w domain.Webhook) bool { return w.Active }), nil
+}
+
+func (f *fakeWebhooks) Update(ctx context.Context, webhook domain.Webhook) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.webhooks[webhook.ID]; !ok {
+		return domain.ErrWebhookNotFound
+	}
+	f.webhooks[webhook.ID] = webhook
+	return nil
+}
+
+func (f *fakeWebhooks) Delete(ctx context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.webhooks, id)
+	return nil
+}
+
+func (f *fakeWebhooks) RecordSuccess(ctx context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	webhook := f.webhooks[id]
+	webhook.ConsecutiveFailures = 0
+	f.webhooks[id] = webhook
+	return nil
+}
+
+func (f *fakeWebhooks) RecordFailure(ctx context.Context, id string, disableAfter int, reason string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	webhook, ok := f.webhooks[id]
+	if !ok {
+		return false, domain.ErrWebhookNotFound
+	}
+	webhook.ConsecutiveFailures++
+	disabled := webhook.Active && webhook.ConsecutiveFailures >= disableAfter
+	if disabled {
+		webhook.Active = false
+		webhook.DisabledReason = reason
+	}
+	f.webhooks[id] = webhook
+	return disabled, nil
+}
+
+func (f *fakeWebhooks) list(match func(domain.Webhook) bool) []domain.Webhook {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var webhooks []domain.Webhook
+	for _, webhook := range f.webhooks {
+		if match(webhook) {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	return webhooks
+}
+
+// fakeDeliveries keeps deliveries in memory
+type fakeDeliveries struct {
+	mu         sync.Mutex
+	deliveries map[string]domain.Delivery
+}
+
+func newFakeDeliveries() *fakeDeliveries {
+	return &fakeDeliveries{deliveries: make(map[string]domain.Delivery)}
+}
+
+func (f *fakeDeliveries) Create(ctx context.Context, delivery domain.Delivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.deliveries[delivery.ID]; !ok {
+		f.deliveries[delivery.ID] = delivery
+	}
+	return nil
+}
+
+func (f *fakeDeliveries) Due(ctx context.Context, now time.Time, limit int) ([]domain.Delivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var due []domain.Delivery
+	for _, delivery := range f.deliveries {
+		if delivery.Claimable(now) {
+			due = append(due, delivery)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (f *fakeDeliveries) Claim(ctx context.Context, id string, now, until time.Time) (domain.Delivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delivery, ok := f.deliveries[id]
+	if !ok || !delivery.Claimable(now) {
+		return domain.Delivery{}, domain.ErrDeliveryClaimed
+	}
+	delivery.Status = domain.DeliverySending
+	delivery.ClaimedUntil = until
+	f.deliveries[id] = delivery
+	return delivery, nil
+}
+
+func (f *fakeDeliveries) Update(ctx context.Context, delivery domain.Delivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (f *fakeDeliveries) List(ctx context.Context, filter domain.DeliveryFilter) (domain.DeliveryPage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var page domain.DeliveryPage
+	for _, delivery := range f.deliveries {
+		if delivery.WebhookID == filter.WebhookID {
+			page.Deliveries = append(page.Deliveries, delivery)
+		}
+	}
+	return page, nil
+}
+
+func (f *fakeDeliveries) Cleanup(ctx context.Context, createdBefore time.Time) error {
+	return nil
+}
+
+func (f *fakeDeliveries) get(id string) domain.Delivery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.deliveries[id]
+}
+
+var testTime = time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+
+func newTestDispatcher(webhooks *fakeWebhooks, deliveries *fakeDeliveries, config DispatcherConfig) (*dispatcher, *time.Time) {
+	d := NewDispatcher(webhooks, deliveries, config).(*dispatcher)
+	// The receivers in these tests listen on loopback
+	d.client = newClient(config.Timeout, nil)
+	now := testTime
+	d.now = func() time.Time { return now }
+	return d, &now
+}
+
+func testConfig() DispatcherConfig {
+	return DispatcherConfig{
+		Retry:        pubsub.RetryPolicy{MaxAttempts: 2, MinBackoff: time.Minute, MaxBackoff: time.Hour},
+		DisableAfter: 2,
+		BatchSize:    10,
+		Concurrency:  2,
+		Timeout:      time.Second,
+		Lease:        time.Minute,
+	}
+}
+
+func likeCreated(id string) module.Event[json.RawMessage] {
+	return module.Event[json.RawMessage]{
+		ID:        id,
+		Type:      module.LikeCreatedEvent,
+		Version:   1,
+		Timestamp: testTime,
+		Payload:   json.RawMessage(`{"post_id":"post-1","username_from":"user1"}`),
+	}
+}
+
+func TestDispatcher_Enqueue(t *testing.T) {
+	webhooks := newFakeWebhooks(
+		domain.Webhook{ID: "likes", Active: true, EventTypes: []string{"LIKE_CREATED"}},
+		domain.Webhook{ID: "posts", Active: true, EventTypes: []string{"POST_CREATED"}},
+		domain.Webhook{ID: "disabled", Active: false, EventTypes: []string{"LIKE_CREATED"}},
+	)
+	deliveries := newFakeDeliveries()
+	d, _ := newTestDispatcher(webhooks, deliveries, testConfig())
+
+	// Enqueueing a redelivered event does not duplicate the delivery
+	require.NoError(t, d.Enqueue(context.Background(), likeCreated("event-1")))
+	require.NoError(t, d.Enqueue(context.Background(), likeCreated("event-1")))
+
+	assert.Len(t, deliveries.deliveries, 1)
+	delivery := deliveries.get(domain.DeliveryID("likes", "event-1"))
+	assert.Equal(t, domain.DeliveryPending, delivery.Status)
+	assert.Equal(t, testTime, delivery.NextAttemptAt)
+	assert.JSONEq(t, `{
+		"id": "event-1",
+		"type": "LIKE_CREATED",
+		"version": 1,
+		"timestamp": "2025-02-01T10:00:00Z",
+		"data": {"post_id": "post-1", "username_from": "user1"}
+	}`, delivery.Payload)
+}
+
+func TestDispatcher_DeliverDueSignsRequests(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	requests := make(chan received, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{header: r.Header, body: body}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	webhooks := newFakeWebhooks(domain.Webhook{
+		ID:                  "likes",
+		URL:                 receiver.URL,
+		Secret:              "0123456789abcdef",
+		Active:              true,
+		EventTypes:          []string{"LIKE_CREATED"},
+		ConsecutiveFailures: 1,
+	})
+	deliveries := newFakeDeliveries()
+	d, _ := newTestDispatcher(webhooks, deliveries, testConfig())
+	require.NoError(t, d.Enqueue(context.Background(), likeCreated("event-1")))
+
+	sent, err := d.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	req := <-requests
+	timestamp := req.header.Get(HeaderTimestamp)
+	assert.Equal(t, "1738404000", timestamp)
+	assert.Equal(t, Sign("0123456789abcdef", timestamp, req.body), req.header.Get(HeaderSignature))
+	assert.Equal(t, "likes", req.header.Get(HeaderWebhookID))
+	assert.Equal(t, "likes_event-1", req.header.Get(HeaderDelivery))
+	assert.Equal(t, "LIKE_CREATED", req.header.Get(HeaderEvent))
+	assert.Equal(t, "application/json", req.header.Get("Content-Type"))
+
+	delivery := deliveries.get("likes_event-1")
+	assert.Equal(t, domain.DeliverySucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusNoContent, delivery.ResponseStatus)
+
+	// A success resets the failures counting towards disabling the webhook
+	webhook, _ := webhooks.GetByID(context.Background(), "likes")
+	assert.Equal(t, 0, webhook.ConsecutiveFailures)
+
+	// Nothing is due any more
+	sent, err = d.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+}
+
+func TestDispatcher_DeliverDueRefusesPrivateAddresses(t *testing.T) {
+	calls := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	webhooks := newFakeWebhooks(domain.Webhook{
+		ID:         "likes",
+		URL:        receiver.URL,
+		Secret:     "0123456789abcdef",
+		Active:     true,
+		EventTypes: []string{"LIKE_CREATED"},
+	})
+	deliveries := newFakeDeliveries()
+	d := NewDispatcher(webhooks, deliveries, testConfig()).(*dispatcher)
+	d.now = func() time.Time { return testTime }
+	require.NoError(t, d.Enqueue(context.Background(), likeCreated("event-1")))
+
+	sent, err := d.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	assert.Equal(t, 0, calls)
+	delivery := deliveries.get("likes_event-1")
+	assert.Equal(t, domain.DeliveryPending, delivery.Status)
+	assert.Contains(t, delivery.Error, domain.ErrForbiddenURL.Error())
+}
+
+func TestDispatcher_DeliverDueRetriesAndDisables(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	webhooks := newFakeWebhooks(domain.Webhook{
+		ID:         "likes",
+		URL:        receiver.URL,
+		Secret:     "0123456789abcdef",
+		Active:     true,
+		EventTypes: []string{"LIKE_CREATED"},
+	})
+	deliveries := newFakeDeliveries()
+	d, now := newTestDispatcher(webhooks, deliveries, testConfig())
+	ctx := context.Background()
+
+	require.NoError(t, d.Enqueue(ctx, likeCreated("event-1")))
+	require.NoError(t, d.Enqueue(ctx, likeCreated("event-2")))
+
+	// First attempt fails, the retry waits for the backoff
+	sent, err := d.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+
+	delivery := deliveries.get("likes_event-1")
+	assert.Equal(t, domain.DeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, delivery.ResponseStatus)
+	assert.Equal(t, "unexpected response status 500", delivery.Error)
+	assert.Equal(t, testTime.Add(time.Minute), delivery.NextAttemptAt)
+
+	sent, err = d.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	// The last attempt fails both deliveries, which disables the webhook
+	*now = now.Add(time.Minute)
+	sent, err = d.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	assert.Equal(t, 4, calls)
+
+	delivery = deliveries.get("likes_event-1")
+	assert.Equal(t, domain.DeliveryFailed, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+
+	webhook, _ := webhooks.GetByID(ctx, "likes")
+	assert.False(t, webhook.Active)
+	assert.Equal(t, 2, webhook.ConsecutiveFailures)
+
+	// A disabled webhook gets no new deliveries
+	require.NoError(t, d.Enqueue(ctx, likeCreated("event-3")))
+	assert.Len(t, deliveries.deliveries, 2)
+}
+
+func TestDispatcher_DeliverDueToRemovedWebhook(t *testing.T) {
+	webhooks := newFakeWebhooks(
+		domain.Webhook{ID: "deleted", Active: true, EventTypes: []string{"LIKE_CREATED"}},
+		domain.Webhook{ID: "disabled", Active: true, EventTypes: []string{"LIKE_CREATED"}},
+	)
+	deliveries := newFakeDeliveries()
+	d, _ := newTestDispatcher(webhooks, deliveries, testConfig())
+	ctx := context.Background()
+	require.NoError(t, d.Enqueue(ctx, likeCreated("event-1")))
+
+	// The pending deliveries fail without a request once their webhook is gone
+	require.NoError(t, webhooks.Delete(ctx, "deleted"))
+	require.NoError(t, webhooks.Update(ctx, domain.Webhook{ID: "disabled", Active: false}))
+
+	sent, err := d.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+
+	assert.Equal(t, domain.DeliveryFailed, deliveries.get("deleted_event-1").Status)
+	assert.Equal(t, "webhook deleted", deliveries.get("deleted_event-1").Error)
+	assert.Equal(t, domain.DeliveryFailed, deliveries.get("disabled_event-1").Status)
+	assert.Equal(t, "webhook disabled", deliveries.get("disabled_event-1").Error)
+	assert.Equal(t, 0, deliveries.get("disabled_event-1").Attempts)
+}
+
+func TestDispatcher_DeliverDueSendsEachDeliveryOnce(t *testing.T) {
+	var mu sync.Mutex
+	received := make(map[string]int)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received[r.Header.Get(HeaderDelivery)]++
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	webhooks := newFakeWebhooks(domain.Webhook{
+		ID:         "likes",
+		URL:        receiver.URL,
+		Secret:     "0123456789abcdef",
+		Active:     true,
+		EventTypes: []string{"LIKE_CREATED"},
+	})
+	deliveries := newFakeDeliveries()
+	ctx := context.Background()
+
+	// Two replicas share the deliveries and poll at the same time
+	first, _ := newTestDispatcher(webhooks, deliveries, testConfig())
+	second, _ := newTestDispatcher(webhooks, deliveries, testConfig())
+	for i := 0; i < 8; i++ {
+		require.NoError(t, first.Enqueue(ctx, likeCreated("event-"+string(rune('a'+i)))))
+	}
+
+	var wg sync.WaitGroup
+	sent := make([]int, 2)
+	for i, d := range []*dispatcher{first, second} {
+		wg.Add(1)
+		go func(i int, d *dispatcher) {
+			defer wg.Done()
+			n, err := d.DeliverDue(ctx)
+			assert.NoError(t, err)
+			sent[i] = n
+		}(i, d)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 8, sent[0]+sent[1])
+	assert.Len(t, received, 8)
+	for id, count := range received {
+		assert.Equal(t, 1, count, id)
+		assert.Equal(t, domain.DeliverySucceeded, deliveries.get(id).Status)
+	}
+}
+
+func TestDispatcher_DeliverDueTakesBackExpiredLeases(t *testing.T) {
+	calls := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	webhooks := newFakeWebhooks(domain.Webhook{
+		ID:         "likes",
+		URL:        receiver.URL,
+		Secret:     "0123456789abcdef",
+		Active:     true,
+		EventTypes: []string{"LIKE_CREATED"},
+	})
+	deliveries := newFakeDeliveries()
+	d, now := newTestDispatcher(webhooks, deliveries, testConfig())
+	ctx := context.Background()
+	require.NoError(t, d.Enqueue(ctx, likeCreated("event-1")))
+
+	// A replica claimed the delivery and stopped before recording the outcome
+	_, err := deliveries.Claim(ctx, "likes_event-1", testTime, testTime.Add(time.Minute))
+	require.NoError(t, err)
+
+	sent, err := d.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	*now = now.Add(time.Minute)
+	sent, err = d.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, 1, calls)
+
+	delivery := deliveries.get("likes_event-1")
+	assert.Equal(t, domain.DeliverySucceeded, delivery.Status)
+	assert.True(t, delivery.ClaimedUntil.IsZero())
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1738404000.{}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t,
+		"sha256=7843ca75a2feb0822ae75225b58050f3c27a2414a6dc922eda47504c6300e4db",
+		Sign("secret", "1738404000", []byte("{}")))
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/webhooks/domain"
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+// WebhooksService manages the webhooks of a user. Webhooks of other users
+// are reported as not found.
+type WebhooksService interface {
+	CreateWebhook(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error)
+	ListWebhooks(ctx context.Context, username string) ([]domain.Webhook, error)
+	GetWebhook(ctx context.Context, username, id string) (domain.Webhook, error)
+	UpdateWebhook(ctx context.Context, username, id string, update domain.WebhookUpdate) (domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, username, id string) error
+	ListDeliveries(ctx context.Context, username string, filter domain.DeliveryFilter) (domain.DeliveryPage, error)
+}
+
+// Dispatcher sends events to the webhooks subscribed to them
+type Dispatcher interface {
+	// Enqueue records a pending delivery of the event for every active
+	// webhook subscribed to its type
+	Enqueue(ctx context.Context, event module.Event[json.RawMessage]) error
+
+	// DeliverDue claims and sends one batch of due deliveries and returns how
+	// many were attempted. Deliveries claimed by another replica are skipped.
+	DeliverDue(ctx context.Context) (int, error)
+
+	// Cleanup deletes deliveries created before the given time
+	Cleanup(ctx context.Context, createdBefore time.Time) error
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ynwd/awesome-blog/internal/webhooks/domain"
+	"github.com/ynwd/awesome-blog/internal/webhooks/repo"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+
+	// minSecretLength is the shortest secret accepted from users. Generated
+	// secrets are twice as long.
+	minSecretLength = 16
+)
+
+var (
+	ErrInvalidUsername = errors.New("invalid username: username cannot be empty")
+)
+
+type webhooksService struct {
+	webhooks   repo.WebhooksRepository
+	deliveries repo.DeliveriesRepository
+	lookupIP   lookupIPFunc
+}
+
+func NewWebhooksService(webhooks repo.WebhooksRepository, deliveries repo.DeliveriesRepository) WebhooksService {
+	return &webhooksService{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+		lookupIP:   lookupIP,
+	}
+}
+
+// CreateWebhook registers an active webhook, generating its secret when
+// none is given
+func (s *webhooksService) CreateWebhook(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	if webhook.Username == "" {
+		return domain.Webhook{}, ErrInvalidUsername
+	}
+	if err := validateURL(ctx, s.lookupIP, webhook.URL); err != nil {
+		return domain.Webhook{}, err
+	}
+
+	eventTypes, err := normalizeEventTypes(webhook.EventTypes)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	webhook.EventTypes = eventTypes
+
+	if webhook.Secret == "" {
+		webhook.Secret = utils.GenerateRandomString(2 * minSecretLength)
+	}
+	if len(webhook.Secret) < minSecretLength {
+		return domain.Webhook{}, domain.ErrInvalidSecret
+	}
+
+	webhook.Active = true
+	webhook.ConsecutiveFailures = 0
+	webhook.DisabledReason = ""
+	webhook.CreatedAt = time.Now().UTC()
+	webhook.UpdatedAt = webhook.CreatedAt
+
+	id, err := s.webhooks.Create(ctx, webhook)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	webhook.ID = id
+	return webhook, nil
+}
+
+func (s *webhooksService) ListWebhooks(ctx context.Context, username string) ([]domain.Webhook, error) {
+	if username == "" {
+		return nil, ErrInvalidUsername
+	}
+	return s.webhooks.ListByUser(ctx, username)
+}
+
+func (s *webhooksService) GetWebhook(ctx context.Context, username, id string) (domain.Webhook, error) {
+	if id == "" {
+		return domain.Webhook{}, domain.ErrWebhookNotFound
+	}
+
+	webhook, err := s.webhooks.GetByID(ctx, id)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	if webhook.Username != username {
+		return domain.Webhook{}, domain.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+func (s *webhooksService) UpdateWebhook(ctx context.Context, username, id string, update domain.WebhookUpdate) (domain.Webhook, error) {
+	webhook, err := s.GetWebhook(ctx, username, id)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	if update.URL != nil {
+		if err := validateURL(ctx, s.lookupIP, *update.URL); err != nil {
+			return domain.Webhook{}, err
+		}
+		webhook.URL = *update.URL
+	}
+	if update.EventTypes != nil {
+		eventTypes, err := normalizeEventTypes(update.EventTypes)
+		if err != nil {
+			return domain.Webhook{}, err
+		}
+		webhook.EventTypes = eventTypes
+	}
+	if update.Secret != nil {
+		if len(*update.Secret) < minSecretLength {
+			return domain.Webhook{}, domain.ErrInvalidSecret
+		}
+		webhook.Secret = *update.Secret
+	}
+	if update.Active != nil {
+		// Re-enabling starts counting failures from scratch
+		if *update.Active && !webhook.Active {
+			webhook.ConsecutiveFailures = 0
+			webhook.DisabledReason = ""
+		}
+		webhook.Active = *update.Active
+	}
+
+	webhook.UpdatedAt = time.Now().UTC()
+	if err := s.webhooks.Update(ctx, webhook); err != nil {
+		return domain.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook removes the webhook. Its pending deliveries fail when they
+// come due, and its delivery log is kept until it expires.
+func (s *webhooksService) DeleteWebhook(ctx context.Context, username, id string) error {
+	if _, err := s.GetWebhook(ctx, username, id); err != nil {
+		return err
+	}
+	return s.webhooks.Delete(ctx, id)
+}
+
+func (s *webhooksService) ListDeliveries(ctx context.Context, username string, filter domain.DeliveryFilter) (domain.DeliveryPage, error) {
+	if _, err := s.GetWebhook(ctx, username, filter.WebhookID); err != nil {
+		return domain.DeliveryPage{}, err
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultPageSize
+	}
+	if filter.Limit > MaxPageSize {
+		filter.Limit = MaxPageSize
+	}
+	return s.deliveries.List(ctx, filter)
+}
+
+// normalizeEventTypes checks that every type can be subscribed to and drops
+// duplicates, keeping the order
+func normalizeEventTypes(eventTypes []string) ([]string, error) {
+	if len(eventTypes) == 0 {
+		return nil, domain.ErrNoEventTypes
+	}
+
+	seen := make(map[string]bool, len(eventTypes))
+	normalized := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		if !subscribable(module.EventType(eventType)) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrInvalidEventType, eventType)
+		}
+		if seen[eventType] {
+			continue
+		}
+		seen[eventType] = true
+		normalized = append(normalized, eventType)
+	}
+	return normalized, nil
+}
+
+func subscribable(eventType module.EventType) bool {
+	for _, t := range domain.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
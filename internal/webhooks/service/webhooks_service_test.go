@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ynwd/awesome-blog/internal/webhooks/domain"
+)
+
+// newTestService resolves hosts without DNS: internal.example.com to a private
+// address, missing.example.com to nothing and any other host to a public one
+func newTestService(webhooks *fakeWebhooks, deliveries *fakeDeliveries) WebhooksService {
+	service := NewWebhooksService(webhooks, deliveries).(*webhooksService)
+	service.lookupIP = func(_ context.Context, host string) ([]net.IP, error) {
+		switch host {
+		case "internal.example.com":
+			return []net.IP{net.ParseIP("93.184.215.14"), net.ParseIP("192.168.1.10")}, nil
+		case "missing.example.com":
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		default:
+			return []net.IP{net.ParseIP("93.184.215.14")}, nil
+		}
+	}
+	return service
+}
+
+func TestWebhooksService_CreateWebhook(t *testing.T) {
+	tests := []struct {
+		name    string
+		webhook domain.Webhook
+		wantErr error
+	}{
+		{
+			name:    "missing username",
+			webhook: domain.Webhook{URL: "https://example.com/hook", EventTypes: []string{"POST_CREATED"}},
+			wantErr: ErrInvalidUsername,
+		},
+		{
+			name:    "relative url",
+			webhook: domain.Webhook{Username: "user1", URL: "/hook", EventTypes: []string{"POST_CREATED"}},
+			wantErr: domain.ErrInvalidURL,
+		},
+		{
+			name:    "unsupported scheme",
+			webhook: domain.Webhook{Username: "user1", URL: "ftp://example.com/hook", EventTypes: []string{"POST_CREATED"}},
+			wantErr: domain.ErrInvalidURL,
+		},
+		{
+			name:    "loopback address",
+			webhook: domain.Webhook{Username: "user1", URL: "http://127.0.0.1/hook", EventTypes: []string{"POST_CREATED"}},
+			wantErr: domain.ErrForbiddenURL,
+		},
+		{
+			name:    "private address",
+			webhook: domain.Webhook{Username: "user1", URL: "http://10.0.0.1/hook", EventTypes: []string{"POST_CREATED"}},
+			wantErr: domain.ErrForbiddenURL,
+		},
+		{
+			name:    "metadata address",
+			webhook: domain.Webhook{Username: "user1", URL: "http://169.254.169.254/latest/meta-data", EventTypes: []string{"POST_CREATED"}},
+			wantErr: domain.ErrForbiddenURL,
+		},
+		{
+			name:    "host resolving to a private address",
+			webhook: domain.Webhook{Username: "user1", URL: "https://internal.example.com/hook", EventTypes: []string{"POST_CREATED"}},
+			wantErr: domain.ErrForbiddenURL,
+		},
+		{
+			name:    "unresolvable host",
+			webhook: domain.Webhook{Username: "user1", URL: "https://missing.example.com/hook", EventTypes: []string{"POST_CREATED"}},
+			wantErr: domain.ErrInvalidURL,
+		},
+		{
+			name:    "no event types",
+			webhook: domain.Webhook{Username: "user1", URL: "https://example.com/hook"},
+			wantErr: domain.ErrNoEventTypes,
+		},
+		{
+			name:    "command event type",
+			webhook: domain.Webhook{Username: "user1", URL: "https://example.com/hook", EventTypes: []string{"POST"}},
+			wantErr: domain.ErrInvalidEventType,
+		},
+		{
+			name:    "short secret",
+			webhook: domain.Webhook{Username: "user1", URL: "https://example.com/hook", EventTypes: []string{"POST_CREATED"}, Secret: "short"},
+			wantErr: domain.ErrInvalidSecret,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestService(newFakeWebhooks(), newFakeDeliveries())
+			_, err := service.CreateWebhook(context.Background(), tt.webhook)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("generates a secret", func(t *testing.T) {
+		webhooks := newFakeWebhooks()
+		service := newTestService(webhooks, newFakeDeliveries())
+
+		webhook, err := service.CreateWebhook(context.Background(), domain.Webhook{
+			Username:   "user1",
+			URL:        "https://example.com/hook",
+			EventTypes: []string{"POST_CREATED", "LIKE_CREATED", "POST_CREATED"},
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, webhook.ID)
+		assert.Len(t, webhook.Secret, 32)
+		assert.True(t, webhook.Active)
+		assert.Equal(t, []string{"POST_CREATED", "LIKE_CREATED"}, webhook.EventTypes)
+		assert.False(t, webhook.CreatedAt.IsZero())
+
+		stored, err := webhooks.GetByID(context.Background(), webhook.ID)
+		require.NoError(t, err)
+		assert.Equal(t, webhook, stored)
+	})
+}
+
+func TestWebhooksService_Ownership(t *testing.T) {
+	webhooks := newFakeWebhooks(domain.Webhook{ID: "hook-1", Username: "user1", Active: true})
+	service := newTestService(webhooks, newFakeDeliveries())
+	ctx := context.Background()
+
+	_, err := service.GetWebhook(ctx, "user2", "hook-1")
+	assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+
+	active := false
+	_, err = service.UpdateWebhook(ctx, "user2", "hook-1", domain.WebhookUpdate{Active: &active})
+	assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+
+	err = service.DeleteWebhook(ctx, "user2", "hook-1")
+	assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+
+	_, err = service.ListDeliveries(ctx, "user2", domain.DeliveryFilter{WebhookID: "hook-1"})
+	assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+
+	webhook, err := service.GetWebhook(ctx, "user1", "hook-1")
+	require.NoError(t, err)
+	assert.True(t, webhook.Active)
+}
+
+func TestWebhooksService_UpdateWebhookReenables(t *testing.T) {
+	webhooks := newFakeWebhooks(domain.Webhook{
+		ID:                  "hook-1",
+		Username:            "user1",
+		URL:                 "https://example.com/hook",
+		EventTypes:          []string{"POST_CREATED"},
+		Active:              false,
+		ConsecutiveFailures: 5,
+		DisabledReason:      "5 deliveries in a row failed",
+	})
+	service := newTestService(webhooks, newFakeDeliveries())
+
+	url := "http://example.com/v2"
+	active := true
+	webhook, err := service.UpdateWebhook(context.Background(), "user1", "hook-1", domain.WebhookUpdate{
+		URL:    &url,
+		Active: &active,
+	})
+	require.NoError(t, err)
+	assert.True(t, webhook.Active)
+	assert.Equal(t, 0, webhook.ConsecutiveFailures)
+	assert.Empty(t, webhook.DisabledReason)
+	assert.Equal(t, "http://example.com/v2", webhook.URL)
+	assert.Equal(t, []string{"POST_CREATED"}, webhook.EventTypes)
+
+	invalid := "not a url"
+	_, err = service.UpdateWebhook(context.Background(), "user1", "hook-1", domain.WebhookUpdate{URL: &invalid})
+	assert.ErrorIs(t, err, domain.ErrInvalidURL)
+
+	private := "http://10.0.0.1/hook"
+	_, err = service.UpdateWebhook(context.Background(), "user1", "hook-1", domain.WebhookUpdate{URL: &private})
+	assert.ErrorIs(t, err, domain.ErrForbiddenURL)
+}
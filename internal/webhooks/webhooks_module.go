@@ -0,0 +1,52 @@
+package webhooks
+
+import (
+	"cloud.google.com/go/firestore"
+	"github.com/ynwd/awesome-blog/internal/webhooks/handler"
+	"github.com/ynwd/awesome-blog/internal/webhooks/repo"
+	"github.com/ynwd/awesome-blog/internal/webhooks/service"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"github.com/ynwd/awesome-blog/pkg/utils"
+)
+
+type Module struct {
+	handler      *handler.WebhooksHandler
+	eventHandler *handler.WebhooksEventHandler
+	dispatcher   service.Dispatcher
+}
+
+func NewModule(firestoreClient *firestore.Client, processed utils.ProcessedEvents, config service.DispatcherConfig) *Module {
+	// Initialize repositories
+	webhooksRepo := repo.NewWebhooksRepository(firestoreClient)
+	deliveriesRepo := repo.NewDeliveriesRepository(firestoreClient)
+
+	// Initialize services
+	webhooksService := service.NewWebhooksService(webhooksRepo, deliveriesRepo)
+	dispatcher := service.NewDispatcher(webhooksRepo, deliveriesRepo, config)
+
+	// Initialize handlers
+	webhooksHandler := handler.NewWebhooksHandler(webhooksService)
+	eventHandler := handler.NewWebhooksEventHandler(dispatcher, processed)
+
+	return &Module{
+		handler:      webhooksHandler,
+		eventHandler: eventHandler,
+		dispatcher:   dispatcher,
+	}
+}
+
+// Dispatcher sends the queued deliveries; the app runs it periodically
+func (m *Module) Dispatcher() service.Dispatcher {
+	return m.dispatcher
+}
+
+func (m *Module) RegisterEvents(registry *module.EventRegistry) {
+	module.Handle(registry, module.PostCreatedEvent, module.PostCreatedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.PostUpdatedEvent, module.PostUpdatedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.PostDeletedEvent, module.PostDeletedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.CommentCreatedEvent, module.CommentCreatedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.CommentUpdatedEvent, module.CommentUpdatedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.CommentDeletedEvent, module.CommentDeletedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.LikeCreatedEvent, module.LikeCreatedEventVersion, m.eventHandler.Handle)
+	module.Handle(registry, module.LikeDeletedEvent, module.LikeDeletedEventVersion, m.eventHandler.Handle)
+}
@@ -0,0 +1,12 @@
+package webhooks
+
+import "github.com/gin-gonic/gin"
+
+func (m *Module) RegisterRoutes(router *gin.Engine) {
+	router.POST("/webhooks", m.handler.CreateWebhook)
+	router.GET("/webhooks", m.handler.ListWebhooks)
+	router.GET("/webhooks/:id", m.handler.GetWebhook)
+	router.PATCH("/webhooks/:id", m.handler.UpdateWebhook)
+	router.DELETE("/webhooks/:id", m.handler.DeleteWebhook)
+	router.GET("/webhooks/:id/deliveries", m.handler.ListDeliveries)
+}
@@ -0,0 +1,175 @@
+// Package markdown renders user-written CommonMark to HTML that is safe to
+// embed in a page.
+package markdown
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+const (
+	// ExcerptLength bounds the excerpt in runes, not counting the ellipsis
+	ExcerptLength = 200
+
+	// WordsPerMinute is the reading speed the reading time assumes
+	WordsPerMinute = 200
+)
+
+// Document is a rendered markdown source
+type Document struct {
+	// HTML is the sanitized rendering of the source
+	HTML string
+
+	// Text is the prose of the source without markup, code blocks, raw HTML
+	// or footnotes, on one line
+	Text string
+
+	// Excerpt is the start of Text, cut at a word boundary
+	Excerpt string
+
+	// ReadingTime is the estimated reading time in whole minutes, at least one
+	// for a source with any words
+	ReadingTime int
+}
+
+var renderer = goldmark.New(
+	goldmark.WithExtensions(
+		extension.Table,
+		extension.Strikethrough,
+		extension.Linkify,
+		extension.Footnote,
+	),
+	// Raw HTML is passed through so the sanitizer decides what stays
+	goldmark.WithRendererOptions(gmhtml.WithUnsafe()),
+)
+
+// policy allows what user-generated content may contain, plus the classes
+// goldmark puts on fenced code and footnotes. Links get rel="nofollow".
+var policy = func() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#.-]+$`)).OnElements("code")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^footnote-(ref|backref)$`)).OnElements("a")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^footnotes$`)).OnElements("div")
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-(noteref|backlink|endnotes)$`)).OnElements("a", "div")
+	p.AllowAttrs("style").Matching(regexp.MustCompile(`^text-align:(left|right|center)$`)).OnElements("th", "td")
+	return p
+}()
+
+// Render renders the source with tables, strikethrough, autolinks and
+// footnotes, and sanitizes the result
+func Render(source string) (Document, error) {
+	src := []byte(source)
+	root := renderer.Parser().Parse(text.NewReader(src))
+
+	var buf bytes.Buffer
+	if err := renderer.Renderer().Render(&buf, src, root); err != nil {
+		return Document{}, err
+	}
+
+	prose, words := plainText(root, src)
+	return Document{
+		HTML:        policy.Sanitize(buf.String()),
+		Text:        prose,
+		Excerpt:     excerpt(prose, ExcerptLength),
+		ReadingTime: readingTime(words),
+	}, nil
+}
+
+// PlainText returns the prose of the source without markup
+func PlainText(source string) string {
+	src := []byte(source)
+	prose, _ := plainText(renderer.Parser().Parse(text.NewReader(src)), src)
+	return prose
+}
+
+// rawText matches inline tags whose contents the sanitizer drops
+var rawText = regexp.MustCompile(`(?i)^<(/?)(script|style)\b`)
+
+// plainText collects the prose of the document and counts its words. Code
+// blocks are left out of the prose but their words are counted, as they take
+// time to read too.
+func plainText(root ast.Node, src []byte) (string, int) {
+	var sb strings.Builder
+	codeWords := 0
+	inRawText := false
+	_ = ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			if n.Type() == ast.TypeBlock {
+				sb.WriteByte(' ')
+			}
+			return ast.WalkContinue, nil
+		}
+
+		switch n := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				segment := lines.At(i)
+				codeWords += len(strings.Fields(string(segment.Value(src))))
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.RawHTML:
+			if m := rawText.FindSubmatch(n.Segments.Value(src)); m != nil {
+				inRawText = len(m[1]) == 0
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.HTMLBlock, *east.FootnoteList, *east.FootnoteLink:
+			return ast.WalkSkipChildren, nil
+		}
+		if inRawText {
+			return ast.WalkContinue, nil
+		}
+
+		switch n := n.(type) {
+		case *ast.Text:
+			sb.WriteString(html.UnescapeString(string(n.Segment.Value(src))))
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				sb.WriteByte(' ')
+			}
+		case *ast.String:
+			sb.Write(n.Value)
+		case *ast.AutoLink:
+			sb.Write(n.Label(src))
+		}
+		return ast.WalkContinue, nil
+	})
+
+	words := strings.Fields(sb.String())
+	return strings.Join(words, " "), len(words) + codeWords
+}
+
+// excerpt returns text up to limit runes, cut at the last word boundary and
+// followed by an ellipsis when shortened
+func excerpt(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+
+	cut := text
+	for i := range text {
+		if limit == 0 {
+			cut = text[:i]
+			break
+		}
+		limit--
+	}
+	if space := strings.LastIndexByte(cut, ' '); space > 0 {
+		cut = cut[:space]
+	}
+	return strings.TrimRight(cut, " ,;:.-") + "…"
+}
+
+func readingTime(words int) int {
+	return (words + WordsPerMinute - 1) / WordsPerMinute
+}
@@ -0,0 +1,100 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender_HTML(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "inline markup",
+			source: "Hello **world** & ~~friends~~ `code`",
+			want:   "<p>Hello <strong>world</strong> &amp; <del>friends</del> <code>code</code></p>\n",
+		},
+		{
+			name:   "fenced code keeps its language",
+			source: "```go\nfmt.Println(\"<hi>\")\n```",
+			want:   "<pre><code class=\"language-go\">fmt.Println(&#34;&lt;hi&gt;&#34;)\n</code></pre>\n",
+		},
+		{
+			name:   "tables keep their alignment",
+			source: "| a | b |\n|:--|--:|\n| 1 | 2 |",
+			want:   "<table>\n<thead>\n<tr>\n<th style=\"text-align:left\">a</th>\n<th style=\"text-align:right\">b</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td style=\"text-align:left\">1</td>\n<td style=\"text-align:right\">2</td>\n</tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name:   "footnotes",
+			source: "Note[^1]\n\n[^1]: Details.",
+			want: "<p>Note<sup id=\"fnref:1\"><a href=\"#fn:1\" class=\"footnote-ref\" role=\"doc-noteref\" rel=\"nofollow\">1</a></sup></p>\n" +
+				"<div class=\"footnotes\" role=\"doc-endnotes\">\n<hr>\n<ol>\n<li id=\"fn:1\">\n" +
+				"<p>Details.\u00a0<a href=\"#fnref:1\" class=\"footnote-backref\" role=\"doc-backlink\" rel=\"nofollow\">↩︎</a></p>\n</li>\n</ol>\n</div>\n",
+		},
+		{
+			name:   "links are nofollow",
+			source: "[docs](https://example.com) and https://go.dev",
+			want:   "<p><a href=\"https://example.com\" rel=\"nofollow\">docs</a> and <a href=\"https://go.dev\" rel=\"nofollow\">https://go.dev</a></p>\n",
+		},
+		{
+			name:   "scripts are removed",
+			source: "Hi <script>alert(1)</script>\n\n<script>\nalert(2)\n</script>",
+			want:   "<p>Hi </p>\n",
+		},
+		{
+			name:   "event handlers and unsafe urls are removed",
+			source: "<b onclick=\"alert(1)\">bold</b> [click](javascript:alert(1)) <img src=\"x.png\" onerror=\"alert(1)\">",
+			want:   "<p><b>bold</b> click <img src=\"x.png\"></p>\n",
+		},
+		{
+			name:   "unknown classes are removed",
+			source: "<p class=\"admin-only\">styled</p>",
+			want:   "<p>styled</p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Render(tt.source)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, doc.HTML)
+		})
+	}
+}
+
+func TestRender_Text(t *testing.T) {
+	source := "# Getting started\n\nInstall the *tool* &amp; run it:\n\n```sh\nmake build install\n```\n\nSee https://go.dev.[^1]\n\n[^1]: A footnote."
+
+	doc, err := Render(source)
+	require.NoError(t, err)
+	assert.Equal(t, "Getting started Install the tool & run it: See https://go.dev.", doc.Text)
+	assert.Equal(t, doc.Text, doc.Excerpt)
+	assert.Equal(t, 1, doc.ReadingTime)
+	assert.Equal(t, doc.Text, PlainText(source))
+
+	// Inline script contents are dropped like the sanitizer drops them
+	assert.Equal(t, "Hi there", PlainText("Hi <script>alert(1)</script><b>there</b>"))
+}
+
+func TestRender_ExcerptAndReadingTime(t *testing.T) {
+	doc, err := Render(strings.Repeat("word ", 450))
+	require.NoError(t, err)
+	assert.Equal(t, 3, doc.ReadingTime)
+	assert.Equal(t, strings.Repeat("word ", 39)+"word…", doc.Excerpt)
+
+	doc, err = Render("")
+	require.NoError(t, err)
+	assert.Equal(t, Document{}, doc)
+}
+
+func TestExcerpt(t *testing.T) {
+	assert.Equal(t, "short", excerpt("short", 10))
+	assert.Equal(t, "héllo…", excerpt("héllo wörld", 8))
+	assert.Equal(t, "one, two…", excerpt("one, two, three", 10))
+	assert.Equal(t, "abcdefghij…", excerpt("abcdefghijklmnop", 10))
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrForbidden = errors.New("admin access required")
+)
+
+// RequireAdmin only lets through authenticated users listed in the
+// comma-separated ADMIN_USERNAMES. The list is read when the middleware is created.
+func RequireAdmin() gin.HandlerFunc {
+	admins := make(map[string]bool)
+	for _, username := range strings.Split(os.Getenv("ADMIN_USERNAMES"), ",") {
+		if username = strings.TrimSpace(username); username != "" {
+			admins[username] = true
+		}
+	}
+
+	return func(c *gin.Context) {
+		username, ok := CurrentUser(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{
+				Error:     ErrUnauthenticated.Error(),
+				RequestID: c.GetString("request_id"),
+			})
+			return
+		}
+		if !admins[username] {
+			c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{
+				Error:     ErrForbidden.Error(),
+				RequestID: c.GetString("request_id"),
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	os.Setenv("ADMIN_USERNAMES", "alice, carol")
+	defer os.Unsetenv("ADMIN_USERNAMES")
+
+	tests := []struct {
+		name       string
+		userID     string
+		wantStatus int
+	}{
+		{name: "admin is allowed", userID: "alice", wantStatus: http.StatusOK},
+		{name: "admin with spacing in the list is allowed", userID: "carol", wantStatus: http.StatusOK},
+		{name: "other users are forbidden", userID: "bob", wantStatus: http.StatusForbidden},
+		{name: "anonymous requests are unauthorized", userID: "", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.userID != "" {
+					c.Set(ContextUserIDKey, tt.userID)
+				}
+			})
+			router.GET("/admin", RequireAdmin(), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -86,8 +86,13 @@ func AuthMiddleware(config AuthConfig) gin.HandlerFunc {
 			return
 		}
 
-		// Get token from Authorization header
+		// Get token from Authorization header. Browsers cannot set headers on
+		// EventSource and WebSocket requests, so stream endpoints also accept
+		// the token in the access_token query parameter.
 		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" && isStreamPath(path) && c.Query("access_token") != "" {
+			authHeader = "Bearer " + c.Query("access_token")
+		}
 		if authHeader == "" {
 			if !config.rateLimitUnauth.AllowRequest(clientIP) {
 				sendRateLimitError(c)
@@ -158,7 +163,8 @@ func AuthMiddleware(config AuthConfig) gin.HandlerFunc {
 		}
 
 		// Set user context
-		c.Set("user_id", claims.UserID)
+		c.Set(ContextUserIDKey, claims.UserID)
+		c.Set(ContextTokenIDKey, claims.ID)
 		c.Set("auth_time", time.Now().UTC())
 
 		// If token is approaching expiry, send new token in response header
@@ -178,6 +184,7 @@ func isPublicPath(path string) bool {
 	switch path {
 	case "/api/v1/auth/login",
 		"/api/v1/auth/register",
+		"/api/v1/auth/refresh",
 		"/login",
 		"/register":
 		return true
@@ -186,6 +193,21 @@ func isPublicPath(path string) bool {
 	}
 }
 
+// isStreamPath reports whether the path is one of the stream routes: /ws,
+// /notifications/stream and /posts/:id/stream. Only these take the token in
+// the query.
+func isStreamPath(path string) bool {
+	if path == "/ws" || path == "/notifications/stream" {
+		return true
+	}
+	id, ok := strings.CutPrefix(path, "/posts/")
+	if !ok {
+		return false
+	}
+	id, ok = strings.CutSuffix(id, "/stream")
+	return ok && id != "" && !strings.Contains(id, "/")
+}
+
 func sendError(c *gin.Context, status int, message string) {
 	requestID, _ := c.Get("request_id")
 	response := ErrorResponse{
@@ -222,3 +222,55 @@ func TestTokenRenewal(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, "new.token.here", w.Header().Get("X-New-Token"))
 }
+
+func TestAuthMiddleware_StreamQueryToken(t *testing.T) {
+	var validated string
+	config := NewAuthConfig()
+	config.JWT = &mockJWT{
+		validateTokenFunc: func(tokenString string, fingerprint *utils.TokenFingerprint) (*jwt.Token, error) {
+			validated = tokenString
+			return &jwt.Token{Valid: true}, nil
+		},
+		getClaimsFunc: func(token *jwt.Token) (*utils.Claims, error) {
+			return &utils.Claims{
+				RegisteredClaims: jwt.RegisteredClaims{
+					IssuedAt: jwt.NewNumericDate(time.Now()),
+					Issuer:   config.AllowedIssuers[0],
+				},
+				UserID: "test-user",
+			}, nil
+		},
+	}
+	router := setupTestRouter(config)
+	router.GET("/posts/:id/stream", func(c *gin.Context) {
+		c.JSON(200, gin.H{"user": c.GetString(ContextUserIDKey)})
+	})
+
+	// Stream endpoints accept the token as a query parameter
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/post-1/stream?access_token=query.token", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user":"test-user"}`, w.Body.String())
+	assert.Equal(t, "query.token", validated)
+
+	// Other endpoints do not, even when they end in /stream
+	router.GET("/users/:name/stream", func(c *gin.Context) { c.Status(200) })
+	for _, path := range []string{"/test", "/users/alice/stream", "/posts/a/b/stream", "/posts//stream"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path+"?access_token=query.token", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code, path)
+	}
+}
+
+func TestIsStreamPath(t *testing.T) {
+	assert.True(t, isStreamPath("/ws"))
+	assert.True(t, isStreamPath("/notifications/stream"))
+	assert.True(t, isStreamPath("/posts/post-1/stream"))
+
+	assert.False(t, isStreamPath("/stream"))
+	assert.False(t, isStreamPath("/users/stream"))
+	assert.False(t, isStreamPath("/api/v1/users/stream"))
+	assert.False(t, isStreamPath("/posts/stream"))
+	assert.False(t, isStreamPath("/posts/a/b/stream"))
+	assert.False(t, isStreamPath("/ws/other"))
+}
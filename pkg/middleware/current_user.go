@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ContextUserIDKey  = "user_id"
+	ContextTokenIDKey = "token_id"
+)
+
+var (
+	ErrUnauthenticated = errors.New("authentication required")
+	ErrUserMismatch    = errors.New("username does not match the authenticated user")
+)
+
+// CurrentUser returns the username that AuthMiddleware stored in the context
+func CurrentUser(c *gin.Context) (string, bool) {
+	userID := c.GetString(ContextUserIDKey)
+	return userID, userID != ""
+}
+
+// ActingUser returns the authenticated user for a write request. A username
+// sent by the client is only accepted when it matches the token's user.
+func ActingUser(c *gin.Context, claimed string) (string, error) {
+	userID, ok := CurrentUser(c)
+	if !ok {
+		return "", ErrUnauthenticated
+	}
+	if claimed != "" && claimed != userID {
+		return "", ErrUserMismatch
+	}
+	return userID, nil
+}
+
+// IdentityErrorStatus maps errors returned by ActingUser to HTTP status codes
+func IdentityErrorStatus(err error) int {
+	if errors.Is(err, ErrUserMismatch) {
+		return http.StatusForbidden
+	}
+	return http.StatusUnauthorized
+}
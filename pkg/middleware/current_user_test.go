@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActingUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		userID     string
+		claimed    string
+		wantUser   string
+		wantErr    error
+		wantStatus int
+	}{
+		{
+			name:     "uses authenticated user when none is claimed",
+			userID:   "alice",
+			wantUser: "alice",
+		},
+		{
+			name:     "accepts matching claimed user",
+			userID:   "alice",
+			claimed:  "alice",
+			wantUser: "alice",
+		},
+		{
+			name:       "rejects mismatched claimed user",
+			userID:     "alice",
+			claimed:    "bob",
+			wantErr:    ErrUserMismatch,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "rejects unauthenticated request",
+			claimed:    "bob",
+			wantErr:    ErrUnauthenticated,
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			if tt.userID != "" {
+				c.Set(ContextUserIDKey, tt.userID)
+			}
+
+			user, err := ActingUser(c, tt.claimed)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantUser, user)
+			if err != nil {
+				assert.Equal(t, tt.wantStatus, IdentityErrorStatus(err))
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// redactedParams are the query parameters whose values are kept out of the
+// request log. Stream endpoints accept the bearer token in access_token.
+var redactedParams = []string{"access_token"}
+
+// Logger is gin's request logger with the values of redactedParams replaced
+// by "REDACTED"
+func Logger() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		var statusColor, methodColor, resetColor string
+		if param.IsOutputColor() {
+			statusColor = param.StatusCodeColor()
+			methodColor = param.MethodColor()
+			resetColor = param.ResetColor()
+		}
+		if param.Latency > time.Minute {
+			param.Latency = param.Latency.Truncate(time.Second)
+		}
+
+		return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			statusColor, param.StatusCode, resetColor,
+			param.Latency,
+			param.ClientIP,
+			methodColor, param.Method, resetColor,
+			RedactQuery(param.Path),
+			param.ErrorMessage,
+		)
+	})
+}
+
+// RedactQuery replaces the values of redactedParams in the query of path,
+// leaving the rest of it as sent
+func RedactQuery(path string) string {
+	base, query, found := strings.Cut(path, "?")
+	if !found {
+		return path
+	}
+
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(key); err == nil && redacted(name) {
+			pairs[i] = key + "=REDACTED"
+		}
+	}
+	return base + "?" + strings.Join(pairs, "&")
+}
+
+func redacted(name string) bool {
+	for _, param := range redactedParams {
+		if name == param {
+			return true
+		}
+	}
+	return false
+}
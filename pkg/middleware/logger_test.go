@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{
+			name: "no query",
+			path: "/posts",
+			want: "/posts",
+		},
+		{
+			name: "other parameters are kept",
+			path: "/posts?limit=10&cursor=abc",
+			want: "/posts?limit=10&cursor=abc",
+		},
+		{
+			name: "access token",
+			path: "/stream?access_token=eyJhbGciOiJIUzI1NiJ9.payload.signature&topics=posts",
+			want: "/stream?access_token=REDACTED&topics=posts",
+		},
+		{
+			name: "escaped and repeated access token",
+			path: "/ws?access%5Ftoken=secret&access_token=other",
+			want: "/ws?access%5Ftoken=REDACTED&access_token=REDACTED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, RedactQuery(tt.path))
+		})
+	}
+}
+
+func TestLoggerRedactsAccessToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var out bytes.Buffer
+	writer := gin.DefaultWriter
+	gin.DefaultWriter = &out
+	t.Cleanup(func() { gin.DefaultWriter = writer })
+
+	router := gin.New()
+	router.Use(Logger())
+	router.GET("/stream", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/stream?access_token=secret-token&topics=posts", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Contains(t, out.String(), `"/stream?access_token=REDACTED&topics=posts"`)
+	assert.NotContains(t, out.String(), "secret-token")
+}
@@ -1,15 +1,114 @@
 package module
 
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type EventType string
 
 const (
 	LikeEvent    EventType = "LIKE"
+	UnlikeEvent  EventType = "UNLIKE"
 	PostEvent    EventType = "POST"
 	CommentEvent EventType = "COMMENT"
+
+	// Domain events, emitted through the outbox after a write is committed.
+	// The commands above ask for a write; these report that it happened.
+	PostCreatedEvent    EventType = "POST_CREATED"
+	PostUpdatedEvent    EventType = "POST_UPDATED"
+	PostDeletedEvent    EventType = "POST_DELETED"
+	CommentCreatedEvent EventType = "COMMENT_CREATED"
+	CommentUpdatedEvent EventType = "COMMENT_UPDATED"
+	CommentDeletedEvent EventType = "COMMENT_DELETED"
+	LikeCreatedEvent    EventType = "LIKE_CREATED"
+	LikeDeletedEvent    EventType = "LIKE_DELETED"
+
+	// UserMessageEvent carries an update for a user's open streams to every
+	// replica, since any of them may hold the user's connections
+	UserMessageEvent EventType = "USER_MESSAGE"
+)
+
+// Schema versions of the event payloads. A version is bumped when its payload
+// changes incompatibly; consumers register a handler per version they read.
+const (
+	LikeEventVersion    = 1
+	UnlikeEventVersion  = 1
+	PostEventVersion    = 1
+	CommentEventVersion = 1
+
+	PostCreatedEventVersion    = 1
+	PostUpdatedEventVersion    = 1
+	PostDeletedEventVersion    = 1
+	CommentCreatedEventVersion = 1
+	CommentUpdatedEventVersion = 1
+	CommentDeletedEventVersion = 1
+	LikeCreatedEventVersion    = 1
+	LikeDeletedEventVersion    = 1
+
+	UserMessageEventVersion = 1
 )
 
+var schemaVersions = map[EventType]int{
+	LikeEvent:    LikeEventVersion,
+	UnlikeEvent:  UnlikeEventVersion,
+	PostEvent:    PostEventVersion,
+	CommentEvent: CommentEventVersion,
+
+	PostCreatedEvent:    PostCreatedEventVersion,
+	PostUpdatedEvent:    PostUpdatedEventVersion,
+	PostDeletedEvent:    PostDeletedEventVersion,
+	CommentCreatedEvent: CommentCreatedEventVersion,
+	CommentUpdatedEvent: CommentUpdatedEventVersion,
+	CommentDeletedEvent: CommentDeletedEventVersion,
+	LikeCreatedEvent:    LikeCreatedEventVersion,
+	LikeDeletedEvent:    LikeDeletedEventVersion,
+
+	UserMessageEvent: UserMessageEventVersion,
+}
+
+// BaseEvent is the envelope of every published event. ID is unique per
+// event and survives redelivery, so consumers can detect duplicates.
 type BaseEvent struct {
-	Type      EventType   `json:"type"`
-	Payload   interface{} `json:"payload"`
-	Timestamp string      `json:"timestamp"`
+	ID         string      `json:"id,omitempty"`
+	ProducerID string      `json:"producer_id,omitempty"`
+	Type       EventType   `json:"type"`
+	Version    int         `json:"version,omitempty"`
+	Payload    interface{} `json:"payload"`
+	Timestamp  string      `json:"timestamp"`
+}
+
+var (
+	producerID     string
+	producerIDOnce sync.Once
+)
+
+// NewEvent returns an event with a unique ID in the current schema version of
+// its type, stamped with this process as its producer and the current time
+func NewEvent(eventType EventType, payload interface{}) BaseEvent {
+	return BaseEvent{
+		ID:         uuid.New().String(),
+		ProducerID: ProducerID(),
+		Type:       eventType,
+		Version:    schemaVersions[eventType],
+		Payload:    payload,
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// ProducerID identifies this process as the source of the events it
+// publishes, as APPLICATION_NAME, host name and process ID
+func ProducerID() string {
+	producerIDOnce.Do(func() {
+		host, err := os.Hostname()
+		if err != nil {
+			host = "unknown"
+		}
+		producerID = fmt.Sprintf("%s/%s/%d", os.Getenv("APPLICATION_NAME"), host, os.Getpid())
+	})
+	return producerID
 }
@@ -0,0 +1,40 @@
+package module
+
+import "context"
+
+// PostLookup lets a module check posts without depending on the posts
+// module's internals. It is provided by the posts module.
+type PostLookup interface {
+	PostExists(ctx context.Context, postID string) (bool, error)
+
+	// PostAuthor returns the username of the post's author, or an empty
+	// string when the post does not exist
+	PostAuthor(ctx context.Context, postID string) (string, error)
+}
+
+// CommentLookup lets a module read comments without depending on the comments
+// module's internals. It is provided by the comments module.
+type CommentLookup interface {
+	// CommentAuthor returns the username of the comment's author, or an empty
+	// string when the comment does not exist or was deleted
+	CommentAuthor(ctx context.Context, commentID string) (string, error)
+}
+
+// UserLookup lets a module check users without depending on the users
+// module's internals. It is provided by the users module.
+type UserLookup interface {
+	UserExists(ctx context.Context, username string) (bool, error)
+}
+
+// FollowGraph reads who users follow without depending on the follows
+// module's internals. It is provided by the follows module.
+type FollowGraph interface {
+	// Followees returns the usernames the user follows
+	Followees(ctx context.Context, username string) ([]string, error)
+}
+
+// LikeCounter reports like counts without depending on the likes module's
+// internals. It is provided by the likes module.
+type LikeCounter interface {
+	LikeCount(ctx context.Context, postID string) (int64, error)
+}
@@ -1,12 +1,26 @@
 package module
 
 import (
-	"context"
-
 	"github.com/gin-gonic/gin"
 )
 
 type Module interface {
 	RegisterRoutes(router *gin.Engine)
-	RegisterEventHandlers(ctx context.Context, baseEvent BaseEvent)
+
+	// RegisterEvents registers the module's handlers for the event types it
+	// consumes. Modules that consume no events register nothing.
+	RegisterEvents(registry *EventRegistry)
+}
+
+// ReplicaModule is a Module that keeps state in the memory of each replica.
+// A subscription shared by the replicas hands each event to one of them, so
+// these handlers are registered on a registry fed by a subscription of the
+// replica alone. Their events are also delivered when the replica was not the
+// one that handled them on the shared subscription.
+type ReplicaModule interface {
+	Module
+
+	// RegisterReplicaEvents registers the handlers that every replica runs
+	// for every event
+	RegisterReplicaEvents(registry *EventRegistry)
 }
@@ -0,0 +1,9 @@
+package module
+
+// UserNotifier pushes an update to the open streams of a user. It is
+// provided by the stream module, which delivers it to the user's streams on
+// every replica. Delivery is best effort: users without an open stream miss
+// the update.
+type UserNotifier interface {
+	NotifyUser(username, id, messageType string, data any)
+}
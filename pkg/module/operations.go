@@ -0,0 +1,61 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+var (
+	ErrOperationNotStarted = errors.New("failed to record operation")
+	ErrPublishFailed       = errors.New("failed to publish event")
+)
+
+// OperationTracker records the outcome of writes requested through the
+// /pubsub endpoints, keyed by the ID of the event that requested them. It is
+// provided by the operations module.
+type OperationTracker interface {
+	// Start records a pending operation for the user's event
+	Start(ctx context.Context, eventID string, eventType EventType, username string) error
+
+	// Succeed records the ID of the document the event created. Events
+	// without an operation are ignored.
+	Succeed(ctx context.Context, eventID, resourceID string) error
+
+	// Fail records why the event could not be handled. Events without an
+	// operation are ignored.
+	Fail(ctx context.Context, eventID, reason string) error
+}
+
+// Publisher publishes events, such as a pubsub.PubSubClient
+type Publisher interface {
+	Publish(ctx context.Context, data interface{}) error
+}
+
+// PublishTracked starts the user's operation under the event ID before any
+// handler can see the event, then publishes it. An operation whose event could
+// not be published is failed. The error wraps ErrOperationNotStarted or
+// ErrPublishFailed.
+func PublishTracked(ctx context.Context, publisher Publisher, tracker OperationTracker, event BaseEvent, username string) error {
+	if err := tracker.Start(ctx, event.ID, event.Type, username); err != nil {
+		return fmt.Errorf("%w: %v", ErrOperationNotStarted, err)
+	}
+
+	if err := publisher.Publish(ctx, event); err != nil {
+		if err := tracker.Fail(ctx, event.ID, err.Error()); err != nil {
+			log.Printf("Error recording failed operation %s: %v", event.ID, err)
+		}
+		return fmt.Errorf("%w: %v", ErrPublishFailed, err)
+	}
+	return nil
+}
+
+// SucceedOperation records the outcome on the operation the publish endpoint
+// started. The entity is already written, so a tracking failure is only
+// logged.
+func SucceedOperation(ctx context.Context, tracker OperationTracker, eventID, resourceID string) {
+	if err := tracker.Succeed(ctx, eventID, resourceID); err != nil {
+		log.Printf("Error recording operation %s: %v", eventID, err)
+	}
+}
@@ -0,0 +1,73 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTracker struct {
+	startErr error
+	calls    []string
+}
+
+func (f *fakeTracker) Start(ctx context.Context, eventID string, eventType EventType, username string) error {
+	f.calls = append(f.calls, "start:"+eventID+":"+string(eventType)+":"+username)
+	return f.startErr
+}
+
+func (f *fakeTracker) Succeed(ctx context.Context, eventID, resourceID string) error {
+	f.calls = append(f.calls, "succeed:"+eventID+":"+resourceID)
+	return errors.New("tracking failed")
+}
+
+func (f *fakeTracker) Fail(ctx context.Context, eventID, reason string) error {
+	f.calls = append(f.calls, "fail:"+eventID+":"+reason)
+	return nil
+}
+
+type fakePublisher struct {
+	err       error
+	published []interface{}
+}
+
+func (f *fakePublisher) Publish(ctx context.Context, data interface{}) error {
+	f.published = append(f.published, data)
+	return f.err
+}
+
+func TestPublishTracked(t *testing.T) {
+	ctx := context.Background()
+	event := BaseEvent{ID: "event-1", Type: PostEvent}
+
+	t.Run("starts the operation and publishes", func(t *testing.T) {
+		tracker, publisher := &fakeTracker{}, &fakePublisher{}
+		assert.NoError(t, PublishTracked(ctx, publisher, tracker, event, "alice"))
+		assert.Equal(t, []string{"start:event-1:POST:alice"}, tracker.calls)
+		assert.Equal(t, []interface{}{event}, publisher.published)
+	})
+
+	t.Run("nothing is published without an operation", func(t *testing.T) {
+		tracker, publisher := &fakeTracker{startErr: errors.New("firestore error")}, &fakePublisher{}
+		err := PublishTracked(ctx, publisher, tracker, event, "alice")
+		assert.ErrorIs(t, err, ErrOperationNotStarted)
+		assert.Empty(t, publisher.published)
+	})
+
+	t.Run("failed publish fails the operation", func(t *testing.T) {
+		tracker, publisher := &fakeTracker{}, &fakePublisher{err: errors.New("broker down")}
+		err := PublishTracked(ctx, publisher, tracker, event, "alice")
+		assert.ErrorIs(t, err, ErrPublishFailed)
+		assert.Equal(t, []string{"start:event-1:POST:alice", "fail:event-1:broker down"}, tracker.calls)
+	})
+}
+
+func TestSucceedOperation(t *testing.T) {
+	tracker := &fakeTracker{}
+
+	// A tracking failure is only logged
+	SucceedOperation(context.Background(), tracker, "event-1", "post-1")
+	assert.Equal(t, []string{"succeed:event-1:post-1"}, tracker.calls)
+}
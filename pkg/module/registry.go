@@ -0,0 +1,159 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrMalformedEvent means the event cannot be decoded, so delivering it
+	// again will not help
+	ErrMalformedEvent = errors.New("malformed event")
+
+	// ErrUnsupportedVersion means the event type is handled, but not in the
+	// schema version of the event
+	ErrUnsupportedVersion = errors.New("unsupported event schema version")
+)
+
+// Event is a decoded event with a typed payload
+type Event[T any] struct {
+	ID         string
+	ProducerID string
+	Type       EventType
+	Version    int
+	Timestamp  time.Time
+	Payload    T
+}
+
+// EventHandler handles one event. Returning an error nacks the event so it
+// is delivered again later.
+type EventHandler[T any] func(ctx context.Context, event Event[T]) error
+
+// envelope is a BaseEvent as received, with the payload left undecoded
+type envelope struct {
+	ID         string          `json:"id"`
+	ProducerID string          `json:"producer_id"`
+	Type       EventType       `json:"type"`
+	Version    int             `json:"version"`
+	Payload    json.RawMessage `json:"payload"`
+	Timestamp  string          `json:"timestamp"`
+}
+
+type registration struct {
+	version int
+	handle  func(ctx context.Context, env envelope, timestamp time.Time) error
+}
+
+// EventRegistry routes events from the subscription to the handlers
+// registered for their type and schema version
+type EventRegistry struct {
+	mu       sync.RWMutex
+	handlers map[EventType][]registration
+
+	// quiet skips logging events of types nobody handles
+	quiet bool
+}
+
+func NewEventRegistry() *EventRegistry {
+	return &EventRegistry{
+		handlers: make(map[EventType][]registration),
+	}
+}
+
+// NewReplicaEventRegistry returns a registry for the handlers of
+// ReplicaModules. Its subscription receives every event while few are
+// handled, so the others are ignored without logging them.
+func NewReplicaEventRegistry() *EventRegistry {
+	registry := NewEventRegistry()
+	registry.quiet = true
+	return registry
+}
+
+// Handle registers handler for events of the given type and schema version,
+// decoding their payload into T. Several handlers may be registered for the
+// same type, and one type may have handlers for several versions.
+func Handle[T any](r *EventRegistry, eventType EventType, version int, handler EventHandler[T]) {
+	reg := registration{
+		version: version,
+		handle: func(ctx context.Context, env envelope, timestamp time.Time) error {
+			var payload T
+			if err := json.Unmarshal(env.Payload, &payload); err != nil {
+				return fmt.Errorf("%w: %s payload: %v", ErrMalformedEvent, env.Type, err)
+			}
+			return handler(ctx, Event[T]{
+				ID:         env.ID,
+				ProducerID: env.ProducerID,
+				Type:       env.Type,
+				Version:    env.Version,
+				Timestamp:  timestamp,
+				Payload:    payload,
+			})
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[eventType] = append(r.handlers[eventType], reg)
+}
+
+// Empty reports whether no handler is registered
+func (r *EventRegistry) Empty() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.handlers) == 0
+}
+
+// Dispatch decodes an event published as a BaseEvent and runs every handler
+// registered for its type and version. Events of a type nobody handles are
+// ignored. If any handler fails the joined errors are returned and the event
+// is delivered to all its handlers again.
+func (r *EventRegistry) Dispatch(ctx context.Context, data []byte) error {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedEvent, err)
+	}
+	if env.Type == "" {
+		return fmt.Errorf("%w: missing type", ErrMalformedEvent)
+	}
+	timestamp, err := time.Parse(time.RFC3339, env.Timestamp)
+	if err != nil {
+		return fmt.Errorf("%w: %s timestamp: %v", ErrMalformedEvent, env.Type, err)
+	}
+
+	// Events from producers that predate schema versions are version 1
+	if env.Version == 0 {
+		env.Version = 1
+	}
+
+	r.mu.RLock()
+	registered := r.handlers[env.Type]
+	r.mu.RUnlock()
+
+	if len(registered) == 0 {
+		if !r.quiet {
+			log.Printf("No handler registered for %s event, ignoring it", env.Type)
+		}
+		return nil
+	}
+
+	var errs []error
+	handled := false
+	for _, reg := range registered {
+		if reg.version != env.Version {
+			continue
+		}
+		handled = true
+		if err := reg.handle(ctx, env, timestamp); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if !handled {
+		return fmt.Errorf("%w: %s version %d", ErrUnsupportedVersion, env.Type, env.Version)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,118 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPayload struct {
+	PostID string `json:"post_id"`
+}
+
+// recorder returns a handler that records the events it handles
+func recorder(events *[]Event[testPayload], err error) EventHandler[testPayload] {
+	return func(ctx context.Context, event Event[testPayload]) error {
+		*events = append(*events, event)
+		return err
+	}
+}
+
+func marshal(t *testing.T, event interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(event)
+	require.NoError(t, err)
+	return data
+}
+
+func TestEventRegistry_Dispatch(t *testing.T) {
+	registry := NewEventRegistry()
+	var likes, unlikes []Event[testPayload]
+	Handle(registry, LikeEvent, 1, recorder(&likes, nil))
+	Handle(registry, UnlikeEvent, 1, recorder(&unlikes, nil))
+
+	event := NewEvent(LikeEvent, testPayload{PostID: "post1"})
+	require.NoError(t, registry.Dispatch(context.Background(), marshal(t, event)))
+
+	require.Len(t, likes, 1)
+	assert.Empty(t, unlikes)
+
+	got := likes[0]
+	assert.Equal(t, event.ID, got.ID)
+	assert.Equal(t, event.ProducerID, got.ProducerID)
+	assert.Equal(t, LikeEvent, got.Type)
+	assert.Equal(t, LikeEventVersion, got.Version)
+	assert.Equal(t, testPayload{PostID: "post1"}, got.Payload)
+	assert.Equal(t, event.Timestamp, got.Timestamp.Format(time.RFC3339))
+}
+
+func TestEventRegistry_DispatchToEveryHandler(t *testing.T) {
+	registry := NewEventRegistry()
+	var first, second []Event[testPayload]
+	Handle(registry, PostEvent, 1, recorder(&first, errors.New("first failed")))
+	Handle(registry, PostEvent, 1, recorder(&second, nil))
+
+	err := registry.Dispatch(context.Background(), marshal(t, NewEvent(PostEvent, testPayload{})))
+
+	assert.EqualError(t, err, "first failed")
+	assert.Len(t, first, 1)
+	assert.Len(t, second, 1)
+}
+
+func TestEventRegistry_DispatchUnregisteredType(t *testing.T) {
+	registry := NewEventRegistry()
+
+	err := registry.Dispatch(context.Background(), marshal(t, NewEvent(CommentEvent, testPayload{})))
+	assert.NoError(t, err)
+}
+
+func TestEventRegistry_DispatchVersions(t *testing.T) {
+	registry := NewEventRegistry()
+	var v1, v2 []Event[testPayload]
+	Handle(registry, PostEvent, 1, recorder(&v1, nil))
+	Handle(registry, PostEvent, 2, recorder(&v2, nil))
+
+	event := NewEvent(PostEvent, testPayload{})
+	event.Version = 2
+	require.NoError(t, registry.Dispatch(context.Background(), marshal(t, event)))
+	assert.Empty(t, v1)
+	assert.Len(t, v2, 1)
+
+	// Events published before schema versions are version 1
+	event.Version = 0
+	require.NoError(t, registry.Dispatch(context.Background(), marshal(t, event)))
+	assert.Len(t, v1, 1)
+
+	event.Version = 3
+	err := registry.Dispatch(context.Background(), marshal(t, event))
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+}
+
+func TestEventRegistry_DispatchMalformed(t *testing.T) {
+	registry := NewEventRegistry()
+	var events []Event[testPayload]
+	Handle(registry, PostEvent, 1, recorder(&events, nil))
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "invalid json", data: `not json`},
+		{name: "missing type", data: `{"payload":{},"timestamp":"2025-02-01T10:00:00Z"}`},
+		{name: "invalid timestamp", data: `{"type":"POST","payload":{},"timestamp":"yesterday"}`},
+		{name: "invalid payload", data: `{"type":"POST","payload":"text","timestamp":"2025-02-01T10:00:00Z"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.Dispatch(context.Background(), []byte(tt.data))
+			assert.ErrorIs(t, err, ErrMalformedEvent)
+		})
+	}
+	assert.Empty(t, events)
+}
@@ -0,0 +1,160 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/ynwd/awesome-blog/pkg/module"
+	"google.golang.org/api/iterator"
+)
+
+// Record statuses
+const (
+	StatusPending = "pending"
+	StatusSent    = "sent"
+
+	// StatusParked records are no longer relayed after failing to publish
+	// too many times. They are kept for inspection and are not cleaned up.
+	StatusParked = "parked"
+)
+
+// Record is an event waiting to be published, stored in the same transaction
+// as the write it reports. Its document ID is the event ID.
+type Record struct {
+	ID        string           `firestore:"-"`
+	Type      module.EventType `firestore:"type"`
+	Data      []byte           `firestore:"data"`
+	Status    string           `firestore:"status"`
+	Attempts  int              `firestore:"attempts"`
+	LastError string           `firestore:"last_error,omitempty"`
+	CreatedAt time.Time        `firestore:"created_at"`
+	SentAt    time.Time        `firestore:"sent_at,omitempty"`
+}
+
+// Store is the outbox as the relay sees it
+type Store interface {
+	// Pending returns up to limit unsent records, oldest first
+	Pending(ctx context.Context, limit int) ([]Record, error)
+	MarkSent(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id string, cause error) error
+	// Park stops relaying a record that keeps failing to publish
+	Park(ctx context.Context, id string) error
+	// Cleanup deletes records sent before the given time
+	Cleanup(ctx context.Context, sentBefore time.Time) error
+}
+
+// Outbox stages events in Firestore transactions and serves them to the relay.
+// Listing pending records requires a composite index on (status, created_at)
+// and cleaning up sent ones an index on (status, sent_at).
+type Outbox struct {
+	client     *firestore.Client
+	collection string
+}
+
+func New(client *firestore.Client) *Outbox {
+	collection := os.Getenv("GOOGLE_CLOUD_FIRESTORE_COLLECTION_OUTBOX")
+	if collection == "" {
+		collection = "outbox"
+	}
+	return &Outbox{
+		client:     client,
+		collection: collection,
+	}
+}
+
+// Add stages a new event of the given type in the transaction, so it is
+// published if and only if the transaction commits
+func (o *Outbox) Add(tx *firestore.Transaction, eventType module.EventType, payload interface{}) error {
+	event := module.NewEvent(eventType, payload)
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(o.client.Collection(o.collection).Doc(event.ID), Record{
+		Type:      eventType,
+		Data:      data,
+		Status:    StatusPending,
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+func (o *Outbox) Pending(ctx context.Context, limit int) ([]Record, error) {
+	iter := o.client.Collection(o.collection).
+		Where("status", "==", StatusPending).
+		OrderBy("created_at", firestore.Asc).
+		Limit(limit).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var records []Record
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var record Record
+		if err := doc.DataTo(&record); err != nil {
+			return nil, err
+		}
+		record.ID = doc.Ref.ID
+		records = append(records, record)
+	}
+}
+
+func (o *Outbox) MarkSent(ctx context.Context, id string) error {
+	_, err := o.client.Collection(o.collection).Doc(id).Update(ctx, []firestore.Update{
+		{Path: "status", Value: StatusSent},
+		{Path: "sent_at", Value: time.Now().UTC()},
+		{Path: "attempts", Value: firestore.Increment(1)},
+	})
+	return err
+}
+
+func (o *Outbox) MarkFailed(ctx context.Context, id string, cause error) error {
+	_, err := o.client.Collection(o.collection).Doc(id).Update(ctx, []firestore.Update{
+		{Path: "attempts", Value: firestore.Increment(1)},
+		{Path: "last_error", Value: cause.Error()},
+	})
+	return err
+}
+
+func (o *Outbox) Park(ctx context.Context, id string) error {
+	_, err := o.client.Collection(o.collection).Doc(id).Update(ctx, []firestore.Update{
+		{Path: "status", Value: StatusParked},
+	})
+	return err
+}
+
+func (o *Outbox) Cleanup(ctx context.Context, sentBefore time.Time) error {
+	docs, err := o.client.Collection(o.collection).
+		Where("status", "==", StatusSent).
+		Where("sent_at", "<", sentBefore).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return err
+	}
+
+	if len(docs) == 0 {
+		return nil
+	}
+
+	bw := o.client.BulkWriter(ctx)
+	for _, doc := range docs {
+		if _, err := bw.Delete(doc.Ref); err != nil {
+			bw.End()
+			return err
+		}
+	}
+	bw.End()
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package pubsub
+
+import (
+	"sync"
+	"time"
+)
+
+// Limits of the per-process delivery counts kept for subscriptions without a
+// dead-letter policy. A message that is not seen again within the TTL, for
+// example because another replica handled it, is forgotten.
+const (
+	attemptTTL         = time.Hour
+	maxTrackedMessages = 10_000
+)
+
+// attemptKey identifies a message on a subscription. A message has the same
+// ID on every subscription of the topic, and each counts its own deliveries.
+func attemptKey(subscriptionID, messageID string) string {
+	return subscriptionID + "/" + messageID
+}
+
+// attemptCounter counts deliveries per message in this process
+type attemptCounter struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	max     int
+	entries map[string]attemptEntry
+	now     func() time.Time
+}
+
+type attemptEntry struct {
+	count   int
+	expires time.Time
+}
+
+func newAttemptCounter(ttl time.Duration, max int) *attemptCounter {
+	return &attemptCounter{
+		ttl:     ttl,
+		max:     max,
+		entries: make(map[string]attemptEntry),
+		now:     time.Now,
+	}
+}
+
+// Add records a delivery of the message and returns which delivery it is
+func (c *attemptCounter) Add(id string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	entry, ok := c.entries[id]
+	if !ok || now.After(entry.expires) {
+		entry = attemptEntry{}
+		if len(c.entries) >= c.max {
+			c.evict(now)
+		}
+	}
+	entry.count++
+	entry.expires = now.Add(c.ttl)
+	c.entries[id] = entry
+	return entry.count
+}
+
+// Forget drops the count of a message that was acked
+func (c *attemptCounter) Forget(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, id)
+}
+
+// evict removes the expired entries, or the one expiring first when none has
+func (c *attemptCounter) evict(now time.Time) {
+	oldest := ""
+	for id, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, id)
+			continue
+		}
+		if oldest == "" || entry.expires.Before(c.entries[oldest].expires) {
+			oldest = id
+		}
+	}
+	if len(c.entries) >= c.max && oldest != "" {
+		delete(c.entries, oldest)
+	}
+}
+
+func (c *attemptCounter) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
@@ -0,0 +1,48 @@
+package pubsub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttemptCounter(t *testing.T) {
+	now := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	counter := newAttemptCounter(time.Minute, 2)
+	counter.now = func() time.Time { return now }
+
+	assert.Equal(t, 1, counter.Add("a"))
+	assert.Equal(t, 2, counter.Add("a"))
+
+	// Acked messages are forgotten
+	counter.Forget("a")
+	assert.Equal(t, 1, counter.Add("a"))
+
+	// A message not seen within the TTL starts over
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, 1, counter.Add("a"))
+
+	// The counter stays bounded, evicting the entry that expires first
+	now = now.Add(time.Second)
+	assert.Equal(t, 1, counter.Add("b"))
+	now = now.Add(time.Second)
+	assert.Equal(t, 1, counter.Add("c"))
+	assert.Equal(t, 2, counter.Len())
+	assert.Equal(t, 2, counter.Add("c"))
+	assert.Equal(t, 2, counter.Add("b"))
+	assert.Equal(t, 1, counter.Add("a"))
+}
+
+func TestAttemptCounter_PerSubscription(t *testing.T) {
+	counter := newAttemptCounter(time.Minute, 10)
+
+	// A message is counted separately on each subscription of the topic
+	assert.Equal(t, 1, counter.Add(attemptKey("shared", "m1")))
+	assert.Equal(t, 2, counter.Add(attemptKey("shared", "m1")))
+	assert.Equal(t, 1, counter.Add(attemptKey("replica", "m1")))
+
+	// Acking it on one subscription keeps the other's count
+	counter.Forget(attemptKey("replica", "m1"))
+	assert.Equal(t, 3, counter.Add(attemptKey("shared", "m1")))
+}
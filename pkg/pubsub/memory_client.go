@@ -0,0 +1,199 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const defaultMemoryBufferSize = 256
+
+var (
+	ErrClientClosed = errors.New("pubsub client is closed")
+)
+
+// memoryMessage is a published message waiting in a subscription
+type memoryMessage struct {
+	data    []byte
+	attempt int
+}
+
+// memorySubscription buffers the messages of one subscription. Receivers of
+// the same subscription share its messages, as they do in Cloud Pub/Sub.
+type memorySubscription struct {
+	messages chan memoryMessage
+}
+
+// memoryClient is an in-process PubSubClient for local development and tests.
+// Every published message is delivered to every subscription as JSON, like
+// the Cloud Pub/Sub client delivers it.
+type memoryClient struct {
+	mu            sync.Mutex
+	subscriptions map[string]*memorySubscription
+	options       options
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+func NewMemoryClient(opts ...Option) PubSubClient {
+	return &memoryClient{
+		subscriptions: make(map[string]*memorySubscription),
+		options:       newOptions(opts),
+		done:          make(chan struct{}),
+	}
+}
+
+// Publish fans the message out to every subscription. Like a Cloud Pub/Sub
+// topic, messages published before any subscription exists are dropped.
+func (c *memoryClient) Publish(ctx context.Context, data interface{}) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-c.done:
+		return ErrClientClosed
+	default:
+	}
+
+	c.mu.Lock()
+	subs := make([]*memorySubscription, 0, len(c.subscriptions))
+	for _, sub := range c.subscriptions {
+		subs = append(subs, sub)
+	}
+	c.mu.Unlock()
+
+	msg := memoryMessage{data: jsonData, attempt: 1}
+	for _, sub := range subs {
+		if c.options.deliveryDelay > 0 {
+			go c.enqueueAfter(sub, msg, c.options.deliveryDelay)
+			continue
+		}
+		if err := c.enqueue(ctx, sub, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribe delivers the subscription's messages to handler until ctx is
+// cancelled or the client is closed. A message is acked when handler returns
+// nil. It is nacked when handler fails or panics, and redelivered with
+// exponential backoff until the retry policy is exhausted or handler returns
+// a Permanent error, when it is dead-lettered.
+func (c *memoryClient) Subscribe(ctx context.Context, subscriptionID string, handler func(data []byte) error) error {
+	return c.receive(ctx, subscriptionID, true, handler)
+}
+
+// receive delivers the messages of the subscription to handler. Failed
+// messages go to the DeadLetterSink when deadLetter is set and are dropped
+// otherwise.
+func (c *memoryClient) receive(ctx context.Context, subscriptionID string, deadLetter bool, handler func(data []byte) error) error {
+	sub := c.subscription(subscriptionID)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-c.done:
+			return nil
+		case msg := <-sub.messages:
+			err := c.deliver(msg, handler)
+			if err == nil {
+				continue
+			}
+
+			delay := c.options.retry.Backoff(msg.attempt)
+			if IsPermanent(err) || c.options.retry.Exhausted(msg.attempt) {
+				if !deadLetter {
+					log.Printf("Dropping message from %s after %d attempts: %v", subscriptionID, msg.attempt, err)
+					continue
+				}
+				if c.options.deadLetter(ctx, subscriptionID, msg.data, msg.attempt, err) == nil {
+					continue
+				}
+				delay = c.options.retry.MaxBackoff
+			}
+
+			msg.attempt++
+			go c.enqueueAfter(sub, msg, delay)
+		}
+	}
+}
+
+// SubscribeReplica is Subscribe on a subscription that is removed when it
+// returns. Failed messages are dropped instead of dead-lettered.
+func (c *memoryClient) SubscribeReplica(ctx context.Context, subscriptionID string, handler func(data []byte) error) error {
+	defer func() {
+		c.mu.Lock()
+		delete(c.subscriptions, subscriptionID)
+		c.mu.Unlock()
+	}()
+	return c.receive(ctx, subscriptionID, false, handler)
+}
+
+func (c *memoryClient) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+// subscription returns the named subscription, creating it on first use
+func (c *memoryClient) subscription(subscriptionID string) *memorySubscription {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sub, ok := c.subscriptions[subscriptionID]
+	if !ok {
+		sub = &memorySubscription{messages: make(chan memoryMessage, c.options.bufferSize)}
+		c.subscriptions[subscriptionID] = sub
+	}
+	return sub
+}
+
+// deliver runs handler on the message and returns why it should be nacked
+func (c *memoryClient) deliver(msg memoryMessage, handler func(data []byte) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+
+	if err := handler(msg.data); err != nil {
+		log.Printf("Handler failed on delivery attempt %d: %v", msg.attempt, err)
+		return err
+	}
+	return nil
+}
+
+func (c *memoryClient) enqueue(ctx context.Context, sub *memorySubscription, msg memoryMessage) error {
+	select {
+	case sub.messages <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		return ErrClientClosed
+	}
+}
+
+func (c *memoryClient) enqueueAfter(sub *memorySubscription, msg memoryMessage, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-c.done:
+		return
+	}
+
+	select {
+	case sub.messages <- msg:
+	case <-c.done:
+	}
+}
@@ -0,0 +1,273 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receive subscribes in the background and returns the channel messages
+// arrive on once handler accepts them
+func receive(ctx context.Context, t *testing.T, client PubSubClient, subscriptionID string, handler func(data []byte) error) <-chan string {
+	t.Helper()
+	events := make(chan string, 10)
+	go client.Subscribe(ctx, subscriptionID, func(data []byte) error {
+		if handler != nil {
+			if err := handler(data); err != nil {
+				return err
+			}
+		}
+		events <- string(data)
+		return nil
+	})
+
+	// Wait until the subscription exists so nothing is published before it
+	require.Eventually(t, func() bool {
+		c := client.(*memoryClient)
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		_, ok := c.subscriptions[subscriptionID]
+		return ok
+	}, time.Second, time.Millisecond)
+	return events
+}
+
+func waitFor(t *testing.T, events <-chan string) string {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for event")
+		return ""
+	}
+}
+
+type recordingSink struct {
+	mu      sync.Mutex
+	letters []DeadLetter
+	err     error
+}
+
+func (s *recordingSink) Put(ctx context.Context, letter DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.letters = append(s.letters, letter)
+	return nil
+}
+
+func (s *recordingSink) stored() []DeadLetter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]DeadLetter(nil), s.letters...)
+}
+
+var fastRetries = RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+func TestMemoryClient_PublishSubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := NewMemoryClient()
+	defer client.Close()
+	events := receive(ctx, t, client, "sub", nil)
+
+	err := client.Publish(ctx, map[string]interface{}{"type": "POST", "count": 1})
+	assert.NoError(t, err)
+
+	// Events arrive JSON encoded, as they do from Cloud Pub/Sub
+	assert.JSONEq(t, `{"type":"POST","count":1}`, waitFor(t, events))
+}
+
+func TestMemoryClient_FanOut(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := NewMemoryClient()
+	defer client.Close()
+	first := receive(ctx, t, client, "first", nil)
+	second := receive(ctx, t, client, "second", nil)
+
+	assert.NoError(t, client.Publish(ctx, "hello"))
+
+	assert.Equal(t, `"hello"`, waitFor(t, first))
+	assert.Equal(t, `"hello"`, waitFor(t, second))
+}
+
+func TestMemoryClient_NoSubscriptionDropsMessage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := NewMemoryClient()
+	defer client.Close()
+	assert.NoError(t, client.Publish(ctx, "lost"))
+
+	events := receive(ctx, t, client, "late", nil)
+	assert.NoError(t, client.Publish(ctx, "kept"))
+	assert.Equal(t, `"kept"`, waitFor(t, events))
+}
+
+func TestMemoryClient_NackRedelivers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sink := &recordingSink{}
+	client := NewMemoryClient(WithRetryPolicy(fastRetries), WithDeadLetterSink(sink))
+	defer client.Close()
+
+	var mu sync.Mutex
+	attempts := 0
+	events := receive(ctx, t, client, "sub", func(data []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		switch attempts {
+		case 1:
+			return errors.New("handler failed")
+		case 2:
+			panic("handler panicked")
+		}
+		return nil
+	})
+
+	assert.NoError(t, client.Publish(ctx, "retry me"))
+	assert.Equal(t, `"retry me"`, waitFor(t, events))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 3, attempts)
+	assert.Empty(t, sink.stored())
+}
+
+func TestMemoryClient_DeadLetter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sink := &recordingSink{}
+	client := NewMemoryClient(WithRetryPolicy(fastRetries), WithDeadLetterSink(sink))
+	defer client.Close()
+
+	receive(ctx, t, client, "sub", func(data []byte) error {
+		return errors.New("always fails")
+	})
+	assert.NoError(t, client.Publish(ctx, map[string]string{"type": "POST"}))
+
+	require.Eventually(t, func() bool { return len(sink.stored()) == 1 }, 2*time.Second, time.Millisecond)
+	letter := sink.stored()[0]
+	assert.Equal(t, "sub", letter.Subscription)
+	assert.JSONEq(t, `{"type":"POST"}`, string(letter.Data))
+	assert.Equal(t, 3, letter.Attempts)
+	assert.Equal(t, "always fails", letter.Error)
+
+	// Nothing is delivered after the message was dead-lettered
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, sink.stored(), 1)
+}
+
+func TestMemoryClient_PermanentErrorDeadLettersImmediately(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sink := &recordingSink{}
+	client := NewMemoryClient(WithRetryPolicy(fastRetries), WithDeadLetterSink(sink))
+	defer client.Close()
+
+	var mu sync.Mutex
+	attempts := 0
+	receive(ctx, t, client, "sub", func(data []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		return Permanent(errors.New("malformed"))
+	})
+	assert.NoError(t, client.Publish(ctx, "broken"))
+
+	require.Eventually(t, func() bool { return len(sink.stored()) == 1 }, 2*time.Second, time.Millisecond)
+	assert.Equal(t, 1, sink.stored()[0].Attempts)
+	assert.Equal(t, "malformed", sink.stored()[0].Error)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, attempts)
+}
+
+func TestMemoryClient_ReplicaSubscriptionDropsFailedMessages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sink := &recordingSink{}
+	client := NewMemoryClient(WithRetryPolicy(fastRetries), WithDeadLetterSink(sink))
+	defer client.Close()
+
+	var mu sync.Mutex
+	attempts := 0
+	go client.SubscribeReplica(ctx, "replica", func(data []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		return errors.New("always fails")
+	})
+	require.Eventually(t, func() bool {
+		c := client.(*memoryClient)
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		_, ok := c.subscriptions["replica"]
+		return ok
+	}, time.Second, time.Millisecond)
+
+	assert.NoError(t, client.Publish(ctx, map[string]string{"type": "POST"}))
+
+	// The message is retried, then dropped without reaching the sink
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return attempts == fastRetries.MaxAttempts
+	}, 2*time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, fastRetries.MaxAttempts, attempts)
+	assert.Empty(t, sink.stored())
+}
+
+func TestMemoryClient_DeliveryDelay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := NewMemoryClient(WithDeliveryDelay(50 * time.Millisecond))
+	defer client.Close()
+	events := receive(ctx, t, client, "sub", nil)
+
+	start := time.Now()
+	assert.NoError(t, client.Publish(ctx, "later"))
+	assert.Equal(t, `"later"`, waitFor(t, events))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestMemoryClient_Close(t *testing.T) {
+	client := NewMemoryClient()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- client.Subscribe(context.Background(), "sub", func(data []byte) error { return nil })
+	}()
+
+	client.Close()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Subscribe did not return after Close")
+	}
+
+	assert.ErrorIs(t, client.Publish(context.Background(), "closed"), ErrClientClosed)
+}
@@ -10,18 +10,48 @@ import (
 	"cloud.google.com/go/pubsub"
 )
 
+// Drivers select the PubSubClient implementation
+const (
+	DriverGCP    = "gcp"
+	DriverMemory = "memory"
+)
+
 type PubSubClient interface {
 	Publish(ctx context.Context, data interface{}) error
-	Subscribe(ctx context.Context, subscriptionID string, handler func(event interface{})) error
+	// Subscribe delivers the data of messages to handler until ctx is done.
+	// Messages are acked when handler returns nil, dead-lettered when it
+	// returns a Permanent error and retried according to the client's
+	// RetryPolicy otherwise.
+	Subscribe(ctx context.Context, subscriptionID string, handler func(data []byte) error) error
+	// SubscribeReplica is Subscribe on a new subscription used by this
+	// process alone, so that every replica receives every message rather
+	// than a share of them. Messages published before it starts are not
+	// delivered, and messages that keep failing are dropped rather than
+	// dead-lettered. The subscription is deleted when ctx is done, and
+	// expires when the process dies without deleting it.
+	SubscribeReplica(ctx context.Context, subscriptionID string, handler func(data []byte) error) error
 	Close()
 }
 
+// Replica subscriptions only need messages while their replica runs. Pub/Sub
+// allows no shorter retention or expiration.
+const (
+	replicaRetention  = 10 * time.Minute
+	replicaExpiration = 24 * time.Hour
+)
+
 type pubSubClient struct {
-	client *pubsub.Client
-	topic  *pubsub.Topic
+	client  *pubsub.Client
+	topic   *pubsub.Topic
+	options options
+
+	// attempts counts deliveries per message ID for subscriptions without a
+	// dead-letter policy, where Cloud Pub/Sub does not report them. The
+	// counts are per replica.
+	attempts *attemptCounter
 }
 
-func NewPubSubClient(projectID, topicID string) (PubSubClient, error) {
+func NewPubSubClient(projectID, topicID string, opts ...Option) (PubSubClient, error) {
 	ctx := context.Background()
 	client, err := pubsub.NewClient(ctx, projectID)
 	if err != nil {
@@ -30,8 +60,10 @@ func NewPubSubClient(projectID, topicID string) (PubSubClient, error) {
 
 	topic := client.Topic(topicID)
 	return &pubSubClient{
-		client: client,
-		topic:  topic,
+		client:   client,
+		topic:    topic,
+		options:  newOptions(opts),
+		attempts: newAttemptCounter(attemptTTL, maxTrackedMessages),
 	}, nil
 }
 
@@ -51,41 +83,154 @@ func (p *pubSubClient) Publish(ctx context.Context, data interface{}) error {
 	return err
 }
 
-func (p *pubSubClient) Subscribe(ctx context.Context, subscriptionID string, handler func(event interface{})) error {
+func (p *pubSubClient) Subscribe(ctx context.Context, subscriptionID string, handler func(data []byte) error) error {
+	sub, err := p.subscription(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+	return p.receive(ctx, sub, subscriptionID, true, handler)
+}
+
+// SubscribeReplica creates the subscription without a dead-letter policy:
+// its messages are also delivered on the shared subscription, which
+// dead-letters them. Messages that fail here are dropped once the retry
+// policy is exhausted rather than handed to the DeadLetterSink, since
+// re-driving them would publish them to every consumer again.
+func (p *pubSubClient) SubscribeReplica(ctx context.Context, subscriptionID string, handler func(data []byte) error) error {
+	sub, err := p.client.CreateSubscription(ctx, subscriptionID, pubsub.SubscriptionConfig{
+		Topic:             p.topic,
+		AckDeadline:       20 * time.Second,
+		RetentionDuration: replicaRetention,
+		ExpirationPolicy:  replicaExpiration,
+		RetryPolicy:       p.retryPolicy(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create subscription: %w", err)
+	}
+	defer func() {
+		deleteCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := sub.Delete(deleteCtx); err != nil {
+			log.Printf("Warning: failed to delete subscription %s, it expires after %s unused: %v", subscriptionID, replicaExpiration, err)
+		}
+	}()
+	return p.receive(ctx, sub, subscriptionID, false, handler)
+}
+
+// receive delivers the messages of sub to handler until ctx is done. Failed
+// messages go to the DeadLetterSink when deadLetter is set and are dropped
+// otherwise.
+func (p *pubSubClient) receive(ctx context.Context, sub *pubsub.Subscription, subscriptionID string, deadLetter bool, handler func(data []byte) error) error {
+	return sub.Receive(ctx, func(msgCtx context.Context, msg *pubsub.Message) {
+		key := attemptKey(subscriptionID, msg.ID)
+		err := handler(msg.Data)
+		if err == nil {
+			p.attempts.Forget(key)
+			msg.Ack()
+			return
+		}
+
+		attempt := p.deliveryAttempt(key, msg)
+		log.Printf("Handler failed on delivery attempt %d: %v", attempt, err)
+		if IsPermanent(err) || p.options.retry.Exhausted(attempt) {
+			if !deadLetter {
+				log.Printf("Dropping message from %s after %d attempts: %v", subscriptionID, attempt, err)
+				p.attempts.Forget(key)
+				msg.Ack()
+				return
+			}
+			if p.options.deadLetter(msgCtx, subscriptionID, msg.Data, attempt, err) == nil {
+				p.attempts.Forget(key)
+				msg.Ack()
+				return
+			}
+		}
+
+		// The subscription's retry policy backs off before redelivering
+		msg.Nack()
+	})
+}
+
+// subscription returns the subscription, creating it if it does not exist.
+// An existing subscription without a retry policy would redeliver nacked
+// messages at once, so the client's policy is added to it, and so is the
+// dead-letter policy when the client has a dead-letter topic.
+func (p *pubSubClient) subscription(ctx context.Context, subscriptionID string) (*pubsub.Subscription, error) {
 	sub := p.client.Subscription(subscriptionID)
 
-	// Check if subscription exists
 	exists, err := sub.Exists(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to check subscription: %w", err)
+		return nil, fmt.Errorf("failed to check subscription: %w", err)
 	}
 
 	if !exists {
-		// Create new subscription if not exists
 		sub, err = p.client.CreateSubscription(ctx, subscriptionID, pubsub.SubscriptionConfig{
 			Topic:            p.topic,
 			AckDeadline:      20 * time.Second,
 			ExpirationPolicy: 25 * time.Hour,
+			RetryPolicy:      p.retryPolicy(),
+			DeadLetterPolicy: p.deadLetterPolicy(),
 		})
 		if err != nil {
-			return fmt.Errorf("failed to create subscription: %w", err)
+			return nil, fmt.Errorf("failed to create subscription: %w", err)
 		}
+		return sub, nil
 	}
 
-	// Start receiving messages
-	return sub.Receive(ctx, func(msgCtx context.Context, msg *pubsub.Message) {
-		var event interface{}
+	cfg, err := sub.Config(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subscription: %w", err)
+	}
 
-		if err := json.Unmarshal(msg.Data, &event); err != nil {
-			log.Printf("Failed to unmarshal message: %v", err)
-			msg.Nack()
-			return
+	var update pubsub.SubscriptionConfigToUpdate
+	if cfg.RetryPolicy == nil {
+		update.RetryPolicy = p.retryPolicy()
+	}
+	if cfg.DeadLetterPolicy == nil {
+		update.DeadLetterPolicy = p.deadLetterPolicy()
+	}
+	if update.RetryPolicy != nil || update.DeadLetterPolicy != nil {
+		if _, err := sub.Update(ctx, update); err != nil {
+			log.Printf("Warning: failed to set the retry policy of subscription %s, failed messages may be redelivered without backoff: %v", subscriptionID, err)
 		}
+	}
+	return sub, nil
+}
 
-		// Execute handler
-		handler(event)
-		msg.Ack()
-	})
+func (p *pubSubClient) retryPolicy() *pubsub.RetryPolicy {
+	return &pubsub.RetryPolicy{
+		MinimumBackoff: p.options.retry.MinBackoff,
+		MaximumBackoff: p.options.retry.MaxBackoff,
+	}
+}
+
+// deadLetterPolicy forwards messages to the dead-letter topic once they
+// exhausted the retry policy, within the 5 to 100 attempts Pub/Sub allows
+func (p *pubSubClient) deadLetterPolicy() *pubsub.DeadLetterPolicy {
+	if p.options.deadLetterTopic == "" {
+		return nil
+	}
+
+	attempts := p.options.retry.MaxAttempts
+	if attempts == 0 {
+		attempts = 100
+	}
+	return &pubsub.DeadLetterPolicy{
+		DeadLetterTopic:     fmt.Sprintf("projects/%s/topics/%s", p.client.Project(), p.options.deadLetterTopic),
+		MaxDeliveryAttempts: min(max(attempts, 5), 100),
+	}
+}
+
+// deliveryAttempt returns which delivery of the message this is. Pub/Sub
+// reports it on subscriptions with a dead-letter policy; otherwise it is
+// counted in this process, so with several replicas a message can be
+// delivered more often than the retry policy allows before it is
+// dead-lettered.
+func (p *pubSubClient) deliveryAttempt(key string, msg *pubsub.Message) int {
+	if msg.DeliveryAttempt != nil {
+		return *msg.DeliveryAttempt
+	}
+	return p.attempts.Add(key)
 }
 
 func (p *pubSubClient) Close() {
@@ -0,0 +1,171 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+)
+
+// RetryPolicy decides how often and how quickly a message whose handler
+// failed is delivered again
+type RetryPolicy struct {
+	// MaxAttempts is the number of deliveries before a message is dead-lettered
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
+
+// DefaultRetryPolicy retries a failing message 5 times, waiting from 1 second
+// up to 1 minute between attempts
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		MinBackoff:  time.Second,
+		MaxBackoff:  time.Minute,
+	}
+}
+
+// Backoff returns how long to wait before delivering the message again after
+// the given failed attempt. The wait doubles with every attempt.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := p.MinBackoff
+	for i := 1; i < attempt && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, p.MaxBackoff)
+}
+
+// Exhausted reports whether a message that failed on the given attempt should
+// be dead-lettered instead of retried
+func (p RetryPolicy) Exhausted(attempt int) bool {
+	return p.MaxAttempts > 0 && attempt >= p.MaxAttempts
+}
+
+// permanentError is a handler failure that redelivery cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks a handler error as one that retrying cannot fix, such as a
+// malformed message. The message is dead-lettered without further attempts.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether the error, or one it wraps, was marked Permanent
+func IsPermanent(err error) bool {
+	var permanent permanentError
+	return errors.As(err, &permanent)
+}
+
+// DeadLetter is a message that failed on every delivery attempt
+type DeadLetter struct {
+	Subscription string
+	Data         json.RawMessage
+	Attempts     int
+	Error        string
+	FailedAt     time.Time
+}
+
+// DeadLetterSink stores messages the retry policy gave up on
+type DeadLetterSink interface {
+	Put(ctx context.Context, letter DeadLetter) error
+}
+
+type options struct {
+	retry           RetryPolicy
+	deadLetters     DeadLetterSink
+	deadLetterTopic string
+	bufferSize      int
+	deliveryDelay   time.Duration
+}
+
+// Option configures a PubSubClient. Options that only apply to one
+// implementation are ignored by the others.
+type Option func(*options)
+
+func newOptions(opts []Option) options {
+	o := options{
+		retry:      DefaultRetryPolicy(),
+		bufferSize: defaultMemoryBufferSize,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = policy
+	}
+}
+
+// WithDeadLetterSink stores messages that exhausted the retry policy. Without
+// a sink they are logged and dropped.
+func WithDeadLetterSink(sink DeadLetterSink) Option {
+	return func(o *options) {
+		o.deadLetters = sink
+	}
+}
+
+// WithDeadLetterTopic gives the Cloud Pub/Sub subscriptions a dead-letter
+// policy forwarding to the topic, so Pub/Sub counts delivery attempts across
+// replicas. Messages are still handed to the DeadLetterSink after MaxAttempts;
+// the topic only receives those the sink failed to store.
+func WithDeadLetterTopic(topicID string) Option {
+	return func(o *options) {
+		o.deadLetterTopic = topicID
+	}
+}
+
+// WithBufferSize sets how many undelivered messages an in-memory subscription
+// holds before Publish blocks
+func WithBufferSize(size int) Option {
+	return func(o *options) {
+		if size > 0 {
+			o.bufferSize = size
+		}
+	}
+}
+
+// WithDeliveryDelay delays every in-memory delivery, to mimic the latency of
+// a real broker
+func WithDeliveryDelay(delay time.Duration) Option {
+	return func(o *options) {
+		if delay >= 0 {
+			o.deliveryDelay = delay
+		}
+	}
+}
+
+// deadLetter hands a message that exhausted the retry policy to the sink. If
+// the sink fails the message must be kept and retried.
+func (o options) deadLetter(ctx context.Context, subscription string, data []byte, attempts int, cause error) error {
+	log.Printf("Dead-lettering message from %s after %d attempts: %v", subscription, attempts, cause)
+	if o.deadLetters == nil {
+		return nil
+	}
+
+	letter := DeadLetter{
+		Subscription: subscription,
+		Data:         json.RawMessage(data),
+		Attempts:     attempts,
+		Error:        cause.Error(),
+		FailedAt:     time.Now().UTC(),
+	}
+	if err := o.deadLetters.Put(ctx, letter); err != nil {
+		log.Printf("Failed to store dead letter: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package pubsub
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, MinBackoff: time.Second, MaxBackoff: 10 * time.Second}
+
+	assert.Equal(t, time.Second, policy.Backoff(1))
+	assert.Equal(t, 2*time.Second, policy.Backoff(2))
+	assert.Equal(t, 4*time.Second, policy.Backoff(3))
+	assert.Equal(t, 8*time.Second, policy.Backoff(4))
+	assert.Equal(t, 10*time.Second, policy.Backoff(5))
+	assert.Equal(t, 10*time.Second, policy.Backoff(50))
+}
+
+func TestRetryPolicy_Exhausted(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3}
+	assert.False(t, policy.Exhausted(2))
+	assert.True(t, policy.Exhausted(3))
+
+	unlimited := RetryPolicy{}
+	assert.False(t, unlimited.Exhausted(100))
+}
+
+func TestPermanent(t *testing.T) {
+	cause := errors.New("malformed")
+	err := fmt.Errorf("dispatch: %w", Permanent(cause))
+
+	assert.True(t, IsPermanent(err))
+	assert.ErrorIs(t, err, cause)
+	assert.False(t, IsPermanent(cause))
+	assert.NoError(t, Permanent(nil))
+}
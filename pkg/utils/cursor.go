@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// PageCursor is the position of the last document of a page in a listing
+// ordered by created_at and document ID
+type PageCursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        string    `json:"i"`
+}
+
+// EncodeCursor encodes the position as base64 JSON so clients treat it as
+// an opaque token
+func EncodeCursor(createdAt time.Time, id string) string {
+	data, _ := json.Marshal(PageCursor{CreatedAt: createdAt, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a token produced by EncodeCursor
+func DecodeCursor(value string) (PageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return PageCursor{}, ErrInvalidCursor
+	}
+
+	var c PageCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return PageCursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2025, 2, 1, 10, 30, 0, 123, time.UTC)
+
+	token := EncodeCursor(createdAt, "doc-1")
+	c, err := DecodeCursor(token)
+
+	assert.NoError(t, err)
+	assert.True(t, createdAt.Equal(c.CreatedAt))
+	assert.Equal(t, "doc-1", c.ID)
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	for _, value := range []string{"not base64!", "bm90IGpzb24", EncodeCursor(time.Now(), "")} {
+		_, err := DecodeCursor(value)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	}
+}
@@ -20,6 +20,9 @@ var (
 	ErrInvalidDevice         = errors.New("invalid device")
 )
 
+// AccessTokenTTL is the lifetime of an access token
+const AccessTokenTTL = 15 * time.Minute
+
 type Claims struct {
 	jwt.RegisteredClaims
 	UserID    string `json:"userId"`
@@ -68,7 +71,7 @@ func NewJWT(blacklist TokenBlacklist) (JWT, error) {
 
 func (t *jwtToken) GenerateToken(userID string, fingerprint *TokenFingerprint) (string, error) {
 	now := time.Now()
-	expirationTime := time.Now().Add(15 * time.Minute)
+	expirationTime := time.Now().Add(AccessTokenTTL)
 
 	tokenID, err := generateTokenID()
 	if err != nil {
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const blacklistLookupTimeout = 3 * time.Second
+
+type blacklistEntry struct {
+	ExpiresAt time.Time `firestore:"expires_at"`
+}
+
+// FirestoreBlacklist is a TokenBlacklist shared by every replica. Entries are
+// keyed by token ID; expired entries are removed by Cleanup (a Firestore TTL
+// policy on expires_at can be enabled as well).
+type FirestoreBlacklist struct {
+	client     *firestore.Client
+	collection string
+}
+
+func NewFirestoreBlacklist(client *firestore.Client) *FirestoreBlacklist {
+	collection := os.Getenv("GOOGLE_CLOUD_FIRESTORE_COLLECTION_TOKEN_BLACKLIST")
+	if collection == "" {
+		collection = "token_blacklist"
+	}
+	return &FirestoreBlacklist{
+		client:     client,
+		collection: collection,
+	}
+}
+
+func (b *FirestoreBlacklist) Add(tokenID string, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), blacklistLookupTimeout)
+	defer cancel()
+
+	_, err := b.client.Collection(b.collection).
+		Doc(tokenID).
+		Set(ctx, blacklistEntry{ExpiresAt: expiresAt})
+
+	return err
+}
+
+// IsBlacklisted fails closed: if the lookup itself fails the token is
+// treated as revoked.
+func (b *FirestoreBlacklist) IsBlacklisted(tokenID string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), blacklistLookupTimeout)
+	defer cancel()
+
+	doc, err := b.client.Collection(b.collection).Doc(tokenID).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return false
+	}
+	if err != nil {
+		log.Printf("Error checking token blacklist: %v", err)
+		return true
+	}
+
+	var entry blacklistEntry
+	if err := doc.DataTo(&entry); err != nil {
+		log.Printf("Error reading token blacklist entry: %v", err)
+		return true
+	}
+	return time.Now().Before(entry.ExpiresAt)
+}
+
+func (b *FirestoreBlacklist) Cleanup() error {
+	ctx := context.Background()
+	docs, err := b.client.Collection(b.collection).
+		Where("expires_at", "<", time.Now()).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return err
+	}
+
+	if len(docs) == 0 {
+		return nil
+	}
+
+	bw := b.client.BulkWriter(ctx)
+	for _, doc := range docs {
+		if _, err := bw.Delete(doc.Ref); err != nil {
+			bw.End()
+			return err
+		}
+	}
+	bw.End()
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ynwd/awesome-blog/tests/helper"
+)
+
+func TestFirestoreBlacklist(t *testing.T) {
+	client := helper.SetupRepoClient(t)
+
+	err := helper.CleanDatabase()
+	assert.NoError(t, err)
+	defer func() {
+		helper.CleanDatabase()
+		client.Close()
+	}()
+
+	bl := NewFirestoreBlacklist(client)
+
+	assert.False(t, bl.IsBlacklisted("token-1"))
+
+	assert.NoError(t, bl.Add("token-1", time.Now().Add(time.Hour)))
+	assert.NoError(t, bl.Add("token-2", time.Now().Add(-time.Minute)))
+	assert.True(t, bl.IsBlacklisted("token-1"))
+
+	// An expired entry no longer revokes the token
+	assert.False(t, bl.IsBlacklisted("token-2"))
+
+	// Cleanup removes expired entries only
+	assert.NoError(t, bl.Cleanup())
+	_, err = client.Collection(bl.collection).Doc("token-2").Get(context.Background())
+	assert.Error(t, err)
+	assert.True(t, bl.IsBlacklisted("token-1"))
+}
+
+func TestFirestoreBlacklist_FailsClosed(t *testing.T) {
+	client := helper.SetupRepoClient(t)
+	bl := NewFirestoreBlacklist(client)
+
+	// A lookup that cannot reach Firestore treats the token as revoked
+	client.Close()
+	assert.True(t, bl.IsBlacklisted("token-1"))
+	assert.Error(t, bl.Add("token-1", time.Now().Add(time.Hour)))
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrPasswordMismatch = errors.New("password does not match")
+)
+
+// PasswordHasher hashes and verifies user passwords
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Compare(hash, password string) error
+	NeedsRehash(hash string) bool
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+// NewPasswordHasher creates a bcrypt hasher using the cost from PASSWORD_HASH_COST,
+// falling back to bcrypt.DefaultCost when it is unset or out of range
+func NewPasswordHasher() PasswordHasher {
+	cost, err := strconv.Atoi(os.Getenv("PASSWORD_HASH_COST"))
+	if err != nil {
+		cost = bcrypt.DefaultCost
+	}
+	return NewBcryptHasher(cost)
+}
+
+// NewBcryptHasher creates a bcrypt hasher with the given cost
+func NewBcryptHasher(cost int) PasswordHasher {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = bcrypt.DefaultCost
+	}
+	return &bcryptHasher{cost: cost}
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Compare checks the password against a bcrypt hash. Legacy records that still
+// hold the plaintext password are compared in constant time.
+func (h *bcryptHasher) Compare(hash, password string) error {
+	if !IsPasswordHash(hash) {
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(password)) != 1 {
+			return ErrPasswordMismatch
+		}
+		return nil
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrPasswordMismatch
+	}
+	return err
+}
+
+// NeedsRehash reports whether the stored value is plaintext or was hashed
+// with a different cost than the one currently configured
+func (h *bcryptHasher) NeedsRehash(hash string) bool {
+	if !IsPasswordHash(hash) {
+		return true
+	}
+	cost, _ := bcrypt.Cost([]byte(hash))
+	return cost != h.cost
+}
+
+// bcryptAlphabet is the base64 alphabet of bcrypt salts and hashes
+const bcryptAlphabet = "./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// IsPasswordHash reports whether the value is a bcrypt hash: a known version,
+// a cost bcrypt accepts and a salt and hash in its alphabet. A legacy
+// plaintext password that only resembles one is not.
+func IsPasswordHash(value string) bool {
+	if len(value) != 60 {
+		return false
+	}
+	if !strings.HasPrefix(value, "$2a$") &&
+		!strings.HasPrefix(value, "$2b$") &&
+		!strings.HasPrefix(value, "$2y$") {
+		return false
+	}
+	if _, err := bcrypt.Cost([]byte(value)); err != nil {
+		return false
+	}
+	if value[6] != '$' {
+		return false
+	}
+	for _, c := range value[7:] {
+		if !strings.ContainsRune(bcryptAlphabet, c) {
+			return false
+		}
+	}
+	return true
+}
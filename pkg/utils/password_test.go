@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBcryptHasher(t *testing.T) {
+	hasher := NewBcryptHasher(4)
+
+	hash, err := hasher.Hash("secret-password")
+	require.NoError(t, err)
+	assert.True(t, IsPasswordHash(hash))
+	assert.NotEqual(t, "secret-password", hash)
+
+	t.Run("Matching password", func(t *testing.T) {
+		assert.NoError(t, hasher.Compare(hash, "secret-password"))
+	})
+
+	t.Run("Mismatched password", func(t *testing.T) {
+		assert.ErrorIs(t, hasher.Compare(hash, "wrong-password"), ErrPasswordMismatch)
+	})
+
+	t.Run("Legacy plaintext password", func(t *testing.T) {
+		assert.NoError(t, hasher.Compare("plaintext", "plaintext"))
+		assert.ErrorIs(t, hasher.Compare("plaintext", "other"), ErrPasswordMismatch)
+	})
+
+	t.Run("Legacy plaintext password shaped like a hash", func(t *testing.T) {
+		for _, plaintext := range []string{
+			"$2a$99$" + strings.Repeat("a", 53),
+			"$2b$10$" + strings.Repeat("!", 53),
+			"$2y$1x$" + strings.Repeat("a", 53),
+		} {
+			assert.False(t, IsPasswordHash(plaintext), plaintext)
+			assert.True(t, hasher.NeedsRehash(plaintext), plaintext)
+			assert.NoError(t, hasher.Compare(plaintext, plaintext), plaintext)
+			assert.ErrorIs(t, hasher.Compare(plaintext, "other"), ErrPasswordMismatch, plaintext)
+		}
+	})
+
+	t.Run("Needs rehash", func(t *testing.T) {
+		assert.False(t, hasher.NeedsRehash(hash))
+		assert.True(t, hasher.NeedsRehash("plaintext"))
+		assert.True(t, NewBcryptHasher(5).NeedsRehash(hash))
+	})
+}
+
+func TestNewPasswordHasherCost(t *testing.T) {
+	t.Setenv("PASSWORD_HASH_COST", "5")
+	hash, err := NewPasswordHasher().Hash("secret-password")
+	require.NoError(t, err)
+	assert.False(t, NewBcryptHasher(5).NeedsRehash(hash))
+
+	t.Setenv("PASSWORD_HASH_COST", "not-a-number")
+	assert.NotNil(t, NewPasswordHasher())
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultProcessedEventsTTL outlasts the 7 day maximum message retention of
+// Cloud Pub/Sub, so a record is kept for as long as its event can be redelivered
+const DefaultProcessedEventsTTL = 7 * 24 * time.Hour
+
+// ProcessedEvents records which events each consumer has handled, so that
+// redelivered events can be skipped. Records expire after a TTL.
+type ProcessedEvents interface {
+	IsProcessed(ctx context.Context, consumer, eventID string) (bool, error)
+	MarkProcessed(ctx context.Context, consumer, eventID string) error
+	Cleanup() error
+}
+
+// ProcessedEventsTTL reads PROCESSED_EVENTS_TTL (a Go duration such as
+// "168h"), falling back to DefaultProcessedEventsTTL
+func ProcessedEventsTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("PROCESSED_EVENTS_TTL"))
+	if err != nil || ttl <= 0 {
+		return DefaultProcessedEventsTTL
+	}
+	return ttl
+}
+
+// HandleOnce runs handle unless the consumer has already processed the event,
+// and records the event as processed when handle succeeds. Events without an
+// ID, from producers that predate event IDs, are always handled. Two
+// deliveries of the same event that run at the same time may both be handled,
+// and so may a redelivery after recording the event failed, so handlers that
+// create entities create them under an ID derived from the event.
+func HandleOnce(ctx context.Context, store ProcessedEvents, consumer, eventID string, handle func() error) error {
+	if eventID == "" {
+		return handle()
+	}
+
+	processed, err := store.IsProcessed(ctx, consumer, eventID)
+	if err != nil {
+		return err
+	}
+	if processed {
+		log.Printf("Skipping event %s already processed by %s", eventID, consumer)
+		return nil
+	}
+
+	if err := handle(); err != nil {
+		return err
+	}
+	return store.MarkProcessed(ctx, consumer, eventID)
+}
+
+type MemoryProcessedEvents struct {
+	ttl     time.Duration
+	expires map[string]time.Time
+	mu      sync.RWMutex
+}
+
+func NewMemoryProcessedEvents(ttl time.Duration) *MemoryProcessedEvents {
+	return &MemoryProcessedEvents{
+		ttl:     ttl,
+		expires: make(map[string]time.Time),
+	}
+}
+
+func (p *MemoryProcessedEvents) IsProcessed(ctx context.Context, consumer, eventID string) (bool, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	expiry, exists := p.expires[processedEventKey(consumer, eventID)]
+	return exists && time.Now().Before(expiry), nil
+}
+
+func (p *MemoryProcessedEvents) MarkProcessed(ctx context.Context, consumer, eventID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.expires[processedEventKey(consumer, eventID)] = time.Now().Add(p.ttl)
+	return nil
+}
+
+func (p *MemoryProcessedEvents) Cleanup() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	for key, expiry := range p.expires {
+		if now.After(expiry) {
+			delete(p.expires, key)
+		}
+	}
+	return nil
+}
+
+func processedEventKey(consumer, eventID string) string {
+	return consumer + ":" + eventID
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type processedEventEntry struct {
+	Consumer    string    `firestore:"consumer"`
+	EventID     string    `firestore:"event_id"`
+	ProcessedAt time.Time `firestore:"processed_at"`
+	ExpiresAt   time.Time `firestore:"expires_at"`
+}
+
+// FirestoreProcessedEvents is a ProcessedEvents store shared by every
+// replica. Entries are keyed by consumer and event ID; expired entries are
+// removed by Cleanup (a Firestore TTL policy on expires_at can be enabled as well).
+type FirestoreProcessedEvents struct {
+	client     *firestore.Client
+	collection string
+	ttl        time.Duration
+}
+
+func NewFirestoreProcessedEvents(client *firestore.Client, ttl time.Duration) *FirestoreProcessedEvents {
+	collection := os.Getenv("GOOGLE_CLOUD_FIRESTORE_COLLECTION_PROCESSED_EVENTS")
+	if collection == "" {
+		collection = "processed_events"
+	}
+	return &FirestoreProcessedEvents{
+		client:     client,
+		collection: collection,
+		ttl:        ttl,
+	}
+}
+
+func (p *FirestoreProcessedEvents) IsProcessed(ctx context.Context, consumer, eventID string) (bool, error) {
+	doc, err := p.client.Collection(p.collection).Doc(processedEventKey(consumer, eventID)).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var entry processedEventEntry
+	if err := doc.DataTo(&entry); err != nil {
+		return false, err
+	}
+	return time.Now().Before(entry.ExpiresAt), nil
+}
+
+func (p *FirestoreProcessedEvents) MarkProcessed(ctx context.Context, consumer, eventID string) error {
+	now := time.Now()
+	_, err := p.client.Collection(p.collection).
+		Doc(processedEventKey(consumer, eventID)).
+		Set(ctx, processedEventEntry{
+			Consumer:    consumer,
+			EventID:     eventID,
+			ProcessedAt: now,
+			ExpiresAt:   now.Add(p.ttl),
+		})
+	return err
+}
+
+func (p *FirestoreProcessedEvents) Cleanup() error {
+	ctx := context.Background()
+	docs, err := p.client.Collection(p.collection).
+		Where("expires_at", "<", time.Now()).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return err
+	}
+
+	if len(docs) == 0 {
+		return nil
+	}
+
+	bw := p.client.BulkWriter(ctx)
+	for _, doc := range docs {
+		if _, err := bw.Delete(doc.Ref); err != nil {
+			bw.End()
+			return err
+		}
+	}
+	bw.End()
+
+	return nil
+}
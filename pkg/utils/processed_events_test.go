@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryProcessedEvents(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryProcessedEvents(time.Hour)
+
+	processed, err := store.IsProcessed(ctx, "posts", "event-1")
+	assert.NoError(t, err)
+	assert.False(t, processed)
+
+	assert.NoError(t, store.MarkProcessed(ctx, "posts", "event-1"))
+
+	processed, _ = store.IsProcessed(ctx, "posts", "event-1")
+	assert.True(t, processed)
+
+	// Each consumer tracks its own events
+	processed, _ = store.IsProcessed(ctx, "summary", "event-1")
+	assert.False(t, processed)
+}
+
+func TestMemoryProcessedEventsExpiry(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryProcessedEvents(10 * time.Millisecond)
+
+	assert.NoError(t, store.MarkProcessed(ctx, "posts", "event-1"))
+	time.Sleep(20 * time.Millisecond)
+
+	processed, _ := store.IsProcessed(ctx, "posts", "event-1")
+	assert.False(t, processed)
+
+	assert.NoError(t, store.Cleanup())
+	assert.Empty(t, store.expires)
+}
+
+func TestHandleOnce(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("redelivered event is handled once", func(t *testing.T) {
+		store := NewMemoryProcessedEvents(time.Hour)
+		calls := 0
+		handle := func() error {
+			calls++
+			return nil
+		}
+
+		assert.NoError(t, HandleOnce(ctx, store, "posts", "event-1", handle))
+		assert.NoError(t, HandleOnce(ctx, store, "posts", "event-1", handle))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("failed event is handled again", func(t *testing.T) {
+		store := NewMemoryProcessedEvents(time.Hour)
+		calls := 0
+		handle := func() error {
+			calls++
+			if calls == 1 {
+				return errors.New("handler failed")
+			}
+			return nil
+		}
+
+		assert.Error(t, HandleOnce(ctx, store, "posts", "event-1", handle))
+		assert.NoError(t, HandleOnce(ctx, store, "posts", "event-1", handle))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("event without ID is always handled", func(t *testing.T) {
+		store := NewMemoryProcessedEvents(time.Hour)
+		calls := 0
+		handle := func() error {
+			calls++
+			return nil
+		}
+
+		assert.NoError(t, HandleOnce(ctx, store, "posts", "", handle))
+		assert.NoError(t, HandleOnce(ctx, store, "posts", "", handle))
+		assert.Equal(t, 2, calls)
+	})
+}
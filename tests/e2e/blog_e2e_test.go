@@ -42,6 +42,7 @@ func TestBlogServiceFlow(t *testing.T) {
 	userName := utils.GenerateRandomString(10)
 	password := "Test123!"
 	var authToken string
+	var refreshToken string
 	var postID string
 
 	// Create Tenant Owner
@@ -72,8 +73,29 @@ func TestBlogServiceFlow(t *testing.T) {
 		var response map[string]interface{}
 		json.Unmarshal(w.Body.Bytes(), &response)
 
-		authToken = response["data"].(string)
+		tokens := response["data"].(map[string]interface{})
+		authToken = tokens["access_token"].(string)
+		refreshToken = tokens["refresh_token"].(string)
 		assert.NotEmpty(t, authToken)
+		assert.NotEmpty(t, refreshToken)
+	})
+
+	t.Run("Refresh Token", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"refresh_token": refreshToken,
+		}
+		w := helper.PerformRequest(testApp.Router(), "POST", "/api/v1/auth/refresh", payload, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+
+		tokens := response["data"].(map[string]interface{})
+		authToken = tokens["access_token"].(string)
+
+		// The rotated refresh token can no longer be used
+		w = helper.PerformRequest(testApp.Router(), "POST", "/api/v1/auth/refresh", payload, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
 	})
 
 	t.Run("Invalid Login", func(t *testing.T) {
@@ -36,7 +36,7 @@ func CleanDatabase() error {
 	if err != nil {
 		return fmt.Errorf("failed to get firestore client: %v", err)
 	}
-	collections := []string{"users", "posts", "comments", "likes"}
+	collections := []string{"users", "posts", "comments", "likes", "activity_counters", "activity_counted_posts", "activity_counted_comments", "activity_counted_likes", "dead_letters", "processed_events", "outbox", "operations", "webhooks", "webhook_deliveries", "notifications", "notification_preferences", "follows", "tags", "token_blacklist"}
 	for _, col := range collections {
 		docs, err := client.Collection(col).Documents(ctx).GetAll()
 		if err != nil {
@@ -0,0 +1,34 @@
+package helper
+
+import (
+	"context"
+
+	"github.com/ynwd/awesome-blog/pkg/module"
+)
+
+type MockOperationTracker struct {
+	StartFunc   func(ctx context.Context, eventID string, eventType module.EventType, username string) error
+	SucceedFunc func(ctx context.Context, eventID, resourceID string) error
+	FailFunc    func(ctx context.Context, eventID, reason string) error
+}
+
+func (m *MockOperationTracker) Start(ctx context.Context, eventID string, eventType module.EventType, username string) error {
+	if m.StartFunc != nil {
+		return m.StartFunc(ctx, eventID, eventType, username)
+	}
+	return nil
+}
+
+func (m *MockOperationTracker) Succeed(ctx context.Context, eventID, resourceID string) error {
+	if m.SucceedFunc != nil {
+		return m.SucceedFunc(ctx, eventID, resourceID)
+	}
+	return nil
+}
+
+func (m *MockOperationTracker) Fail(ctx context.Context, eventID, reason string) error {
+	if m.FailFunc != nil {
+		return m.FailFunc(ctx, eventID, reason)
+	}
+	return nil
+}
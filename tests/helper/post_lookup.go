@@ -0,0 +1,37 @@
+package helper
+
+import "context"
+
+// MockPostLookup implements module.PostLookup. Without PostExistsFunc every
+// post exists, and without PostAuthorFunc every post is written by "author".
+type MockPostLookup struct {
+	PostExistsFunc func(ctx context.Context, postID string) (bool, error)
+	PostAuthorFunc func(ctx context.Context, postID string) (string, error)
+}
+
+func (m *MockPostLookup) PostExists(ctx context.Context, postID string) (bool, error) {
+	if m.PostExistsFunc != nil {
+		return m.PostExistsFunc(ctx, postID)
+	}
+	return true, nil
+}
+
+func (m *MockPostLookup) PostAuthor(ctx context.Context, postID string) (string, error) {
+	if m.PostAuthorFunc != nil {
+		return m.PostAuthorFunc(ctx, postID)
+	}
+	return "author", nil
+}
+
+// MockLikeCounter implements module.LikeCounter. Without LikeCountFunc every
+// post has no likes.
+type MockLikeCounter struct {
+	LikeCountFunc func(ctx context.Context, postID string) (int64, error)
+}
+
+func (m *MockLikeCounter) LikeCount(ctx context.Context, postID string) (int64, error) {
+	if m.LikeCountFunc != nil {
+		return m.LikeCountFunc(ctx, postID)
+	}
+	return 0, nil
+}
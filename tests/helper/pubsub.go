@@ -4,7 +4,7 @@ import "context"
 
 type MockPubSub struct {
 	PublishFunc   func(ctx context.Context, event interface{}) error
-	SubscribeFunc func(ctx context.Context, subscriptionID string, handler func(event interface{})) error
+	SubscribeFunc func(ctx context.Context, subscriptionID string, handler func(data []byte) error) error
 }
 
 func (m *MockPubSub) Publish(ctx context.Context, event interface{}) error {
@@ -14,7 +14,14 @@ func (m *MockPubSub) Publish(ctx context.Context, event interface{}) error {
 	return nil
 }
 
-func (m *MockPubSub) Subscribe(ctx context.Context, subscriptionID string, handler func(event interface{})) error {
+func (m *MockPubSub) Subscribe(ctx context.Context, subscriptionID string, handler func(data []byte) error) error {
+	if m.SubscribeFunc != nil {
+		return m.SubscribeFunc(ctx, subscriptionID, handler)
+	}
+	return nil
+}
+
+func (m *MockPubSub) SubscribeReplica(ctx context.Context, subscriptionID string, handler func(data []byte) error) error {
 	if m.SubscribeFunc != nil {
 		return m.SubscribeFunc(ctx, subscriptionID, handler)
 	}
@@ -0,0 +1,29 @@
+package helper
+
+import "context"
+
+// MockUserLookup implements module.UserLookup. Without UserExistsFunc every
+// user exists.
+type MockUserLookup struct {
+	UserExistsFunc func(ctx context.Context, username string) (bool, error)
+}
+
+func (m *MockUserLookup) UserExists(ctx context.Context, username string) (bool, error) {
+	if m.UserExistsFunc != nil {
+		return m.UserExistsFunc(ctx, username)
+	}
+	return true, nil
+}
+
+// MockFollowGraph implements module.FollowGraph. Without FolloweesFunc users
+// follow nobody.
+type MockFollowGraph struct {
+	FolloweesFunc func(ctx context.Context, username string) ([]string, error)
+}
+
+func (m *MockFollowGraph) Followees(ctx context.Context, username string) ([]string, error) {
+	if m.FolloweesFunc != nil {
+		return m.FolloweesFunc(ctx, username)
+	}
+	return nil, nil
+}